@@ -0,0 +1,93 @@
+package bifrost
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+)
+
+const (
+	minPolicyPort = 1
+	maxPolicyPort = 65535
+)
+
+//counterfeiter:generate . AppPolicyDesirer
+
+type AppPolicyDesirer interface {
+	Desire(policies []opi.AppNetworkPolicy) error
+	List() ([]opi.AppNetworkPolicy, error)
+	Delete(policies []opi.AppNetworkPolicy) error
+}
+
+type AppPolicy struct {
+	Desirer AppPolicyDesirer
+}
+
+func (a *AppPolicy) CreatePolicies(request cf.NetworkPoliciesRequest) error {
+	policies, err := toAppNetworkPolicies(request.Policies)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(a.Desirer.Desire(policies), "failed to desire network policies")
+}
+
+func (a *AppPolicy) ListPolicies() (cf.NetworkPoliciesResponse, error) {
+	policies, err := a.Desirer.List()
+	if err != nil {
+		return cf.NetworkPoliciesResponse{}, errors.Wrap(err, "failed to list network policies")
+	}
+
+	response := cf.NetworkPoliciesResponse{Policies: []cf.NetworkPolicy{}}
+	for _, policy := range policies {
+		response.Policies = append(response.Policies, cf.NetworkPolicy{
+			Source: cf.NetworkPolicySource{ID: policy.SourceAppGUID},
+			Destination: cf.NetworkPolicyDestination{
+				ID:       policy.DestinationAppGUID,
+				Protocol: policy.Protocol,
+				Ports:    cf.PortRange{Start: policy.Ports.Start, End: policy.Ports.End},
+			},
+		})
+	}
+
+	return response, nil
+}
+
+func (a *AppPolicy) DeletePolicies(request cf.NetworkPoliciesRequest) error {
+	policies, err := toAppNetworkPolicies(request.Policies)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(a.Desirer.Delete(policies), "failed to delete network policies")
+}
+
+func toAppNetworkPolicies(cfPolicies []cf.NetworkPolicy) ([]opi.AppNetworkPolicy, error) {
+	policies := make([]opi.AppNetworkPolicy, 0, len(cfPolicies))
+
+	for _, p := range cfPolicies {
+		protocol := strings.ToLower(p.Destination.Protocol)
+		ports := p.Destination.Ports
+
+		switch {
+		case p.Source.ID == "" || p.Destination.ID == "":
+			return nil, errors.Wrap(eirini.ErrInvalidNetworkPolicy, "source and destination ids are required")
+		case protocol != "tcp" && protocol != "udp":
+			return nil, errors.Wrapf(eirini.ErrInvalidNetworkPolicy, "unsupported protocol %q", p.Destination.Protocol)
+		case ports.Start < minPolicyPort || ports.End > maxPolicyPort || ports.Start > ports.End:
+			return nil, errors.Wrapf(eirini.ErrInvalidNetworkPolicy, "invalid port range %d-%d", ports.Start, ports.End)
+		}
+
+		policies = append(policies, opi.AppNetworkPolicy{
+			SourceAppGUID:      p.Source.ID,
+			DestinationAppGUID: p.Destination.ID,
+			Protocol:           protocol,
+			Ports:              opi.PortRange{Start: ports.Start, End: ports.End},
+		})
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,146 @@
+package bifrost_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/bifrost/bifrostfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppPolicy", func() {
+	var (
+		desirer   *bifrostfakes.FakeAppPolicyDesirer
+		appPolicy *bifrost.AppPolicy
+		request   cf.NetworkPoliciesRequest
+	)
+
+	BeforeEach(func() {
+		desirer = new(bifrostfakes.FakeAppPolicyDesirer)
+		appPolicy = &bifrost.AppPolicy{Desirer: desirer}
+		request = cf.NetworkPoliciesRequest{
+			Policies: []cf.NetworkPolicy{
+				{
+					Source: cf.NetworkPolicySource{ID: "app-a"},
+					Destination: cf.NetworkPolicyDestination{
+						ID:       "app-b",
+						Protocol: "TCP",
+						Ports:    cf.PortRange{Start: 8080, End: 8080},
+					},
+				},
+			},
+		}
+	})
+
+	Describe("CreatePolicies", func() {
+		var err error
+
+		JustBeforeEach(func() {
+			err = appPolicy.CreatePolicies(request)
+		})
+
+		It("desires the policies", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(desirer.DesireCallCount()).To(Equal(1))
+			Expect(desirer.DesireArgsForCall(0)).To(ConsistOf(opi.AppNetworkPolicy{
+				SourceAppGUID:      "app-a",
+				DestinationAppGUID: "app-b",
+				Protocol:           "tcp",
+				Ports:              opi.PortRange{Start: 8080, End: 8080},
+			}))
+		})
+
+		When("desiring fails", func() {
+			BeforeEach(func() {
+				desirer.DesireReturns(errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to desire network policies")))
+			})
+		})
+
+		When("the protocol is not supported", func() {
+			BeforeEach(func() {
+				request.Policies[0].Destination.Protocol = "icmp"
+			})
+
+			It("rejects the request", func() {
+				Expect(errors.Is(err, eirini.ErrInvalidNetworkPolicy)).To(BeTrue())
+				Expect(desirer.DesireCallCount()).To(BeZero())
+			})
+		})
+
+		When("the port range is invalid", func() {
+			BeforeEach(func() {
+				request.Policies[0].Destination.Ports = cf.PortRange{Start: 9000, End: 8000}
+			})
+
+			It("rejects the request", func() {
+				Expect(errors.Is(err, eirini.ErrInvalidNetworkPolicy)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("invalid port range 9000-8000")))
+			})
+		})
+
+		When("the destination id is missing", func() {
+			BeforeEach(func() {
+				request.Policies[0].Destination.ID = ""
+			})
+
+			It("rejects the request", func() {
+				Expect(errors.Is(err, eirini.ErrInvalidNetworkPolicy)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("ListPolicies", func() {
+		It("returns the desired policies", func() {
+			desirer.ListReturns([]opi.AppNetworkPolicy{{
+				SourceAppGUID:      "app-a",
+				DestinationAppGUID: "app-b",
+				Protocol:           "udp",
+				Ports:              opi.PortRange{Start: 53, End: 53},
+			}}, nil)
+
+			response, err := appPolicy.ListPolicies()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Policies).To(ConsistOf(cf.NetworkPolicy{
+				Source: cf.NetworkPolicySource{ID: "app-a"},
+				Destination: cf.NetworkPolicyDestination{
+					ID:       "app-b",
+					Protocol: "udp",
+					Ports:    cf.PortRange{Start: 53, End: 53},
+				},
+			}))
+		})
+
+		When("listing fails", func() {
+			It("returns an error", func() {
+				desirer.ListReturns(nil, errors.New("boom"))
+
+				_, err := appPolicy.ListPolicies()
+				Expect(err).To(MatchError(ContainSubstring("failed to list network policies")))
+			})
+		})
+	})
+
+	Describe("DeletePolicies", func() {
+		It("deletes the policies", func() {
+			Expect(appPolicy.DeletePolicies(request)).To(Succeed())
+			Expect(desirer.DeleteCallCount()).To(Equal(1))
+			Expect(desirer.DeleteArgsForCall(0)).To(HaveLen(1))
+		})
+
+		When("deleting fails", func() {
+			It("returns an error", func() {
+				desirer.DeleteReturns(errors.New("boom"))
+
+				Expect(appPolicy.DeletePolicies(request)).To(MatchError(ContainSubstring("failed to delete network policies")))
+			})
+		})
+	})
+})
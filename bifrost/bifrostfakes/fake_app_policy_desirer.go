@@ -0,0 +1,266 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bifrostfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/opi"
+)
+
+type FakeAppPolicyDesirer struct {
+	DeleteStub        func([]opi.AppNetworkPolicy) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 []opi.AppNetworkPolicy
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DesireStub        func([]opi.AppNetworkPolicy) error
+	desireMutex       sync.RWMutex
+	desireArgsForCall []struct {
+		arg1 []opi.AppNetworkPolicy
+	}
+	desireReturns struct {
+		result1 error
+	}
+	desireReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListStub        func() ([]opi.AppNetworkPolicy, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+	}
+	listReturns struct {
+		result1 []opi.AppNetworkPolicy
+		result2 error
+	}
+	listReturnsOnCall map[int]struct {
+		result1 []opi.AppNetworkPolicy
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAppPolicyDesirer) Delete(arg1 []opi.AppNetworkPolicy) error {
+	var arg1Copy []opi.AppNetworkPolicy
+	if arg1 != nil {
+		arg1Copy = make([]opi.AppNetworkPolicy, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 []opi.AppNetworkPolicy
+	}{arg1Copy})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1Copy})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeAppPolicyDesirer) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeAppPolicyDesirer) DeleteCalls(stub func([]opi.AppNetworkPolicy) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeAppPolicyDesirer) DeleteArgsForCall(i int) []opi.AppNetworkPolicy {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAppPolicyDesirer) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyDesirer) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyDesirer) Desire(arg1 []opi.AppNetworkPolicy) error {
+	var arg1Copy []opi.AppNetworkPolicy
+	if arg1 != nil {
+		arg1Copy = make([]opi.AppNetworkPolicy, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.desireMutex.Lock()
+	ret, specificReturn := fake.desireReturnsOnCall[len(fake.desireArgsForCall)]
+	fake.desireArgsForCall = append(fake.desireArgsForCall, struct {
+		arg1 []opi.AppNetworkPolicy
+	}{arg1Copy})
+	stub := fake.DesireStub
+	fakeReturns := fake.desireReturns
+	fake.recordInvocation("Desire", []interface{}{arg1Copy})
+	fake.desireMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeAppPolicyDesirer) DesireCallCount() int {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	return len(fake.desireArgsForCall)
+}
+
+func (fake *FakeAppPolicyDesirer) DesireCalls(stub func([]opi.AppNetworkPolicy) error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = stub
+}
+
+func (fake *FakeAppPolicyDesirer) DesireArgsForCall(i int) []opi.AppNetworkPolicy {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	argsForCall := fake.desireArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAppPolicyDesirer) DesireReturns(result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	fake.desireReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyDesirer) DesireReturnsOnCall(i int, result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	if fake.desireReturnsOnCall == nil {
+		fake.desireReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.desireReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyDesirer) List() ([]opi.AppNetworkPolicy, error) {
+	fake.listMutex.Lock()
+	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+	}{})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
+	fake.recordInvocation("List", []interface{}{})
+	fake.listMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppPolicyDesirer) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeAppPolicyDesirer) ListCalls(stub func() ([]opi.AppNetworkPolicy, error)) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = stub
+}
+
+func (fake *FakeAppPolicyDesirer) ListReturns(result1 []opi.AppNetworkPolicy, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []opi.AppNetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyDesirer) ListReturnsOnCall(i int, result1 []opi.AppNetworkPolicy, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	if fake.listReturnsOnCall == nil {
+		fake.listReturnsOnCall = make(map[int]struct {
+			result1 []opi.AppNetworkPolicy
+			result2 error
+		})
+	}
+	fake.listReturnsOnCall[i] = struct {
+		result1 []opi.AppNetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyDesirer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAppPolicyDesirer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bifrost.AppPolicyDesirer = new(FakeAppPolicyDesirer)
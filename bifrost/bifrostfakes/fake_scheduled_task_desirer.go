@@ -0,0 +1,414 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bifrostfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/opi"
+)
+
+type FakeScheduledTaskDesirer struct {
+	DeleteStub        func(string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DesireStub        func(string, *opi.ScheduledTask, ...k8s.DesireOption) error
+	desireMutex       sync.RWMutex
+	desireArgsForCall []struct {
+		arg1 string
+		arg2 *opi.ScheduledTask
+		arg3 []k8s.DesireOption
+	}
+	desireReturns struct {
+		result1 error
+	}
+	desireReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetStub        func(string) (*opi.ScheduledTask, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 string
+	}
+	getReturns struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}
+	ListStub        func() ([]*opi.ScheduledTask, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+	}
+	listReturns struct {
+		result1 []*opi.ScheduledTask
+		result2 error
+	}
+	listReturnsOnCall map[int]struct {
+		result1 []*opi.ScheduledTask
+		result2 error
+	}
+	UpdateStub        func(*opi.ScheduledTask) error
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 *opi.ScheduledTask
+	}
+	updateReturns struct {
+		result1 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeScheduledTaskDesirer) Delete(arg1 string) error {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskDesirer) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) DeleteCalls(stub func(string) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskDesirer) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) Desire(arg1 string, arg2 *opi.ScheduledTask, arg3 ...k8s.DesireOption) error {
+	fake.desireMutex.Lock()
+	ret, specificReturn := fake.desireReturnsOnCall[len(fake.desireArgsForCall)]
+	fake.desireArgsForCall = append(fake.desireArgsForCall, struct {
+		arg1 string
+		arg2 *opi.ScheduledTask
+		arg3 []k8s.DesireOption
+	}{arg1, arg2, arg3})
+	stub := fake.DesireStub
+	fakeReturns := fake.desireReturns
+	fake.recordInvocation("Desire", []interface{}{arg1, arg2, arg3})
+	fake.desireMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireCallCount() int {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	return len(fake.desireArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireCalls(stub func(string, *opi.ScheduledTask, ...k8s.DesireOption) error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireArgsForCall(i int) (string, *opi.ScheduledTask, []k8s.DesireOption) {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	argsForCall := fake.desireArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireReturns(result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	fake.desireReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireReturnsOnCall(i int, result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	if fake.desireReturnsOnCall == nil {
+		fake.desireReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.desireReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) Get(arg1 string) (*opi.ScheduledTask, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeScheduledTaskDesirer) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) GetCalls(stub func(string) (*opi.ScheduledTask, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskDesirer) GetReturns(result1 *opi.ScheduledTask, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) GetReturnsOnCall(i int, result1 *opi.ScheduledTask, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 *opi.ScheduledTask
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) List() ([]*opi.ScheduledTask, error) {
+	fake.listMutex.Lock()
+	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+	}{})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
+	fake.recordInvocation("List", []interface{}{})
+	fake.listMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeScheduledTaskDesirer) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) ListCalls(stub func() ([]*opi.ScheduledTask, error)) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) ListReturns(result1 []*opi.ScheduledTask, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []*opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) ListReturnsOnCall(i int, result1 []*opi.ScheduledTask, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	if fake.listReturnsOnCall == nil {
+		fake.listReturnsOnCall = make(map[int]struct {
+			result1 []*opi.ScheduledTask
+			result2 error
+		})
+	}
+	fake.listReturnsOnCall[i] = struct {
+		result1 []*opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) Update(arg1 *opi.ScheduledTask) error {
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 *opi.ScheduledTask
+	}{arg1})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateCalls(stub func(*opi.ScheduledTask) error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateArgsForCall(i int) *opi.ScheduledTask {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateReturns(result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateReturnsOnCall(i int, result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeScheduledTaskDesirer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bifrost.ScheduledTaskDesirer = new(FakeScheduledTaskDesirer)
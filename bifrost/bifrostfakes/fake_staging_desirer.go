@@ -0,0 +1,114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bifrostfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/opi"
+)
+
+type FakeStagingDesirer struct {
+	DesireStagingStub        func(string, *opi.StagingTask) error
+	desireStagingMutex       sync.RWMutex
+	desireStagingArgsForCall []struct {
+		arg1 string
+		arg2 *opi.StagingTask
+	}
+	desireStagingReturns struct {
+		result1 error
+	}
+	desireStagingReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeStagingDesirer) DesireStaging(arg1 string, arg2 *opi.StagingTask) error {
+	fake.desireStagingMutex.Lock()
+	ret, specificReturn := fake.desireStagingReturnsOnCall[len(fake.desireStagingArgsForCall)]
+	fake.desireStagingArgsForCall = append(fake.desireStagingArgsForCall, struct {
+		arg1 string
+		arg2 *opi.StagingTask
+	}{arg1, arg2})
+	stub := fake.DesireStagingStub
+	fakeReturns := fake.desireStagingReturns
+	fake.recordInvocation("DesireStaging", []interface{}{arg1, arg2})
+	fake.desireStagingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStagingDesirer) DesireStagingCallCount() int {
+	fake.desireStagingMutex.RLock()
+	defer fake.desireStagingMutex.RUnlock()
+	return len(fake.desireStagingArgsForCall)
+}
+
+func (fake *FakeStagingDesirer) DesireStagingCalls(stub func(string, *opi.StagingTask) error) {
+	fake.desireStagingMutex.Lock()
+	defer fake.desireStagingMutex.Unlock()
+	fake.DesireStagingStub = stub
+}
+
+func (fake *FakeStagingDesirer) DesireStagingArgsForCall(i int) (string, *opi.StagingTask) {
+	fake.desireStagingMutex.RLock()
+	defer fake.desireStagingMutex.RUnlock()
+	argsForCall := fake.desireStagingArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStagingDesirer) DesireStagingReturns(result1 error) {
+	fake.desireStagingMutex.Lock()
+	defer fake.desireStagingMutex.Unlock()
+	fake.DesireStagingStub = nil
+	fake.desireStagingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStagingDesirer) DesireStagingReturnsOnCall(i int, result1 error) {
+	fake.desireStagingMutex.Lock()
+	defer fake.desireStagingMutex.Unlock()
+	fake.DesireStagingStub = nil
+	if fake.desireStagingReturnsOnCall == nil {
+		fake.desireStagingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.desireStagingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStagingDesirer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.desireStagingMutex.RLock()
+	defer fake.desireStagingMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeStagingDesirer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bifrost.StagingDesirer = new(FakeStagingDesirer)
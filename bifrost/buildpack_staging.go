@@ -0,0 +1,129 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+)
+
+//counterfeiter:generate . StagingDesirer
+//counterfeiter:generate . StagingDeleter
+
+type StagingDesirer interface {
+	DesireStaging(namespace string, task *opi.StagingTask) error
+}
+
+type StagingDeleter interface {
+	Delete(guid string) (string, error)
+}
+
+type BuildpackStaging struct {
+	Logger           lager.Logger
+	Namespacer       TaskNamespacer
+	StagingDesirer   StagingDesirer
+	StagingDeleter   StagingDeleter
+	StagingCompleter StagingCompleter
+	EiriniAddress    string
+	DownloaderImage  string
+	ExecutorImage    string
+	UploaderImage    string
+}
+
+func (s BuildpackStaging) TransferStaging(ctx context.Context, stagingGUID string, request cf.StagingRequest) error {
+	logger := s.Logger.Session("transfer-staging", lager.Data{"staging-guid": stagingGUID})
+
+	lifecycle := request.Lifecycle.BuildpackLifecycle
+	if lifecycle == nil {
+		return errors.New("missing buildpack lifecycle")
+	}
+
+	buildpacksJSON, err := json.Marshal(lifecycle.Buildpacks)
+	if err != nil {
+		logger.Error("failed-to-marshal-buildpacks", err)
+
+		return errors.Wrap(err, "failed to marshal buildpacks")
+	}
+
+	env := map[string]string{
+		eirini.EnvDownloadURL:        lifecycle.AppBitsDownloadURI,
+		eirini.EnvDropletUploadURL:   lifecycle.DropletUploadURI,
+		eirini.EnvBuildpacks:         string(buildpacksJSON),
+		eirini.EnvStagingGUID:        stagingGUID,
+		eirini.EnvCompletionCallback: request.CompletionCallback,
+		eirini.EnvEiriniAddress:      s.EiriniAddress,
+		eirini.EnvAppID:              request.AppGUID,
+	}
+
+	stagingTask := &opi.StagingTask{
+		Task: &opi.Task{
+			GUID:               stagingGUID,
+			AppName:            request.AppName,
+			AppGUID:            request.AppGUID,
+			OrgName:            request.OrgName,
+			OrgGUID:            request.OrgGUID,
+			SpaceName:          request.SpaceName,
+			SpaceGUID:          request.SpaceGUID,
+			CompletionCallback: request.CompletionCallback,
+			Env:                mergeEnvs(request.Environment, env),
+			MemoryMB:           request.MemoryMB,
+			DiskMB:             request.DiskMB,
+			CPUWeight:          request.CPUWeight,
+			PlacementTags:      isolationSegmentTags(request.IsolationSegment),
+		},
+		DownloaderImage: s.DownloaderImage,
+		ExecutorImage:   s.ExecutorImage,
+		UploaderImage:   s.UploaderImage,
+	}
+
+	namespace := s.Namespacer.GetNamespace("")
+
+	return errors.Wrap(s.StagingDesirer.DesireStaging(namespace, stagingTask), "failed to desire staging")
+}
+
+func (s BuildpackStaging) CompleteStaging(taskCompletedRequest cf.StagingCompletedRequest) error {
+	logger := s.Logger.Session("complete-staging", lager.Data{"staging-guid": taskCompletedRequest.TaskGUID})
+
+	err := s.StagingCompleter.CompleteStaging(taskCompletedRequest)
+	if err != nil {
+		logger.Error("failed-to-complete-staging", err)
+	}
+
+	if _, deleteErr := s.StagingDeleter.Delete(taskCompletedRequest.TaskGUID); deleteErr != nil {
+		logger.Error("failed-to-delete-staging-job", deleteErr)
+	}
+
+	return errors.Wrap(err, "failed to complete staging")
+}
+
+func (s BuildpackStaging) CancelStaging(stagingGUID string) error {
+	logger := s.Logger.Session("cancel-staging", lager.Data{"staging-guid": stagingGUID})
+
+	callbackURL, err := s.StagingDeleter.Delete(stagingGUID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete staging %s", stagingGUID)
+	}
+
+	if len(callbackURL) == 0 {
+		return nil
+	}
+
+	go func() {
+		err := s.StagingCompleter.CompleteStaging(cf.StagingCompletedRequest{
+			TaskGUID:      stagingGUID,
+			Failed:        true,
+			FailureReason: "staging was cancelled",
+			Annotation:    fmt.Sprintf(`{"completion_callback": "%s"}`, callbackURL),
+		})
+		if err != nil {
+			logger.Error("failed-to-notify-cloud-controller", err)
+		}
+	}()
+
+	return nil
+}
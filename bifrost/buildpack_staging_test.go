@@ -0,0 +1,272 @@
+package bifrost_test
+
+import (
+	"context"
+	"errors"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/bifrost/bifrostfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildpackStaging", func() {
+	var (
+		stager           bifrost.BuildpackStaging
+		namespacer       *bifrostfakes.FakeTaskNamespacer
+		stagingDesirer   *bifrostfakes.FakeStagingDesirer
+		stagingDeleter   *bifrostfakes.FakeStagingDeleter
+		stagingCompleter *bifrostfakes.FakeStagingCompleter
+	)
+
+	BeforeEach(func() {
+		namespacer = new(bifrostfakes.FakeTaskNamespacer)
+		namespacer.GetNamespaceReturns("staging-ns")
+		stagingDesirer = new(bifrostfakes.FakeStagingDesirer)
+		stagingDeleter = new(bifrostfakes.FakeStagingDeleter)
+		stagingCompleter = new(bifrostfakes.FakeStagingCompleter)
+
+		stager = bifrost.BuildpackStaging{
+			Logger:           lagertest.NewTestLogger(""),
+			Namespacer:       namespacer,
+			StagingDesirer:   stagingDesirer,
+			StagingDeleter:   stagingDeleter,
+			StagingCompleter: stagingCompleter,
+			EiriniAddress:    "https://eirini.example.com",
+			DownloaderImage:  "eirini/downloader",
+			ExecutorImage:    "eirini/executor",
+			UploaderImage:    "eirini/uploader",
+		}
+	})
+
+	Describe("TransferStaging", func() {
+		var (
+			stagingRequest cf.StagingRequest
+			stagingErr     error
+		)
+
+		BeforeEach(func() {
+			stagingRequest = cf.StagingRequest{
+				AppGUID:   "app-guid",
+				AppName:   "app-name",
+				OrgName:   "org-name",
+				OrgGUID:   "org-guid",
+				SpaceName: "space-name",
+				SpaceGUID: "space-guid",
+				Environment: []cf.EnvironmentVariable{
+					{Name: "FOO", Value: "bar"},
+					{Name: eirini.EnvAppID, Value: "overridden"},
+				},
+				CompletionCallback: "cc.example.com/staging/completed",
+				MemoryMB:           256,
+				DiskMB:             1024,
+				CPUWeight:          50,
+				Lifecycle: cf.StagingLifecycle{
+					BuildpackLifecycle: &cf.StagingBuildpackLifecycle{
+						AppBitsDownloadURI: "cc.example.com/download",
+						DropletUploadURI:   "cc.example.com/upload",
+						Buildpacks: []cf.Buildpack{
+							{Name: "go_buildpack", Key: "go-key", URL: "example.com/go", SkipDetect: true},
+						},
+						Stack: "cflinuxfs3",
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			stagingErr = stager.TransferStaging(context.Background(), "staging-guid", stagingRequest)
+		})
+
+		It("should succeed", func() {
+			Expect(stagingErr).NotTo(HaveOccurred())
+		})
+
+		It("should desire the staging task in the default namespace", func() {
+			Expect(namespacer.GetNamespaceCallCount()).To(Equal(1))
+			Expect(namespacer.GetNamespaceArgsForCall(0)).To(BeEmpty())
+
+			Expect(stagingDesirer.DesireStagingCallCount()).To(Equal(1))
+			namespace, _ := stagingDesirer.DesireStagingArgsForCall(0)
+			Expect(namespace).To(Equal("staging-ns"))
+		})
+
+		It("should desire a staging task with the right fields", func() {
+			_, task := stagingDesirer.DesireStagingArgsForCall(0)
+			Expect(task.GUID).To(Equal("staging-guid"))
+			Expect(task.AppGUID).To(Equal("app-guid"))
+			Expect(task.AppName).To(Equal("app-name"))
+			Expect(task.OrgName).To(Equal("org-name"))
+			Expect(task.OrgGUID).To(Equal("org-guid"))
+			Expect(task.SpaceName).To(Equal("space-name"))
+			Expect(task.SpaceGUID).To(Equal("space-guid"))
+			Expect(task.CompletionCallback).To(Equal("cc.example.com/staging/completed"))
+			Expect(task.MemoryMB).To(Equal(int64(256)))
+			Expect(task.DiskMB).To(Equal(int64(1024)))
+			Expect(task.CPUWeight).To(Equal(uint8(50)))
+			Expect(task.DownloaderImage).To(Equal("eirini/downloader"))
+			Expect(task.ExecutorImage).To(Equal("eirini/executor"))
+			Expect(task.UploaderImage).To(Equal("eirini/uploader"))
+		})
+
+		It("should pass the staging env to the task", func() {
+			_, task := stagingDesirer.DesireStagingArgsForCall(0)
+			Expect(task.Env).To(Equal(map[string]string{
+				"FOO":                        "bar",
+				eirini.EnvDownloadURL:        "cc.example.com/download",
+				eirini.EnvDropletUploadURL:   "cc.example.com/upload",
+				eirini.EnvBuildpacks:         `[{"name":"go_buildpack","key":"go-key","url":"example.com/go","skip_detect":true}]`,
+				eirini.EnvStagingGUID:        "staging-guid",
+				eirini.EnvCompletionCallback: "cc.example.com/staging/completed",
+				eirini.EnvEiriniAddress:      "https://eirini.example.com",
+				eirini.EnvAppID:              "app-guid",
+			}))
+		})
+
+		When("the app runs in an isolation segment", func() {
+			BeforeEach(func() {
+				stagingRequest.IsolationSegment = "isolated"
+			})
+
+			It("should stage in the isolation segment", func() {
+				_, task := stagingDesirer.DesireStagingArgsForCall(0)
+				Expect(task.PlacementTags).To(Equal([]string{"isolated"}))
+			})
+		})
+
+		When("the buildpack lifecycle is missing", func() {
+			BeforeEach(func() {
+				stagingRequest.Lifecycle.BuildpackLifecycle = nil
+			})
+
+			It("should return an error", func() {
+				Expect(stagingErr).To(MatchError("missing buildpack lifecycle"))
+			})
+
+			It("should not desire a staging task", func() {
+				Expect(stagingDesirer.DesireStagingCallCount()).To(Equal(0))
+			})
+		})
+
+		When("desiring the staging task fails", func() {
+			BeforeEach(func() {
+				stagingDesirer.DesireStagingReturns(errors.New("boom"))
+			})
+
+			It("should return an error", func() {
+				Expect(stagingErr).To(MatchError(ContainSubstring("boom")))
+			})
+		})
+	})
+
+	Describe("CompleteStaging", func() {
+		var (
+			completedRequest cf.StagingCompletedRequest
+			completeErr      error
+		)
+
+		BeforeEach(func() {
+			completedRequest = cf.StagingCompletedRequest{
+				TaskGUID:   "staging-guid",
+				Result:     `{"lifecycle_type":"buildpack"}`,
+				Annotation: `{"completion_callback": "cc.example.com/staging/completed"}`,
+			}
+		})
+
+		JustBeforeEach(func() {
+			completeErr = stager.CompleteStaging(completedRequest)
+		})
+
+		It("should succeed", func() {
+			Expect(completeErr).NotTo(HaveOccurred())
+		})
+
+		It("should forward the request to the staging completer", func() {
+			Expect(stagingCompleter.CompleteStagingCallCount()).To(Equal(1))
+			Expect(stagingCompleter.CompleteStagingArgsForCall(0)).To(Equal(completedRequest))
+		})
+
+		It("should delete the staging job", func() {
+			Expect(stagingDeleter.DeleteCallCount()).To(Equal(1))
+			Expect(stagingDeleter.DeleteArgsForCall(0)).To(Equal("staging-guid"))
+		})
+
+		When("completing the staging fails", func() {
+			BeforeEach(func() {
+				stagingCompleter.CompleteStagingReturns(errors.New("boom"))
+			})
+
+			It("should return an error", func() {
+				Expect(completeErr).To(MatchError(ContainSubstring("boom")))
+			})
+
+			It("should still delete the staging job", func() {
+				Expect(stagingDeleter.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		When("deleting the staging job fails", func() {
+			BeforeEach(func() {
+				stagingDeleter.DeleteReturns("", errors.New("boom"))
+			})
+
+			It("should not return an error", func() {
+				Expect(completeErr).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("CancelStaging", func() {
+		var cancelErr error
+
+		BeforeEach(func() {
+			stagingDeleter.DeleteReturns("cc.example.com/staging/completed", nil)
+		})
+
+		JustBeforeEach(func() {
+			cancelErr = stager.CancelStaging("staging-guid")
+		})
+
+		It("should succeed", func() {
+			Expect(cancelErr).NotTo(HaveOccurred())
+		})
+
+		It("should delete the staging job", func() {
+			Expect(stagingDeleter.DeleteCallCount()).To(Equal(1))
+			Expect(stagingDeleter.DeleteArgsForCall(0)).To(Equal("staging-guid"))
+		})
+
+		It("should notify cloud controller that staging failed", func() {
+			Eventually(stagingCompleter.CompleteStagingCallCount).Should(Equal(1))
+			Expect(stagingCompleter.CompleteStagingArgsForCall(0)).To(Equal(cf.StagingCompletedRequest{
+				TaskGUID:      "staging-guid",
+				Failed:        true,
+				FailureReason: "staging was cancelled",
+				Annotation:    `{"completion_callback": "cc.example.com/staging/completed"}`,
+			}))
+		})
+
+		When("the staging has no completion callback", func() {
+			BeforeEach(func() {
+				stagingDeleter.DeleteReturns("", nil)
+			})
+
+			It("should not notify cloud controller", func() {
+				Consistently(stagingCompleter.CompleteStagingCallCount).Should(Equal(0))
+			})
+		})
+
+		When("deleting the staging job fails", func() {
+			BeforeEach(func() {
+				stagingDeleter.DeleteReturns("", errors.New("boom"))
+			})
+
+			It("should return an error", func() {
+				Expect(cancelErr).To(MatchError(ContainSubstring("boom")))
+			})
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/models/cf"
@@ -13,7 +14,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-const DockerHubHost = "index.docker.io/v1/"
+const (
+	DockerHubHost = "index.docker.io/v1/"
+
+	buildpackLauncher = "/lifecycle/launch"
+)
 
 var dockerRX = regexp.MustCompile(`([a-zA-Z0-9.-]+)(:([0-9]+))?/(\S+/\S+)`)
 
@@ -22,6 +27,7 @@ type lifecycleOptions struct {
 	env             map[string]string
 	image           string
 	privateRegistry *opi.PrivateRegistry
+	dropletDownload *opi.DropletDownload
 	runsAsRoot      bool
 }
 
@@ -30,14 +36,25 @@ type OPIConverter struct {
 	imageMetadataFetcher ImageMetadataFetcher
 	imageRefParser       ImageRefParser
 	allowRunImageAsRoot  bool
+	downloaderImage      string
+	stackImages          map[string]string
 }
 
-func NewOPIConverter(logger lager.Logger, imageMetadataFetcher ImageMetadataFetcher, imageRefParser ImageRefParser, allowRunImageAsRoot bool) *OPIConverter {
+func NewOPIConverter(
+	logger lager.Logger,
+	imageMetadataFetcher ImageMetadataFetcher,
+	imageRefParser ImageRefParser,
+	allowRunImageAsRoot bool,
+	downloaderImage string,
+	stackImages map[string]string,
+) *OPIConverter {
 	return &OPIConverter{
 		logger:               logger,
 		imageMetadataFetcher: imageMetadataFetcher,
 		imageRefParser:       imageRefParser,
 		allowRunImageAsRoot:  allowRunImageAsRoot,
+		downloaderImage:      downloaderImage,
+		stackImages:          stackImages,
 	}
 }
 
@@ -51,14 +68,21 @@ func (c *OPIConverter) ConvertLRP(request cf.DesireLRPRequest) (opi.LRP, error)
 		port = request.Ports[0]
 		env[eirini.EnvCFInstanceAddr] = fmt.Sprintf("0.0.0.0:%d", port)
 		env[eirini.EnvCFInstancePort] = fmt.Sprintf("%d", port)
-		env[eirini.EnvCFInstancePorts] = fmt.Sprintf(`[{"external":%d,"internal":%d}]`, port, port)
+		env[eirini.EnvCFInstancePorts] = instancePorts(request.Ports)
+	}
+
+	if request.HealthCheckPort != 0 {
+		port = request.HealthCheckPort
 	}
 
 	healthcheck := opi.Healtcheck{
-		Type:      request.HealthCheckType,
-		Endpoint:  request.HealthCheckHTTPEndpoint,
-		TimeoutMs: request.HealthCheckTimeoutMs,
-		Port:      port,
+		Type:             request.HealthCheckType,
+		Endpoint:         request.HealthCheckHTTPEndpoint,
+		TimeoutMs:        request.HealthCheckTimeoutMs,
+		Port:             port,
+		StartTimeoutMs:   request.StartTimeoutMs,
+		IntervalMs:       request.HealthCheckIntervalMs,
+		FailureThreshold: request.HealthCheckThreshold,
 	}
 
 	lrpLifecycleOptions, err := c.getLifecycleOptions(request)
@@ -81,10 +105,18 @@ func (c *OPIConverter) ConvertLRP(request cf.DesireLRPRequest) (opi.LRP, error)
 		return opi.LRP{}, err
 	}
 
+	egressRules, err := convertEgressRules(request.EgressRules)
+	if err != nil {
+		return opi.LRP{}, err
+	}
+
+	appEnv := mergeMaps(request.Environment, env, lrpLifecycleOptions.env)
+
 	return opi.LRP{
 		AppName:                request.AppName,
 		AppGUID:                request.AppGUID,
 		AppURIs:                routes,
+		InternalRoutes:         convertInternalRoutes(request.InternalRoutes),
 		LastUpdated:            request.LastUpdated,
 		OrgName:                request.OrganizationName,
 		OrgGUID:                request.OrganizationGUID,
@@ -95,16 +127,20 @@ func (c *OPIConverter) ConvertLRP(request cf.DesireLRPRequest) (opi.LRP, error)
 		Image:                  lrpLifecycleOptions.image,
 		TargetInstances:        request.NumInstances,
 		Command:                lrpLifecycleOptions.command,
-		Env:                    mergeMaps(request.Environment, env, lrpLifecycleOptions.env),
+		Env:                    appEnv,
+		Sidecars:               convertSidecars(request.Sidecars, request.ProcessType, appEnv, lrpLifecycleOptions.dropletDownload != nil),
 		Health:                 healthcheck,
 		Ports:                  request.Ports,
 		MemoryMB:               request.MemoryMB,
 		DiskMB:                 request.DiskMB,
 		CPUWeight:              request.CPUWeight,
 		VolumeMounts:           convertVolumeMounts(request),
+		PlacementTags:          request.PlacementTags,
+		EgressRules:            egressRules,
 		LRP:                    request.LRP,
 		UserDefinedAnnotations: request.UserDefinedAnnotations,
 		PrivateRegistry:        lrpLifecycleOptions.privateRegistry,
+		DropletDownload:        lrpLifecycleOptions.dropletDownload,
 		RunsAsRoot:             lrpLifecycleOptions.runsAsRoot,
 	}, nil
 }
@@ -119,6 +155,11 @@ func (c *OPIConverter) ConvertTask(taskGUID string, request cf.TaskRequest) (opi
 		"TMPDIR": "/home/vcap/tmp",
 	}
 
+	egressRules, err := convertEgressRules(request.EgressRules)
+	if err != nil {
+		return opi.Task{}, err
+	}
+
 	task := opi.Task{
 		GUID:               taskGUID,
 		Name:               request.Name,
@@ -129,10 +170,39 @@ func (c *OPIConverter) ConvertTask(taskGUID string, request cf.TaskRequest) (opi
 		SpaceName:          request.SpaceName,
 		OrgGUID:            request.OrgGUID,
 		SpaceGUID:          request.SpaceGUID,
+		MemoryMB:           request.MemoryMB,
+		DiskMB:             request.DiskMB,
+		CPUWeight:          request.CPUWeight,
+		PlacementTags:      isolationSegmentTags(request.IsolationSegment),
+		EgressRules:        egressRules,
+		TimeoutSeconds:     request.TimeoutSeconds,
+		ResultFile:         request.ResultFile,
+	}
+
+	if request.RetryPolicy != nil {
+		task.RetryPolicy = &opi.TaskRetryPolicy{
+			MaxAttempts:      request.RetryPolicy.MaxAttempts,
+			BackoffSeconds:   request.RetryPolicy.BackoffSeconds,
+			RetryOnExitCodes: request.RetryPolicy.RetryOnExitCodes,
+		}
+	}
+
+	if request.Lifecycle.BuildpackLifecycle != nil {
+		options, err := c.getBuildpackLifecycleOptions(request.Lifecycle.BuildpackLifecycle)
+		if err != nil {
+			return opi.Task{}, err
+		}
+
+		task.Image = options.image
+		task.Command = options.command
+		task.DropletDownload = options.dropletDownload
+		task.Env = mergeEnvs(request.Environment, mergeMaps(env, options.env))
+
+		return task, nil
 	}
 
 	if request.Lifecycle.DockerLifecycle == nil {
-		return opi.Task{}, errors.New("docker is the only supported lifecycle")
+		return opi.Task{}, errors.New("missing lifecycle data")
 	}
 
 	lifecycle := request.Lifecycle.DockerLifecycle
@@ -206,6 +276,142 @@ func getRequestedRoutes(request cf.DesireLRPRequest) ([]opi.Route, error) {
 	return routes, nil
 }
 
+// convertSidecars returns the sidecars that apply to the given process type.
+// Sidecars share the app environment and, for buildpack apps, are started by
+// the buildpack launcher from the droplet.
+func convertSidecars(sidecars []cf.Sidecar, processType string, appEnv map[string]string, isBuildpackApp bool) []opi.Sidecar {
+	var result []opi.Sidecar
+
+	for _, s := range sidecars {
+		if len(s.ProcessTypes) != 0 && !contains(s.ProcessTypes, processType) {
+			continue
+		}
+
+		sidecar := opi.Sidecar{
+			Name:     s.Name,
+			Command:  []string{"/bin/sh", "-c", s.Command},
+			MemoryMB: s.MemoryMB,
+			Env:      mergeMaps(appEnv, s.Environment),
+			Ports:    s.Ports,
+		}
+
+		if isBuildpackApp {
+			sidecar.Command = []string{buildpackLauncher}
+			sidecar.Env[eirini.EnvStartCommand] = s.Command
+		}
+
+		result = append(result, sidecar)
+	}
+
+	return result
+}
+
+// instancePorts returns the CF_INSTANCE_PORTS value for the given app ports.
+// Apps are reached on the pod IP directly, so external and internal ports
+// are the same.
+func instancePorts(ports []int32) string {
+	mappings := make([]string, 0, len(ports))
+	for _, p := range ports {
+		mappings = append(mappings, fmt.Sprintf(`{"external":%d,"internal":%d}`, p, p))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(mappings, ","))
+}
+
+// isolationSegmentTags returns the placement tags of workloads running in the
+// given isolation segment. Workloads in the shared segment have none.
+func isolationSegmentTags(isolationSegment string) []string {
+	if isolationSegment == "" {
+		return nil
+	}
+
+	return []string{isolationSegment}
+}
+
+func convertInternalRoutes(internalRoutes []cf.InternalRoute) []string {
+	if len(internalRoutes) == 0 {
+		return nil
+	}
+
+	hostnames := make([]string, 0, len(internalRoutes))
+	for _, route := range internalRoutes {
+		hostnames = append(hostnames, route.Hostname)
+	}
+
+	return hostnames
+}
+
+// convertEgressRules parses the application security group rules of an app.
+// Ports can only be set on TCP and UDP rules and every rule needs at least
+// one destination.
+func convertEgressRules(rawRules []json.RawMessage) ([]opi.EgressRule, error) {
+	var rules []opi.EgressRule
+
+	for _, raw := range rawRules {
+		var rule cf.EgressRule
+		if err := json.Unmarshal(raw, &rule); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal egress rule")
+		}
+
+		destinations := rule.Destinations
+		if rule.Destination != "" {
+			destinations = append([]string{rule.Destination}, destinations...)
+		}
+
+		if len(destinations) == 0 {
+			return nil, errors.New("egress rule has no destinations")
+		}
+
+		protocol := strings.ToLower(rule.Protocol)
+		if !contains([]string{"tcp", "udp", "icmp", "all"}, protocol) {
+			return nil, fmt.Errorf("unsupported egress rule protocol %q", rule.Protocol)
+		}
+
+		hasPorts := len(rule.Ports) != 0 || rule.PortRange != nil
+		if hasPorts && protocol != "tcp" && protocol != "udp" {
+			return nil, fmt.Errorf("egress rule with protocol %q cannot have ports", protocol)
+		}
+
+		opiRule := opi.EgressRule{
+			Protocol:     protocol,
+			Destinations: destinations,
+			Ports:        rule.Ports,
+		}
+
+		if rule.PortRange != nil {
+			opiRule.PortRange = &opi.PortRange{Start: rule.PortRange.Start, End: rule.PortRange.End}
+		}
+
+		if rule.IcmpInfo != nil {
+			opiRule.IcmpInfo = &opi.ICMPInfo{Type: rule.IcmpInfo.Type, Code: rule.IcmpInfo.Code}
+		}
+
+		rules = append(rules, opiRule)
+	}
+
+	return rules, nil
+}
+
+func containsPort(ports []int32, port int32) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func mergeMaps(maps ...map[string]string) map[string]string {
 	result := make(map[string]string)
 
@@ -242,6 +448,10 @@ func mergeEnvs(requestEnv []cf.EnvironmentVariable, appliedEnv map[string]string
 }
 
 func (c *OPIConverter) getLifecycleOptions(request cf.DesireLRPRequest) (*lifecycleOptions, error) {
+	if request.Lifecycle.BuildpackLifecycle != nil {
+		return c.getBuildpackLifecycleOptions(request.Lifecycle.BuildpackLifecycle)
+	}
+
 	options := &lifecycleOptions{}
 
 	if request.Lifecycle.DockerLifecycle == nil {
@@ -273,6 +483,29 @@ func (c *OPIConverter) getLifecycleOptions(request cf.DesireLRPRequest) (*lifecy
 	return options, nil
 }
 
+func (c *OPIConverter) getBuildpackLifecycleOptions(lifecycle *cf.BuildpackLifecycle) (*lifecycleOptions, error) {
+	image, ok := c.stackImages[lifecycle.Stack]
+	if !ok {
+		return nil, fmt.Errorf("unsupported stack %q", lifecycle.Stack)
+	}
+
+	if lifecycle.DropletURL == "" {
+		return nil, errors.New("missing droplet url")
+	}
+
+	return &lifecycleOptions{
+		image:   image,
+		command: []string{buildpackLauncher},
+		env: map[string]string{
+			eirini.EnvStartCommand: lifecycle.StartCommand,
+		},
+		dropletDownload: &opi.DropletDownload{
+			URL:             lifecycle.DropletURL,
+			DownloaderImage: c.downloaderImage,
+		},
+	}, nil
+}
+
 func convertVolumeMounts(request cf.DesireLRPRequest) []opi.VolumeMount {
 	volumeMounts := []opi.VolumeMount{}
 	for _, vm := range request.VolumeMounts {
@@ -290,5 +523,9 @@ func (c *OPIConverter) validateRequest(request cf.DesireLRPRequest) error {
 		return errors.New("DiskMB cannot be 0")
 	}
 
+	if request.HealthCheckPort != 0 && !containsPort(request.Ports, request.HealthCheckPort) {
+		return fmt.Errorf("health check port %d is not one of the app ports", request.HealthCheckPort)
+	}
+
 	return nil
 }
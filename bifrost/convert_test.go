@@ -38,6 +38,8 @@ var _ = Describe("OPI Converter", func() {
 			imgMetadataFetcher.Spy,
 			imgRefParser.Spy,
 			allowRunImageAsRoot,
+			"eirini/downloader",
+			map[string]string{"cflinuxfs3": "eirini/cflinuxfs3"},
 		)
 	})
 
@@ -84,6 +86,9 @@ var _ = Describe("OPI Converter", func() {
 				HealthCheckType:         "http",
 				HealthCheckHTTPEndpoint: "/heat",
 				HealthCheckTimeoutMs:    400,
+				HealthCheckIntervalMs:   5000,
+				HealthCheckThreshold:    6,
+				StartTimeoutMs:          90000,
 				Ports:                   []int32{8000, 8888},
 				Routes: map[string]json.RawMessage{
 					"cf-router": rawJSON,
@@ -98,7 +103,8 @@ var _ = Describe("OPI Converter", func() {
 						MountDir: "/path/two",
 					},
 				},
-				LRP: "full LRP request",
+				LRP:           "full LRP request",
+				PlacementTags: []string{"gpu", "isolated"},
 				UserDefinedAnnotations: map[string]string{
 					"prometheus.io/scrape": "scrape",
 				},
@@ -176,7 +182,7 @@ var _ = Describe("OPI Converter", func() {
 		It("should set CF_INSTANCE_* env variables", func() {
 			Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvCFInstanceAddr, "0.0.0.0:8000"))
 			Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvCFInstancePort, "8000"))
-			Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvCFInstancePorts, MatchJSON(`[{"external": 8000, "internal": 8000}]`)))
+			Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvCFInstancePorts, MatchJSON(`[{"external": 8000, "internal": 8000}, {"external": 8888, "internal": 8888}]`)))
 		})
 
 		It("should set LANG env variable", func() {
@@ -211,6 +217,10 @@ var _ = Describe("OPI Converter", func() {
 			Expect(lrp.LRP).To(Equal("full LRP request"))
 		})
 
+		It("should set the placement tags", func() {
+			Expect(lrp.PlacementTags).To(Equal([]string{"gpu", "isolated"}))
+		})
+
 		It("should set user defined annotation", func() {
 			Expect(lrp.UserDefinedAnnotations["prometheus.io/scrape"]).To(Equal("scrape"))
 		})
@@ -235,6 +245,30 @@ var _ = Describe("OPI Converter", func() {
 			})
 		})
 
+		Context("when a health check port is specified", func() {
+			BeforeEach(func() {
+				desireLRPRequest.HealthCheckPort = 8888
+			})
+
+			It("should health check the given port", func() {
+				Expect(lrp.Health.Port).To(Equal(int32(8888)))
+			})
+
+			It("should keep the first port as the instance port", func() {
+				Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvCFInstancePort, "8000"))
+			})
+
+			Context("and it is not one of the app ports", func() {
+				BeforeEach(func() {
+					desireLRPRequest.HealthCheckPort = 9999
+				})
+
+				It("fails", func() {
+					Expect(err).To(MatchError("health check port 9999 is not one of the app ports"))
+				})
+			})
+		})
+
 		Context("when the disk quota is not provided", func() {
 			BeforeEach(func() {
 				desireLRPRequest.DiskMB = 0
@@ -245,6 +279,211 @@ var _ = Describe("OPI Converter", func() {
 			})
 		})
 
+		Context("when the app has internal routes", func() {
+			BeforeEach(func() {
+				desireLRPRequest.InternalRoutes = []cf.InternalRoute{
+					{Hostname: "app.apps.internal"},
+					{Hostname: "app.other.internal"},
+				}
+			})
+
+			It("should set the internal route hostnames", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lrp.InternalRoutes).To(Equal([]string{"app.apps.internal", "app.other.internal"}))
+			})
+		})
+
+		Context("when the app has egress rules", func() {
+			BeforeEach(func() {
+				desireLRPRequest.EgressRules = []json.RawMessage{
+					json.RawMessage(`{"protocol":"tcp","destinations":["10.0.0.0/24"],"ports":[80,443],"log":true}`),
+					json.RawMessage(`{"protocol":"UDP","destination":"10.0.0.53","port_range":{"start":53,"end":54}}`),
+					json.RawMessage(`{"protocol":"icmp","destinations":["0.0.0.0/0"],"icmp_info":{"type":8,"code":0}}`),
+				}
+			})
+
+			It("should parse them", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lrp.EgressRules).To(Equal([]opi.EgressRule{
+					{Protocol: "tcp", Destinations: []string{"10.0.0.0/24"}, Ports: []int32{80, 443}},
+					{Protocol: "udp", Destinations: []string{"10.0.0.53"}, PortRange: &opi.PortRange{Start: 53, End: 54}},
+					{Protocol: "icmp", Destinations: []string{"0.0.0.0/0"}, IcmpInfo: &opi.ICMPInfo{Type: 8, Code: 0}},
+				}))
+			})
+
+			Context("and a rule is not valid JSON", func() {
+				BeforeEach(func() {
+					desireLRPRequest.EgressRules = []json.RawMessage{json.RawMessage(`"raw message"`)}
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to unmarshal egress rule")))
+				})
+			})
+
+			Context("and a rule has no destinations", func() {
+				BeforeEach(func() {
+					desireLRPRequest.EgressRules = []json.RawMessage{json.RawMessage(`{"protocol":"all"}`)}
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError("egress rule has no destinations"))
+				})
+			})
+
+			Context("and a rule has an unsupported protocol", func() {
+				BeforeEach(func() {
+					desireLRPRequest.EgressRules = []json.RawMessage{json.RawMessage(`{"protocol":"sctp","destinations":["10.0.0.1"]}`)}
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError(`unsupported egress rule protocol "sctp"`))
+				})
+			})
+
+			Context("and a rule sets ports on a protocol without ports", func() {
+				BeforeEach(func() {
+					desireLRPRequest.EgressRules = []json.RawMessage{json.RawMessage(`{"protocol":"all","destinations":["10.0.0.1"],"ports":[80]}`)}
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError(`egress rule with protocol "all" cannot have ports`))
+				})
+			})
+		})
+
+		Context("when the app has sidecars", func() {
+			BeforeEach(func() {
+				desireLRPRequest.Sidecars = []cf.Sidecar{
+					{
+						Name:         "web-sidecar",
+						Command:      "./sidecar --web",
+						MemoryMB:     32,
+						Environment:  map[string]string{"SIDECAR_VAR": "sidecar", "VAR_FROM_CC": "overridden"},
+						ProcessTypes: []string{"web", "worker"},
+						Ports:        []int32{9000},
+					},
+					{
+						Name:         "worker-sidecar",
+						Command:      "./sidecar --worker",
+						MemoryMB:     64,
+						ProcessTypes: []string{"worker"},
+					},
+					{
+						Name:     "any-sidecar",
+						Command:  "./sidecar --any",
+						MemoryMB: 16,
+					},
+				}
+			})
+
+			It("only keeps the sidecars of the process type", func() {
+				Expect(lrp.Sidecars).To(HaveLen(2))
+				Expect(lrp.Sidecars[0].Name).To(Equal("web-sidecar"))
+				Expect(lrp.Sidecars[1].Name).To(Equal("any-sidecar"))
+			})
+
+			It("runs the sidecar command in a shell", func() {
+				Expect(lrp.Sidecars[0].Command).To(Equal([]string{"/bin/sh", "-c", "./sidecar --web"}))
+			})
+
+			It("sets the sidecar memory and ports", func() {
+				Expect(lrp.Sidecars[0].MemoryMB).To(Equal(int64(32)))
+				Expect(lrp.Sidecars[0].Ports).To(Equal([]int32{9000}))
+				Expect(lrp.Sidecars[1].Ports).To(BeEmpty())
+			})
+
+			It("merges the sidecar env on top of the app env", func() {
+				Expect(lrp.Sidecars[0].Env).To(Equal(map[string]string{
+					"VAR_FROM_CC":       "overridden",
+					"SIDECAR_VAR":       "sidecar",
+					"LANG":              "en_US.UTF-8",
+					"CF_INSTANCE_ADDR":  "0.0.0.0:8000",
+					"CF_INSTANCE_PORT":  "8000",
+					"CF_INSTANCE_PORTS": `[{"external":8000,"internal":8000},{"external":8888,"internal":8888}]`,
+				}))
+				Expect(lrp.Sidecars[1].Env).To(Equal(lrp.Env))
+			})
+
+			Context("and the app is using buildpack lifecycle", func() {
+				BeforeEach(func() {
+					desireLRPRequest.Lifecycle = cf.Lifecycle{
+						BuildpackLifecycle: &cf.BuildpackLifecycle{
+							DropletURL:   "https://cc.example.com/droplet",
+							StartCommand: "bundle exec rackup",
+							Stack:        "cflinuxfs3",
+						},
+					}
+				})
+
+				It("runs the sidecar command via the launcher", func() {
+					Expect(lrp.Sidecars[0].Command).To(Equal([]string{"/lifecycle/launch"}))
+					Expect(lrp.Sidecars[0].Env).To(HaveKeyWithValue(eirini.EnvStartCommand, "./sidecar --web"))
+					Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvStartCommand, "bundle exec rackup"))
+				})
+			})
+		})
+
+		Context("When the app is using buildpack lifecycle", func() {
+			BeforeEach(func() {
+				desireLRPRequest.Lifecycle = cf.Lifecycle{
+					BuildpackLifecycle: &cf.BuildpackLifecycle{
+						DropletURL:   "https://cc.example.com/droplet",
+						StartCommand: "bundle exec rackup",
+						Stack:        "cflinuxfs3",
+					},
+				}
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("uses the image of the stack", func() {
+				Expect(lrp.Image).To(Equal("eirini/cflinuxfs3"))
+			})
+
+			It("runs the start command via the launcher", func() {
+				Expect(lrp.Command).To(Equal([]string{"/lifecycle/launch"}))
+				Expect(lrp.Env).To(HaveKeyWithValue(eirini.EnvStartCommand, "bundle exec rackup"))
+			})
+
+			It("downloads the droplet", func() {
+				Expect(lrp.DropletDownload).To(Equal(&opi.DropletDownload{
+					URL:             "https://cc.example.com/droplet",
+					DownloaderImage: "eirini/downloader",
+				}))
+			})
+
+			It("does not run as root", func() {
+				Expect(lrp.RunsAsRoot).To(BeFalse())
+			})
+
+			It("does not fetch image metadata", func() {
+				Expect(imgMetadataFetcher.CallCount()).To(BeZero())
+			})
+
+			Context("when the stack is not supported", func() {
+				BeforeEach(func() {
+					desireLRPRequest.Lifecycle.BuildpackLifecycle.Stack = "windows"
+				})
+
+				It("fails", func() {
+					Expect(err).To(MatchError(ContainSubstring(`unsupported stack "windows"`)))
+				})
+			})
+
+			Context("when the droplet url is missing", func() {
+				BeforeEach(func() {
+					desireLRPRequest.Lifecycle.BuildpackLifecycle.DropletURL = ""
+				})
+
+				It("fails", func() {
+					Expect(err).To(MatchError(ContainSubstring("missing droplet url")))
+				})
+			})
+		})
+
 		Context("When the app is using docker lifecycle", func() {
 			BeforeEach(func() {
 				desireLRPRequest.Lifecycle = cf.Lifecycle{
@@ -269,6 +508,9 @@ var _ = Describe("OPI Converter", func() {
 				Expect(health.Port).To(Equal(int32(8000)))
 				Expect(health.Endpoint).To(Equal("/heat"))
 				Expect(health.TimeoutMs).To(Equal(uint(400)))
+				Expect(health.StartTimeoutMs).To(Equal(uint(90000)))
+				Expect(health.IntervalMs).To(Equal(uint(5000)))
+				Expect(health.FailureThreshold).To(Equal(int32(6)))
 			})
 
 			It("shouldn't set privateRegistry information", func() {
@@ -456,6 +698,9 @@ var _ = Describe("OPI Converter", func() {
 							Command: []string{"some", "command"},
 						},
 					},
+					MemoryMB:  1024,
+					DiskMB:    2048,
+					CPUWeight: 50,
 				}
 			})
 
@@ -473,11 +718,86 @@ var _ = Describe("OPI Converter", func() {
 						"USER":   "vcap",
 						"TMPDIR": "/home/vcap/tmp",
 					},
-					Command: []string{"some", "command"},
-					Image:   "some/image",
+					Command:   []string{"some", "command"},
+					Image:     "some/image",
+					MemoryMB:  1024,
+					DiskMB:    2048,
+					CPUWeight: 50,
 				}))
 			})
 
+			When("the task runs in an isolation segment", func() {
+				BeforeEach(func() {
+					taskRequest.IsolationSegment = "isolated"
+				})
+
+				It("places the task with the isolation segment tag", func() {
+					Expect(task.PlacementTags).To(Equal([]string{"isolated"}))
+				})
+			})
+
+			When("the task has a timeout", func() {
+				BeforeEach(func() {
+					taskRequest.TimeoutSeconds = 600
+				})
+
+				It("keeps the timeout", func() {
+					Expect(task.TimeoutSeconds).To(BeNumerically("==", 600))
+				})
+			})
+
+			When("the task has a result file", func() {
+				BeforeEach(func() {
+					taskRequest.ResultFile = "/tmp/result.json"
+				})
+
+				It("keeps the result file", func() {
+					Expect(task.ResultFile).To(Equal("/tmp/result.json"))
+				})
+			})
+
+			When("the task has a retry policy", func() {
+				BeforeEach(func() {
+					taskRequest.RetryPolicy = &cf.TaskRetryPolicy{
+						MaxAttempts:      3,
+						BackoffSeconds:   10,
+						RetryOnExitCodes: []int32{75},
+					}
+				})
+
+				It("converts it", func() {
+					Expect(task.RetryPolicy).To(Equal(&opi.TaskRetryPolicy{
+						MaxAttempts:      3,
+						BackoffSeconds:   10,
+						RetryOnExitCodes: []int32{75},
+					}))
+				})
+			})
+
+			When("the task has egress rules", func() {
+				BeforeEach(func() {
+					taskRequest.EgressRules = []json.RawMessage{
+						json.RawMessage(`{"protocol":"all","destinations":["10.0.0.0-10.0.0.255"]}`),
+					}
+				})
+
+				It("parses them", func() {
+					Expect(task.EgressRules).To(Equal([]opi.EgressRule{
+						{Protocol: "all", Destinations: []string{"10.0.0.0-10.0.0.255"}},
+					}))
+				})
+
+				When("a rule is invalid", func() {
+					BeforeEach(func() {
+						taskRequest.EgressRules = []json.RawMessage{json.RawMessage(`{"protocol":"all"}`)}
+					})
+
+					It("fails", func() {
+						Expect(err).To(MatchError("egress rule has no destinations"))
+					})
+				})
+			})
+
 			When("the docker image is in a private registry", func() {
 				BeforeEach(func() {
 					taskRequest.Lifecycle.DockerLifecycle.Image = "private-registry/some/image"
@@ -495,7 +815,54 @@ var _ = Describe("OPI Converter", func() {
 			})
 		})
 
-		When("the task does not have any docker lifecycle information", func() {
+		When("the task has a buildpack lifecycle", func() {
+			BeforeEach(func() {
+				taskRequest = cf.TaskRequest{
+					AppGUID:            "our-app-id",
+					Name:               "task-name",
+					Environment:        []cf.EnvironmentVariable{{Name: "HOWARD", Value: "the alien"}},
+					CompletionCallback: "example.com/call/me/maybe",
+					Lifecycle: cf.Lifecycle{
+						BuildpackLifecycle: &cf.BuildpackLifecycle{
+							DropletURL:   "https://cc.example.com/droplet",
+							StartCommand: "rake db:migrate",
+							Stack:        "cflinuxfs3",
+						},
+					},
+				}
+			})
+
+			It("should convert the task request", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(task.Image).To(Equal("eirini/cflinuxfs3"))
+				Expect(task.Command).To(Equal([]string{"/lifecycle/launch"}))
+				Expect(task.DropletDownload).To(Equal(&opi.DropletDownload{
+					URL:             "https://cc.example.com/droplet",
+					DownloaderImage: "eirini/downloader",
+				}))
+				Expect(task.Env).To(Equal(map[string]string{
+					"HOWARD":        "the alien",
+					"HOME":          "/home/vcap/app",
+					"PATH":          "/usr/local/bin:/usr/bin:/bin",
+					"USER":          "vcap",
+					"TMPDIR":        "/home/vcap/tmp",
+					"START_COMMAND": "rake db:migrate",
+				}))
+				Expect(task.PrivateRegistry).To(BeNil())
+			})
+
+			When("the stack is not supported", func() {
+				BeforeEach(func() {
+					taskRequest.Lifecycle.BuildpackLifecycle.Stack = "windows"
+				})
+
+				It("fails", func() {
+					Expect(err).To(MatchError(ContainSubstring(`unsupported stack "windows"`)))
+				})
+			})
+		})
+
+		When("the task does not have any lifecycle information", func() {
 			BeforeEach(func() {
 				taskRequest = cf.TaskRequest{
 					AppGUID:            "our-app-id",
@@ -507,7 +874,7 @@ var _ = Describe("OPI Converter", func() {
 			})
 
 			It("fails with a useful message", func() {
-				Expect(err).To(MatchError("docker is the only supported lifecycle"))
+				Expect(err).To(MatchError("missing lifecycle data"))
 			})
 		})
 	})
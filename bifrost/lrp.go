@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/models/cf"
 	"code.cloudfoundry.org/eirini/opi"
@@ -92,11 +93,149 @@ func (l *LRP) Update(ctx context.Context, request cf.UpdateDesiredLRPRequest) er
 		return err
 	}
 
-	lrp.Image = request.Update.Image
+	if err = applyUpdate(lrp, request.Update); err != nil {
+		return err
+	}
 
 	return errors.Wrap(l.Desirer.Update(lrp), "failed to update")
 }
 
+func applyUpdate(lrp *opi.LRP, update cf.DesiredLRPUpdate) error {
+	if update.Image != "" {
+		lrp.Image = update.Image
+	}
+
+	previousEnv := lrp.Env
+
+	if update.Environment != nil {
+		lrp.Env = mergeMaps(update.Environment, eiriniManagedEnv(lrp.Env))
+	}
+
+	if update.StartCommand != nil {
+		lrp.Env = mergeMaps(lrp.Env, map[string]string{eirini.EnvStartCommand: *update.StartCommand})
+	}
+
+	if update.Command != nil {
+		lrp.Command = update.Command
+	}
+
+	if update.MemoryMB != nil {
+		lrp.MemoryMB = *update.MemoryMB
+	}
+
+	if update.DiskMB != nil {
+		lrp.DiskMB = *update.DiskMB
+	}
+
+	if update.CPUWeight != nil {
+		lrp.CPUWeight = *update.CPUWeight
+	}
+
+	if update.HealthCheckType != nil {
+		lrp.Health.Type = *update.HealthCheckType
+	}
+
+	if update.HealthCheckHTTPEndpoint != nil {
+		lrp.Health.Endpoint = *update.HealthCheckHTTPEndpoint
+	}
+
+	if update.HealthCheckTimeoutMs != nil {
+		lrp.Health.TimeoutMs = *update.HealthCheckTimeoutMs
+	}
+
+	if update.HealthCheckPort != nil {
+		port := *update.HealthCheckPort
+		if port != 0 && !containsPort(lrp.Ports, port) {
+			return errors.Wrapf(eirini.ErrInvalidLRPUpdate, "health check port %d is not one of the app ports", port)
+		}
+
+		lrp.Health.Port = port
+	}
+
+	if update.HealthCheckIntervalMs != nil {
+		lrp.Health.IntervalMs = *update.HealthCheckIntervalMs
+	}
+
+	if update.HealthCheckThreshold != nil {
+		lrp.Health.FailureThreshold = *update.HealthCheckThreshold
+	}
+
+	if update.StartTimeoutMs != nil {
+		lrp.Health.StartTimeoutMs = *update.StartTimeoutMs
+	}
+
+	switch {
+	case update.Sidecars != nil:
+		lrp.Sidecars = convertSidecars(update.Sidecars, lrp.ProcessType, lrp.Env, lrp.DropletDownload != nil)
+	case update.Environment != nil || update.StartCommand != nil:
+		lrp.Sidecars = updateSidecarEnv(lrp.Sidecars, previousEnv, lrp.Env)
+	}
+
+	if update.UserDefinedAnnotations != nil {
+		lrp.UserDefinedAnnotations = update.UserDefinedAnnotations
+	}
+
+	if update.RegistryUsername != nil || update.RegistryPassword != nil {
+		lrp.PrivateRegistry = &opi.PrivateRegistry{
+			Server:   parseRegistryHost(lrp.Image),
+			Username: stringValue(update.RegistryUsername),
+			Password: stringValue(update.RegistryPassword),
+		}
+	}
+
+	return nil
+}
+
+// updateSidecarEnv gives the sidecars the updated app env. The env of a
+// sidecar is the app env merged with its own, so the vars that differ from the
+// previous app env are its own and are kept.
+func updateSidecarEnv(sidecars []opi.Sidecar, previousAppEnv, appEnv map[string]string) []opi.Sidecar {
+	updated := make([]opi.Sidecar, 0, len(sidecars))
+
+	for _, sidecar := range sidecars {
+		ownEnv := map[string]string{}
+
+		for k, v := range sidecar.Env {
+			if previous, ok := previousAppEnv[k]; !ok || previous != v {
+				ownEnv[k] = v
+			}
+		}
+
+		sidecar.Env = mergeMaps(appEnv, ownEnv)
+		updated = append(updated, sidecar)
+	}
+
+	return updated
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// eiriniManagedEnv returns the env vars eirini sets on top of the ones
+// requested by CC, so that they survive an env update.
+func eiriniManagedEnv(env map[string]string) map[string]string {
+	managed := map[string]string{}
+
+	for _, name := range []string{
+		"LANG",
+		eirini.EnvCFInstanceAddr,
+		eirini.EnvCFInstancePort,
+		eirini.EnvCFInstancePorts,
+		eirini.EnvStartCommand,
+	} {
+		if value, ok := env[name]; ok {
+			managed[name] = value
+		}
+	}
+
+	return managed
+}
+
 func (l *LRP) GetApp(ctx context.Context, identifier opi.LRPIdentifier) (cf.DesiredLRP, error) {
 	lrp, err := l.Desirer.Get(identifier)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/bifrost"
 	"code.cloudfoundry.org/eirini/bifrost/bifrostfakes"
 	"code.cloudfoundry.org/eirini/models/cf"
@@ -209,10 +210,30 @@ var _ = Describe("Bifrost LRP", func() {
 			lrpDesirer.GetReturns(&opi.LRP{
 				TargetInstances: 2,
 				LastUpdated:     "whenever",
+				Ports:           []int32{8080, 9090},
 				AppURIs: []opi.Route{
 					{Hostname: "my.route", Port: 8080},
 					{Hostname: "your.route", Port: 5555},
 				},
+				Env: map[string]string{
+					"LANG":                   "en_US.UTF-8",
+					eirini.EnvCFInstancePort: "8080",
+					eirini.EnvStartCommand:   "./run",
+					"USER_VAR":               "old",
+				},
+				Command:   []string{"/lifecycle/launch"},
+				MemoryMB:  256,
+				DiskMB:    512,
+				CPUWeight: 10,
+				Health: opi.Healtcheck{
+					Type:      "port",
+					Port:      8080,
+					TimeoutMs: 1000,
+				},
+				Sidecars: []opi.Sidecar{
+					{Name: "old-sidecar"},
+				},
+				UserDefinedAnnotations: map[string]string{"foo": "bar"},
 			}, nil)
 
 			lrpDesirer.UpdateReturns(nil)
@@ -245,6 +266,204 @@ var _ = Describe("Bifrost LRP", func() {
 			Expect(lrp.Image).To(Equal("the/image"))
 		})
 
+		It("should keep the fields that are not part of the update", func() {
+			lrp := lrpDesirer.UpdateArgsForCall(0)
+			Expect(lrp.Env).To(HaveKeyWithValue("USER_VAR", "old"))
+			Expect(lrp.Command).To(Equal([]string{"/lifecycle/launch"}))
+			Expect(lrp.MemoryMB).To(Equal(int64(256)))
+			Expect(lrp.DiskMB).To(Equal(int64(512)))
+			Expect(lrp.CPUWeight).To(Equal(uint8(10)))
+			Expect(lrp.Health).To(Equal(opi.Healtcheck{Type: "port", Port: 8080, TimeoutMs: 1000}))
+			Expect(lrp.Sidecars).To(Equal([]opi.Sidecar{{Name: "old-sidecar"}}))
+			Expect(lrp.UserDefinedAnnotations).To(Equal(map[string]string{"foo": "bar"}))
+		})
+
+		Context("when the update only changes the env", func() {
+			BeforeEach(func() {
+				startCommand := "./new-run"
+				updateRequest.Update.Environment = map[string]string{"USER_VAR": "new", "NEW_VAR": "added"}
+				updateRequest.Update.StartCommand = &startCommand
+
+				lrpDesirer.GetReturns(&opi.LRP{
+					Env: map[string]string{
+						"LANG":                 "en_US.UTF-8",
+						eirini.EnvStartCommand: "./run",
+						"USER_VAR":             "old",
+						"REMOVED_VAR":          "gone",
+					},
+					Sidecars: []opi.Sidecar{
+						{
+							Name:    "the-sidecar",
+							Command: []string{"/lifecycle/launch"},
+							Env: map[string]string{
+								"LANG":                 "en_US.UTF-8",
+								eirini.EnvStartCommand: "./sidecar",
+								"USER_VAR":             "old",
+								"REMOVED_VAR":          "gone",
+								"SIDECAR_VAR":          "value",
+							},
+						},
+					},
+				}, nil)
+			})
+
+			It("should give the sidecars the new app env and keep their own env", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.Sidecars).To(HaveLen(1))
+				Expect(lrp.Sidecars[0].Name).To(Equal("the-sidecar"))
+				Expect(lrp.Sidecars[0].Command).To(Equal([]string{"/lifecycle/launch"}))
+				Expect(lrp.Sidecars[0].Env).To(Equal(map[string]string{
+					"LANG":                 "en_US.UTF-8",
+					eirini.EnvStartCommand: "./sidecar",
+					"USER_VAR":             "new",
+					"NEW_VAR":              "added",
+					"SIDECAR_VAR":          "value",
+				}))
+			})
+		})
+
+		Context("when the update changes the app process", func() {
+			BeforeEach(func() {
+				startCommand := "./new-run"
+				memoryMB := int64(1024)
+				diskMB := int64(2048)
+				cpuWeight := uint8(40)
+				healthCheckType := "http"
+				healthCheckEndpoint := "/health"
+				healthCheckTimeoutMs := uint(5000)
+				healthCheckPort := int32(9090)
+
+				updateRequest.Update.Environment = map[string]string{"USER_VAR": "new"}
+				updateRequest.Update.StartCommand = &startCommand
+				updateRequest.Update.Command = []string{"/bin/sh", "-c", "run"}
+				updateRequest.Update.MemoryMB = &memoryMB
+				updateRequest.Update.DiskMB = &diskMB
+				updateRequest.Update.CPUWeight = &cpuWeight
+				updateRequest.Update.HealthCheckType = &healthCheckType
+				updateRequest.Update.HealthCheckHTTPEndpoint = &healthCheckEndpoint
+				updateRequest.Update.HealthCheckTimeoutMs = &healthCheckTimeoutMs
+				updateRequest.Update.HealthCheckPort = &healthCheckPort
+				updateRequest.Update.Sidecars = []cf.Sidecar{
+					{
+						Name:        "new-sidecar",
+						Command:     "./sidecar",
+						MemoryMB:    32,
+						Environment: map[string]string{"SIDECAR_VAR": "value"},
+					},
+				}
+				updateRequest.Update.UserDefinedAnnotations = map[string]string{"baz": "qux"}
+			})
+
+			It("should replace the user env and keep the env managed by eirini", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.Env).To(Equal(map[string]string{
+					"LANG":                   "en_US.UTF-8",
+					eirini.EnvCFInstancePort: "8080",
+					eirini.EnvStartCommand:   "./new-run",
+					"USER_VAR":               "new",
+				}))
+			})
+
+			It("should update the command and resources", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.Command).To(Equal([]string{"/bin/sh", "-c", "run"}))
+				Expect(lrp.MemoryMB).To(Equal(int64(1024)))
+				Expect(lrp.DiskMB).To(Equal(int64(2048)))
+				Expect(lrp.CPUWeight).To(Equal(uint8(40)))
+			})
+
+			It("should update the health check", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.Health).To(Equal(opi.Healtcheck{
+					Type:      "http",
+					Port:      9090,
+					Endpoint:  "/health",
+					TimeoutMs: 5000,
+				}))
+			})
+
+			Context("when the health check port is not one of the app ports", func() {
+				BeforeEach(func() {
+					healthCheckPort := int32(7070)
+					updateRequest.Update.HealthCheckPort = &healthCheckPort
+				})
+
+				It("should return an invalid update error", func() {
+					Expect(errors.Is(err, eirini.ErrInvalidLRPUpdate)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("health check port 7070 is not one of the app ports")))
+				})
+
+				It("should not update the app", func() {
+					Expect(lrpDesirer.UpdateCallCount()).To(BeZero())
+				})
+			})
+
+			It("should update the sidecars and user defined annotations", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.Sidecars).To(Equal([]opi.Sidecar{
+					{
+						Name:     "new-sidecar",
+						Command:  []string{"/bin/sh", "-c", "./sidecar"},
+						MemoryMB: 32,
+						Env: map[string]string{
+							"LANG":                   "en_US.UTF-8",
+							eirini.EnvCFInstancePort: "8080",
+							eirini.EnvStartCommand:   "./new-run",
+							"USER_VAR":               "new",
+							"SIDECAR_VAR":            "value",
+						},
+					},
+				}))
+				Expect(lrp.UserDefinedAnnotations).To(Equal(map[string]string{"baz": "qux"}))
+			})
+		})
+
+		It("should not change the private registry", func() {
+			lrp := lrpDesirer.UpdateArgsForCall(0)
+			Expect(lrp.PrivateRegistry).To(BeNil())
+		})
+
+		Context("when the update carries registry credentials", func() {
+			BeforeEach(func() {
+				username := "user"
+				password := "new-password"
+
+				updateRequest.Update.Image = "registry.io/the/image"
+				updateRequest.Update.RegistryUsername = &username
+				updateRequest.Update.RegistryPassword = &password
+			})
+
+			It("should submit the new credentials for the image registry", func() {
+				lrp := lrpDesirer.UpdateArgsForCall(0)
+				Expect(lrp.PrivateRegistry).To(Equal(&opi.PrivateRegistry{
+					Server:   "registry.io",
+					Username: "user",
+					Password: "new-password",
+				}))
+			})
+
+			Context("and no image", func() {
+				BeforeEach(func() {
+					updateRequest.Update.Image = ""
+					lrpDesirer.GetReturns(&opi.LRP{Image: "other-registry.io/the/image"}, nil)
+				})
+
+				It("should keep the current image", func() {
+					lrp := lrpDesirer.UpdateArgsForCall(0)
+					Expect(lrp.Image).To(Equal("other-registry.io/the/image"))
+				})
+
+				It("should submit the credentials for the registry of the current image", func() {
+					lrp := lrpDesirer.UpdateArgsForCall(0)
+					Expect(lrp.PrivateRegistry).To(Equal(&opi.PrivateRegistry{
+						Server:   "other-registry.io",
+						Username: "user",
+						Password: "new-password",
+					}))
+				})
+			})
+		})
+
 		Context("when the update fails", func() {
 			BeforeEach(func() {
 				lrpDesirer.UpdateReturns(errors.New("your app is bad"))
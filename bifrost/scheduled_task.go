@@ -0,0 +1,105 @@
+package bifrost
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+)
+
+//counterfeiter:generate . ScheduledTaskDesirer
+
+type ScheduledTaskDesirer interface {
+	Desire(namespace string, task *opi.ScheduledTask, opts ...k8s.DesireOption) error
+	Update(task *opi.ScheduledTask) error
+	Get(guid string) (*opi.ScheduledTask, error)
+	List() ([]*opi.ScheduledTask, error)
+	Delete(guid string) error
+}
+
+type ScheduledTask struct {
+	Namespacer           TaskNamespacer
+	Converter            TaskConverter
+	ScheduledTaskDesirer ScheduledTaskDesirer
+}
+
+func (s *ScheduledTask) GetScheduledTask(taskGUID string) (cf.ScheduledTaskResponse, error) {
+	task, err := s.ScheduledTaskDesirer.Get(taskGUID)
+	if err != nil {
+		return cf.ScheduledTaskResponse{}, errors.Wrap(err, "failed to get scheduled task")
+	}
+
+	return toScheduledTaskResponse(task), nil
+}
+
+func (s *ScheduledTask) ListScheduledTasks() (cf.ScheduledTasksResponse, error) {
+	tasks, err := s.ScheduledTaskDesirer.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list scheduled tasks")
+	}
+
+	tasksResp := cf.ScheduledTasksResponse{}
+	for _, task := range tasks {
+		tasksResp = append(tasksResp, toScheduledTaskResponse(task))
+	}
+
+	return tasksResp, nil
+}
+
+func (s *ScheduledTask) TransferScheduledTask(ctx context.Context, taskGUID string, request cf.ScheduledTaskRequest) error {
+	task, err := s.Converter.ConvertTask(taskGUID, request.TaskRequest)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert task")
+	}
+
+	scheduledTask := &opi.ScheduledTask{
+		Task:                       &task,
+		Schedule:                   request.Schedule,
+		ConcurrencyPolicy:          request.ConcurrencyPolicy,
+		Suspend:                    request.Suspend,
+		SuccessfulRunsHistoryLimit: request.SuccessfulRunsHistoryLimit,
+		FailedRunsHistoryLimit:     request.FailedRunsHistoryLimit,
+	}
+
+	namespace := s.Namespacer.GetNamespace(request.Namespace)
+
+	return errors.Wrap(s.ScheduledTaskDesirer.Desire(namespace, scheduledTask), "failed to desire")
+}
+
+func (s *ScheduledTask) UpdateScheduledTask(taskGUID string, request cf.ScheduledTaskUpdateRequest) error {
+	scheduledTask := &opi.ScheduledTask{
+		Task:                       &opi.Task{GUID: taskGUID},
+		Schedule:                   request.Schedule,
+		ConcurrencyPolicy:          request.ConcurrencyPolicy,
+		Suspend:                    request.Suspend,
+		SuccessfulRunsHistoryLimit: request.SuccessfulRunsHistoryLimit,
+		FailedRunsHistoryLimit:     request.FailedRunsHistoryLimit,
+	}
+
+	return errors.Wrap(s.ScheduledTaskDesirer.Update(scheduledTask), "failed to update scheduled task")
+}
+
+func (s *ScheduledTask) DeleteScheduledTask(taskGUID string) error {
+	return errors.Wrapf(s.ScheduledTaskDesirer.Delete(taskGUID), "failed to delete scheduled task %s", taskGUID)
+}
+
+func toScheduledTaskResponse(task *opi.ScheduledTask) cf.ScheduledTaskResponse {
+	return cf.ScheduledTaskResponse{
+		GUID:              task.GUID,
+		Name:              task.Name,
+		AppGUID:           task.AppGUID,
+		AppName:           task.AppName,
+		OrgGUID:           task.OrgGUID,
+		OrgName:           task.OrgName,
+		SpaceGUID:         task.SpaceGUID,
+		SpaceName:         task.SpaceName,
+		Command:           task.Command,
+		Schedule:          task.Schedule,
+		ConcurrencyPolicy: task.ConcurrencyPolicy,
+		Suspend:           task.Suspend,
+		LastScheduledAt:   task.LastScheduledAt,
+		ActiveRuns:        task.ActiveRuns,
+	}
+}
@@ -0,0 +1,244 @@
+package bifrost_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/eirini/bifrost"
+	"code.cloudfoundry.org/eirini/bifrost/bifrostfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("ScheduledTask", func() {
+	var (
+		err                  error
+		scheduledTaskBifrost *bifrost.ScheduledTask
+		taskConverter        *bifrostfakes.FakeTaskConverter
+		scheduledTaskDesirer *bifrostfakes.FakeScheduledTaskDesirer
+		namespacer           *bifrostfakes.FakeTaskNamespacer
+		historyLimit         int32
+	)
+
+	BeforeEach(func() {
+		taskConverter = new(bifrostfakes.FakeTaskConverter)
+		scheduledTaskDesirer = new(bifrostfakes.FakeScheduledTaskDesirer)
+		namespacer = new(bifrostfakes.FakeTaskNamespacer)
+		historyLimit = 5
+
+		taskConverter.ConvertTaskReturns(opi.Task{GUID: "my-guid"}, nil)
+		namespacer.GetNamespaceReturns("our-namespace")
+
+		scheduledTaskBifrost = &bifrost.ScheduledTask{
+			Converter:            taskConverter,
+			ScheduledTaskDesirer: scheduledTaskDesirer,
+			Namespacer:           namespacer,
+		}
+	})
+
+	Describe("Transfer Scheduled Task", func() {
+		var request cf.ScheduledTaskRequest
+
+		BeforeEach(func() {
+			request = cf.ScheduledTaskRequest{
+				TaskRequest: cf.TaskRequest{
+					Name:      "cake",
+					AppGUID:   "app-guid",
+					Namespace: "my-namespace",
+				},
+				Schedule:                   "*/5 * * * *",
+				ConcurrencyPolicy:          opi.ForbidConcurrentRuns,
+				Suspend:                    true,
+				SuccessfulRunsHistoryLimit: &historyLimit,
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = scheduledTaskBifrost.TransferScheduledTask(context.Background(), "task-guid", request)
+		})
+
+		It("converts the task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(taskConverter.ConvertTaskCallCount()).To(Equal(1))
+			actualTaskGUID, actualTaskRequest := taskConverter.ConvertTaskArgsForCall(0)
+			Expect(actualTaskGUID).To(Equal("task-guid"))
+			Expect(actualTaskRequest).To(Equal(request.TaskRequest))
+		})
+
+		It("desires the scheduled task in the requested namespace", func() {
+			Expect(namespacer.GetNamespaceCallCount()).To(Equal(1))
+			Expect(namespacer.GetNamespaceArgsForCall(0)).To(Equal("my-namespace"))
+
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(Equal(1))
+			namespace, scheduledTask, _ := scheduledTaskDesirer.DesireArgsForCall(0)
+			Expect(namespace).To(Equal("our-namespace"))
+			Expect(scheduledTask).To(Equal(&opi.ScheduledTask{
+				Task:                       &opi.Task{GUID: "my-guid"},
+				Schedule:                   "*/5 * * * *",
+				ConcurrencyPolicy:          opi.ForbidConcurrentRuns,
+				Suspend:                    true,
+				SuccessfulRunsHistoryLimit: &historyLimit,
+			}))
+		})
+
+		When("converting the task fails", func() {
+			BeforeEach(func() {
+				taskConverter.ConvertTaskReturns(opi.Task{}, errors.New("task-conv-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("task-conv-err")))
+			})
+
+			It("does not desire the scheduled task", func() {
+				Expect(scheduledTaskDesirer.DesireCallCount()).To(BeZero())
+			})
+		})
+
+		When("desiring the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.DesireReturns(errors.New("desire-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("desire-err")))
+			})
+		})
+	})
+
+	Describe("Update Scheduled Task", func() {
+		JustBeforeEach(func() {
+			err = scheduledTaskBifrost.UpdateScheduledTask("task-guid", cf.ScheduledTaskUpdateRequest{
+				Schedule:               "0 * * * *",
+				ConcurrencyPolicy:      opi.ReplaceConcurrentRuns,
+				FailedRunsHistoryLimit: &historyLimit,
+			})
+		})
+
+		It("updates the schedule of the task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledTaskDesirer.UpdateCallCount()).To(Equal(1))
+			Expect(scheduledTaskDesirer.UpdateArgsForCall(0)).To(Equal(&opi.ScheduledTask{
+				Task:                   &opi.Task{GUID: "task-guid"},
+				Schedule:               "0 * * * *",
+				ConcurrencyPolicy:      opi.ReplaceConcurrentRuns,
+				FailedRunsHistoryLimit: &historyLimit,
+			}))
+		})
+
+		When("updating the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.UpdateReturns(errors.New("update-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("update-err")))
+			})
+		})
+	})
+
+	Describe("Get Scheduled Task", func() {
+		var response cf.ScheduledTaskResponse
+
+		BeforeEach(func() {
+			scheduledTaskDesirer.GetReturns(&opi.ScheduledTask{
+				Task: &opi.Task{
+					GUID:    "task-guid",
+					Name:    "task-name",
+					AppName: "app-name",
+					Command: []string{"foo", "bar"},
+				},
+				Schedule:        "*/5 * * * *",
+				Suspend:         true,
+				LastScheduledAt: 123,
+				ActiveRuns:      2,
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			response, err = scheduledTaskBifrost.GetScheduledTask("task-guid")
+		})
+
+		It("returns the scheduled task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledTaskDesirer.GetArgsForCall(0)).To(Equal("task-guid"))
+			Expect(response).To(Equal(cf.ScheduledTaskResponse{
+				GUID:            "task-guid",
+				Name:            "task-name",
+				AppName:         "app-name",
+				Command:         []string{"foo", "bar"},
+				Schedule:        "*/5 * * * *",
+				Suspend:         true,
+				LastScheduledAt: 123,
+				ActiveRuns:      2,
+			}))
+		})
+
+		When("getting the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.GetReturns(nil, errors.New("get-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("get-err")))
+			})
+		})
+	})
+
+	Describe("List Scheduled Tasks", func() {
+		var response cf.ScheduledTasksResponse
+
+		BeforeEach(func() {
+			scheduledTaskDesirer.ListReturns([]*opi.ScheduledTask{
+				{Task: &opi.Task{GUID: "task-1"}, Schedule: "@hourly"},
+				{Task: &opi.Task{GUID: "task-2"}, Schedule: "@daily"},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			response, err = scheduledTaskBifrost.ListScheduledTasks()
+		})
+
+		It("lists the scheduled tasks", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response).To(ConsistOf(
+				cf.ScheduledTaskResponse{GUID: "task-1", Schedule: "@hourly"},
+				cf.ScheduledTaskResponse{GUID: "task-2", Schedule: "@daily"},
+			))
+		})
+
+		When("listing the scheduled tasks fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.ListReturns(nil, errors.New("list-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("list-err")))
+			})
+		})
+	})
+
+	Describe("Delete Scheduled Task", func() {
+		JustBeforeEach(func() {
+			err = scheduledTaskBifrost.DeleteScheduledTask("task-guid")
+		})
+
+		It("deletes the scheduled task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledTaskDesirer.DeleteCallCount()).To(Equal(1))
+			Expect(scheduledTaskDesirer.DeleteArgsForCall(0)).To(Equal("task-guid"))
+		})
+
+		When("deleting the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.DeleteReturns(errors.New("delete-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("delete-err")))
+			})
+		})
+	})
+})
@@ -51,7 +51,7 @@ func (t *Task) GetTask(taskGUID string) (cf.TaskResponse, error) {
 		return cf.TaskResponse{}, errors.Wrap(err, "failed to get task")
 	}
 
-	return cf.TaskResponse{GUID: task.GUID}, nil
+	return toTaskResponse(task), nil
 }
 
 func (t *Task) ListTasks() (cf.TasksResponse, error) {
@@ -62,7 +62,7 @@ func (t *Task) ListTasks() (cf.TasksResponse, error) {
 
 	tasksResp := cf.TasksResponse{}
 	for _, task := range tasks {
-		tasksResp = append(tasksResp, cf.TaskResponse{GUID: task.GUID})
+		tasksResp = append(tasksResp, toTaskResponse(task))
 	}
 
 	return tasksResp, nil
@@ -99,3 +99,22 @@ func (t *Task) CancelTask(taskGUID string) error {
 
 	return nil
 }
+
+func toTaskResponse(task *opi.Task) cf.TaskResponse {
+	return cf.TaskResponse{
+		GUID:          task.GUID,
+		Name:          task.Name,
+		AppGUID:       task.AppGUID,
+		AppName:       task.AppName,
+		OrgGUID:       task.OrgGUID,
+		OrgName:       task.OrgName,
+		SpaceGUID:     task.SpaceGUID,
+		SpaceName:     task.SpaceName,
+		Command:       task.Command,
+		State:         task.Status.State,
+		StartedAt:     task.Status.StartedAt,
+		FinishedAt:    task.Status.FinishedAt,
+		ExitCode:      task.Status.ExitCode,
+		FailureReason: task.Status.FailureReason,
+	}
+}
@@ -116,7 +116,25 @@ var _ = Describe("Task", func() {
 		var taskResponse cf.TaskResponse
 
 		BeforeEach(func() {
-			taskDesirer.GetReturns(&opi.Task{GUID: taskGUID}, nil)
+			exitCode := int32(3)
+			taskDesirer.GetReturns(&opi.Task{
+				GUID:      taskGUID,
+				Name:      "task-name",
+				AppGUID:   "app-guid",
+				AppName:   "app-name",
+				OrgGUID:   "org-guid",
+				OrgName:   "org-name",
+				SpaceGUID: "space-guid",
+				SpaceName: "space-name",
+				Command:   []string{"run", "it"},
+				Status: opi.TaskStatus{
+					State:         opi.TaskFailedState,
+					StartedAt:     123,
+					FinishedAt:    456,
+					ExitCode:      &exitCode,
+					FailureReason: "Error",
+				},
+			}, nil)
 		})
 
 		JustBeforeEach(func() {
@@ -133,6 +151,26 @@ var _ = Describe("Task", func() {
 			Expect(taskResponse.GUID).To(Equal(taskGUID))
 		})
 
+		It("returns the task status and metadata", func() {
+			exitCode := int32(3)
+			Expect(taskResponse).To(Equal(cf.TaskResponse{
+				GUID:          taskGUID,
+				Name:          "task-name",
+				AppGUID:       "app-guid",
+				AppName:       "app-name",
+				OrgGUID:       "org-guid",
+				OrgName:       "org-name",
+				SpaceGUID:     "space-guid",
+				SpaceName:     "space-name",
+				Command:       []string{"run", "it"},
+				State:         "FAILED",
+				StartedAt:     123,
+				FinishedAt:    456,
+				ExitCode:      &exitCode,
+				FailureReason: "Error",
+			}))
+		})
+
 		When("finding the task fails", func() {
 			BeforeEach(func() {
 				taskDesirer.GetReturns(nil, errors.New("task-error"))
@@ -148,7 +186,10 @@ var _ = Describe("Task", func() {
 		var tasksResponse cf.TasksResponse
 
 		BeforeEach(func() {
-			taskDesirer.ListReturns([]*opi.Task{{GUID: taskGUID}}, nil)
+			taskDesirer.ListReturns([]*opi.Task{{
+				GUID:   taskGUID,
+				Status: opi.TaskStatus{State: opi.TaskRunningState},
+			}}, nil)
 		})
 
 		JustBeforeEach(func() {
@@ -163,6 +204,7 @@ var _ = Describe("Task", func() {
 			Expect(taskDesirer.ListCallCount()).To(Equal(1))
 			Expect(tasksResponse).To(HaveLen(1))
 			Expect(tasksResponse[0].GUID).To(Equal(taskGUID))
+			Expect(tasksResponse[0].State).To(Equal("RUNNING"))
 		})
 
 		When("listing tasks fails", func() {
@@ -20,7 +20,9 @@ import (
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
@@ -79,7 +81,9 @@ func main() {
 
 	lrpReconciler := createLRPReconciler(logger, controllerClient, clientset, eiriniCfg, mgr.GetScheme())
 	taskReconciler := createTaskReconciler(logger, controllerClient, clientset, eiriniCfg, mgr.GetScheme())
+	scheduledTaskReconciler := createScheduledTaskReconciler(logger, controllerClient, clientset, eiriniCfg, mgr.GetScheme())
 	podCrashReconciler := createPodCrashReconciler(logger, eiriniCfg.WorkloadsNamespace, controllerClient, clientset)
+	appPolicyReconciler := createAppPolicyReconciler(logger, controllerClient, clientset, eiriniCfg)
 
 	err = builder.
 		ControllerManagedBy(mgr).
@@ -95,6 +99,13 @@ func main() {
 		Complete(taskReconciler)
 	cmdcommons.ExitfIfError(err, "Failed to build Task reconciler")
 
+	err = builder.
+		ControllerManagedBy(mgr).
+		For(&eiriniv1.ScheduledTask{}).
+		Owns(&batchv1beta1.CronJob{}).
+		Complete(scheduledTaskReconciler)
+	cmdcommons.ExitfIfError(err, "Failed to build ScheduledTask reconciler")
+
 	predicates := []predicate.Predicate{reconciler.NewSourceTypeUpdatePredicate("APP")}
 	err = builder.
 		ControllerManagedBy(mgr).
@@ -102,6 +113,12 @@ func main() {
 		Complete(podCrashReconciler)
 	cmdcommons.ExitfIfError(err, "Failed to build Pod Crash reconciler")
 
+	err = builder.
+		ControllerManagedBy(mgr).
+		For(&appsv1.StatefulSet{}, builder.WithPredicates(predicate.NewPredicateFuncs(isAppStatefulSet))).
+		Complete(appPolicyReconciler)
+	cmdcommons.ExitfIfError(err, "Failed to build App Policy reconciler")
+
 	err = mgr.Start(ctrl.SetupSignalHandler())
 	cmdcommons.ExitfIfError(err, "Failed to start manager")
 }
@@ -125,19 +142,32 @@ func createLRPReconciler(
 	eiriniCfg *eirini.Config,
 	scheme *runtime.Scheme,
 ) *reconciler.LRP {
+	probeDefaults := k8s.ProbeDefaults{
+		TimeoutSeconds:      eiriniCfg.Properties.ProbeTimeoutSeconds,
+		PeriodSeconds:       eiriniCfg.Properties.ProbePeriodSeconds,
+		FailureThreshold:    eiriniCfg.Properties.ProbeFailureThreshold,
+		StartTimeoutSeconds: eiriniCfg.Properties.ProbeStartTimeoutSeconds,
+	}
+
 	stDesirer := &k8s.StatefulSetDesirer{
 		Pods:                              client.NewPod(clientset, eiriniCfg.WorkloadsNamespace),
 		Secrets:                           client.NewSecret(clientset),
 		StatefulSets:                      client.NewStatefulSet(clientset, eiriniCfg.WorkloadsNamespace),
 		PodDisruptionBudgets:              client.NewPodDisruptionBudget(clientset),
+		NetworkPolicies:                   client.NewNetworkPolicy(clientset, eiriniCfg.WorkloadsNamespace),
+		Services:                          client.NewService(clientset),
 		EventsClient:                      client.NewEvent(clientset),
 		StatefulSetToLRPMapper:            k8s.StatefulSetToLRP,
 		RegistrySecretName:                eiriniCfg.Properties.RegistrySecretName,
-		LivenessProbeCreator:              k8s.CreateLivenessProbe,
-		ReadinessProbeCreator:             k8s.CreateReadinessProbe,
+		LivenessProbeCreator:              k8s.NewLivenessProbeCreator(probeDefaults),
+		ReadinessProbeCreator:             k8s.NewReadinessProbeCreator(probeDefaults),
+		StartupProbeCreator:               k8s.NewStartupProbeCreator(probeDefaults),
 		Logger:                            logger.Session("stateful-set-desirer"),
 		ApplicationServiceAccount:         eiriniCfg.Properties.ApplicationServiceAccount,
 		AllowAutomountServiceAccountToken: eiriniCfg.Properties.UnsafeAllowAutomountServiceAccountToken,
+		NodePlacements:                    eiriniCfg.Properties.NodePlacements,
+		InternalServiceType:               eiriniCfg.Properties.InternalServiceType,
+		SensitiveEnv:                      eiriniCfg.Properties.SensitiveEnvPatterns,
 	}
 
 	return reconciler.NewLRP(
@@ -154,16 +184,45 @@ func createTaskReconciler(
 	clientset kubernetes.Interface,
 	eiriniCfg *eirini.Config,
 	scheme *runtime.Scheme) *reconciler.Task {
-	taskDesirer := k8s.NewTaskDesirer(
+	taskDesirer := createTaskDesirer(logger, clientset, eiriniCfg)
+
+	return reconciler.NewTask(logger, controllerClient, taskDesirer, scheme)
+}
+
+func createScheduledTaskReconciler(
+	logger lager.Logger,
+	controllerClient runtimeclient.Client,
+	clientset kubernetes.Interface,
+	eiriniCfg *eirini.Config,
+	scheme *runtime.Scheme) *reconciler.ScheduledTask {
+	scheduledTaskDesirer := k8s.NewScheduledTaskDesirer(
+		logger,
+		client.NewCronJob(clientset, eiriniCfg.WorkloadsNamespace),
+		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, eiriniCfg.WorkloadsNamespace),
+		createTaskDesirer(logger, clientset, eiriniCfg),
+	)
+
+	return reconciler.NewScheduledTask(logger, controllerClient, scheduledTaskDesirer, scheme)
+}
+
+func createTaskDesirer(logger lager.Logger, clientset kubernetes.Interface, eiriniCfg *eirini.Config) *k8s.TaskDesirer {
+	return k8s.NewTaskDesirer(
 		logger,
 		client.NewJob(clientset, eiriniCfg.WorkloadsNamespace),
+		client.NewPod(clientset, eiriniCfg.WorkloadsNamespace),
 		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, eiriniCfg.WorkloadsNamespace),
 		eiriniCfg.Properties.ApplicationServiceAccount,
 		eiriniCfg.Properties.RegistrySecretName,
 		eiriniCfg.Properties.UnsafeAllowAutomountServiceAccountToken,
+		eiriniCfg.Properties.NodePlacements,
+		eiriniCfg.Properties.SensitiveEnvPatterns,
+		k8s.TaskTimeouts{
+			DefaultSeconds: eiriniCfg.Properties.TaskTimeoutSeconds,
+			MaxSeconds:     eiriniCfg.Properties.MaxTaskTimeoutSeconds,
+		},
 	)
-
-	return reconciler.NewTask(logger, controllerClient, taskDesirer, scheme)
 }
 
 func createPodCrashReconciler(
@@ -177,3 +236,22 @@ func createPodCrashReconciler(
 
 	return reconciler.NewPodCrash(logger, controllerClient, crashEventGenerator, eventsClient, statefulSetClient)
 }
+
+func createAppPolicyReconciler(
+	logger lager.Logger,
+	controllerClient runtimeclient.Client,
+	clientset kubernetes.Interface,
+	eiriniCfg *eirini.Config) *reconciler.AppPolicy {
+	appPolicyDesirer := k8s.NewAppPolicyDesirer(
+		logger.Session("app-policy-desirer"),
+		client.NewNetworkPolicy(clientset, eiriniCfg.WorkloadsNamespace),
+		client.NewStatefulSet(clientset, eiriniCfg.WorkloadsNamespace),
+		eiriniCfg.Properties.DefaultWorkloadsNamespace,
+	)
+
+	return reconciler.NewAppPolicy(logger, controllerClient, appPolicyDesirer)
+}
+
+func isAppStatefulSet(meta metav1.Object, _ runtime.Object) bool {
+	return meta.GetLabels()[k8s.LabelSourceType] == k8s.AppSourceType
+}
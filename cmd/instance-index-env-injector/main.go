@@ -4,10 +4,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"code.cloudfoundry.org/eirini"
 	cmdcommons "code.cloudfoundry.org/eirini/cmd"
+	"code.cloudfoundry.org/eirini/k8s/client"
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
 	"code.cloudfoundry.org/eirini/k8s/webhook"
+	"code.cloudfoundry.org/eirini/util"
 	eirinix "code.cloudfoundry.org/eirinix"
 	"code.cloudfoundry.org/lager"
 	"github.com/jessevdk/go-flags"
@@ -49,9 +53,48 @@ func main() {
 	err = manager.AddExtension(webhook.NewInstanceIndexEnvInjector(log))
 	cmdcommons.ExitfIfError(err, "failed to register the instance index env injector extension")
 
+	if cfg.InstanceIdentityCASecretName != "" {
+		registerInstanceIdentityInjector(log, cfg, manager)
+	}
+
 	log.Fatal("instance-index-env-injector-errored", manager.Start())
 }
 
+func registerInstanceIdentityInjector(log lager.Logger, cfg *eirini.InstanceIndexEnvInjectorConfig, manager eirinix.Manager) {
+	clientset := cmdcommons.CreateKubeClient(cfg.ConfigPath)
+	secrets := client.NewSecret(clientset)
+
+	caSecret, err := secrets.Get(cfg.InstanceIdentityCASecretNamespace, cfg.InstanceIdentityCASecretName)
+	cmdcommons.ExitfIfError(err, "Failed to get the instance identity CA secret")
+
+	ca, err := instanceid.NewCAFromSecret(caSecret)
+	cmdcommons.ExitfIfError(err, "Failed to load the instance identity CA")
+
+	validityInSecs := cfg.InstanceIdentityCertValidityInSecs
+	if validityInSecs == 0 {
+		validityInSecs = eirini.InstanceIdentityCertValidityInSecs
+	}
+
+	rotationIntervalInSecs := cfg.InstanceIdentityRotationIntervalInSecs
+	if rotationIntervalInSecs == 0 {
+		rotationIntervalInSecs = eirini.InstanceIdentityRotationIntervalInSecs
+	}
+
+	validity := time.Duration(validityInSecs) * time.Second
+
+	err = manager.AddExtension(webhook.NewInstanceIdentityInjector(log, ca, secrets, validity))
+	cmdcommons.ExitfIfError(err, "failed to register the instance identity injector extension")
+
+	pods := client.NewPod(clientset, cfg.WorkloadsNamespace)
+	rotator := instanceid.NewRotator(log, secrets, pods, ca, cfg.WorkloadsNamespace, validity, validity/2)
+	scheduler := &util.TickerTaskScheduler{
+		Ticker: time.NewTicker(time.Duration(rotationIntervalInSecs) * time.Second),
+		Logger: log.Session("instance-identity-rotator"),
+	}
+
+	go scheduler.Schedule(rotator.Rotate)
+}
+
 func readConfigFile(path string) (*eirini.InstanceIndexEnvInjectorConfig, error) {
 	fileBytes, err := ioutil.ReadFile(filepath.Clean(path))
 	if err != nil {
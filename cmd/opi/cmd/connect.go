@@ -38,12 +38,15 @@ func connect(cmd *cobra.Command, args []string) {
 	clientset := cmdcommons.CreateKubeClient(cfg.Properties.ConfigPath)
 
 	dockerStagingBifrost := initDockerStagingBifrost(cfg)
+	buildpackStagingBifrost := initBuildpackStagingBifrost(cfg, clientset)
 	taskBifrost := initTaskBifrost(cfg, clientset)
+	scheduledTaskBifrost := initScheduledTaskBifrost(cfg, clientset)
 	bifrost := initLRPBifrost(clientset, cfg)
+	policyBifrost := initPolicyBifrost(cfg, clientset)
 
 	handlerLogger := lager.NewLogger("handler")
 	handlerLogger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
-	handler := handler.New(bifrost, dockerStagingBifrost, taskBifrost, handlerLogger)
+	handler := handler.New(bifrost, dockerStagingBifrost, buildpackStagingBifrost, taskBifrost, scheduledTaskBifrost, policyBifrost, handlerLogger)
 	handlerLogger.Info("opi-connected")
 
 	if cfg.Properties.ServePlaintext {
@@ -124,14 +127,22 @@ func initTaskDesirer(cfg *eirini.Config, clientset kubernetes.Interface) *k8s.Ta
 	return k8s.NewTaskDesirer(
 		logger,
 		client.NewJob(clientset, cfg.WorkloadsNamespace),
+		client.NewPod(clientset, cfg.WorkloadsNamespace),
 		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, cfg.WorkloadsNamespace),
 		cfg.Properties.ApplicationServiceAccount,
 		cfg.Properties.RegistrySecretName,
 		cfg.Properties.UnsafeAllowAutomountServiceAccountToken,
+		cfg.Properties.NodePlacements,
+		cfg.Properties.SensitiveEnvPatterns,
+		k8s.TaskTimeouts{
+			DefaultSeconds: cfg.Properties.TaskTimeoutSeconds,
+			MaxSeconds:     cfg.Properties.MaxTaskTimeoutSeconds,
+		},
 	)
 }
 
-func initTaskDeleter(clientset kubernetes.Interface, jobClient k8s.JobDeletingClient) *k8s.TaskDeleter {
+func initTaskDeleter(cfg *eirini.Config, clientset kubernetes.Interface, jobClient k8s.JobDeletingClient) *k8s.TaskDeleter {
 	logger := lager.NewLogger("task-desirer")
 	logger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
 
@@ -139,6 +150,7 @@ func initTaskDeleter(clientset kubernetes.Interface, jobClient k8s.JobDeletingCl
 		logger,
 		jobClient,
 		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, cfg.WorkloadsNamespace),
 	)
 }
 
@@ -155,11 +167,40 @@ func initDockerStagingBifrost(cfg *eirini.Config) *bifrost.DockerStaging {
 	}
 }
 
+func initBuildpackStagingBifrost(cfg *eirini.Config, clientset kubernetes.Interface) *bifrost.BuildpackStaging {
+	logger := lager.NewLogger("buildpack-staging-bifrost")
+	logger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
+	stagingCompleter := initStagingCompleter(cfg, logger)
+	jobClient := client.NewJob(clientset, cfg.WorkloadsNamespace)
+	namespacer := bifrost.NewNamespacer(cfg.Properties.DefaultWorkloadsNamespace)
+
+	stagingDesirer := k8s.NewStagingDesirer(
+		logger,
+		jobClient,
+		cfg.Properties.ApplicationServiceAccount,
+		cfg.Properties.RegistrySecretName,
+		cfg.Properties.UnsafeAllowAutomountServiceAccountToken,
+		cfg.Properties.NodePlacements,
+	)
+
+	return &bifrost.BuildpackStaging{
+		Logger:           logger,
+		Namespacer:       namespacer,
+		StagingDesirer:   stagingDesirer,
+		StagingDeleter:   initTaskDeleter(cfg, clientset, jobClient),
+		StagingCompleter: stagingCompleter,
+		EiriniAddress:    cfg.Properties.EiriniAddress,
+		DownloaderImage:  cfg.Properties.DownloaderImage,
+		ExecutorImage:    cfg.Properties.ExecutorImage,
+		UploaderImage:    cfg.Properties.UploaderImage,
+	}
+}
+
 func initTaskBifrost(cfg *eirini.Config, clientset kubernetes.Interface) *bifrost.Task {
 	converter := initConverter(cfg)
 	taskDesirer := initTaskDesirer(cfg, clientset)
 	jobClient := client.NewJob(clientset, cfg.WorkloadsNamespace)
-	taskDeleter := initTaskDeleter(clientset, jobClient)
+	taskDeleter := initTaskDeleter(cfg, clientset, jobClient)
 	retryableJSONClient := initRetryableJSONClient(cfg)
 	namespacer := bifrost.NewNamespacer(cfg.Properties.DefaultWorkloadsNamespace)
 
@@ -172,6 +213,39 @@ func initTaskBifrost(cfg *eirini.Config, clientset kubernetes.Interface) *bifros
 	}
 }
 
+func initScheduledTaskBifrost(cfg *eirini.Config, clientset kubernetes.Interface) *bifrost.ScheduledTask {
+	logger := lager.NewLogger("scheduled-task-desirer")
+	logger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
+
+	scheduledTaskDesirer := k8s.NewScheduledTaskDesirer(
+		logger,
+		client.NewCronJob(clientset, cfg.WorkloadsNamespace),
+		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, cfg.WorkloadsNamespace),
+		initTaskDesirer(cfg, clientset),
+	)
+
+	return &bifrost.ScheduledTask{
+		Converter:            initConverter(cfg),
+		ScheduledTaskDesirer: scheduledTaskDesirer,
+		Namespacer:           bifrost.NewNamespacer(cfg.Properties.DefaultWorkloadsNamespace),
+	}
+}
+
+func initPolicyBifrost(cfg *eirini.Config, clientset kubernetes.Interface) *bifrost.AppPolicy {
+	logger := lager.NewLogger("app-policy-desirer")
+	logger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
+
+	return &bifrost.AppPolicy{
+		Desirer: k8s.NewAppPolicyDesirer(
+			logger,
+			client.NewNetworkPolicy(clientset, cfg.WorkloadsNamespace),
+			client.NewStatefulSet(clientset, cfg.WorkloadsNamespace),
+			cfg.Properties.DefaultWorkloadsNamespace,
+		),
+	}
+}
+
 func setConfigFromFile(path string) *eirini.Config {
 	fileBytes, err := ioutil.ReadFile(filepath.Clean(path))
 	cmdcommons.ExitfIfError(err, "Failed to read config file")
@@ -187,19 +261,32 @@ func initLRPBifrost(clientset kubernetes.Interface, cfg *eirini.Config) *bifrost
 	desireLogger := lager.NewLogger("desirer")
 	desireLogger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
 
+	probeDefaults := k8s.ProbeDefaults{
+		TimeoutSeconds:      cfg.Properties.ProbeTimeoutSeconds,
+		PeriodSeconds:       cfg.Properties.ProbePeriodSeconds,
+		FailureThreshold:    cfg.Properties.ProbeFailureThreshold,
+		StartTimeoutSeconds: cfg.Properties.ProbeStartTimeoutSeconds,
+	}
+
 	desirer := &k8s.StatefulSetDesirer{
 		Pods:                              client.NewPod(clientset, cfg.WorkloadsNamespace),
 		Secrets:                           client.NewSecret(clientset),
 		StatefulSets:                      client.NewStatefulSet(clientset, cfg.WorkloadsNamespace),
 		PodDisruptionBudgets:              client.NewPodDisruptionBudget(clientset),
+		NetworkPolicies:                   client.NewNetworkPolicy(clientset, cfg.WorkloadsNamespace),
+		Services:                          client.NewService(clientset),
 		EventsClient:                      client.NewEvent(clientset),
 		StatefulSetToLRPMapper:            k8s.StatefulSetToLRP,
 		RegistrySecretName:                cfg.Properties.RegistrySecretName,
-		LivenessProbeCreator:              k8s.CreateLivenessProbe,
-		ReadinessProbeCreator:             k8s.CreateReadinessProbe,
+		LivenessProbeCreator:              k8s.NewLivenessProbeCreator(probeDefaults),
+		ReadinessProbeCreator:             k8s.NewReadinessProbeCreator(probeDefaults),
+		StartupProbeCreator:               k8s.NewStartupProbeCreator(probeDefaults),
 		Logger:                            desireLogger,
 		ApplicationServiceAccount:         cfg.Properties.ApplicationServiceAccount,
 		AllowAutomountServiceAccountToken: cfg.Properties.UnsafeAllowAutomountServiceAccountToken,
+		NodePlacements:                    cfg.Properties.NodePlacements,
+		InternalServiceType:               cfg.Properties.InternalServiceType,
+		SensitiveEnv:                      cfg.Properties.SensitiveEnvPatterns,
 	}
 	converter := initConverter(cfg)
 	namespacer := bifrost.NewNamespacer(cfg.Properties.DefaultWorkloadsNamespace)
@@ -220,5 +307,7 @@ func initConverter(cfg *eirini.Config) *bifrost.OPIConverter {
 		docker.Fetch,
 		docker.Parse,
 		cfg.Properties.AllowRunImageAsRoot,
+		cfg.Properties.DownloaderImage,
+		cfg.Properties.StackImages,
 	)
 }
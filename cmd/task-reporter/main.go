@@ -10,13 +10,16 @@ import (
 	cmdcommons "code.cloudfoundry.org/eirini/cmd"
 	"code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/k8s/client"
+	k8sstaging "code.cloudfoundry.org/eirini/k8s/informers/staging"
 	k8stask "code.cloudfoundry.org/eirini/k8s/informers/task"
 	"code.cloudfoundry.org/eirini/k8s/reconciler"
+	"code.cloudfoundry.org/eirini/stager"
 	"code.cloudfoundry.org/eirini/util"
 	"code.cloudfoundry.org/lager"
 	"github.com/jessevdk/go-flags"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
@@ -100,6 +103,21 @@ func main() {
 		Complete(taskReconciler)
 	cmdcommons.ExitfIfError(err, "Failed to build task reporter reconciler")
 
+	stagingLogger := lager.NewLogger("staging-informer")
+	stagingLogger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.DEBUG))
+
+	stagingReconciler := k8sstaging.NewReconciler(stagingLogger,
+		mgr.GetClient(),
+		stager.NewCallbackStagingCompleter(stagingLogger, util.NewRetryableJSONClient(httpClient)),
+		initTaskDeleter(clientset, cfg.WorkloadsNamespace),
+	)
+
+	err = builder.
+		ControllerManagedBy(mgr).
+		For(&batchv1.Job{}, builder.WithPredicates(reconciler.NewSourceTypeUpdatePredicate(k8s.StagingSourceType))).
+		Complete(stagingReconciler)
+	cmdcommons.ExitfIfError(err, "Failed to build staging reporter reconciler")
+
 	err = mgr.Start(ctrl.SetupSignalHandler())
 	cmdcommons.ExitfIfError(err, "Failed to start manager")
 }
@@ -112,6 +130,7 @@ func initTaskDeleter(clientset kubernetes.Interface, workloadsNamespace string)
 		logger,
 		client.NewJob(clientset, workloadsNamespace),
 		client.NewSecret(clientset),
+		client.NewNetworkPolicy(clientset, workloadsNamespace),
 	)
 }
 
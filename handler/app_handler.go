@@ -172,7 +172,14 @@ func (a *App) Update(w http.ResponseWriter, r *http.Request, ps httprouter.Param
 
 	if err := a.lrpBifrost.Update(r.Context(), request); err != nil {
 		loggerSession.Error("bifrost-failed", err)
-		writeUpdateErrorResponse(w, err, http.StatusInternalServerError, loggerSession)
+
+		statusCode := http.StatusInternalServerError
+
+		if errors.Is(err, eirini.ErrInvalidLRPUpdate) {
+			statusCode = http.StatusBadRequest
+		}
+
+		writeUpdateErrorResponse(w, err, statusCode, loggerSession)
 	}
 }
 
@@ -30,7 +30,7 @@ var _ = Describe("AppHandler", func() {
 	BeforeEach(func() {
 		lrpBifrost = new(handlerfakes.FakeLRPBifrost)
 		lager = lagertest.NewTestLogger("app-handler-test")
-		ts = httptest.NewServer(New(lrpBifrost, nil, nil, lager))
+		ts = httptest.NewServer(New(lrpBifrost, nil, nil, nil, nil, nil, lager))
 	})
 
 	AfterEach(func() {
@@ -531,6 +531,20 @@ var _ = Describe("AppHandler", func() {
 				verifyResponseObject()
 			})
 		})
+
+		Context("when the update is invalid", func() {
+			BeforeEach(func() {
+				lrpBifrost.UpdateReturns(errors.Wrap(eirini.ErrInvalidLRPUpdate, "health check port 7070 is not one of the app ports"))
+			})
+
+			It("should return a 400 HTTP status code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("shoud return a response object containing the error", func() {
+				verifyResponseObject()
+			})
+		})
 	})
 
 	Context("Stop an app", func() {
@@ -12,7 +12,10 @@ import (
 
 //counterfeiter:generate . LRPBifrost
 //counterfeiter:generate . StagingBifrost
+//counterfeiter:generate . BuildpackStagingBifrost
 //counterfeiter:generate . TaskBifrost
+//counterfeiter:generate . ScheduledTaskBifrost
+//counterfeiter:generate . PolicyBifrost
 
 type LRPBifrost interface {
 	Transfer(ctx context.Context, request cf.DesireLRPRequest) error
@@ -31,24 +34,50 @@ type TaskBifrost interface {
 	CancelTask(taskGUID string) error
 }
 
+type ScheduledTaskBifrost interface {
+	GetScheduledTask(taskGUID string) (cf.ScheduledTaskResponse, error)
+	ListScheduledTasks() (cf.ScheduledTasksResponse, error)
+	TransferScheduledTask(ctx context.Context, taskGUID string, request cf.ScheduledTaskRequest) error
+	UpdateScheduledTask(taskGUID string, request cf.ScheduledTaskUpdateRequest) error
+	DeleteScheduledTask(taskGUID string) error
+}
+
+type PolicyBifrost interface {
+	CreatePolicies(request cf.NetworkPoliciesRequest) error
+	ListPolicies() (cf.NetworkPoliciesResponse, error)
+	DeletePolicies(request cf.NetworkPoliciesRequest) error
+}
+
 type StagingBifrost interface {
 	TransferStaging(ctx context.Context, stagingGUID string, request cf.StagingRequest) error
 	CompleteStaging(cf.StagingCompletedRequest) error
 }
 
+type BuildpackStagingBifrost interface {
+	StagingBifrost
+	CancelStaging(stagingGUID string) error
+}
+
 func New(lrpBifrost LRPBifrost,
 	dockerStagingBifrost StagingBifrost,
+	buildpackStagingBifrost BuildpackStagingBifrost,
 	taskBifrost TaskBifrost,
+	scheduledTaskBifrost ScheduledTaskBifrost,
+	policyBifrost PolicyBifrost,
 	lager lager.Logger) http.Handler {
 	handler := httprouter.New()
 
 	appHandler := NewAppHandler(lrpBifrost, lager)
-	stageHandler := NewStageHandler(dockerStagingBifrost, lager)
+	stageHandler := NewStageHandler(dockerStagingBifrost, buildpackStagingBifrost, lager)
 	taskHandler := NewTaskHandler(lager, taskBifrost)
+	scheduledTaskHandler := NewScheduledTaskHandler(lager, scheduledTaskBifrost)
+	policyHandler := NewPolicyHandler(lager, policyBifrost)
 
 	registerAppsEndpoints(handler, appHandler)
 	registerStageEndpoint(handler, stageHandler)
 	registerTaskEndpoints(handler, taskHandler)
+	registerScheduledTaskEndpoints(handler, scheduledTaskHandler)
+	registerPolicyEndpoints(handler, policyHandler)
 
 	return handler
 }
@@ -65,6 +94,8 @@ func registerAppsEndpoints(handler *httprouter.Router, appHandler *App) {
 
 func registerStageEndpoint(handler *httprouter.Router, stageHandler *Stage) {
 	handler.POST("/stage/:staging_guid", stageHandler.Run)
+	handler.PUT("/stage/:staging_guid/completed", stageHandler.StagingComplete)
+	handler.DELETE("/stage/:staging_guid", stageHandler.Cancel)
 }
 
 func registerTaskEndpoints(handler *httprouter.Router, taskHandler *Task) {
@@ -73,3 +104,17 @@ func registerTaskEndpoints(handler *httprouter.Router, taskHandler *Task) {
 	handler.POST("/tasks/:task_guid", taskHandler.Run)
 	handler.DELETE("/tasks/:task_guid", taskHandler.Cancel)
 }
+
+func registerScheduledTaskEndpoints(handler *httprouter.Router, scheduledTaskHandler *ScheduledTask) {
+	handler.GET("/scheduled_tasks", scheduledTaskHandler.List)
+	handler.GET("/scheduled_tasks/:task_guid", scheduledTaskHandler.Get)
+	handler.POST("/scheduled_tasks/:task_guid", scheduledTaskHandler.Desire)
+	handler.PUT("/scheduled_tasks/:task_guid", scheduledTaskHandler.Update)
+	handler.DELETE("/scheduled_tasks/:task_guid", scheduledTaskHandler.Delete)
+}
+
+func registerPolicyEndpoints(handler *httprouter.Router, policyHandler *Policy) {
+	handler.GET("/policies", policyHandler.List)
+	handler.POST("/policies", policyHandler.Create)
+	handler.POST("/policies/delete", policyHandler.Delete)
+}
@@ -15,22 +15,28 @@ import (
 
 var _ = Describe("Handler", func() {
 	var (
-		ts                   *httptest.Server
-		client               *http.Client
-		lrpBifrost           *handlerfakes.FakeLRPBifrost
-		dockerStagingBifrost *handlerfakes.FakeStagingBifrost
-		taskBifrost          *handlerfakes.FakeTaskBifrost
-		handlerClient        http.Handler
+		ts                      *httptest.Server
+		client                  *http.Client
+		lrpBifrost              *handlerfakes.FakeLRPBifrost
+		dockerStagingBifrost    *handlerfakes.FakeStagingBifrost
+		buildpackStagingBifrost *handlerfakes.FakeBuildpackStagingBifrost
+		taskBifrost             *handlerfakes.FakeTaskBifrost
+		scheduledTaskBifrost    *handlerfakes.FakeScheduledTaskBifrost
+		policyBifrost           *handlerfakes.FakePolicyBifrost
+		handlerClient           http.Handler
 	)
 
 	BeforeEach(func() {
 		client = &http.Client{}
 		lrpBifrost = new(handlerfakes.FakeLRPBifrost)
 		dockerStagingBifrost = new(handlerfakes.FakeStagingBifrost)
+		buildpackStagingBifrost = new(handlerfakes.FakeBuildpackStagingBifrost)
 		taskBifrost = new(handlerfakes.FakeTaskBifrost)
+		scheduledTaskBifrost = new(handlerfakes.FakeScheduledTaskBifrost)
+		policyBifrost = new(handlerfakes.FakePolicyBifrost)
 
 		lager := lagertest.NewTestLogger("handler-test")
-		handlerClient = New(lrpBifrost, dockerStagingBifrost, taskBifrost, lager)
+		handlerClient = New(lrpBifrost, dockerStagingBifrost, buildpackStagingBifrost, taskBifrost, scheduledTaskBifrost, policyBifrost, lager)
 	})
 
 	JustBeforeEach(func() {
@@ -156,6 +162,30 @@ var _ = Describe("Handler", func() {
 			})
 		})
 
+		Context("PUT /stage/:staging_guid/completed", func() {
+			BeforeEach(func() {
+				method = "PUT"
+				path = "/stage/stage_123/completed"
+				expectedStatus = http.StatusOK
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("DELETE /stage/:staging_guid", func() {
+			BeforeEach(func() {
+				method = "DELETE"
+				path = "/stage/stage_123"
+				expectedStatus = http.StatusNoContent
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
 		Context("POST /tasks/:id", func() {
 			BeforeEach(func() {
 				method = "POST"
@@ -184,5 +214,101 @@ var _ = Describe("Handler", func() {
 				assertEndpoint()
 			})
 		})
+
+		Context("GET /scheduled_tasks", func() {
+			BeforeEach(func() {
+				method = "GET"
+				path = "/scheduled_tasks"
+				expectedStatus = http.StatusOK
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("GET /scheduled_tasks/:id", func() {
+			BeforeEach(func() {
+				method = "GET"
+				path = "/scheduled_tasks/task_123"
+				expectedStatus = http.StatusOK
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("POST /scheduled_tasks/:id", func() {
+			BeforeEach(func() {
+				method = "POST"
+				path = "/scheduled_tasks/task_123"
+				expectedStatus = http.StatusAccepted
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("PUT /scheduled_tasks/:id", func() {
+			BeforeEach(func() {
+				method = "PUT"
+				path = "/scheduled_tasks/task_123"
+				expectedStatus = http.StatusOK
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("DELETE /scheduled_tasks/:id", func() {
+			BeforeEach(func() {
+				method = "DELETE"
+				path = "/scheduled_tasks/task_123"
+				expectedStatus = http.StatusNoContent
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("GET /policies", func() {
+			BeforeEach(func() {
+				method = "GET"
+				path = "/policies"
+				expectedStatus = http.StatusOK
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("POST /policies", func() {
+			BeforeEach(func() {
+				method = "POST"
+				path = "/policies"
+				expectedStatus = http.StatusCreated
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
+
+		Context("POST /policies/delete", func() {
+			BeforeEach(func() {
+				method = "POST"
+				path = "/policies/delete"
+				expectedStatus = http.StatusNoContent
+			})
+
+			It("serves the endpoint", func() {
+				assertEndpoint()
+			})
+		})
 	})
 })
@@ -0,0 +1,265 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package handlerfakes
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/eirini/handler"
+	"code.cloudfoundry.org/eirini/models/cf"
+)
+
+type FakeBuildpackStagingBifrost struct {
+	CancelStagingStub        func(string) error
+	cancelStagingMutex       sync.RWMutex
+	cancelStagingArgsForCall []struct {
+		arg1 string
+	}
+	cancelStagingReturns struct {
+		result1 error
+	}
+	cancelStagingReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CompleteStagingStub        func(cf.StagingCompletedRequest) error
+	completeStagingMutex       sync.RWMutex
+	completeStagingArgsForCall []struct {
+		arg1 cf.StagingCompletedRequest
+	}
+	completeStagingReturns struct {
+		result1 error
+	}
+	completeStagingReturnsOnCall map[int]struct {
+		result1 error
+	}
+	TransferStagingStub        func(context.Context, string, cf.StagingRequest) error
+	transferStagingMutex       sync.RWMutex
+	transferStagingArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 cf.StagingRequest
+	}
+	transferStagingReturns struct {
+		result1 error
+	}
+	transferStagingReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStaging(arg1 string) error {
+	fake.cancelStagingMutex.Lock()
+	ret, specificReturn := fake.cancelStagingReturnsOnCall[len(fake.cancelStagingArgsForCall)]
+	fake.cancelStagingArgsForCall = append(fake.cancelStagingArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CancelStagingStub
+	fakeReturns := fake.cancelStagingReturns
+	fake.recordInvocation("CancelStaging", []interface{}{arg1})
+	fake.cancelStagingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStagingCallCount() int {
+	fake.cancelStagingMutex.RLock()
+	defer fake.cancelStagingMutex.RUnlock()
+	return len(fake.cancelStagingArgsForCall)
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStagingCalls(stub func(string) error) {
+	fake.cancelStagingMutex.Lock()
+	defer fake.cancelStagingMutex.Unlock()
+	fake.CancelStagingStub = stub
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStagingArgsForCall(i int) string {
+	fake.cancelStagingMutex.RLock()
+	defer fake.cancelStagingMutex.RUnlock()
+	argsForCall := fake.cancelStagingArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStagingReturns(result1 error) {
+	fake.cancelStagingMutex.Lock()
+	defer fake.cancelStagingMutex.Unlock()
+	fake.CancelStagingStub = nil
+	fake.cancelStagingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) CancelStagingReturnsOnCall(i int, result1 error) {
+	fake.cancelStagingMutex.Lock()
+	defer fake.cancelStagingMutex.Unlock()
+	fake.CancelStagingStub = nil
+	if fake.cancelStagingReturnsOnCall == nil {
+		fake.cancelStagingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelStagingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStaging(arg1 cf.StagingCompletedRequest) error {
+	fake.completeStagingMutex.Lock()
+	ret, specificReturn := fake.completeStagingReturnsOnCall[len(fake.completeStagingArgsForCall)]
+	fake.completeStagingArgsForCall = append(fake.completeStagingArgsForCall, struct {
+		arg1 cf.StagingCompletedRequest
+	}{arg1})
+	stub := fake.CompleteStagingStub
+	fakeReturns := fake.completeStagingReturns
+	fake.recordInvocation("CompleteStaging", []interface{}{arg1})
+	fake.completeStagingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStagingCallCount() int {
+	fake.completeStagingMutex.RLock()
+	defer fake.completeStagingMutex.RUnlock()
+	return len(fake.completeStagingArgsForCall)
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStagingCalls(stub func(cf.StagingCompletedRequest) error) {
+	fake.completeStagingMutex.Lock()
+	defer fake.completeStagingMutex.Unlock()
+	fake.CompleteStagingStub = stub
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStagingArgsForCall(i int) cf.StagingCompletedRequest {
+	fake.completeStagingMutex.RLock()
+	defer fake.completeStagingMutex.RUnlock()
+	argsForCall := fake.completeStagingArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStagingReturns(result1 error) {
+	fake.completeStagingMutex.Lock()
+	defer fake.completeStagingMutex.Unlock()
+	fake.CompleteStagingStub = nil
+	fake.completeStagingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) CompleteStagingReturnsOnCall(i int, result1 error) {
+	fake.completeStagingMutex.Lock()
+	defer fake.completeStagingMutex.Unlock()
+	fake.CompleteStagingStub = nil
+	if fake.completeStagingReturnsOnCall == nil {
+		fake.completeStagingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.completeStagingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStaging(arg1 context.Context, arg2 string, arg3 cf.StagingRequest) error {
+	fake.transferStagingMutex.Lock()
+	ret, specificReturn := fake.transferStagingReturnsOnCall[len(fake.transferStagingArgsForCall)]
+	fake.transferStagingArgsForCall = append(fake.transferStagingArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 cf.StagingRequest
+	}{arg1, arg2, arg3})
+	stub := fake.TransferStagingStub
+	fakeReturns := fake.transferStagingReturns
+	fake.recordInvocation("TransferStaging", []interface{}{arg1, arg2, arg3})
+	fake.transferStagingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStagingCallCount() int {
+	fake.transferStagingMutex.RLock()
+	defer fake.transferStagingMutex.RUnlock()
+	return len(fake.transferStagingArgsForCall)
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStagingCalls(stub func(context.Context, string, cf.StagingRequest) error) {
+	fake.transferStagingMutex.Lock()
+	defer fake.transferStagingMutex.Unlock()
+	fake.TransferStagingStub = stub
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStagingArgsForCall(i int) (context.Context, string, cf.StagingRequest) {
+	fake.transferStagingMutex.RLock()
+	defer fake.transferStagingMutex.RUnlock()
+	argsForCall := fake.transferStagingArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStagingReturns(result1 error) {
+	fake.transferStagingMutex.Lock()
+	defer fake.transferStagingMutex.Unlock()
+	fake.TransferStagingStub = nil
+	fake.transferStagingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) TransferStagingReturnsOnCall(i int, result1 error) {
+	fake.transferStagingMutex.Lock()
+	defer fake.transferStagingMutex.Unlock()
+	fake.TransferStagingStub = nil
+	if fake.transferStagingReturnsOnCall == nil {
+		fake.transferStagingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.transferStagingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBuildpackStagingBifrost) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cancelStagingMutex.RLock()
+	defer fake.cancelStagingMutex.RUnlock()
+	fake.completeStagingMutex.RLock()
+	defer fake.completeStagingMutex.RUnlock()
+	fake.transferStagingMutex.RLock()
+	defer fake.transferStagingMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBuildpackStagingBifrost) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ handler.BuildpackStagingBifrost = new(FakeBuildpackStagingBifrost)
@@ -0,0 +1,256 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package handlerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/handler"
+	"code.cloudfoundry.org/eirini/models/cf"
+)
+
+type FakePolicyBifrost struct {
+	CreatePoliciesStub        func(cf.NetworkPoliciesRequest) error
+	createPoliciesMutex       sync.RWMutex
+	createPoliciesArgsForCall []struct {
+		arg1 cf.NetworkPoliciesRequest
+	}
+	createPoliciesReturns struct {
+		result1 error
+	}
+	createPoliciesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeletePoliciesStub        func(cf.NetworkPoliciesRequest) error
+	deletePoliciesMutex       sync.RWMutex
+	deletePoliciesArgsForCall []struct {
+		arg1 cf.NetworkPoliciesRequest
+	}
+	deletePoliciesReturns struct {
+		result1 error
+	}
+	deletePoliciesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListPoliciesStub        func() (cf.NetworkPoliciesResponse, error)
+	listPoliciesMutex       sync.RWMutex
+	listPoliciesArgsForCall []struct {
+	}
+	listPoliciesReturns struct {
+		result1 cf.NetworkPoliciesResponse
+		result2 error
+	}
+	listPoliciesReturnsOnCall map[int]struct {
+		result1 cf.NetworkPoliciesResponse
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePolicyBifrost) CreatePolicies(arg1 cf.NetworkPoliciesRequest) error {
+	fake.createPoliciesMutex.Lock()
+	ret, specificReturn := fake.createPoliciesReturnsOnCall[len(fake.createPoliciesArgsForCall)]
+	fake.createPoliciesArgsForCall = append(fake.createPoliciesArgsForCall, struct {
+		arg1 cf.NetworkPoliciesRequest
+	}{arg1})
+	stub := fake.CreatePoliciesStub
+	fakeReturns := fake.createPoliciesReturns
+	fake.recordInvocation("CreatePolicies", []interface{}{arg1})
+	fake.createPoliciesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePolicyBifrost) CreatePoliciesCallCount() int {
+	fake.createPoliciesMutex.RLock()
+	defer fake.createPoliciesMutex.RUnlock()
+	return len(fake.createPoliciesArgsForCall)
+}
+
+func (fake *FakePolicyBifrost) CreatePoliciesCalls(stub func(cf.NetworkPoliciesRequest) error) {
+	fake.createPoliciesMutex.Lock()
+	defer fake.createPoliciesMutex.Unlock()
+	fake.CreatePoliciesStub = stub
+}
+
+func (fake *FakePolicyBifrost) CreatePoliciesArgsForCall(i int) cf.NetworkPoliciesRequest {
+	fake.createPoliciesMutex.RLock()
+	defer fake.createPoliciesMutex.RUnlock()
+	argsForCall := fake.createPoliciesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePolicyBifrost) CreatePoliciesReturns(result1 error) {
+	fake.createPoliciesMutex.Lock()
+	defer fake.createPoliciesMutex.Unlock()
+	fake.CreatePoliciesStub = nil
+	fake.createPoliciesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePolicyBifrost) CreatePoliciesReturnsOnCall(i int, result1 error) {
+	fake.createPoliciesMutex.Lock()
+	defer fake.createPoliciesMutex.Unlock()
+	fake.CreatePoliciesStub = nil
+	if fake.createPoliciesReturnsOnCall == nil {
+		fake.createPoliciesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createPoliciesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePolicyBifrost) DeletePolicies(arg1 cf.NetworkPoliciesRequest) error {
+	fake.deletePoliciesMutex.Lock()
+	ret, specificReturn := fake.deletePoliciesReturnsOnCall[len(fake.deletePoliciesArgsForCall)]
+	fake.deletePoliciesArgsForCall = append(fake.deletePoliciesArgsForCall, struct {
+		arg1 cf.NetworkPoliciesRequest
+	}{arg1})
+	stub := fake.DeletePoliciesStub
+	fakeReturns := fake.deletePoliciesReturns
+	fake.recordInvocation("DeletePolicies", []interface{}{arg1})
+	fake.deletePoliciesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePolicyBifrost) DeletePoliciesCallCount() int {
+	fake.deletePoliciesMutex.RLock()
+	defer fake.deletePoliciesMutex.RUnlock()
+	return len(fake.deletePoliciesArgsForCall)
+}
+
+func (fake *FakePolicyBifrost) DeletePoliciesCalls(stub func(cf.NetworkPoliciesRequest) error) {
+	fake.deletePoliciesMutex.Lock()
+	defer fake.deletePoliciesMutex.Unlock()
+	fake.DeletePoliciesStub = stub
+}
+
+func (fake *FakePolicyBifrost) DeletePoliciesArgsForCall(i int) cf.NetworkPoliciesRequest {
+	fake.deletePoliciesMutex.RLock()
+	defer fake.deletePoliciesMutex.RUnlock()
+	argsForCall := fake.deletePoliciesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePolicyBifrost) DeletePoliciesReturns(result1 error) {
+	fake.deletePoliciesMutex.Lock()
+	defer fake.deletePoliciesMutex.Unlock()
+	fake.DeletePoliciesStub = nil
+	fake.deletePoliciesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePolicyBifrost) DeletePoliciesReturnsOnCall(i int, result1 error) {
+	fake.deletePoliciesMutex.Lock()
+	defer fake.deletePoliciesMutex.Unlock()
+	fake.DeletePoliciesStub = nil
+	if fake.deletePoliciesReturnsOnCall == nil {
+		fake.deletePoliciesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deletePoliciesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePolicyBifrost) ListPolicies() (cf.NetworkPoliciesResponse, error) {
+	fake.listPoliciesMutex.Lock()
+	ret, specificReturn := fake.listPoliciesReturnsOnCall[len(fake.listPoliciesArgsForCall)]
+	fake.listPoliciesArgsForCall = append(fake.listPoliciesArgsForCall, struct {
+	}{})
+	stub := fake.ListPoliciesStub
+	fakeReturns := fake.listPoliciesReturns
+	fake.recordInvocation("ListPolicies", []interface{}{})
+	fake.listPoliciesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePolicyBifrost) ListPoliciesCallCount() int {
+	fake.listPoliciesMutex.RLock()
+	defer fake.listPoliciesMutex.RUnlock()
+	return len(fake.listPoliciesArgsForCall)
+}
+
+func (fake *FakePolicyBifrost) ListPoliciesCalls(stub func() (cf.NetworkPoliciesResponse, error)) {
+	fake.listPoliciesMutex.Lock()
+	defer fake.listPoliciesMutex.Unlock()
+	fake.ListPoliciesStub = stub
+}
+
+func (fake *FakePolicyBifrost) ListPoliciesReturns(result1 cf.NetworkPoliciesResponse, result2 error) {
+	fake.listPoliciesMutex.Lock()
+	defer fake.listPoliciesMutex.Unlock()
+	fake.ListPoliciesStub = nil
+	fake.listPoliciesReturns = struct {
+		result1 cf.NetworkPoliciesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePolicyBifrost) ListPoliciesReturnsOnCall(i int, result1 cf.NetworkPoliciesResponse, result2 error) {
+	fake.listPoliciesMutex.Lock()
+	defer fake.listPoliciesMutex.Unlock()
+	fake.ListPoliciesStub = nil
+	if fake.listPoliciesReturnsOnCall == nil {
+		fake.listPoliciesReturnsOnCall = make(map[int]struct {
+			result1 cf.NetworkPoliciesResponse
+			result2 error
+		})
+	}
+	fake.listPoliciesReturnsOnCall[i] = struct {
+		result1 cf.NetworkPoliciesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePolicyBifrost) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createPoliciesMutex.RLock()
+	defer fake.createPoliciesMutex.RUnlock()
+	fake.deletePoliciesMutex.RLock()
+	defer fake.deletePoliciesMutex.RUnlock()
+	fake.listPoliciesMutex.RLock()
+	defer fake.listPoliciesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakePolicyBifrost) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ handler.PolicyBifrost = new(FakePolicyBifrost)
@@ -0,0 +1,416 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package handlerfakes
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/eirini/handler"
+	"code.cloudfoundry.org/eirini/models/cf"
+)
+
+type FakeScheduledTaskBifrost struct {
+	DeleteScheduledTaskStub        func(string) error
+	deleteScheduledTaskMutex       sync.RWMutex
+	deleteScheduledTaskArgsForCall []struct {
+		arg1 string
+	}
+	deleteScheduledTaskReturns struct {
+		result1 error
+	}
+	deleteScheduledTaskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetScheduledTaskStub        func(string) (cf.ScheduledTaskResponse, error)
+	getScheduledTaskMutex       sync.RWMutex
+	getScheduledTaskArgsForCall []struct {
+		arg1 string
+	}
+	getScheduledTaskReturns struct {
+		result1 cf.ScheduledTaskResponse
+		result2 error
+	}
+	getScheduledTaskReturnsOnCall map[int]struct {
+		result1 cf.ScheduledTaskResponse
+		result2 error
+	}
+	ListScheduledTasksStub        func() (cf.ScheduledTasksResponse, error)
+	listScheduledTasksMutex       sync.RWMutex
+	listScheduledTasksArgsForCall []struct {
+	}
+	listScheduledTasksReturns struct {
+		result1 cf.ScheduledTasksResponse
+		result2 error
+	}
+	listScheduledTasksReturnsOnCall map[int]struct {
+		result1 cf.ScheduledTasksResponse
+		result2 error
+	}
+	TransferScheduledTaskStub        func(context.Context, string, cf.ScheduledTaskRequest) error
+	transferScheduledTaskMutex       sync.RWMutex
+	transferScheduledTaskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 cf.ScheduledTaskRequest
+	}
+	transferScheduledTaskReturns struct {
+		result1 error
+	}
+	transferScheduledTaskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateScheduledTaskStub        func(string, cf.ScheduledTaskUpdateRequest) error
+	updateScheduledTaskMutex       sync.RWMutex
+	updateScheduledTaskArgsForCall []struct {
+		arg1 string
+		arg2 cf.ScheduledTaskUpdateRequest
+	}
+	updateScheduledTaskReturns struct {
+		result1 error
+	}
+	updateScheduledTaskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTask(arg1 string) error {
+	fake.deleteScheduledTaskMutex.Lock()
+	ret, specificReturn := fake.deleteScheduledTaskReturnsOnCall[len(fake.deleteScheduledTaskArgsForCall)]
+	fake.deleteScheduledTaskArgsForCall = append(fake.deleteScheduledTaskArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteScheduledTaskStub
+	fakeReturns := fake.deleteScheduledTaskReturns
+	fake.recordInvocation("DeleteScheduledTask", []interface{}{arg1})
+	fake.deleteScheduledTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTaskCallCount() int {
+	fake.deleteScheduledTaskMutex.RLock()
+	defer fake.deleteScheduledTaskMutex.RUnlock()
+	return len(fake.deleteScheduledTaskArgsForCall)
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTaskCalls(stub func(string) error) {
+	fake.deleteScheduledTaskMutex.Lock()
+	defer fake.deleteScheduledTaskMutex.Unlock()
+	fake.DeleteScheduledTaskStub = stub
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTaskArgsForCall(i int) string {
+	fake.deleteScheduledTaskMutex.RLock()
+	defer fake.deleteScheduledTaskMutex.RUnlock()
+	argsForCall := fake.deleteScheduledTaskArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTaskReturns(result1 error) {
+	fake.deleteScheduledTaskMutex.Lock()
+	defer fake.deleteScheduledTaskMutex.Unlock()
+	fake.DeleteScheduledTaskStub = nil
+	fake.deleteScheduledTaskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) DeleteScheduledTaskReturnsOnCall(i int, result1 error) {
+	fake.deleteScheduledTaskMutex.Lock()
+	defer fake.deleteScheduledTaskMutex.Unlock()
+	fake.DeleteScheduledTaskStub = nil
+	if fake.deleteScheduledTaskReturnsOnCall == nil {
+		fake.deleteScheduledTaskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteScheduledTaskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTask(arg1 string) (cf.ScheduledTaskResponse, error) {
+	fake.getScheduledTaskMutex.Lock()
+	ret, specificReturn := fake.getScheduledTaskReturnsOnCall[len(fake.getScheduledTaskArgsForCall)]
+	fake.getScheduledTaskArgsForCall = append(fake.getScheduledTaskArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetScheduledTaskStub
+	fakeReturns := fake.getScheduledTaskReturns
+	fake.recordInvocation("GetScheduledTask", []interface{}{arg1})
+	fake.getScheduledTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTaskCallCount() int {
+	fake.getScheduledTaskMutex.RLock()
+	defer fake.getScheduledTaskMutex.RUnlock()
+	return len(fake.getScheduledTaskArgsForCall)
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTaskCalls(stub func(string) (cf.ScheduledTaskResponse, error)) {
+	fake.getScheduledTaskMutex.Lock()
+	defer fake.getScheduledTaskMutex.Unlock()
+	fake.GetScheduledTaskStub = stub
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTaskArgsForCall(i int) string {
+	fake.getScheduledTaskMutex.RLock()
+	defer fake.getScheduledTaskMutex.RUnlock()
+	argsForCall := fake.getScheduledTaskArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTaskReturns(result1 cf.ScheduledTaskResponse, result2 error) {
+	fake.getScheduledTaskMutex.Lock()
+	defer fake.getScheduledTaskMutex.Unlock()
+	fake.GetScheduledTaskStub = nil
+	fake.getScheduledTaskReturns = struct {
+		result1 cf.ScheduledTaskResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskBifrost) GetScheduledTaskReturnsOnCall(i int, result1 cf.ScheduledTaskResponse, result2 error) {
+	fake.getScheduledTaskMutex.Lock()
+	defer fake.getScheduledTaskMutex.Unlock()
+	fake.GetScheduledTaskStub = nil
+	if fake.getScheduledTaskReturnsOnCall == nil {
+		fake.getScheduledTaskReturnsOnCall = make(map[int]struct {
+			result1 cf.ScheduledTaskResponse
+			result2 error
+		})
+	}
+	fake.getScheduledTaskReturnsOnCall[i] = struct {
+		result1 cf.ScheduledTaskResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskBifrost) ListScheduledTasks() (cf.ScheduledTasksResponse, error) {
+	fake.listScheduledTasksMutex.Lock()
+	ret, specificReturn := fake.listScheduledTasksReturnsOnCall[len(fake.listScheduledTasksArgsForCall)]
+	fake.listScheduledTasksArgsForCall = append(fake.listScheduledTasksArgsForCall, struct {
+	}{})
+	stub := fake.ListScheduledTasksStub
+	fakeReturns := fake.listScheduledTasksReturns
+	fake.recordInvocation("ListScheduledTasks", []interface{}{})
+	fake.listScheduledTasksMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeScheduledTaskBifrost) ListScheduledTasksCallCount() int {
+	fake.listScheduledTasksMutex.RLock()
+	defer fake.listScheduledTasksMutex.RUnlock()
+	return len(fake.listScheduledTasksArgsForCall)
+}
+
+func (fake *FakeScheduledTaskBifrost) ListScheduledTasksCalls(stub func() (cf.ScheduledTasksResponse, error)) {
+	fake.listScheduledTasksMutex.Lock()
+	defer fake.listScheduledTasksMutex.Unlock()
+	fake.ListScheduledTasksStub = stub
+}
+
+func (fake *FakeScheduledTaskBifrost) ListScheduledTasksReturns(result1 cf.ScheduledTasksResponse, result2 error) {
+	fake.listScheduledTasksMutex.Lock()
+	defer fake.listScheduledTasksMutex.Unlock()
+	fake.ListScheduledTasksStub = nil
+	fake.listScheduledTasksReturns = struct {
+		result1 cf.ScheduledTasksResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskBifrost) ListScheduledTasksReturnsOnCall(i int, result1 cf.ScheduledTasksResponse, result2 error) {
+	fake.listScheduledTasksMutex.Lock()
+	defer fake.listScheduledTasksMutex.Unlock()
+	fake.ListScheduledTasksStub = nil
+	if fake.listScheduledTasksReturnsOnCall == nil {
+		fake.listScheduledTasksReturnsOnCall = make(map[int]struct {
+			result1 cf.ScheduledTasksResponse
+			result2 error
+		})
+	}
+	fake.listScheduledTasksReturnsOnCall[i] = struct {
+		result1 cf.ScheduledTasksResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTask(arg1 context.Context, arg2 string, arg3 cf.ScheduledTaskRequest) error {
+	fake.transferScheduledTaskMutex.Lock()
+	ret, specificReturn := fake.transferScheduledTaskReturnsOnCall[len(fake.transferScheduledTaskArgsForCall)]
+	fake.transferScheduledTaskArgsForCall = append(fake.transferScheduledTaskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 cf.ScheduledTaskRequest
+	}{arg1, arg2, arg3})
+	stub := fake.TransferScheduledTaskStub
+	fakeReturns := fake.transferScheduledTaskReturns
+	fake.recordInvocation("TransferScheduledTask", []interface{}{arg1, arg2, arg3})
+	fake.transferScheduledTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTaskCallCount() int {
+	fake.transferScheduledTaskMutex.RLock()
+	defer fake.transferScheduledTaskMutex.RUnlock()
+	return len(fake.transferScheduledTaskArgsForCall)
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTaskCalls(stub func(context.Context, string, cf.ScheduledTaskRequest) error) {
+	fake.transferScheduledTaskMutex.Lock()
+	defer fake.transferScheduledTaskMutex.Unlock()
+	fake.TransferScheduledTaskStub = stub
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTaskArgsForCall(i int) (context.Context, string, cf.ScheduledTaskRequest) {
+	fake.transferScheduledTaskMutex.RLock()
+	defer fake.transferScheduledTaskMutex.RUnlock()
+	argsForCall := fake.transferScheduledTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTaskReturns(result1 error) {
+	fake.transferScheduledTaskMutex.Lock()
+	defer fake.transferScheduledTaskMutex.Unlock()
+	fake.TransferScheduledTaskStub = nil
+	fake.transferScheduledTaskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) TransferScheduledTaskReturnsOnCall(i int, result1 error) {
+	fake.transferScheduledTaskMutex.Lock()
+	defer fake.transferScheduledTaskMutex.Unlock()
+	fake.TransferScheduledTaskStub = nil
+	if fake.transferScheduledTaskReturnsOnCall == nil {
+		fake.transferScheduledTaskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.transferScheduledTaskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTask(arg1 string, arg2 cf.ScheduledTaskUpdateRequest) error {
+	fake.updateScheduledTaskMutex.Lock()
+	ret, specificReturn := fake.updateScheduledTaskReturnsOnCall[len(fake.updateScheduledTaskArgsForCall)]
+	fake.updateScheduledTaskArgsForCall = append(fake.updateScheduledTaskArgsForCall, struct {
+		arg1 string
+		arg2 cf.ScheduledTaskUpdateRequest
+	}{arg1, arg2})
+	stub := fake.UpdateScheduledTaskStub
+	fakeReturns := fake.updateScheduledTaskReturns
+	fake.recordInvocation("UpdateScheduledTask", []interface{}{arg1, arg2})
+	fake.updateScheduledTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTaskCallCount() int {
+	fake.updateScheduledTaskMutex.RLock()
+	defer fake.updateScheduledTaskMutex.RUnlock()
+	return len(fake.updateScheduledTaskArgsForCall)
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTaskCalls(stub func(string, cf.ScheduledTaskUpdateRequest) error) {
+	fake.updateScheduledTaskMutex.Lock()
+	defer fake.updateScheduledTaskMutex.Unlock()
+	fake.UpdateScheduledTaskStub = stub
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTaskArgsForCall(i int) (string, cf.ScheduledTaskUpdateRequest) {
+	fake.updateScheduledTaskMutex.RLock()
+	defer fake.updateScheduledTaskMutex.RUnlock()
+	argsForCall := fake.updateScheduledTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTaskReturns(result1 error) {
+	fake.updateScheduledTaskMutex.Lock()
+	defer fake.updateScheduledTaskMutex.Unlock()
+	fake.UpdateScheduledTaskStub = nil
+	fake.updateScheduledTaskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) UpdateScheduledTaskReturnsOnCall(i int, result1 error) {
+	fake.updateScheduledTaskMutex.Lock()
+	defer fake.updateScheduledTaskMutex.Unlock()
+	fake.UpdateScheduledTaskStub = nil
+	if fake.updateScheduledTaskReturnsOnCall == nil {
+		fake.updateScheduledTaskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateScheduledTaskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskBifrost) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteScheduledTaskMutex.RLock()
+	defer fake.deleteScheduledTaskMutex.RUnlock()
+	fake.getScheduledTaskMutex.RLock()
+	defer fake.getScheduledTaskMutex.RUnlock()
+	fake.listScheduledTasksMutex.RLock()
+	defer fake.listScheduledTasksMutex.RUnlock()
+	fake.transferScheduledTaskMutex.RLock()
+	defer fake.transferScheduledTaskMutex.RUnlock()
+	fake.updateScheduledTaskMutex.RLock()
+	defer fake.updateScheduledTaskMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeScheduledTaskBifrost) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ handler.ScheduledTaskBifrost = new(FakeScheduledTaskBifrost)
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager"
+	"github.com/julienschmidt/httprouter"
+)
+
+type Policy struct {
+	logger        lager.Logger
+	policyBifrost PolicyBifrost
+}
+
+func NewPolicyHandler(logger lager.Logger, policyBifrost PolicyBifrost) *Policy {
+	return &Policy{
+		logger:        logger,
+		policyBifrost: policyBifrost,
+	}
+}
+
+func (p *Policy) Create(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	logger := p.logger.Session("create-policies")
+
+	var request cf.NetworkPoliciesRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		logger.Error("request-body-decoding-failed", err)
+		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := p.policyBifrost.CreatePolicies(request); err != nil {
+		logger.Error("create-policies-failed", err)
+		writeErrorResponse(logger, resp, policyErrorStatus(err), err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+}
+
+func (p *Policy) List(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	logger := p.logger.Session("list-policies")
+
+	response, err := p.policyBifrost.ListPolicies()
+	if err != nil {
+		logger.Error("list-policies-failed", err)
+		writeErrorResponse(logger, resp, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(response); err != nil {
+		logger.Error("encode-json-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (p *Policy) Delete(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	logger := p.logger.Session("delete-policies")
+
+	var request cf.NetworkPoliciesRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		logger.Error("request-body-decoding-failed", err)
+		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := p.policyBifrost.DeletePolicies(request); err != nil {
+		logger.Error("delete-policies-failed", err)
+		writeErrorResponse(logger, resp, policyErrorStatus(err), err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+func policyErrorStatus(err error) int {
+	if errors.Is(err, eirini.ErrInvalidNetworkPolicy) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
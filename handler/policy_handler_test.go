@@ -0,0 +1,160 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/eirini"
+	. "code.cloudfoundry.org/eirini/handler"
+	"code.cloudfoundry.org/eirini/handler/handlerfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("PolicyHandler", func() {
+	var (
+		ts            *httptest.Server
+		policyBifrost *handlerfakes.FakePolicyBifrost
+
+		response *http.Response
+		body     string
+		path     string
+		method   string
+	)
+
+	BeforeEach(func() {
+		policyBifrost = new(handlerfakes.FakePolicyBifrost)
+
+		method = "POST"
+		path = "/policies"
+		body = `{
+			"policies": [{
+				"source": {"id": "app-a"},
+				"destination": {"id": "app-b", "protocol": "tcp", "ports": {"start": 8080, "end": 8090}}
+			}]
+		}`
+	})
+
+	JustBeforeEach(func() {
+		handler := New(nil, nil, nil, nil, nil, policyBifrost, lagertest.NewTestLogger("test"))
+		ts = httptest.NewServer(handler)
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader([]byte(body)))
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err = (&http.Client{}).Do(req)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	expectedRequest := cf.NetworkPoliciesRequest{
+		Policies: []cf.NetworkPolicy{{
+			Source: cf.NetworkPolicySource{ID: "app-a"},
+			Destination: cf.NetworkPolicyDestination{
+				ID:       "app-b",
+				Protocol: "tcp",
+				Ports:    cf.PortRange{Start: 8080, End: 8090},
+			},
+		}},
+	}
+
+	Describe("Create", func() {
+		It("returns 201 Created", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusCreated))
+		})
+
+		It("creates the policies", func() {
+			Expect(policyBifrost.CreatePoliciesCallCount()).To(Equal(1))
+			Expect(policyBifrost.CreatePoliciesArgsForCall(0)).To(Equal(expectedRequest))
+		})
+
+		When("the body is not valid JSON", func() {
+			BeforeEach(func() {
+				body = "{"
+			})
+
+			It("returns 400 Bad Request", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(policyBifrost.CreatePoliciesCallCount()).To(BeZero())
+			})
+		})
+
+		When("the policy is invalid", func() {
+			BeforeEach(func() {
+				policyBifrost.CreatePoliciesReturns(errors.Wrap(eirini.ErrInvalidNetworkPolicy, "nope"))
+			})
+
+			It("returns 400 Bad Request", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		When("creating the policies fails", func() {
+			BeforeEach(func() {
+				policyBifrost.CreatePoliciesReturns(errors.New("boom"))
+			})
+
+			It("returns 500 Internal Server Error", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			method = "GET"
+			body = ""
+			policyBifrost.ListPoliciesReturns(cf.NetworkPoliciesResponse{Policies: expectedRequest.Policies}, nil)
+		})
+
+		It("returns the policies", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+			var listResponse cf.NetworkPoliciesResponse
+			Expect(json.NewDecoder(response.Body).Decode(&listResponse)).To(Succeed())
+			Expect(listResponse.Policies).To(Equal(expectedRequest.Policies))
+		})
+
+		When("listing the policies fails", func() {
+			BeforeEach(func() {
+				policyBifrost.ListPoliciesReturns(cf.NetworkPoliciesResponse{}, errors.New("boom"))
+			})
+
+			It("returns 500 Internal Server Error", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			path = "/policies/delete"
+		})
+
+		It("returns 204 No Content", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+		})
+
+		It("deletes the policies", func() {
+			Expect(policyBifrost.DeletePoliciesCallCount()).To(Equal(1))
+			Expect(policyBifrost.DeletePoliciesArgsForCall(0)).To(Equal(expectedRequest))
+		})
+
+		When("deleting the policies fails", func() {
+			BeforeEach(func() {
+				policyBifrost.DeletePoliciesReturns(errors.New("boom"))
+			})
+
+			It("returns 500 Internal Server Error", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+})
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager"
+	"github.com/julienschmidt/httprouter"
+)
+
+type ScheduledTask struct {
+	logger               lager.Logger
+	scheduledTaskBifrost ScheduledTaskBifrost
+}
+
+func NewScheduledTaskHandler(logger lager.Logger, scheduledTaskBifrost ScheduledTaskBifrost) *ScheduledTask {
+	return &ScheduledTask{
+		logger:               logger,
+		scheduledTaskBifrost: scheduledTaskBifrost,
+	}
+}
+
+func (t *ScheduledTask) Get(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	taskGUID := ps.ByName("task_guid")
+	logger := t.logger.Session("get-scheduled-task-request", lager.Data{"task-guid": taskGUID})
+
+	response, err := t.scheduledTaskBifrost.GetScheduledTask(taskGUID)
+	if err != nil {
+		writeScheduledTaskErrorResponse(logger, resp, "get-scheduled-task-request-failed", err)
+
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(response); err != nil {
+		logger.Error("encode-json-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (t *ScheduledTask) List(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	logger := t.logger.Session("list-scheduled-tasks")
+
+	tasks, err := t.scheduledTaskBifrost.ListScheduledTasks()
+	if err != nil {
+		logger.Error("list-scheduled-tasks-request-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	if err = json.NewEncoder(resp).Encode(tasks); err != nil {
+		logger.Error("encode-json-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (t *ScheduledTask) Desire(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	taskGUID := ps.ByName("task_guid")
+	logger := t.logger.Session("scheduled-task-request", lager.Data{"task-guid": taskGUID})
+
+	var request cf.ScheduledTaskRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		logger.Error("scheduled-task-request-body-decoding-failed", err)
+		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := t.scheduledTaskBifrost.TransferScheduledTask(req.Context(), taskGUID, request); err != nil {
+		logger.Error("scheduled-task-request-create-failed", err)
+		writeErrorResponse(logger, resp, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+func (t *ScheduledTask) Update(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	taskGUID := ps.ByName("task_guid")
+	logger := t.logger.Session("scheduled-task-update", lager.Data{"task-guid": taskGUID})
+
+	var request cf.ScheduledTaskUpdateRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		logger.Error("scheduled-task-update-body-decoding-failed", err)
+		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := t.scheduledTaskBifrost.UpdateScheduledTask(taskGUID, request); err != nil {
+		writeScheduledTaskErrorResponse(logger, resp, "scheduled-task-update-failed", err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusOK)
+}
+
+func (t *ScheduledTask) Delete(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	taskGUID := ps.ByName("task_guid")
+	logger := t.logger.Session("scheduled-task-delete", lager.Data{"task-guid": taskGUID})
+
+	if err := t.scheduledTaskBifrost.DeleteScheduledTask(taskGUID); err != nil {
+		writeScheduledTaskErrorResponse(logger, resp, "scheduled-task-delete-failed", err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+func writeScheduledTaskErrorResponse(logger lager.Logger, resp http.ResponseWriter, action string, err error) {
+	if errors.Is(err, eirini.ErrNotFound) {
+		logger.Info("scheduled-task-not-found")
+		writeErrorResponse(logger, resp, http.StatusNotFound, err)
+
+		return
+	}
+
+	logger.Error(action, err)
+	writeErrorResponse(logger, resp, http.StatusInternalServerError, err)
+}
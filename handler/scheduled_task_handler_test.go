@@ -0,0 +1,284 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/eirini"
+	. "code.cloudfoundry.org/eirini/handler"
+	"code.cloudfoundry.org/eirini/handler/handlerfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("ScheduledTaskHandler", func() {
+	var (
+		ts                   *httptest.Server
+		logger               *lagertest.TestLogger
+		scheduledTaskBifrost *handlerfakes.FakeScheduledTaskBifrost
+
+		response *http.Response
+		body     string
+		path     string
+		method   string
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		scheduledTaskBifrost = new(handlerfakes.FakeScheduledTaskBifrost)
+
+		method = "POST"
+		path = "/scheduled_tasks/guid_1234"
+		body = ""
+	})
+
+	JustBeforeEach(func() {
+		handler := New(nil, nil, nil, nil, scheduledTaskBifrost, nil, logger)
+		ts = httptest.NewServer(handler)
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader([]byte(body)))
+		Expect(err).NotTo(HaveOccurred())
+
+		client := &http.Client{}
+		response, err = client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("Desire", func() {
+		BeforeEach(func() {
+			method = "POST"
+			path = "/scheduled_tasks/guid_1234"
+			body = `{
+				"guid": "some-guid",
+				"name": "task-name",
+				"app_guid": "our-app-id",
+				"namespace": "our-namespace",
+				"completion_callback": "example.com/call/me/maybe",
+				"lifecycle": {
+					"docker_lifecycle": {
+						"image": "eirini/dorini"
+					}
+				},
+				"schedule": "*/5 * * * *",
+				"concurrency_policy": "Forbid",
+				"suspend": true,
+				"successful_runs_history_limit": 2,
+				"failed_runs_history_limit": 4
+			}`
+		})
+
+		It("should return 202 Accepted code", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		It("should transfer the scheduled task", func() {
+			Expect(scheduledTaskBifrost.TransferScheduledTaskCallCount()).To(Equal(1))
+			_, actualTaskGUID, actualRequest := scheduledTaskBifrost.TransferScheduledTaskArgsForCall(0)
+			Expect(actualTaskGUID).To(Equal("guid_1234"))
+
+			successfulRunsHistoryLimit := int32(2)
+			failedRunsHistoryLimit := int32(4)
+			Expect(actualRequest).To(Equal(cf.ScheduledTaskRequest{
+				TaskRequest: cf.TaskRequest{
+					GUID:               "some-guid",
+					Name:               "task-name",
+					AppGUID:            "our-app-id",
+					Namespace:          "our-namespace",
+					CompletionCallback: "example.com/call/me/maybe",
+					Lifecycle: cf.Lifecycle{
+						DockerLifecycle: &cf.DockerLifecycle{
+							Image: "eirini/dorini",
+						},
+					},
+				},
+				Schedule:                   "*/5 * * * *",
+				ConcurrencyPolicy:          "Forbid",
+				Suspend:                    true,
+				SuccessfulRunsHistoryLimit: &successfulRunsHistoryLimit,
+				FailedRunsHistoryLimit:     &failedRunsHistoryLimit,
+			}))
+		})
+
+		When("transferring the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.TransferScheduledTaskReturns(errors.New("transfer-err"))
+			})
+
+			It("should return 500 Internal Server Error code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("the request body cannot be unmarshalled", func() {
+			BeforeEach(func() {
+				body = "random stuff"
+			})
+
+			It("should return 400 Bad Request code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("should not transfer the scheduled task", func() {
+				Expect(scheduledTaskBifrost.TransferScheduledTaskCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		BeforeEach(func() {
+			method = "PUT"
+			path = "/scheduled_tasks/guid_1234"
+			body = `{"schedule": "0 * * * *", "suspend": true}`
+		})
+
+		It("succeeds", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("updates the scheduled task", func() {
+			Expect(scheduledTaskBifrost.UpdateScheduledTaskCallCount()).To(Equal(1))
+			actualTaskGUID, actualRequest := scheduledTaskBifrost.UpdateScheduledTaskArgsForCall(0)
+			Expect(actualTaskGUID).To(Equal("guid_1234"))
+			Expect(actualRequest).To(Equal(cf.ScheduledTaskUpdateRequest{
+				Schedule: "0 * * * *",
+				Suspend:  true,
+			}))
+		})
+
+		When("the scheduled task does not exist", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.UpdateScheduledTaskReturns(errors.Wrap(eirini.ErrNotFound, "boom"))
+			})
+
+			It("returns a 404 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		When("updating the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.UpdateScheduledTaskReturns(errors.New("boom"))
+			})
+
+			It("returns a 500 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("the request body cannot be unmarshalled", func() {
+			BeforeEach(func() {
+				body = "random stuff"
+			})
+
+			It("should return 400 Bad Request code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			method = "DELETE"
+			path = "/scheduled_tasks/guid_1234"
+		})
+
+		It("succeeds", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+		})
+
+		It("deletes the scheduled task", func() {
+			Expect(scheduledTaskBifrost.DeleteScheduledTaskCallCount()).To(Equal(1))
+			Expect(scheduledTaskBifrost.DeleteScheduledTaskArgsForCall(0)).To(Equal("guid_1234"))
+		})
+
+		When("deleting the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.DeleteScheduledTaskReturns(errors.New("boom"))
+			})
+
+			It("returns a 500 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		BeforeEach(func() {
+			method = "GET"
+			path = "/scheduled_tasks/guid_1234"
+
+			scheduledTaskBifrost.GetScheduledTaskReturns(cf.ScheduledTaskResponse{
+				GUID:     "guid_1234",
+				Schedule: "*/5 * * * *",
+			}, nil)
+		})
+
+		It("retrieves the scheduled task", func() {
+			Expect(scheduledTaskBifrost.GetScheduledTaskCallCount()).To(Equal(1))
+			Expect(scheduledTaskBifrost.GetScheduledTaskArgsForCall(0)).To(Equal("guid_1234"))
+
+			var taskResponse cf.ScheduledTaskResponse
+			Expect(json.NewDecoder(response.Body).Decode(&taskResponse)).To(Succeed())
+			Expect(taskResponse.GUID).To(Equal("guid_1234"))
+			Expect(taskResponse.Schedule).To(Equal("*/5 * * * *"))
+		})
+
+		When("there is no scheduled task with the required guid", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.GetScheduledTaskReturns(cf.ScheduledTaskResponse{}, errors.Wrap(eirini.ErrNotFound, "foo"))
+			})
+
+			It("returns a 404 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		When("getting the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.GetScheduledTaskReturns(cf.ScheduledTaskResponse{}, errors.New("boom"))
+			})
+
+			It("returns a 500 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			method = "GET"
+			path = "/scheduled_tasks"
+
+			scheduledTaskBifrost.ListScheduledTasksReturns(cf.ScheduledTasksResponse{
+				{GUID: "task-1"},
+				{GUID: "task-2"},
+			}, nil)
+		})
+
+		It("lists the scheduled tasks", func() {
+			var tasksResponse cf.ScheduledTasksResponse
+			Expect(json.NewDecoder(response.Body).Decode(&tasksResponse)).To(Succeed())
+			Expect(tasksResponse).To(HaveLen(2))
+			Expect(tasksResponse[0].GUID).To(Equal("task-1"))
+			Expect(tasksResponse[1].GUID).To(Equal("task-2"))
+		})
+
+		When("listing the scheduled tasks fails", func() {
+			BeforeEach(func() {
+				scheduledTaskBifrost.ListScheduledTasksReturns(nil, errors.New("boom"))
+			})
+
+			It("returns a 500 status", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+})
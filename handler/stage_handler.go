@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/models/cf"
 	"code.cloudfoundry.org/lager"
 	"github.com/julienschmidt/httprouter"
@@ -13,16 +14,18 @@ import (
 )
 
 type Stage struct {
-	dockerStagingBifrost StagingBifrost
-	logger               lager.Logger
+	dockerStagingBifrost    StagingBifrost
+	buildpackStagingBifrost BuildpackStagingBifrost
+	logger                  lager.Logger
 }
 
-func NewStageHandler(dockerStagingBifrost StagingBifrost, logger lager.Logger) *Stage {
+func NewStageHandler(dockerStagingBifrost StagingBifrost, buildpackStagingBifrost BuildpackStagingBifrost, logger lager.Logger) *Stage {
 	logger = logger.Session("staging-handler")
 
 	return &Stage{
-		dockerStagingBifrost: dockerStagingBifrost,
-		logger:               logger,
+		dockerStagingBifrost:    dockerStagingBifrost,
+		buildpackStagingBifrost: buildpackStagingBifrost,
+		logger:                  logger,
 	}
 }
 
@@ -38,8 +41,8 @@ func (s *Stage) Run(resp http.ResponseWriter, req *http.Request, ps httprouter.P
 		return
 	}
 
-	if stagingRequest.Lifecycle.DockerLifecycle == nil {
-		err := errors.New("docker is the only supported lifecycle")
+	if stagingRequest.Lifecycle.DockerLifecycle == nil && stagingRequest.Lifecycle.BuildpackLifecycle == nil {
+		err := errors.New("missing lifecycle data")
 		logger.Error("staging-failed", err)
 		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
 
@@ -57,7 +60,62 @@ func (s *Stage) Run(resp http.ResponseWriter, req *http.Request, ps httprouter.P
 	resp.WriteHeader(http.StatusAccepted)
 }
 
+func (s *Stage) StagingComplete(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	stagingGUID := ps.ByName("staging_guid")
+	logger := s.logger.Session("staging-complete", lager.Data{"staging-guid": stagingGUID})
+
+	var stagingCompletedRequest cf.StagingCompletedRequest
+	if err := json.NewDecoder(req.Body).Decode(&stagingCompletedRequest); err != nil {
+		logger.Error("failed-to-decode-request", err)
+		writeErrorResponse(logger, resp, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if stagingCompletedRequest.TaskGUID == "" {
+		stagingCompletedRequest.TaskGUID = stagingGUID
+	}
+
+	if err := s.buildpackStagingBifrost.CompleteStaging(stagingCompletedRequest); err != nil {
+		logger.Error("failed-to-complete-staging", err)
+		writeErrorResponse(logger, resp, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusOK)
+}
+
+func (s *Stage) Cancel(resp http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	stagingGUID := ps.ByName("staging_guid")
+	logger := s.logger.Session("cancel-staging", lager.Data{"staging-guid": stagingGUID})
+
+	if err := s.buildpackStagingBifrost.CancelStaging(stagingGUID); err != nil {
+		if errors.Is(err, eirini.ErrNotFound) {
+			logger.Info("staging-not-found")
+			writeErrorResponse(logger, resp, http.StatusNotFound, err)
+
+			return
+		}
+
+		logger.Error("failed-to-cancel-staging", err)
+		writeErrorResponse(logger, resp, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
 func (s *Stage) stage(stagingGUID string, stagingRequest cf.StagingRequest) error {
+	if stagingRequest.Lifecycle.BuildpackLifecycle != nil {
+		return s.buildpackStagingBifrost.TransferStaging(
+			context.Background(),
+			stagingGUID,
+			stagingRequest,
+		)
+	}
+
 	return s.dockerStagingBifrost.TransferStaging(
 		context.Background(),
 		stagingGUID,
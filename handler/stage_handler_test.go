@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 
+	"code.cloudfoundry.org/eirini"
 	. "code.cloudfoundry.org/eirini/handler"
 	"code.cloudfoundry.org/eirini/handler/handlerfakes"
 	"code.cloudfoundry.org/eirini/models/cf"
@@ -21,22 +23,24 @@ var _ = Describe("StageHandler", func() {
 		ts     *httptest.Server
 		logger *lagertest.TestLogger
 
-		dockerStagingClient *handlerfakes.FakeStagingBifrost
-		bifrostTaskClient   *handlerfakes.FakeTaskBifrost
-		response            *http.Response
-		body                string
-		path                string
-		method              string
+		dockerStagingClient    *handlerfakes.FakeStagingBifrost
+		buildpackStagingClient *handlerfakes.FakeBuildpackStagingBifrost
+		bifrostTaskClient      *handlerfakes.FakeTaskBifrost
+		response               *http.Response
+		body                   string
+		path                   string
+		method                 string
 	)
 
 	BeforeEach(func() {
 		logger = lagertest.NewTestLogger("test")
 		dockerStagingClient = new(handlerfakes.FakeStagingBifrost)
+		buildpackStagingClient = new(handlerfakes.FakeBuildpackStagingBifrost)
 		bifrostTaskClient = new(handlerfakes.FakeTaskBifrost)
 	})
 
 	JustBeforeEach(func() {
-		handler := New(nil, dockerStagingClient, bifrostTaskClient, logger)
+		handler := New(nil, dockerStagingClient, buildpackStagingClient, bifrostTaskClient, nil, nil, logger)
 		ts = httptest.NewServer(handler)
 		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader([]byte(body)))
 		Expect(err).NotTo(HaveOccurred())
@@ -89,22 +93,59 @@ var _ = Describe("StageHandler", func() {
 			}))
 		})
 
-		Context("and the lifecycle type is unsupported", func() {
+		It("should not use the buildpack staging client", func() {
+			Expect(buildpackStagingClient.TransferStagingCallCount()).To(Equal(0))
+		})
 
+		Context("and the lifecycle is buildpack", func() {
 			BeforeEach(func() {
 				body = `{
 				"app_guid": "our-app-id",
-				"environment": [{"name": "HOWARD", "value": "the alien"}],
 				"lifecycle": {
 					"buildpack_lifecycle": {
 						"app_bits_download_uri": "example.com/download",
-						"droplet_upload_uri": "example.com/upload"
+						"droplet_upload_uri": "example.com/upload",
+						"buildpacks": [{"name": "go_buildpack", "key": "go-key", "url": "example.com/go", "skip_detect": true}],
+						"stack": "cflinuxfs3"
 					}
 				},
 				"completion_callback": "example.com/call/me/maybe"
 			}`
 			})
 
+			It("should return 202 Accepted code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusAccepted))
+			})
+
+			It("should stage it using the buildpack staging client", func() {
+				Expect(buildpackStagingClient.TransferStagingCallCount()).To(Equal(1))
+				_, stagingGUID, stagingRequest := buildpackStagingClient.TransferStagingArgsForCall(0)
+
+				Expect(stagingGUID).To(Equal("guid_1234"))
+				Expect(stagingRequest.Lifecycle.BuildpackLifecycle).To(Equal(&cf.StagingBuildpackLifecycle{
+					AppBitsDownloadURI: "example.com/download",
+					DropletUploadURI:   "example.com/upload",
+					Buildpacks: []cf.Buildpack{
+						{Name: "go_buildpack", Key: "go-key", URL: "example.com/go", SkipDetect: true},
+					},
+					Stack: "cflinuxfs3",
+				}))
+			})
+
+			It("should not use the docker staging client", func() {
+				Expect(dockerStagingClient.TransferStagingCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("and the lifecycle is missing", func() {
+			BeforeEach(func() {
+				body = `{
+				"app_guid": "our-app-id",
+				"lifecycle": {},
+				"completion_callback": "example.com/call/me/maybe"
+			}`
+			})
+
 			It("should return a 400 Bad Request status code", func() {
 				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
 			})
@@ -114,11 +155,12 @@ var _ = Describe("StageHandler", func() {
 				stagingError := cf.Error{}
 				err := json.Unmarshal(bytes, &stagingError)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(stagingError.Message).To(ContainSubstring("docker is the only supported lifecycle"))
+				Expect(stagingError.Message).To(ContainSubstring("missing lifecycle data"))
 			})
 
 			It("should not desire a task", func() {
 				Expect(dockerStagingClient.TransferStagingCallCount()).To(Equal(0))
+				Expect(buildpackStagingClient.TransferStagingCallCount()).To(Equal(0))
 			})
 		})
 
@@ -168,4 +210,103 @@ var _ = Describe("StageHandler", func() {
 			})
 		})
 	})
+
+	Context("When staging completes", func() {
+		BeforeEach(func() {
+			method = "PUT"
+			path = "/stage/guid_1234/completed"
+			body = `{
+				"task_guid": "guid_1234",
+				"failed": true,
+				"failure_reason": "buildpack compilation failed",
+				"annotation": "{\"completion_callback\": \"example.com/call/me/maybe\"}"
+			}`
+		})
+
+		It("should return 200 OK", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should complete the staging using the buildpack staging client", func() {
+			Expect(buildpackStagingClient.CompleteStagingCallCount()).To(Equal(1))
+			Expect(buildpackStagingClient.CompleteStagingArgsForCall(0)).To(Equal(cf.StagingCompletedRequest{
+				TaskGUID:      "guid_1234",
+				Failed:        true,
+				FailureReason: "buildpack compilation failed",
+				Annotation:    `{"completion_callback": "example.com/call/me/maybe"}`,
+			}))
+		})
+
+		Context("and the task guid is not in the body", func() {
+			BeforeEach(func() {
+				body = `{"failed": false}`
+			})
+
+			It("should use the staging guid from the path", func() {
+				Expect(buildpackStagingClient.CompleteStagingCallCount()).To(Equal(1))
+				Expect(buildpackStagingClient.CompleteStagingArgsForCall(0).TaskGUID).To(Equal("guid_1234"))
+			})
+		})
+
+		Context("and the body is invalid", func() {
+			BeforeEach(func() {
+				body = "{ this json is invalid"
+			})
+
+			It("should return a 400 Bad Request status code", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("should not complete the staging", func() {
+				Expect(buildpackStagingClient.CompleteStagingCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("and completing the staging fails", func() {
+			BeforeEach(func() {
+				buildpackStagingClient.CompleteStagingReturns(errors.New("boom"))
+			})
+
+			It("should return a 500 Internal Server Error", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Context("When staging is cancelled", func() {
+		BeforeEach(func() {
+			method = "DELETE"
+			path = "/stage/guid_1234"
+			body = ""
+		})
+
+		It("should return 204 No Content", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+		})
+
+		It("should cancel the staging using the buildpack staging client", func() {
+			Expect(buildpackStagingClient.CancelStagingCallCount()).To(Equal(1))
+			Expect(buildpackStagingClient.CancelStagingArgsForCall(0)).To(Equal("guid_1234"))
+		})
+
+		Context("and cancelling fails", func() {
+			BeforeEach(func() {
+				buildpackStagingClient.CancelStagingReturns(errors.New("boom"))
+			})
+
+			It("should return a 500 Internal Server Error", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		Context("and the staging does not exist", func() {
+			BeforeEach(func() {
+				buildpackStagingClient.CancelStagingReturns(fmt.Errorf("failed to delete staging: %w", eirini.ErrNotFound))
+			})
+
+			It("should return a 404 Not Found", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
 })
@@ -39,7 +39,7 @@ var _ = Describe("TaskHandler", func() {
 
 	JustBeforeEach(func() {
 		logger = lagertest.NewTestLogger("test")
-		handler := New(nil, nil, taskBifrost, logger)
+		handler := New(nil, nil, nil, taskBifrost, nil, nil, logger)
 		ts = httptest.NewServer(handler)
 		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader([]byte(body)))
 		Expect(err).NotTo(HaveOccurred())
@@ -74,7 +74,10 @@ var _ = Describe("TaskHandler", func() {
 						"registry_username": "user",
 						"registry_password": "pass"
 					}
-				}
+				},
+				"memory_mb": 1024,
+				"disk_mb": 2048,
+				"cpu_weight": 50
 			}`
 		})
 
@@ -104,6 +107,9 @@ var _ = Describe("TaskHandler", func() {
 						RegistryPassword: "pass",
 					},
 				},
+				MemoryMB:  1024,
+				DiskMB:    2048,
+				CPUWeight: 50,
 			}))
 		})
 
@@ -0,0 +1,390 @@
+package k8s
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	AppPolicySourceType = "APP_POLICY"
+
+	LabelSourceAppGUID      = "cloudfoundry.org/source_app_guid"
+	LabelDestinationAppGUID = "cloudfoundry.org/destination_app_guid"
+
+	AnnotationAppPolicy = "cloudfoundry.org/app_policy"
+
+	appPolicyNamePrefix = "c2c"
+)
+
+//counterfeiter:generate . AppPolicyClient
+//counterfeiter:generate . AppStatefulSetLister
+
+type AppPolicyClient interface {
+	Create(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	Update(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	Delete(namespace string, name string) error
+	GetBySourceType(sourceType string) ([]networkingv1.NetworkPolicy, error)
+}
+
+type AppStatefulSetLister interface {
+	GetByAppGUID(appGUID string) ([]appsv1.StatefulSet, error)
+}
+
+// AppPolicyDesirer turns container-to-container policies into ingress
+// NetworkPolicies. Pods are selected by their app GUID rather than by their
+// LRP version, so the policies keep applying to the pods of an app after it
+// is restaged. NetworkPolicies only select pods in their own namespace, so
+// they are created in every namespace the destination app runs in, or in the
+// default namespace until the app has been desired.
+type AppPolicyDesirer struct {
+	logger       lager.Logger
+	client       AppPolicyClient
+	statefulSets AppStatefulSetLister
+	namespace    string
+}
+
+func NewAppPolicyDesirer(logger lager.Logger, client AppPolicyClient, statefulSets AppStatefulSetLister, namespace string) *AppPolicyDesirer {
+	return &AppPolicyDesirer{
+		logger:       logger,
+		client:       client,
+		statefulSets: statefulSets,
+		namespace:    namespace,
+	}
+}
+
+func (d *AppPolicyDesirer) Desire(policies []opi.AppNetworkPolicy) error {
+	logger := d.logger.Session("desire")
+
+	for _, policy := range policies {
+		networkPolicy, err := ToAppNetworkPolicy(policy)
+		if err != nil {
+			return err
+		}
+
+		namespaces, err := d.appNamespaces(policy.DestinationAppGUID)
+		if err != nil {
+			logger.Error("failed-to-list-app-namespaces", err, lager.Data{"app-guid": policy.DestinationAppGUID})
+
+			return err
+		}
+
+		for _, namespace := range namespaces {
+			if err := d.create(logger, namespace, networkPolicy); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *AppPolicyDesirer) List() ([]opi.AppNetworkPolicy, error) {
+	networkPolicies, err := d.client.GetBySourceType(AppPolicySourceType)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list network policies")
+	}
+
+	policies := make([]opi.AppNetworkPolicy, 0, len(networkPolicies))
+	listed := map[string]bool{}
+
+	for _, networkPolicy := range networkPolicies {
+		if listed[networkPolicy.Name] {
+			continue
+		}
+
+		listed[networkPolicy.Name] = true
+
+		policy, err := appPolicyFromAnnotation(networkPolicy)
+		if err != nil {
+			d.logger.Error("failed-to-read-app-policy", err, lager.Data{"name": networkPolicy.Name})
+
+			continue
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+func (d *AppPolicyDesirer) Delete(policies []opi.AppNetworkPolicy) error {
+	logger := d.logger.Session("delete")
+
+	networkPolicies, err := d.client.GetBySourceType(AppPolicySourceType)
+	if err != nil {
+		return errors.Wrap(err, "failed to list network policies")
+	}
+
+	for _, policy := range policies {
+		name := AppNetworkPolicyName(policy)
+
+		for _, networkPolicy := range networkPolicies {
+			if networkPolicy.Name != name {
+				continue
+			}
+
+			err := d.client.Delete(networkPolicy.Namespace, networkPolicy.Name)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				logger.Error("failed-to-delete-network-policy", err, lager.Data{"name": name})
+
+				return errors.Wrap(err, "failed to delete network policy")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Refresh rebuilds the NetworkPolicies the app takes part in from the
+// policies they were created for, undoing any drift in their pod selectors.
+// The policies the app is the destination of are also moved to the
+// namespaces the app currently runs in.
+func (d *AppPolicyDesirer) Refresh(appGUID string) error {
+	logger := d.logger.Session("refresh", lager.Data{"app-guid": appGUID})
+
+	networkPolicies, err := d.client.GetBySourceType(AppPolicySourceType)
+	if err != nil {
+		return errors.Wrap(err, "failed to list network policies")
+	}
+
+	namespaces, err := d.appNamespaces(appGUID)
+	if err != nil {
+		logger.Error("failed-to-list-app-namespaces", err)
+
+		return err
+	}
+
+	var (
+		ingressPolicies = map[string]*networkingv1.NetworkPolicy{}
+		placed          = map[types.NamespacedName]bool{}
+		stale           []networkingv1.NetworkPolicy
+	)
+
+	for i := range networkPolicies {
+		networkPolicy := networkPolicies[i]
+		isDestination := networkPolicy.Labels[LabelDestinationAppGUID] == appGUID
+
+		if !isDestination && networkPolicy.Labels[LabelSourceAppGUID] != appGUID {
+			continue
+		}
+
+		policy, err := appPolicyFromAnnotation(networkPolicy)
+		if err != nil {
+			logger.Error("failed-to-read-app-policy", err, lager.Data{"name": networkPolicy.Name})
+
+			continue
+		}
+
+		desired, err := ToAppNetworkPolicy(policy)
+		if err != nil {
+			return err
+		}
+
+		if isDestination {
+			ingressPolicies[desired.Name] = desired
+
+			if !containsString(namespaces, networkPolicy.Namespace) {
+				stale = append(stale, networkPolicy)
+
+				continue
+			}
+
+			placed[types.NamespacedName{Namespace: networkPolicy.Namespace, Name: networkPolicy.Name}] = true
+		}
+
+		if reflect.DeepEqual(networkPolicy.Spec, desired.Spec) {
+			continue
+		}
+
+		networkPolicy.Spec = desired.Spec
+		if _, err := d.client.Update(networkPolicy.Namespace, &networkPolicy); err != nil {
+			logger.Error("failed-to-update-network-policy", err, lager.Data{"name": networkPolicy.Name})
+
+			return errors.Wrap(err, "failed to update network policy")
+		}
+	}
+
+	for _, name := range sortedKeys(ingressPolicies) {
+		for _, namespace := range namespaces {
+			if placed[types.NamespacedName{Namespace: namespace, Name: name}] {
+				continue
+			}
+
+			if err := d.create(logger, namespace, ingressPolicies[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, networkPolicy := range stale {
+		err := d.client.Delete(networkPolicy.Namespace, networkPolicy.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error("failed-to-delete-network-policy", err, lager.Data{"name": networkPolicy.Name, "namespace": networkPolicy.Namespace})
+
+			return errors.Wrap(err, "failed to delete network policy")
+		}
+	}
+
+	return nil
+}
+
+func (d *AppPolicyDesirer) create(logger lager.Logger, namespace string, networkPolicy *networkingv1.NetworkPolicy) error {
+	_, err := d.client.Create(namespace, networkPolicy.DeepCopy())
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		logger.Error("failed-to-create-network-policy", err, lager.Data{"name": networkPolicy.Name, "namespace": namespace})
+
+		return errors.Wrap(err, "failed to create network policy")
+	}
+
+	return nil
+}
+
+// appNamespaces returns the namespaces the app has StatefulSets in, or the
+// default namespace if it has none yet.
+func (d *AppPolicyDesirer) appNamespaces(appGUID string) ([]string, error) {
+	statefulSets, err := d.statefulSets.GetByAppGUID(appGUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list statefulsets")
+	}
+
+	namespaces := []string{}
+
+	for _, statefulSet := range statefulSets {
+		if !containsString(namespaces, statefulSet.Namespace) {
+			namespaces = append(namespaces, statefulSet.Namespace)
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return []string{d.namespace}, nil
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// AppNetworkPolicyName derives the name from the whole policy, so that
+// desiring the same policy twice does not create a second NetworkPolicy.
+func AppNetworkPolicyName(policy opi.AppNetworkPolicy) string {
+	key := fmt.Sprintf("%s|%s|%s|%d-%d",
+		policy.SourceAppGUID, policy.DestinationAppGUID, policy.Protocol, policy.Ports.Start, policy.Ports.End)
+	sum := sha256.Sum256([]byte(key))
+
+	return fmt.Sprintf("%s-%s", appPolicyNamePrefix, hex.EncodeToString(sum[:]))
+}
+
+// ToAppNetworkPolicy allows ingress to the destination app from the source
+// app on the policy ports. Selecting the destination pods isolates them, so
+// ingress from pods that are not app or task instances, such as the router,
+// is allowed as well. Apps reaching the destination through its internal
+// routes still need a policy, as in Cloud Foundry.
+func ToAppNetworkPolicy(policy opi.AppNetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	ports, err := toNetworkPolicyPorts(opi.EgressRule{
+		Protocol:  policy.Protocol,
+		PortRange: &opi.PortRange{Start: policy.Ports.Start, End: policy.Ports.End},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal app policy")
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: AppNetworkPolicyName(policy),
+			Labels: map[string]string{
+				LabelSourceType:         AppPolicySourceType,
+				LabelSourceAppGUID:      policy.SourceAppGUID,
+				LabelDestinationAppGUID: policy.DestinationAppGUID,
+			},
+			Annotations: map[string]string{
+				AnnotationAppPolicy: string(policyJSON),
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: appPodLabels(policy.DestinationAppGUID)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							PodSelector:       &metav1.LabelSelector{MatchLabels: appPodLabels(policy.SourceAppGUID)},
+							NamespaceSelector: &metav1.LabelSelector{},
+						},
+					},
+					Ports: ports,
+				},
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							PodSelector: &metav1.LabelSelector{
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{
+										Key:      LabelSourceType,
+										Operator: metav1.LabelSelectorOpNotIn,
+										Values:   []string{AppSourceType, taskSourceType},
+									},
+								},
+							},
+							NamespaceSelector: &metav1.LabelSelector{},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func appPodLabels(appGUID string) map[string]string {
+	return map[string]string{
+		LabelAppGUID:    appGUID,
+		LabelSourceType: AppSourceType,
+	}
+}
+
+func appPolicyFromAnnotation(networkPolicy networkingv1.NetworkPolicy) (opi.AppNetworkPolicy, error) {
+	var policy opi.AppNetworkPolicy
+	if err := json.Unmarshal([]byte(networkPolicy.Annotations[AnnotationAppPolicy]), &policy); err != nil {
+		return opi.AppNetworkPolicy{}, errors.Wrap(err, "failed to unmarshal app policy")
+	}
+
+	return policy, nil
+}
+
+func sortedKeys(networkPolicies map[string]*networkingv1.NetworkPolicy) []string {
+	names := make([]string, 0, len(networkPolicies))
+	for name := range networkPolicies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
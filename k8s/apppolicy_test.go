@@ -0,0 +1,390 @@
+package k8s_test
+
+import (
+	"errors"
+
+	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/k8sfakes"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("AppPolicyDesirer", func() {
+	var (
+		client       *k8sfakes.FakeAppPolicyClient
+		statefulSets *k8sfakes.FakeAppStatefulSetLister
+		desirer      *AppPolicyDesirer
+		policy       opi.AppNetworkPolicy
+	)
+
+	BeforeEach(func() {
+		client = new(k8sfakes.FakeAppPolicyClient)
+		statefulSets = new(k8sfakes.FakeAppStatefulSetLister)
+		desirer = NewAppPolicyDesirer(lagertest.NewTestLogger("app-policy"), client, statefulSets, "workloads")
+		policy = opi.AppNetworkPolicy{
+			SourceAppGUID:      "app-a",
+			DestinationAppGUID: "app-b",
+			Protocol:           "tcp",
+			Ports:              opi.PortRange{Start: 8080, End: 8081},
+		}
+	})
+
+	existingPolicyIn := func(namespace string, p opi.AppNetworkPolicy) networkingv1.NetworkPolicy {
+		networkPolicy, err := ToAppNetworkPolicy(p)
+		Expect(err).NotTo(HaveOccurred())
+		networkPolicy.Namespace = namespace
+
+		return *networkPolicy
+	}
+
+	existingPolicy := func(p opi.AppNetworkPolicy) networkingv1.NetworkPolicy {
+		return existingPolicyIn("workloads", p)
+	}
+
+	appStatefulSetIn := func(namespace string) appsv1.StatefulSet {
+		return appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "app-b-sts", Namespace: namespace}}
+	}
+
+	Describe("ToAppNetworkPolicy", func() {
+		var networkPolicy *networkingv1.NetworkPolicy
+
+		BeforeEach(func() {
+			var err error
+			networkPolicy, err = ToAppNetworkPolicy(policy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("selects the destination app pods regardless of their version", func() {
+			Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+				LabelAppGUID:    "app-b",
+				LabelSourceType: "APP",
+			}))
+			Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+		})
+
+		It("allows the source app pods in any namespace", func() {
+			Expect(networkPolicy.Spec.Ingress).To(HaveLen(2))
+			Expect(networkPolicy.Spec.Ingress[0].From).To(ConsistOf(networkingv1.NetworkPolicyPeer{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{LabelAppGUID: "app-a", LabelSourceType: "APP"}},
+				NamespaceSelector: &metav1.LabelSelector{},
+			}))
+		})
+
+		It("keeps allowing pods that are not app or task instances, such as the router", func() {
+			Expect(networkPolicy.Spec.Ingress[1].From).To(ConsistOf(networkingv1.NetworkPolicyPeer{
+				PodSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: LabelSourceType, Operator: metav1.LabelSelectorOpNotIn, Values: []string{"APP", "TASK"}},
+					},
+				},
+				NamespaceSelector: &metav1.LabelSelector{},
+			}))
+			Expect(networkPolicy.Spec.Ingress[1].Ports).To(BeEmpty())
+		})
+
+		It("allows the destination ports", func() {
+			tcp := corev1.ProtocolTCP
+			port8080, port8081 := intstr.FromInt(8080), intstr.FromInt(8081)
+
+			Expect(networkPolicy.Spec.Ingress[0].Ports).To(ConsistOf(
+				networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port8080},
+				networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port8081},
+			))
+		})
+
+		It("labels the policy with both apps", func() {
+			Expect(networkPolicy.Labels).To(Equal(map[string]string{
+				LabelSourceType:         "APP_POLICY",
+				LabelSourceAppGUID:      "app-a",
+				LabelDestinationAppGUID: "app-b",
+			}))
+		})
+
+		It("names the policy after its content", func() {
+			Expect(networkPolicy.Name).To(HavePrefix("c2c-"))
+			Expect(networkPolicy.Name).To(Equal(AppNetworkPolicyName(policy)))
+
+			policy.Ports.End = 8082
+			Expect(networkPolicy.Name).NotTo(Equal(AppNetworkPolicyName(policy)))
+		})
+	})
+
+	Describe("Desire", func() {
+		var err error
+
+		JustBeforeEach(func() {
+			err = desirer.Desire([]opi.AppNetworkPolicy{policy})
+		})
+
+		It("creates the network policy in the default namespace", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statefulSets.GetByAppGUIDArgsForCall(0)).To(Equal("app-b"))
+			Expect(client.CreateCallCount()).To(Equal(1))
+			namespace, networkPolicy := client.CreateArgsForCall(0)
+			Expect(namespace).To(Equal("workloads"))
+			Expect(networkPolicy.Name).To(Equal(AppNetworkPolicyName(policy)))
+		})
+
+		When("the destination app runs in other namespaces", func() {
+			BeforeEach(func() {
+				statefulSets.GetByAppGUIDReturns([]appsv1.StatefulSet{
+					appStatefulSetIn("space-ns"),
+					appStatefulSetIn("other-ns"),
+					appStatefulSetIn("space-ns"),
+				}, nil)
+			})
+
+			It("creates the network policy in each of them", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.CreateCallCount()).To(Equal(2))
+				namespace, _ := client.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("other-ns"))
+				namespace, _ = client.CreateArgsForCall(1)
+				Expect(namespace).To(Equal("space-ns"))
+			})
+		})
+
+		When("listing the destination app statefulsets fails", func() {
+			BeforeEach(func() {
+				statefulSets.GetByAppGUIDReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to list statefulsets")))
+				Expect(client.CreateCallCount()).To(BeZero())
+			})
+		})
+
+		When("the network policy already exists", func() {
+			BeforeEach(func() {
+				client.CreateReturns(nil, k8serrors.NewAlreadyExists(schema.GroupResource{}, "boom"))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("creating the network policy fails", func() {
+			BeforeEach(func() {
+				client.CreateReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to create network policy")))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the policies the network policies were created for", func() {
+			client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{existingPolicy(policy)}, nil)
+
+			policies, err := desirer.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(ConsistOf(policy))
+			Expect(client.GetBySourceTypeArgsForCall(0)).To(Equal("APP_POLICY"))
+		})
+
+		It("lists a policy desired in several namespaces once", func() {
+			client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{
+				existingPolicyIn("space-ns", policy),
+				existingPolicyIn("other-ns", policy),
+			}, nil)
+
+			policies, err := desirer.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(ConsistOf(policy))
+		})
+
+		When("listing fails", func() {
+			It("returns an error", func() {
+				client.GetBySourceTypeReturns(nil, errors.New("boom"))
+
+				_, err := desirer.List()
+				Expect(err).To(MatchError(ContainSubstring("failed to list network policies")))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		var err error
+
+		BeforeEach(func() {
+			other := policy
+			other.Protocol = "udp"
+			client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{existingPolicy(policy), existingPolicy(other)}, nil)
+		})
+
+		JustBeforeEach(func() {
+			err = desirer.Delete([]opi.AppNetworkPolicy{policy})
+		})
+
+		It("deletes only the matching network policy", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.DeleteCallCount()).To(Equal(1))
+			namespace, name := client.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("workloads"))
+			Expect(name).To(Equal(AppNetworkPolicyName(policy)))
+		})
+
+		When("the network policy is already gone", func() {
+			BeforeEach(func() {
+				client.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{}, "boom"))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("deleting fails", func() {
+			BeforeEach(func() {
+				client.DeleteReturns(errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to delete network policy")))
+			})
+		})
+	})
+
+	Describe("Refresh", func() {
+		var (
+			drifted networkingv1.NetworkPolicy
+			err     error
+		)
+
+		BeforeEach(func() {
+			drifted = existingPolicy(policy)
+			drifted.Spec.PodSelector.MatchLabels[LabelVersion] = "old-version"
+
+			unrelated := policy
+			unrelated.SourceAppGUID = "app-c"
+			unrelated.DestinationAppGUID = "app-d"
+			unrelatedPolicy := existingPolicy(unrelated)
+			unrelatedPolicy.Spec.PodSelector.MatchLabels[LabelVersion] = "old-version"
+
+			client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{drifted, existingPolicy(policy), unrelatedPolicy}, nil)
+		})
+
+		JustBeforeEach(func() {
+			err = desirer.Refresh("app-b")
+		})
+
+		It("restores the network policies of the app that drifted", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(1))
+			namespace, networkPolicy := client.UpdateArgsForCall(0)
+			Expect(namespace).To(Equal("workloads"))
+			Expect(networkPolicy.Spec.PodSelector.MatchLabels).NotTo(HaveKey(LabelVersion))
+		})
+
+		It("does not create or delete network policies that are in place", func() {
+			Expect(client.CreateCallCount()).To(BeZero())
+			Expect(client.DeleteCallCount()).To(BeZero())
+		})
+
+		When("updating fails", func() {
+			BeforeEach(func() {
+				client.UpdateReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to update network policy")))
+			})
+		})
+
+		When("the app runs in another namespace than its network policies", func() {
+			var sourcePolicy networkingv1.NetworkPolicy
+
+			BeforeEach(func() {
+				statefulSets.GetByAppGUIDReturns([]appsv1.StatefulSet{appStatefulSetIn("space-ns")}, nil)
+
+				outgoing := policy
+				outgoing.SourceAppGUID = "app-b"
+				outgoing.DestinationAppGUID = "app-c"
+				sourcePolicy = existingPolicy(outgoing)
+
+				client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{existingPolicy(policy), sourcePolicy}, nil)
+			})
+
+			It("creates the network policies the app is the destination of in its namespace", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.CreateCallCount()).To(Equal(1))
+				namespace, networkPolicy := client.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("space-ns"))
+				Expect(networkPolicy.Name).To(Equal(AppNetworkPolicyName(policy)))
+			})
+
+			It("deletes them from the namespace the app no longer runs in", func() {
+				Expect(client.DeleteCallCount()).To(Equal(1))
+				namespace, name := client.DeleteArgsForCall(0)
+				Expect(namespace).To(Equal("workloads"))
+				Expect(name).To(Equal(AppNetworkPolicyName(policy)))
+			})
+
+			It("leaves the network policies the app is only the source of", func() {
+				for i := 0; i < client.DeleteCallCount(); i++ {
+					_, name := client.DeleteArgsForCall(i)
+					Expect(name).NotTo(Equal(sourcePolicy.Name))
+				}
+			})
+
+			When("creating the network policy fails", func() {
+				BeforeEach(func() {
+					client.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("keeps the existing network policy", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to create network policy")))
+					Expect(client.DeleteCallCount()).To(BeZero())
+				})
+			})
+
+			When("deleting the stale network policy fails", func() {
+				BeforeEach(func() {
+					client.DeleteReturns(errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to delete network policy")))
+				})
+			})
+		})
+
+		When("the network policy of the app is missing from one of its namespaces", func() {
+			BeforeEach(func() {
+				statefulSets.GetByAppGUIDReturns([]appsv1.StatefulSet{appStatefulSetIn("workloads"), appStatefulSetIn("space-ns")}, nil)
+				client.GetBySourceTypeReturns([]networkingv1.NetworkPolicy{existingPolicy(policy)}, nil)
+			})
+
+			It("creates it there", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.CreateCallCount()).To(Equal(1))
+				namespace, _ := client.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("space-ns"))
+				Expect(client.DeleteCallCount()).To(BeZero())
+			})
+		})
+
+		When("listing the app statefulsets fails", func() {
+			BeforeEach(func() {
+				statefulSets.GetByAppGUIDReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to list statefulsets")))
+			})
+		})
+	})
+})
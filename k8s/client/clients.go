@@ -10,7 +10,9 @@ import (
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -58,6 +60,25 @@ func (c *Pod) GetByLRPIdentifier(id opi.LRPIdentifier) ([]corev1.Pod, error) {
 	return podList.Items, nil
 }
 
+func (c *Pod) GetByTaskGUID(guid string) ([]corev1.Pod, error) {
+	podList, err := c.clientSet.CoreV1().Pods(c.workloadsNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf(
+			"%s=%s,%s=%s",
+			k8s.LabelGUID, guid,
+			k8s.LabelSourceType, "TASK",
+		),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods by task guid")
+	}
+
+	return podList.Items, nil
+}
+
+func (c *Pod) Get(namespace, name string) (*corev1.Pod, error) {
+	return c.clientSet.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
 func (c *Pod) Delete(namespace, name string) error {
 	return c.clientSet.CoreV1().Pods(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 }
@@ -90,6 +111,65 @@ func (c *PodDisruptionBudget) Delete(namespace string, name string) error {
 	return c.clientSet.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 }
 
+type NetworkPolicy struct {
+	clientSet          kubernetes.Interface
+	workloadsNamespace string
+}
+
+func NewNetworkPolicy(clientSet kubernetes.Interface, workloadsNamespace string) *NetworkPolicy {
+	return &NetworkPolicy{
+		clientSet:          clientSet,
+		workloadsNamespace: workloadsNamespace,
+	}
+}
+
+func (c *NetworkPolicy) GetBySourceType(sourceType string) ([]networkingv1.NetworkPolicy, error) {
+	networkPolicyList, err := c.clientSet.NetworkingV1().NetworkPolicies(c.workloadsNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", k8s.LabelSourceType, sourceType),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list network policies by resource type")
+	}
+
+	return networkPolicyList.Items, nil
+}
+
+func (c *NetworkPolicy) Create(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	return c.clientSet.NetworkingV1().NetworkPolicies(namespace).Create(context.Background(), networkPolicy, metav1.CreateOptions{})
+}
+
+func (c *NetworkPolicy) Update(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	return c.clientSet.NetworkingV1().NetworkPolicies(namespace).Update(context.Background(), networkPolicy, metav1.UpdateOptions{})
+}
+
+func (c *NetworkPolicy) Delete(namespace string, name string) error {
+	return c.clientSet.NetworkingV1().NetworkPolicies(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+}
+
+type Service struct {
+	clientSet kubernetes.Interface
+}
+
+func NewService(clientSet kubernetes.Interface) *Service {
+	return &Service{clientSet: clientSet}
+}
+
+func (c *Service) Create(namespace string, service *corev1.Service) (*corev1.Service, error) {
+	return c.clientSet.CoreV1().Services(namespace).Create(context.Background(), service, metav1.CreateOptions{})
+}
+
+func (c *Service) Get(namespace, name string) (*corev1.Service, error) {
+	return c.clientSet.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func (c *Service) Update(namespace string, service *corev1.Service) (*corev1.Service, error) {
+	return c.clientSet.CoreV1().Services(namespace).Update(context.Background(), service, metav1.UpdateOptions{})
+}
+
+func (c *Service) Delete(namespace string, name string) error {
+	return c.clientSet.CoreV1().Services(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+}
+
 type StatefulSet struct {
 	clientSet          kubernetes.Interface
 	workloadsNamespace string
@@ -121,6 +201,21 @@ func (c *StatefulSet) GetBySourceType(sourceType string) ([]appsv1.StatefulSet,
 	return statefulSetList.Items, nil
 }
 
+func (c *StatefulSet) GetByAppGUID(appGUID string) ([]appsv1.StatefulSet, error) {
+	statefulSetList, err := c.clientSet.AppsV1().StatefulSets(c.workloadsNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf(
+			"%s=%s,%s=%s",
+			k8s.LabelSourceType, k8s.AppSourceType,
+			k8s.LabelAppGUID, appGUID,
+		),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list statefulsets by app guid")
+	}
+
+	return statefulSetList.Items, nil
+}
+
 func (c *StatefulSet) GetByLRPIdentifier(id opi.LRPIdentifier) ([]appsv1.StatefulSet, error) {
 	statefulSetList, err := c.clientSet.AppsV1().StatefulSets(c.workloadsNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: fmt.Sprintf(
@@ -213,6 +308,49 @@ func (c *Job) SetLabel(job *batchv1.Job, label, value string) (*batchv1.Job, err
 		patchBytes, metav1.PatchOptions{})
 }
 
+type CronJob struct {
+	clientSet          kubernetes.Interface
+	workloadsNamespace string
+}
+
+func NewCronJob(clientSet kubernetes.Interface, workloadsNamespace string) *CronJob {
+	return &CronJob{
+		clientSet:          clientSet,
+		workloadsNamespace: workloadsNamespace,
+	}
+}
+
+func (c *CronJob) Create(namespace string, cronJob *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	return c.clientSet.BatchV1beta1().CronJobs(namespace).Create(context.Background(), cronJob, metav1.CreateOptions{})
+}
+
+func (c *CronJob) Update(namespace string, cronJob *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	return c.clientSet.BatchV1beta1().CronJobs(namespace).Update(context.Background(), cronJob, metav1.UpdateOptions{})
+}
+
+func (c *CronJob) Delete(namespace string, name string) error {
+	backgroundPropagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{
+		PropagationPolicy: &backgroundPropagation,
+	}
+
+	return c.clientSet.BatchV1beta1().CronJobs(namespace).Delete(context.Background(), name, deleteOpts)
+}
+
+func (c *CronJob) GetByGUID(guid string) ([]batchv1beta1.CronJob, error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8s.LabelGUID, guid)}
+	cronJobs, err := c.clientSet.BatchV1beta1().CronJobs(c.workloadsNamespace).List(context.Background(), listOpts)
+
+	return cronJobs.Items, errors.Wrap(err, "failed to list cron jobs by guid")
+}
+
+func (c *CronJob) List() ([]batchv1beta1.CronJob, error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8s.LabelScheduledTask, k8s.ScheduledTaskTrue)}
+	cronJobs, err := c.clientSet.BatchV1beta1().CronJobs(c.workloadsNamespace).List(context.Background(), listOpts)
+
+	return cronJobs.Items, errors.Wrap(err, "failed to list cron jobs")
+}
+
 type Secret struct {
 	clientSet kubernetes.Interface
 }
@@ -225,6 +363,17 @@ func (c *Secret) Get(namespace, name string) (*corev1.Secret, error) {
 	return c.clientSet.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 }
 
+func (c *Secret) List(namespace, labelSelector string) ([]corev1.Secret, error) {
+	secretList, err := c.clientSet.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list secrets")
+	}
+
+	return secretList.Items, nil
+}
+
 func (c *Secret) Create(namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
 	return c.clientSet.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
 }
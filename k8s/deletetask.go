@@ -2,16 +2,21 @@ package k8s
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s/utils"
 	"code.cloudfoundry.org/lager"
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 //counterfeiter:generate . JobDeletingClient
 //counterfeiter:generate . SecretsDeleter
+//counterfeiter:generate . NetworkPolicyDeleter
 
 type JobDeletingClient interface {
 	GetByGUID(guid string, includeCompleted bool) ([]batchv1.Job, error)
@@ -22,67 +27,104 @@ type SecretsDeleter interface {
 	Delete(namespace, name string) error
 }
 
+type NetworkPolicyDeleter interface {
+	Delete(namespace, name string) error
+}
+
 type TaskDeleter struct {
-	logger         lager.Logger
-	jobClient      JobDeletingClient
-	secretsDeleter SecretsDeleter
+	logger               lager.Logger
+	jobClient            JobDeletingClient
+	secretsDeleter       SecretsDeleter
+	networkPolicyDeleter NetworkPolicyDeleter
 }
 
 func NewTaskDeleter(
 	logger lager.Logger,
 	jobClient JobDeletingClient,
 	secretsDeleter SecretsDeleter,
+	networkPolicyDeleter NetworkPolicyDeleter,
 ) *TaskDeleter {
 	return &TaskDeleter{
-		logger:         logger,
-		jobClient:      jobClient,
-		secretsDeleter: secretsDeleter,
+		logger:               logger,
+		jobClient:            jobClient,
+		secretsDeleter:       secretsDeleter,
+		networkPolicyDeleter: networkPolicyDeleter,
 	}
 }
 
 func (d *TaskDeleter) Delete(guid string) (string, error) {
 	logger := d.logger.Session("delete", lager.Data{"guid": guid})
 
-	job, err := d.getJobByGUID(logger, guid)
+	jobs, err := d.getJobsByGUID(logger, guid)
 	if err != nil {
 		return "", err
 	}
 
-	return d.delete(logger, job)
+	return d.delete(logger, jobs)
 }
 
-func (d *TaskDeleter) getJobByGUID(logger lager.Logger, guid string) (batchv1.Job, error) {
+// getJobsByGUID returns the jobs of all the attempts of the task, the job of
+// the first attempt first.
+func (d *TaskDeleter) getJobsByGUID(logger lager.Logger, guid string) ([]batchv1.Job, error) {
 	jobs, err := d.jobClient.GetByGUID(guid, true)
 	if err != nil {
 		logger.Error("failed-to-list-jobs", err)
 
-		return batchv1.Job{}, errors.Wrap(err, "failed to list jobs")
+		return nil, errors.Wrap(err, "failed to list jobs")
+	}
+
+	if len(jobs) == 0 {
+		logger.Info("no-jobs-found")
+
+		return nil, errors.Wrapf(eirini.ErrNotFound, "no job found for guid %s", guid)
 	}
 
-	if len(jobs) != 1 {
+	if _, err := LatestTaskAttempt(jobs); err != nil {
 		logger.Error("job-does-not-have-1-instance", nil, lager.Data{"instances": len(jobs)})
 
-		return batchv1.Job{}, fmt.Errorf("job with guid %s should have 1 instance, but it has: %d", guid, len(jobs))
+		return nil, fmt.Errorf("job with guid %s should have 1 instance, but it has: %d", guid, len(jobs))
 	}
 
-	return jobs[0], nil
+	sort.Slice(jobs, func(i, j int) bool {
+		return TaskAttempt(&jobs[i]) < TaskAttempt(&jobs[j])
+	})
+
+	return jobs, nil
 }
 
-func (d *TaskDeleter) delete(logger lager.Logger, job batchv1.Job) (string, error) {
+// delete deletes the secrets and the network policy, which are named after the
+// job of the first attempt, and then the jobs of all the attempts.
+func (d *TaskDeleter) delete(logger lager.Logger, jobs []batchv1.Job) (string, error) {
+	job := jobs[0]
+
 	if err := d.deleteDockerRegistrySecret(logger, job); err != nil {
 		return "", err
 	}
 
-	callbackURL := job.Annotations[AnnotationCompletionCallback]
+	if err := d.deleteEnvSecret(logger, job); err != nil {
+		return "", err
+	}
+
+	err := d.networkPolicyDeleter.Delete(job.Namespace, job.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-network-policy", err)
 
-	if len(job.OwnerReferences) != 0 {
-		return callbackURL, nil
+		return "", errors.Wrap(err, "failed to delete network policy")
 	}
 
-	if err := d.jobClient.Delete(job.Namespace, job.Name); err != nil {
-		logger.Error("failed-to-delete-job", err)
+	callbackURL := job.Annotations[AnnotationCompletionCallback]
+
+	for _, attempt := range jobs {
+		if len(attempt.OwnerReferences) != 0 {
+			continue
+		}
+
+		err := d.jobClient.Delete(attempt.Namespace, attempt.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error("failed-to-delete-job", err, lager.Data{"name": attempt.Name})
 
-		return "", errors.Wrap(err, "failed to delete job")
+			return "", errors.Wrap(err, "failed to delete job")
+		}
 	}
 
 	return callbackURL, nil
@@ -110,6 +152,35 @@ func (d *TaskDeleter) deleteDockerRegistrySecret(logger lager.Logger, job batchv
 	return nil
 }
 
+func (d *TaskDeleter) deleteEnvSecret(logger lager.Logger, job batchv1.Job) error {
+	name := envSecretName(job.Name)
+
+	if !referencesSecret(job.Spec.Template.Spec.Containers, name) {
+		return nil
+	}
+
+	err := d.secretsDeleter.Delete(job.Namespace, name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-secret", err, lager.Data{"name": name, "namespace": job.Namespace})
+
+		return errors.Wrap(err, "failed to delete env secret")
+	}
+
+	return nil
+}
+
+func referencesSecret(containers []corev1.Container, secretName string) bool {
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func dockerImagePullSecretNamePrefix(appName, spaceName, taskGUID string) string {
 	secretNamePrefix := fmt.Sprintf("%s-%s", appName, spaceName)
 
@@ -3,6 +3,7 @@ package k8s_test
 import (
 	"fmt"
 
+	"code.cloudfoundry.org/eirini"
 	. "code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/k8s/k8sfakes"
 	"code.cloudfoundry.org/eirini/opi"
@@ -12,7 +13,9 @@ import (
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var _ = Describe("TaskDeleter", func() {
@@ -26,13 +29,15 @@ var _ = Describe("TaskDeleter", func() {
 		task          *opi.Task
 		deleter       *TaskDeleter
 		jobClient     *k8sfakes.FakeJobDeletingClient
-		secretDeleter *k8sfakes.FakeSecretsCreatorDeleter
+		secretDeleter *k8sfakes.FakeSecretsClient
+		policyDeleter *k8sfakes.FakeNetworkPolicyDeleter
 		job           batchv1.Job
 	)
 
 	BeforeEach(func() {
 		jobClient = new(k8sfakes.FakeJobDeletingClient)
-		secretDeleter = new(k8sfakes.FakeSecretsCreatorDeleter)
+		secretDeleter = new(k8sfakes.FakeSecretsClient)
+		policyDeleter = new(k8sfakes.FakeNetworkPolicyDeleter)
 		task = &opi.Task{
 			Image: Image,
 			Name:  "task-name",
@@ -42,6 +47,7 @@ var _ = Describe("TaskDeleter", func() {
 			lagertest.NewTestLogger("deletetask"),
 			jobClient,
 			secretDeleter,
+			policyDeleter,
 		)
 
 		job = batchv1.Job{
@@ -79,6 +85,39 @@ var _ = Describe("TaskDeleter", func() {
 			Expect(includeCompleted).To(Equal(true))
 		})
 
+		It("deletes the network policy of the job", func() {
+			_, err := deleter.Delete(taskGUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policyDeleter.DeleteCallCount()).To(Equal(1))
+			namespace, name := policyDeleter.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("my-namespace"))
+			Expect(name).To(Equal("my-job"))
+		})
+
+		Context("when the job has no network policy", func() {
+			BeforeEach(func() {
+				policyDeleter.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{}, "my-job"))
+			})
+
+			It("still deletes the job", func() {
+				_, err := deleter.Delete(taskGUID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobClient.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when deleting the network policy fails", func() {
+			BeforeEach(func() {
+				policyDeleter.DeleteReturns(errors.New("boom"))
+			})
+
+			It("returns the error without deleting the job", func() {
+				_, err := deleter.Delete(taskGUID)
+				Expect(err).To(MatchError(ContainSubstring("failed to delete network policy")))
+				Expect(jobClient.DeleteCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when the job has an owner", func() {
 			BeforeEach(func() {
 				job.OwnerReferences = []metav1.OwnerReference{
@@ -103,9 +142,9 @@ var _ = Describe("TaskDeleter", func() {
 				jobClient.GetByGUIDReturns([]batchv1.Job{}, nil)
 			})
 
-			It("should return an error", func() {
+			It("should return a not found error", func() {
 				_, err := deleter.Delete(taskGUID)
-				Expect(err).To(MatchError(fmt.Sprintf("job with guid %s should have 1 instance, but it has: %d", taskGUID, 0)))
+				Expect(errors.Is(err, eirini.ErrNotFound)).To(BeTrue())
 				Expect(jobClient.GetByGUIDCallCount()).To(Equal(1))
 				Expect(jobClient.DeleteCallCount()).To(BeZero())
 			})
@@ -124,6 +163,46 @@ var _ = Describe("TaskDeleter", func() {
 			})
 		})
 
+		Context("when the task has been retried", func() {
+			var nextAttempt *batchv1.Job
+
+			BeforeEach(func() {
+				job.Annotations[AnnotationTaskAttempt] = "1"
+				job.Annotations[AnnotationTaskMaxAttempts] = "2"
+				nextAttempt = NextTaskAttempt(job)
+				jobClient.GetByGUIDReturns([]batchv1.Job{*nextAttempt, job}, nil)
+			})
+
+			It("deletes the jobs of all the attempts", func() {
+				_, err := deleter.Delete(taskGUID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobClient.DeleteCallCount()).To(Equal(2))
+				_, name := jobClient.DeleteArgsForCall(0)
+				Expect(name).To(Equal("my-job"))
+				_, name = jobClient.DeleteArgsForCall(1)
+				Expect(name).To(Equal("my-job-2"))
+			})
+
+			It("deletes the network policy of the first attempt", func() {
+				_, err := deleter.Delete(taskGUID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policyDeleter.DeleteCallCount()).To(Equal(1))
+				_, name := policyDeleter.DeleteArgsForCall(0)
+				Expect(name).To(Equal("my-job"))
+			})
+
+			When("the job of an attempt is already gone", func() {
+				BeforeEach(func() {
+					jobClient.DeleteReturnsOnCall(1, k8serrors.NewNotFound(schema.GroupResource{}, "my-job-2"))
+				})
+
+				It("succeeds", func() {
+					_, err := deleter.Delete(taskGUID)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
 		Context("when the job references image pull secrets", func() {
 			var dockerRegistrySecretName string
 
@@ -158,6 +237,62 @@ var _ = Describe("TaskDeleter", func() {
 			})
 		})
 
+		Context("when the job references an env secret", func() {
+			BeforeEach(func() {
+				job.Spec.Template.Spec.Containers = []corev1.Container{
+					{
+						Name: "opi-task",
+						Env: []corev1.EnvVar{
+							{Name: "FOO", Value: "foo"},
+							{
+								Name: "VCAP_SERVICES",
+								ValueFrom: &corev1.EnvVarSource{
+									SecretKeyRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "my-job-env"},
+										Key:                  "VCAP_SERVICES",
+									},
+								},
+							},
+						},
+					},
+				}
+				jobClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+			})
+
+			It("deletes the env secret", func() {
+				_, err := deleter.Delete(task.GUID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secretDeleter.DeleteCallCount()).To(Equal(1))
+				actualNamespace, actualSecretName := secretDeleter.DeleteArgsForCall(0)
+				Expect(actualNamespace).To(Equal("my-namespace"))
+				Expect(actualSecretName).To(Equal("my-job-env"))
+			})
+
+			Context("when the env secret is already gone", func() {
+				BeforeEach(func() {
+					secretDeleter.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{}, "my-job-env"))
+				})
+
+				It("still deletes the job", func() {
+					_, err := deleter.Delete(task.GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(jobClient.DeleteCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when deleting the env secret fails", func() {
+				BeforeEach(func() {
+					secretDeleter.DeleteReturns(errors.New("env-secret-delete-failure"))
+				})
+
+				It("returns the error without deleting the job", func() {
+					_, err := deleter.Delete(task.GUID)
+					Expect(err).To(MatchError(ContainSubstring("env-secret-delete-failure")))
+					Expect(jobClient.DeleteCallCount()).To(BeZero())
+				})
+			})
+		})
+
 		Context("when listing the jobs by label fails", func() {
 			BeforeEach(func() {
 				jobClient.GetByGUIDReturns(nil, errors.New("failed to list jobs"))
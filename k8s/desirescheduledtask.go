@@ -0,0 +1,298 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//counterfeiter:generate . CronJobClient
+
+type CronJobClient interface {
+	Create(namespace string, cronJob *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error)
+	Update(namespace string, cronJob *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error)
+	Delete(namespace string, name string) error
+	GetByGUID(guid string) ([]batchv1beta1.CronJob, error)
+	List() ([]batchv1beta1.CronJob, error)
+}
+
+// ScheduledTaskDesirer runs tasks on a cron schedule. Each scheduled task is a
+// CronJob whose job template is the job the TaskDesirer would run for the
+// task, so every run is reported by the task reporter like any other task.
+type ScheduledTaskDesirer struct {
+	logger               lager.Logger
+	cronJobClient        CronJobClient
+	secretsDeleter       SecretsDeleter
+	networkPolicyDeleter NetworkPolicyDeleter
+	taskDesirer          *TaskDesirer
+}
+
+func NewScheduledTaskDesirer(
+	logger lager.Logger,
+	cronJobClient CronJobClient,
+	secretsDeleter SecretsDeleter,
+	networkPolicyDeleter NetworkPolicyDeleter,
+	taskDesirer *TaskDesirer,
+) *ScheduledTaskDesirer {
+	return &ScheduledTaskDesirer{
+		logger:               logger.Session("scheduled-task-desirer"),
+		cronJobClient:        cronJobClient,
+		secretsDeleter:       secretsDeleter,
+		networkPolicyDeleter: networkPolicyDeleter,
+		taskDesirer:          taskDesirer,
+	}
+}
+
+func (d *ScheduledTaskDesirer) Desire(namespace string, task *opi.ScheduledTask, opts ...DesireOption) error {
+	logger := d.logger.Session("desire", lager.Data{"guid": task.GUID, "name": task.Name, "namespace": namespace})
+
+	job, err := d.taskDesirer.prepareTaskJob(logger, namespace, task.Task)
+	if err != nil {
+		return err
+	}
+
+	cronJob := toCronJob(job, task)
+
+	if err = applyOpts(cronJob, opts...); err != nil {
+		logger.Error("failed-to-apply-option", err)
+
+		return err
+	}
+
+	if imageInPrivateRegistry(task.Task) {
+		if _, err = d.taskDesirer.createTaskSecret(namespace, task.Task, opts...); err != nil {
+			logger.Error("failed-to-create-image-pull-secret", err)
+
+			return errors.Wrap(err, "failed to create task secret")
+		}
+	}
+
+	if err = d.taskDesirer.createEnvSecret(namespace, cronJob.Name, task.Task, opts...); err != nil {
+		logger.Error("failed-to-create-env-secret", err)
+
+		return err
+	}
+
+	if _, err = d.cronJobClient.Create(namespace, cronJob); err != nil {
+		logger.Error("failed-to-create-cron-job", err)
+
+		return errors.Wrap(err, "failed to create cron job")
+	}
+
+	if err = d.taskDesirer.createNetworkPolicy(namespace, cronJob.Name, task.Task, opts...); err != nil {
+		logger.Error("failed-to-create-network-policy", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// Update changes the schedule of a scheduled task. The task itself cannot be
+// changed; runs that are already active are not affected.
+func (d *ScheduledTaskDesirer) Update(task *opi.ScheduledTask) error {
+	logger := d.logger.Session("update", lager.Data{"guid": task.GUID})
+
+	cronJob, err := d.getCronJob(task.GUID)
+	if err != nil {
+		logger.Error("failed-to-get-cron-job", err)
+
+		return err
+	}
+
+	setSchedule(&cronJob.Spec, task)
+
+	if _, err = d.cronJobClient.Update(cronJob.Namespace, &cronJob); err != nil {
+		logger.Error("failed-to-update-cron-job", err)
+
+		return errors.Wrap(err, "failed to update cron job")
+	}
+
+	return nil
+}
+
+func (d *ScheduledTaskDesirer) Get(guid string) (*opi.ScheduledTask, error) {
+	cronJob, err := d.getCronJob(guid)
+	if err != nil {
+		return nil, err
+	}
+
+	return toScheduledTask(cronJob), nil
+}
+
+func (d *ScheduledTaskDesirer) List() ([]*opi.ScheduledTask, error) {
+	cronJobs, err := d.cronJobClient.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cron jobs")
+	}
+
+	tasks := make([]*opi.ScheduledTask, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		tasks = append(tasks, toScheduledTask(cronJob))
+	}
+
+	return tasks, nil
+}
+
+// Delete stops running the task and deletes its runs. Scheduled tasks owned
+// by a ScheduledTask resource are left to the garbage collector.
+func (d *ScheduledTaskDesirer) Delete(guid string) error {
+	logger := d.logger.Session("delete", lager.Data{"guid": guid})
+
+	cronJob, err := d.getCronJob(guid)
+	if err != nil {
+		logger.Error("failed-to-get-cron-job", err)
+
+		return err
+	}
+
+	if err = d.deleteSecrets(logger, cronJob); err != nil {
+		return err
+	}
+
+	err = d.networkPolicyDeleter.Delete(cronJob.Namespace, cronJob.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-network-policy", err)
+
+		return errors.Wrap(err, "failed to delete network policy")
+	}
+
+	if len(cronJob.OwnerReferences) != 0 {
+		return nil
+	}
+
+	if err = d.cronJobClient.Delete(cronJob.Namespace, cronJob.Name); err != nil {
+		logger.Error("failed-to-delete-cron-job", err)
+
+		return errors.Wrap(err, "failed to delete cron job")
+	}
+
+	return nil
+}
+
+func (d *ScheduledTaskDesirer) getCronJob(guid string) (batchv1beta1.CronJob, error) {
+	cronJobs, err := d.cronJobClient.GetByGUID(guid)
+	if err != nil {
+		return batchv1beta1.CronJob{}, errors.Wrap(err, "failed to get cron job")
+	}
+
+	switch len(cronJobs) {
+	case 0:
+		return batchv1beta1.CronJob{}, eirini.ErrNotFound
+	case 1:
+		return cronJobs[0], nil
+	default:
+		return batchv1beta1.CronJob{}, fmt.Errorf("multiple cron jobs found for scheduled task GUID %q", guid)
+	}
+}
+
+func (d *ScheduledTaskDesirer) deleteSecrets(logger lager.Logger, cronJob batchv1beta1.CronJob) error {
+	template := cronJob.Spec.JobTemplate
+	dockerSecretNamePrefix := dockerImagePullSecretNamePrefix(
+		template.Annotations[AnnotationAppName],
+		template.Annotations[AnnotationSpaceName],
+		template.Labels[LabelGUID],
+	)
+
+	for _, secret := range template.Spec.Template.Spec.ImagePullSecrets {
+		if !strings.HasPrefix(secret.Name, dockerSecretNamePrefix) {
+			continue
+		}
+
+		if err := d.secretsDeleter.Delete(cronJob.Namespace, secret.Name); err != nil {
+			logger.Error("failed-to-delete-secret", err, lager.Data{"name": secret.Name, "namespace": cronJob.Namespace})
+
+			return errors.Wrap(err, "failed to delete secret")
+		}
+	}
+
+	envSecret := envSecretName(cronJob.Name)
+	if !referencesSecret(template.Spec.Template.Spec.Containers, envSecret) {
+		return nil
+	}
+
+	err := d.secretsDeleter.Delete(cronJob.Namespace, envSecret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-secret", err, lager.Data{"name": envSecret, "namespace": cronJob.Namespace})
+
+		return errors.Wrap(err, "failed to delete env secret")
+	}
+
+	return nil
+}
+
+func toCronJob(job *batch.Job, task *opi.ScheduledTask) *batchv1beta1.CronJob {
+	// The job labels are shared with the pod template, so runs can be told
+	// apart from one-off tasks all the way down to their pods.
+	job.Labels[LabelScheduledTask] = ScheduledTaskTrue
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        job.Name,
+			Namespace:   job.Namespace,
+			Labels:      job.Labels,
+			Annotations: job.Annotations,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      job.Labels,
+					Annotations: job.Annotations,
+				},
+				Spec: job.Spec,
+			},
+		},
+	}
+
+	setSchedule(&cronJob.Spec, task)
+
+	return cronJob
+}
+
+func setSchedule(spec *batchv1beta1.CronJobSpec, task *opi.ScheduledTask) {
+	suspend := task.Suspend
+
+	spec.Schedule = task.Schedule
+	spec.ConcurrencyPolicy = batchv1beta1.ConcurrencyPolicy(task.ConcurrencyPolicy)
+	spec.Suspend = &suspend
+	spec.SuccessfulJobsHistoryLimit = task.SuccessfulRunsHistoryLimit
+	spec.FailedJobsHistoryLimit = task.FailedRunsHistoryLimit
+}
+
+func toScheduledTask(cronJob batchv1beta1.CronJob) *opi.ScheduledTask {
+	template := batch.Job{
+		ObjectMeta: cronJob.Spec.JobTemplate.ObjectMeta,
+		Spec:       cronJob.Spec.JobTemplate.Spec,
+	}
+
+	// Runs have a status of their own, the scheduled task does not
+	task := toTask(template, nil)
+	task.Status = opi.TaskStatus{}
+
+	scheduledTask := &opi.ScheduledTask{
+		Task:                       task,
+		Schedule:                   cronJob.Spec.Schedule,
+		ConcurrencyPolicy:          string(cronJob.Spec.ConcurrencyPolicy),
+		SuccessfulRunsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+		FailedRunsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+		ActiveRuns:                 len(cronJob.Status.Active),
+	}
+
+	if cronJob.Spec.Suspend != nil {
+		scheduledTask.Suspend = *cronJob.Spec.Suspend
+	}
+
+	if cronJob.Status.LastScheduleTime != nil {
+		scheduledTask.LastScheduledAt = cronJob.Status.LastScheduleTime.UnixNano()
+	}
+
+	return scheduledTask
+}
@@ -0,0 +1,530 @@
+package k8s_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/eirini"
+	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/k8sfakes"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("ScheduledTaskDesirer", func() {
+	const taskGUID = "scheduled-task-123"
+
+	var (
+		task                     *opi.ScheduledTask
+		desirer                  *ScheduledTaskDesirer
+		fakeCronJobClient        *k8sfakes.FakeCronJobClient
+		fakeSecretsCreator       *k8sfakes.FakeSecretsCreator
+		fakePolicyClient         *k8sfakes.FakeNetworkPolicyClient
+		fakeSecretsDeleter       *k8sfakes.FakeSecretsDeleter
+		fakeNetworkPolicyDeleter *k8sfakes.FakeNetworkPolicyDeleter
+		historyLimit             int32
+		err                      error
+	)
+
+	BeforeEach(func() {
+		fakeCronJobClient = new(k8sfakes.FakeCronJobClient)
+		fakeSecretsCreator = new(k8sfakes.FakeSecretsCreator)
+		fakePolicyClient = new(k8sfakes.FakeNetworkPolicyClient)
+		fakeSecretsDeleter = new(k8sfakes.FakeSecretsDeleter)
+		fakeNetworkPolicyDeleter = new(k8sfakes.FakeNetworkPolicyDeleter)
+		historyLimit = 2
+
+		task = &opi.ScheduledTask{
+			Task: &opi.Task{
+				GUID:               taskGUID,
+				Name:               "task-name",
+				Image:              "docker.png",
+				Command:            []string{"/lifecycle/launch"},
+				CompletionCallback: "cloud-countroller.io/task/completed",
+				AppName:            "my-app",
+				AppGUID:            "my-app-guid",
+				OrgName:            "my-org",
+				OrgGUID:            "org-id",
+				SpaceName:          "my-space",
+				SpaceGUID:          "space-id",
+				Env:                map[string]string{"FOO": "bar"},
+			},
+			Schedule:                   "*/5 * * * *",
+			ConcurrencyPolicy:          opi.ForbidConcurrentRuns,
+			Suspend:                    true,
+			SuccessfulRunsHistoryLimit: &historyLimit,
+		}
+
+		taskDesirer := NewTaskDesirer(
+			lagertest.NewTestLogger("desiretask"),
+			new(k8sfakes.FakeJobCreatingClient),
+			new(k8sfakes.FakeTaskPodsGetter),
+			fakeSecretsCreator,
+			fakePolicyClient,
+			"service-account",
+			"registry-secret",
+			false,
+			NodePlacements{},
+			SensitiveEnv{"*_PASSWORD"},
+			TaskTimeouts{},
+		)
+
+		desirer = NewScheduledTaskDesirer(
+			lagertest.NewTestLogger("desirescheduledtask"),
+			fakeCronJobClient,
+			fakeSecretsDeleter,
+			fakeNetworkPolicyDeleter,
+			taskDesirer,
+		)
+	})
+
+	Describe("Desire", func() {
+		var (
+			cronJob    *batchv1beta1.CronJob
+			desireOpts []DesireOption
+		)
+
+		BeforeEach(func() {
+			desireOpts = []DesireOption{}
+		})
+
+		JustBeforeEach(func() {
+			err = desirer.Desire("app-namespace", task, desireOpts...)
+		})
+
+		It("creates a cron job for the task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeCronJobClient.CreateCallCount()).To(Equal(1))
+
+			var namespace string
+			namespace, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(cronJob.Namespace).To(Equal("app-namespace"))
+			Expect(cronJob.Name).To(Equal("my-app-my-space-task-name"))
+		})
+
+		It("sets the schedule", func() {
+			_, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+			Expect(cronJob.Spec.Schedule).To(Equal("*/5 * * * *"))
+			Expect(cronJob.Spec.ConcurrencyPolicy).To(Equal(batchv1beta1.ForbidConcurrent))
+			Expect(cronJob.Spec.Suspend).To(PointTo(BeTrue()))
+			Expect(cronJob.Spec.SuccessfulJobsHistoryLimit).To(PointTo(BeNumerically("==", 2)))
+			Expect(cronJob.Spec.FailedJobsHistoryLimit).To(BeNil())
+		})
+
+		It("runs the job the task desirer builds for the task", func() {
+			_, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+			jobSpec := cronJob.Spec.JobTemplate.Spec
+
+			Expect(jobSpec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(jobSpec.Template.Spec.ServiceAccountName).To(Equal("service-account"))
+			Expect(jobSpec.Template.Spec.Containers).To(HaveLen(1))
+			Expect(jobSpec.Template.Spec.Containers[0].Image).To(Equal("docker.png"))
+			Expect(jobSpec.Template.Spec.Containers[0].Command).To(Equal([]string{"/lifecycle/launch"}))
+		})
+
+		It("labels the runs and their pods as runs of a scheduled task", func() {
+			_, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+
+			for _, labels := range []map[string]string{
+				cronJob.Labels,
+				cronJob.Spec.JobTemplate.Labels,
+				cronJob.Spec.JobTemplate.Spec.Template.Labels,
+			} {
+				Expect(labels).To(SatisfyAll(
+					HaveKeyWithValue(LabelGUID, taskGUID),
+					HaveKeyWithValue(LabelSourceType, "TASK"),
+					HaveKeyWithValue(LabelScheduledTask, ScheduledTaskTrue),
+				))
+			}
+		})
+
+		It("lets the runs report their completion to the task callback", func() {
+			_, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+			Expect(cronJob.Spec.JobTemplate.Spec.Template.Annotations).To(SatisfyAll(
+				HaveKeyWithValue(AnnotationCompletionCallback, "cloud-countroller.io/task/completed"),
+				HaveKeyWithValue(AnnotationOpiTaskContainerName, "opi-task"),
+			))
+		})
+
+		When("the task has sensitive env vars", func() {
+			BeforeEach(func() {
+				task.Env["DB_PASSWORD"] = "hunter2"
+			})
+
+			It("stores them in a secret named after the cron job", func() {
+				Expect(fakeSecretsCreator.CreateCallCount()).To(Equal(1))
+				_, secret := fakeSecretsCreator.CreateArgsForCall(0)
+				Expect(secret.Name).To(Equal("my-app-my-space-task-name-env"))
+			})
+		})
+
+		When("the task has egress rules", func() {
+			BeforeEach(func() {
+				task.EgressRules = []opi.EgressRule{{Protocol: "tcp", Destinations: []string{"10.0.0.0-10.0.0.255"}}}
+			})
+
+			It("creates a network policy named after the cron job", func() {
+				Expect(fakePolicyClient.CreateCallCount()).To(Equal(1))
+				_, policy := fakePolicyClient.CreateArgsForCall(0)
+				Expect(policy.Name).To(Equal("my-app-my-space-task-name"))
+			})
+		})
+
+		When("desire options are given", func() {
+			BeforeEach(func() {
+				desireOpts = append(desireOpts, func(resource interface{}) error {
+					resource.(metav1.Object).SetOwnerReferences([]metav1.OwnerReference{{Name: "the-owner"}})
+
+					return nil
+				})
+			})
+
+			It("applies them to the cron job", func() {
+				_, cronJob = fakeCronJobClient.CreateArgsForCall(0)
+				Expect(cronJob.OwnerReferences).To(ConsistOf(metav1.OwnerReference{Name: "the-owner"}))
+			})
+		})
+
+		When("creating the cron job fails", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.CreateReturns(nil, errors.New("create-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("create-failed")))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		var existing batchv1beta1.CronJob
+
+		BeforeEach(func() {
+			suspend := false
+			existing = batchv1beta1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "the-cron-job", Namespace: "app-namespace"},
+				Spec: batchv1beta1.CronJobSpec{
+					Schedule:          "@daily",
+					ConcurrencyPolicy: batchv1beta1.AllowConcurrent,
+					Suspend:           &suspend,
+					JobTemplate: batchv1beta1.JobTemplateSpec{
+						Spec: batch.JobSpec{Parallelism: int32ptr(1)},
+					},
+				},
+			}
+			fakeCronJobClient.GetByGUIDReturns([]batchv1beta1.CronJob{existing}, nil)
+		})
+
+		JustBeforeEach(func() {
+			err = desirer.Update(task)
+		})
+
+		It("updates the schedule of the cron job", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeCronJobClient.GetByGUIDArgsForCall(0)).To(Equal(taskGUID))
+			Expect(fakeCronJobClient.UpdateCallCount()).To(Equal(1))
+
+			namespace, cronJob := fakeCronJobClient.UpdateArgsForCall(0)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(cronJob.Name).To(Equal("the-cron-job"))
+			Expect(cronJob.Spec.Schedule).To(Equal("*/5 * * * *"))
+			Expect(cronJob.Spec.ConcurrencyPolicy).To(Equal(batchv1beta1.ForbidConcurrent))
+			Expect(cronJob.Spec.Suspend).To(PointTo(BeTrue()))
+			Expect(cronJob.Spec.SuccessfulJobsHistoryLimit).To(PointTo(BeNumerically("==", 2)))
+		})
+
+		It("keeps the job template", func() {
+			_, cronJob := fakeCronJobClient.UpdateArgsForCall(0)
+			Expect(cronJob.Spec.JobTemplate).To(Equal(existing.Spec.JobTemplate))
+		})
+
+		When("the scheduled task does not exist", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.GetByGUIDReturns(nil, nil)
+			})
+
+			It("returns a not found error", func() {
+				Expect(errors.Is(err, eirini.ErrNotFound)).To(BeTrue())
+			})
+		})
+
+		When("updating the cron job fails", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.UpdateReturns(nil, errors.New("update-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("update-failed")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		var (
+			scheduledTask    *opi.ScheduledTask
+			lastScheduleTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			lastScheduleTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			suspend := true
+			fakeCronJobClient.GetByGUIDReturns([]batchv1beta1.CronJob{
+				{
+					Spec: batchv1beta1.CronJobSpec{
+						Schedule:                   "@hourly",
+						ConcurrencyPolicy:          batchv1beta1.ReplaceConcurrent,
+						Suspend:                    &suspend,
+						SuccessfulJobsHistoryLimit: &historyLimit,
+						JobTemplate: batchv1beta1.JobTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Labels: map[string]string{
+									LabelGUID: taskGUID,
+									LabelName: "task-name",
+								},
+								Annotations: map[string]string{
+									AnnotationAppName:            "my-app",
+									AnnotationCompletionCallback: "the-callback",
+								},
+							},
+							Spec: batch.JobSpec{
+								Template: corev1.PodTemplateSpec{
+									Spec: corev1.PodSpec{
+										Containers: []corev1.Container{
+											{Name: "opi-task", Image: "docker.png", Command: []string{"run"}},
+										},
+									},
+								},
+							},
+						},
+					},
+					Status: batchv1beta1.CronJobStatus{
+						Active:           []corev1.ObjectReference{{Name: "run-1"}},
+						LastScheduleTime: &lastScheduleTime,
+					},
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			scheduledTask, err = desirer.Get(taskGUID)
+		})
+
+		It("returns the scheduled task", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledTask.GUID).To(Equal(taskGUID))
+			Expect(scheduledTask.Name).To(Equal("task-name"))
+			Expect(scheduledTask.AppName).To(Equal("my-app"))
+			Expect(scheduledTask.CompletionCallback).To(Equal("the-callback"))
+			Expect(scheduledTask.Image).To(Equal("docker.png"))
+			Expect(scheduledTask.Command).To(Equal([]string{"run"}))
+			Expect(scheduledTask.Schedule).To(Equal("@hourly"))
+			Expect(scheduledTask.ConcurrencyPolicy).To(Equal(opi.ReplaceConcurrentRuns))
+			Expect(scheduledTask.Suspend).To(BeTrue())
+			Expect(scheduledTask.SuccessfulRunsHistoryLimit).To(PointTo(BeNumerically("==", 2)))
+		})
+
+		It("returns the state of the schedule", func() {
+			Expect(scheduledTask.ActiveRuns).To(Equal(1))
+			Expect(scheduledTask.LastScheduledAt).To(Equal(lastScheduleTime.UnixNano()))
+		})
+
+		When("there is no such scheduled task", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.GetByGUIDReturns(nil, nil)
+			})
+
+			It("returns a not found error", func() {
+				Expect(errors.Is(err, eirini.ErrNotFound)).To(BeTrue())
+			})
+		})
+
+		When("there are multiple cron jobs for the guid", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.GetByGUIDReturns([]batchv1beta1.CronJob{{}, {}}, nil)
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("multiple cron jobs")))
+			})
+		})
+
+		When("getting the cron job fails", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.GetByGUIDReturns(nil, errors.New("get-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("get-failed")))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		var scheduledTasks []*opi.ScheduledTask
+
+		BeforeEach(func() {
+			fakeCronJobClient.ListReturns([]batchv1beta1.CronJob{
+				{Spec: batchv1beta1.CronJobSpec{
+					Schedule:    "@hourly",
+					JobTemplate: batchv1beta1.JobTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelGUID: "task-1"}}},
+				}},
+				{Spec: batchv1beta1.CronJobSpec{
+					Schedule:    "@daily",
+					JobTemplate: batchv1beta1.JobTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelGUID: "task-2"}}},
+				}},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			scheduledTasks, err = desirer.List()
+		})
+
+		It("returns all scheduled tasks", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledTasks).To(HaveLen(2))
+			Expect(scheduledTasks[0].GUID).To(Equal("task-1"))
+			Expect(scheduledTasks[0].Schedule).To(Equal("@hourly"))
+			Expect(scheduledTasks[1].GUID).To(Equal("task-2"))
+			Expect(scheduledTasks[1].Schedule).To(Equal("@daily"))
+		})
+
+		When("listing the cron jobs fails", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.ListReturns(nil, errors.New("list-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("list-failed")))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		var cronJob batchv1beta1.CronJob
+
+		BeforeEach(func() {
+			cronJob = batchv1beta1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "the-cron-job", Namespace: "app-namespace"},
+				Spec: batchv1beta1.CronJobSpec{
+					JobTemplate: batchv1beta1.JobTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels:      map[string]string{LabelGUID: taskGUID},
+							Annotations: map[string]string{AnnotationAppName: "my-app", AnnotationSpaceName: "my-space"},
+						},
+						Spec: batch.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									ImagePullSecrets: []corev1.LocalObjectReference{
+										{Name: "registry-secret"},
+										{Name: "my-app-my-space-registry-secret-abcd"},
+									},
+									Containers: []corev1.Container{
+										{
+											Name: "opi-task",
+											Env: []corev1.EnvVar{{
+												Name: "DB_PASSWORD",
+												ValueFrom: &corev1.EnvVarSource{
+													SecretKeyRef: &corev1.SecretKeySelector{
+														LocalObjectReference: corev1.LocalObjectReference{Name: "the-cron-job-env"},
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			fakeCronJobClient.GetByGUIDReturns([]batchv1beta1.CronJob{cronJob}, nil)
+			err = desirer.Delete(taskGUID)
+		})
+
+		It("deletes the cron job", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeCronJobClient.DeleteCallCount()).To(Equal(1))
+			namespace, name := fakeCronJobClient.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(name).To(Equal("the-cron-job"))
+		})
+
+		It("deletes the secrets of the task", func() {
+			Expect(fakeSecretsDeleter.DeleteCallCount()).To(Equal(2))
+
+			namespace, name := fakeSecretsDeleter.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(name).To(Equal("my-app-my-space-registry-secret-abcd"))
+
+			namespace, name = fakeSecretsDeleter.DeleteArgsForCall(1)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(name).To(Equal("the-cron-job-env"))
+		})
+
+		It("deletes the network policy of the task", func() {
+			Expect(fakeNetworkPolicyDeleter.DeleteCallCount()).To(Equal(1))
+			namespace, name := fakeNetworkPolicyDeleter.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("app-namespace"))
+			Expect(name).To(Equal("the-cron-job"))
+		})
+
+		When("the task has no network policy", func() {
+			BeforeEach(func() {
+				fakeNetworkPolicyDeleter.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{}, "the-cron-job"))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeCronJobClient.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the cron job is owned by a ScheduledTask resource", func() {
+			BeforeEach(func() {
+				cronJob.OwnerReferences = []metav1.OwnerReference{{Name: "the-scheduled-task"}}
+			})
+
+			It("leaves the cron job to the garbage collector", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeCronJobClient.DeleteCallCount()).To(BeZero())
+			})
+		})
+
+		When("deleting the cron job fails", func() {
+			BeforeEach(func() {
+				fakeCronJobClient.DeleteReturns(errors.New("delete-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("delete-failed")))
+			})
+		})
+
+		When("deleting a secret fails", func() {
+			BeforeEach(func() {
+				fakeSecretsDeleter.DeleteReturns(errors.New("secret-delete-failed"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("secret-delete-failed")))
+			})
+
+			It("does not delete the cron job", func() {
+				Expect(fakeCronJobClient.DeleteCallCount()).To(BeZero())
+			})
+		})
+	})
+})
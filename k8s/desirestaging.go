@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/k8s/utils"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	StagingSourceType = "STG"
+
+	stagingDownloaderContainerName = "opi-staging-downloader"
+	stagingExecutorContainerName   = "opi-staging-executor"
+	stagingUploaderContainerName   = "opi-staging-uploader"
+
+	stagingWorkspaceVolumeName  = "staging-workspace"
+	stagingWorkspaceMountPath   = "/workspace"
+	stagingOutputVolumeName     = "staging-output"
+	stagingOutputMountPath      = "/out"
+	ccUploaderCertsVolumeName   = "cc-uploader-certs"
+	ccUploaderCertsMountPath    = "/etc/cf-api/certs"
+	eiriniClientCertsVolumeName = "eirini-client-certs"
+	eiriniClientCertsMountPath  = "/etc/eirini/certs"
+)
+
+type StagingDesirer struct {
+	logger                            lager.Logger
+	jobClient                         JobCreatingClient
+	serviceAccountName                string
+	registrySecretName                string
+	allowAutomountServiceAccountToken bool
+	nodePlacements                    NodePlacements
+}
+
+func NewStagingDesirer(
+	logger lager.Logger,
+	jobClient JobCreatingClient,
+	serviceAccountName string,
+	registrySecretName string,
+	allowAutomountServiceAccountToken bool,
+	nodePlacements NodePlacements,
+) *StagingDesirer {
+	return &StagingDesirer{
+		logger:                            logger.Session("staging-desirer"),
+		jobClient:                         jobClient,
+		serviceAccountName:                serviceAccountName,
+		registrySecretName:                registrySecretName,
+		allowAutomountServiceAccountToken: allowAutomountServiceAccountToken,
+		nodePlacements:                    nodePlacements,
+	}
+}
+
+func (d *StagingDesirer) DesireStaging(namespace string, task *opi.StagingTask) error {
+	logger := d.logger.Session("desire-staging", lager.Data{"guid": task.GUID, "namespace": namespace})
+
+	job := d.toStagingJob(task)
+
+	if _, err := d.jobClient.Create(namespace, job); err != nil {
+		logger.Error("failed-to-create-job", err)
+
+		return errors.Wrap(err, "failed to create staging job")
+	}
+
+	return nil
+}
+
+func (d *StagingDesirer) toStagingJob(task *opi.StagingTask) *batch.Job {
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+	}
+
+	envs := MapToEnvVar(task.Env)
+
+	workspaceMount := corev1.VolumeMount{Name: stagingWorkspaceVolumeName, MountPath: stagingWorkspaceMountPath}
+	outputMount := corev1.VolumeMount{Name: stagingOutputVolumeName, MountPath: stagingOutputMountPath}
+	ccUploaderCertsMount := corev1.VolumeMount{Name: ccUploaderCertsVolumeName, MountPath: ccUploaderCertsMountPath, ReadOnly: true}
+	eiriniClientCertsMount := corev1.VolumeMount{Name: eiriniClientCertsVolumeName, MountPath: eiriniClientCertsMountPath, ReadOnly: true}
+
+	initContainers := []corev1.Container{
+		{
+			Name:            stagingDownloaderContainerName,
+			Image:           task.DownloaderImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Env:             envs,
+			SecurityContext: securityContext,
+			VolumeMounts:    []corev1.VolumeMount{workspaceMount, ccUploaderCertsMount},
+		},
+		{
+			Name:            stagingExecutorContainerName,
+			Image:           task.ExecutorImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Env:             envs,
+			SecurityContext: securityContext,
+			Resources:       getContainerResources(task.CPUWeight, task.MemoryMB, task.DiskMB),
+			VolumeMounts:    []corev1.VolumeMount{workspaceMount, outputMount},
+		},
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            stagingUploaderContainerName,
+			Image:           task.UploaderImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Env:             envs,
+			SecurityContext: securityContext,
+			VolumeMounts:    []corev1.VolumeMount{outputMount, ccUploaderCertsMount, eiriniClientCertsMount},
+		},
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name:         stagingWorkspaceVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name:         stagingOutputVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name: ccUploaderCertsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: eirini.CCUploaderSecretName},
+			},
+		},
+		{
+			Name: eiriniClientCertsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: eirini.EiriniClientSecretName},
+			},
+		},
+	}
+
+	job := &batch.Job{
+		Spec: batch.JobSpec{
+			Parallelism:  int32ptr(parallelism),
+			Completions:  int32ptr(completions),
+			BackoffLimit: int32ptr(0),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: d.serviceAccountName,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: &runAsNonRoot,
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: d.registrySecretName},
+					},
+					InitContainers: initContainers,
+					Containers:     containers,
+					Volumes:        volumes,
+				},
+			},
+		},
+	}
+
+	if !d.allowAutomountServiceAccountToken {
+		automountServiceAccountToken := false
+		job.Spec.Template.Spec.AutomountServiceAccountToken = &automountServiceAccountToken
+	}
+
+	name := fmt.Sprintf("%s-%s", task.AppName, task.SpaceName)
+	sanitizedName := fmt.Sprintf("%s-staging", utils.SanitizeName(name, task.GUID))
+	job.Name = utils.SanitizeNameWithMaxStringLen(sanitizedName, task.GUID, 50)
+
+	job.Labels = map[string]string{
+		LabelGUID:       task.GUID,
+		LabelAppGUID:    task.AppGUID,
+		LabelSourceType: StagingSourceType,
+	}
+
+	job.Annotations = map[string]string{
+		AnnotationAppName:              task.AppName,
+		AnnotationAppID:                task.AppGUID,
+		AnnotationOrgName:              task.OrgName,
+		AnnotationOrgGUID:              task.OrgGUID,
+		AnnotationSpaceName:            task.SpaceName,
+		AnnotationSpaceGUID:            task.SpaceGUID,
+		AnnotationCompletionCallback:   task.CompletionCallback,
+		corev1.SeccompPodAnnotationKey: corev1.SeccompProfileRuntimeDefault,
+	}
+
+	job.Spec.Template.Labels = job.Labels
+	job.Spec.Template.Annotations = job.Annotations
+
+	d.nodePlacements.Apply(d.logger, &job.Spec.Template.Spec, task.PlacementTags)
+
+	return job
+}
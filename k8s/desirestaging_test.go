@@ -0,0 +1,235 @@
+package k8s_test
+
+import (
+	"code.cloudfoundry.org/eirini"
+	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/k8sfakes"
+	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("StagingDesirer", func() {
+	var (
+		stagingTask    *opi.StagingTask
+		desirer        *StagingDesirer
+		fakeJobClient  *k8sfakes.FakeJobCreatingClient
+		job            *batch.Job
+		jobNamespace   string
+		desireErr      error
+		nodePlacements NodePlacements
+	)
+
+	BeforeEach(func() {
+		fakeJobClient = new(k8sfakes.FakeJobCreatingClient)
+		nodePlacements = NodePlacements{
+			"isolated": {NodeSelector: map[string]string{"segment": "isolated"}},
+		}
+		stagingTask = &opi.StagingTask{
+			Task: &opi.Task{
+				GUID:               "staging-guid",
+				AppName:            "my-app",
+				AppGUID:            "my-app-guid",
+				OrgName:            "my-org",
+				OrgGUID:            "org-id",
+				SpaceName:          "my-space",
+				SpaceGUID:          "space-id",
+				CompletionCallback: "cloud-controller.io/staging/completed",
+				Env: map[string]string{
+					eirini.EnvDownloadURL: "example.com/download",
+					eirini.EnvStagingGUID: "staging-guid",
+				},
+				MemoryMB:  1,
+				CPUWeight: 2,
+				DiskMB:    3,
+			},
+			DownloaderImage: "eirini/downloader",
+			ExecutorImage:   "eirini/executor",
+			UploaderImage:   "eirini/uploader",
+		}
+
+		desirer = NewStagingDesirer(
+			lagertest.NewTestLogger("staging-desirer"),
+			fakeJobClient,
+			"service-account",
+			"registry-secret",
+			false,
+			nodePlacements,
+		)
+	})
+
+	JustBeforeEach(func() {
+		desireErr = desirer.DesireStaging("app-namespace", stagingTask)
+	})
+
+	It("should succeed", func() {
+		Expect(desireErr).NotTo(HaveOccurred())
+	})
+
+	It("should create a job in the given namespace", func() {
+		Expect(fakeJobClient.CreateCallCount()).To(Equal(1))
+		jobNamespace, job = fakeJobClient.CreateArgsForCall(0)
+		Expect(jobNamespace).To(Equal("app-namespace"))
+	})
+
+	Context("the created job", func() {
+		JustBeforeEach(func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+		})
+
+		It("should have a sanitized name with a staging suffix", func() {
+			Expect(job.Name).To(Equal("my-app-my-space-staging"))
+		})
+
+		It("should be labelled as a staging job", func() {
+			Expect(job.Labels).To(Equal(map[string]string{
+				LabelGUID:       "staging-guid",
+				LabelAppGUID:    "my-app-guid",
+				LabelSourceType: StagingSourceType,
+			}))
+			Expect(job.Spec.Template.Labels).To(Equal(job.Labels))
+		})
+
+		It("should store the completion callback as an annotation", func() {
+			Expect(job.Annotations).To(HaveKeyWithValue(AnnotationCompletionCallback, "cloud-controller.io/staging/completed"))
+			Expect(job.Annotations).To(HaveKeyWithValue(AnnotationAppID, "my-app-guid"))
+			Expect(job.Annotations).To(HaveKeyWithValue(corev1.SeccompPodAnnotationKey, corev1.SeccompProfileRuntimeDefault))
+		})
+
+		It("should never restart and never retry", func() {
+			Expect(job.Spec.BackoffLimit).To(PointTo(Equal(int32(0))))
+			Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		})
+
+		It("should run as non root with the configured service account", func() {
+			Expect(job.Spec.Template.Spec.SecurityContext.RunAsNonRoot).To(PointTo(BeTrue()))
+			Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal("service-account"))
+			Expect(job.Spec.Template.Spec.AutomountServiceAccountToken).To(PointTo(BeFalse()))
+			Expect(job.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "registry-secret"}))
+		})
+
+		It("should download and build the app in init containers", func() {
+			initContainers := job.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(2))
+
+			Expect(initContainers[0].Name).To(Equal("opi-staging-downloader"))
+			Expect(initContainers[0].Image).To(Equal("eirini/downloader"))
+			Expect(initContainers[1].Name).To(Equal("opi-staging-executor"))
+			Expect(initContainers[1].Image).To(Equal("eirini/executor"))
+		})
+
+		It("should upload the droplet in the main container", func() {
+			containers := job.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(1))
+			Expect(containers[0].Name).To(Equal("opi-staging-uploader"))
+			Expect(containers[0].Image).To(Equal("eirini/uploader"))
+		})
+
+		It("should pass the staging env to all containers", func() {
+			allContainers := append(job.Spec.Template.Spec.InitContainers, job.Spec.Template.Spec.Containers...)
+			for _, c := range allContainers {
+				Expect(c.Env).To(ContainElements(
+					corev1.EnvVar{Name: eirini.EnvDownloadURL, Value: "example.com/download"},
+					corev1.EnvVar{Name: eirini.EnvStagingGUID, Value: "staging-guid"},
+				))
+			}
+		})
+
+		It("should apply the staging resources to the executor", func() {
+			executor := job.Spec.Template.Spec.InitContainers[1]
+			Expect(executor.Resources).To(Equal(corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory:           *resource.NewScaledQuantity(1, resource.Mega),
+					corev1.ResourceEphemeralStorage: *resource.NewScaledQuantity(3, resource.Mega),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: *resource.NewScaledQuantity(1, resource.Mega),
+					corev1.ResourceCPU:    *resource.NewScaledQuantity(20, resource.Milli),
+				},
+			}))
+		})
+
+		It("should share the workspace and output volumes between containers", func() {
+			volumes := job.Spec.Template.Spec.Volumes
+			Expect(volumes).To(ContainElements(
+				MatchFields(IgnoreExtras, Fields{"Name": Equal("staging-workspace")}),
+				MatchFields(IgnoreExtras, Fields{"Name": Equal("staging-output")}),
+				MatchFields(IgnoreExtras, Fields{
+					"Name": Equal("cc-uploader-certs"),
+					"VolumeSource": MatchFields(IgnoreExtras, Fields{
+						"Secret": PointTo(MatchFields(IgnoreExtras, Fields{"SecretName": Equal(eirini.CCUploaderSecretName)})),
+					}),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Name": Equal("eirini-client-certs"),
+					"VolumeSource": MatchFields(IgnoreExtras, Fields{
+						"Secret": PointTo(MatchFields(IgnoreExtras, Fields{"SecretName": Equal(eirini.EiriniClientSecretName)})),
+					}),
+				}),
+			))
+
+			uploader := job.Spec.Template.Spec.Containers[0]
+			Expect(uploader.VolumeMounts).To(ContainElement(
+				corev1.VolumeMount{Name: "staging-output", MountPath: "/out"},
+			))
+		})
+	})
+
+	When("the app runs in an isolation segment", func() {
+		BeforeEach(func() {
+			stagingTask.PlacementTags = []string{"isolated"}
+		})
+
+		It("should stage on the nodes of the segment", func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+			Expect(job.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"segment": "isolated"}))
+		})
+
+		When("the segment has no configured placement", func() {
+			BeforeEach(func() {
+				stagingTask.PlacementTags = []string{"unknown"}
+			})
+
+			It("creates the job without constraining its nodes", func() {
+				Expect(desireErr).NotTo(HaveOccurred())
+				Expect(fakeJobClient.CreateCallCount()).To(Equal(1))
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+			})
+		})
+	})
+
+	When("automounting the service account token is allowed", func() {
+		BeforeEach(func() {
+			desirer = NewStagingDesirer(
+				lagertest.NewTestLogger("staging-desirer"),
+				fakeJobClient,
+				"service-account",
+				"registry-secret",
+				true,
+				nodePlacements,
+			)
+		})
+
+		It("should not set automountServiceAccountToken", func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+			Expect(job.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+		})
+	})
+
+	When("creating the job fails", func() {
+		BeforeEach(func() {
+			fakeJobClient.CreateReturns(nil, errors.New("boom"))
+		})
+
+		It("should return an error", func() {
+			Expect(desireErr).To(MatchError(ContainSubstring("failed to create staging job")))
+		})
+	})
+})
@@ -11,6 +11,8 @@ import (
 	"github.com/pkg/errors"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -21,14 +23,21 @@ const (
 )
 
 //counterfeiter:generate . JobCreatingClient
+//counterfeiter:generate . TaskPodsGetter
 //counterfeiter:generate . SecretsCreator
 
 type JobCreatingClient interface {
 	Create(namespace string, job *batch.Job) (*batch.Job, error)
+	Delete(namespace string, name string) error
 	GetByGUID(guid string, includeCompleted bool) ([]batch.Job, error)
 	List(includeCompleted bool) ([]batch.Job, error)
 }
 
+type TaskPodsGetter interface {
+	GetAll() ([]corev1.Pod, error)
+	GetByTaskGUID(guid string) ([]corev1.Pod, error)
+}
+
 type SecretsCreator interface {
 	Create(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
 }
@@ -41,45 +50,91 @@ type KeyPath struct {
 type TaskDesirer struct {
 	logger                            lager.Logger
 	jobClient                         JobCreatingClient
+	podsGetter                        TaskPodsGetter
 	secretsCreator                    SecretsCreator
+	networkPolicies                   NetworkPolicyClient
 	serviceAccountName                string
 	registrySecretName                string
 	allowAutomountServiceAccountToken bool
+	nodePlacements                    NodePlacements
+	sensitiveEnv                      SensitiveEnv
+	timeouts                          TaskTimeouts
+}
+
+// TaskTimeouts limit how long tasks are allowed to run. Tasks that do not ask
+// for a timeout get the default one, and no task gets more than the maximum.
+// Zero means no limit.
+type TaskTimeouts struct {
+	DefaultSeconds int64
+	MaxSeconds     int64
+}
+
+func (t TaskTimeouts) forTask(task *opi.Task) int64 {
+	timeout := task.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = t.DefaultSeconds
+	}
+
+	if t.MaxSeconds > 0 && (timeout <= 0 || timeout > t.MaxSeconds) {
+		timeout = t.MaxSeconds
+	}
+
+	return timeout
 }
 
 func NewTaskDesirer(
 	logger lager.Logger,
 	jobClient JobCreatingClient,
+	podsGetter TaskPodsGetter,
 	secretsCreator SecretsCreator,
+	networkPolicies NetworkPolicyClient,
 	serviceAccountName string,
 	registrySecretName string,
 	allowAutomountServiceAccountToken bool,
+	nodePlacements NodePlacements,
+	sensitiveEnv SensitiveEnv,
+	timeouts TaskTimeouts,
 ) *TaskDesirer {
 	return &TaskDesirer{
 		logger:                            logger.Session("task-desirer"),
 		jobClient:                         jobClient,
+		podsGetter:                        podsGetter,
 		secretsCreator:                    secretsCreator,
+		networkPolicies:                   networkPolicies,
 		serviceAccountName:                serviceAccountName,
 		registrySecretName:                registrySecretName,
 		allowAutomountServiceAccountToken: allowAutomountServiceAccountToken,
+		nodePlacements:                    nodePlacements,
+		sensitiveEnv:                      sensitiveEnv,
+		timeouts:                          timeouts,
 	}
 }
 
 func NewTaskDesirerWithEiriniInstance(
 	logger lager.Logger,
 	jobClient JobCreatingClient,
+	podsGetter TaskPodsGetter,
 	secretsCreator SecretsCreator,
+	networkPolicies NetworkPolicyClient,
 	serviceAccountName string,
 	registrySecretName string,
 	allowAutomountServiceAccountToken bool,
+	nodePlacements NodePlacements,
+	sensitiveEnv SensitiveEnv,
+	timeouts TaskTimeouts,
 ) *TaskDesirer {
 	desirer := NewTaskDesirer(
 		logger,
 		jobClient,
+		podsGetter,
 		secretsCreator,
+		networkPolicies,
 		serviceAccountName,
 		registrySecretName,
 		allowAutomountServiceAccountToken,
+		nodePlacements,
+		sensitiveEnv,
+		timeouts,
 	)
 
 	return desirer
@@ -88,18 +143,11 @@ func NewTaskDesirerWithEiriniInstance(
 func (d *TaskDesirer) Desire(namespace string, task *opi.Task, opts ...DesireOption) error {
 	logger := d.logger.Session("desire", lager.Data{"guid": task.GUID, "name": task.Name, "namespace": namespace})
 
-	job := d.toTaskJob(task)
-
-	if imageInPrivateRegistry(task) {
-		if err := d.addImagePullSecret(namespace, task, job); err != nil {
-			logger.Error("failed-to-add-image-pull-secret", err)
-
-			return err
-		}
+	job, err := d.prepareTaskJob(logger, namespace, task)
+	if err != nil {
+		return err
 	}
 
-	job.Namespace = namespace
-
 	for _, opt := range opts {
 		err := opt(job)
 		if err != nil {
@@ -109,48 +157,227 @@ func (d *TaskDesirer) Desire(namespace string, task *opi.Task, opts ...DesireOpt
 		}
 	}
 
-	_, err := d.jobClient.Create(namespace, job)
+	createdJob, err := d.jobClient.Create(namespace, job)
 	if err != nil {
 		logger.Error("failed-to-create-job", err)
 
 		return errors.Wrap(err, "failed to create job")
 	}
 
+	// The secrets and the network policy are owned by the job, so that
+	// deleting the job when creating them fails cleans up the ones created,
+	// and the task can be desired again.
+	if err := d.createJobResources(logger, namespace, createdJob, task, opts...); err != nil {
+		if deleteErr := d.jobClient.Delete(namespace, createdJob.Name); deleteErr != nil {
+			logger.Error("failed-to-delete-job", deleteErr)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (d *TaskDesirer) createJobResources(logger lager.Logger, namespace string, job *batch.Job, task *opi.Task, opts ...DesireOption) error {
+	opts = append([]DesireOption{withOwner(job, batch.SchemeGroupVersion.WithKind("Job"))}, opts...)
+
+	if imageInPrivateRegistry(task) {
+		if _, err := d.createTaskSecret(namespace, task, opts...); err != nil {
+			logger.Error("failed-to-create-image-pull-secret", err)
+
+			return errors.Wrap(err, "failed to create task secret")
+		}
+	}
+
+	if err := d.createEnvSecret(namespace, job.Name, task, opts...); err != nil {
+		logger.Error("failed-to-create-env-secret", err)
+
+		return err
+	}
+
+	if err := d.createNetworkPolicy(namespace, job.Name, task, opts...); err != nil {
+		logger.Error("failed-to-create-network-policy", err)
+
+		return err
+	}
+
 	return nil
 }
 
+// prepareTaskJob builds the job running the task. The image pull secret it
+// needs, if any, is left to be created.
+func (d *TaskDesirer) prepareTaskJob(logger lager.Logger, namespace string, task *opi.Task) (*batch.Job, error) {
+	job, err := d.toTaskJob(task)
+	if err != nil {
+		logger.Error("failed-to-create-job-spec", err)
+
+		return nil, err
+	}
+
+	if imageInPrivateRegistry(task) {
+		spec := &job.Spec.Template.Spec
+		spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{
+			Name: dockerImagePullSecretName(task),
+		})
+	}
+
+	job.Namespace = namespace
+
+	return job, nil
+}
+
+// withOwner makes the resource depend on the owner without controlling it,
+// which leaves the resource free to be controlled by a custom resource.
+func withOwner(owner metav1.Object, gvk schema.GroupVersionKind) DesireOption {
+	return func(resource interface{}) error {
+		obj := resource.(metav1.Object)
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), metav1.OwnerReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+		}))
+
+		return nil
+	}
+}
+
+func (d *TaskDesirer) createNetworkPolicy(namespace, jobName string, task *opi.Task, opts ...DesireOption) error {
+	podLabels := map[string]string{
+		LabelGUID:       task.GUID,
+		LabelSourceType: taskSourceType,
+	}
+
+	networkPolicy, err := ToNetworkPolicy(jobName, podLabels, task.EgressRules)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate network policy")
+	}
+
+	if networkPolicy == nil {
+		return nil
+	}
+
+	networkPolicy.Namespace = namespace
+
+	if err = applyOpts(networkPolicy, opts...); err != nil {
+		return err
+	}
+
+	_, err = d.networkPolicies.Create(namespace, networkPolicy)
+
+	return errors.Wrap(err, "failed to create network policy")
+}
+
+// Get returns the task with the given GUID along with its status. Tasks that
+// have completed are returned too, until their job is deleted.
 func (d *TaskDesirer) Get(taskGUID string) (*opi.Task, error) {
-	jobs, err := d.jobClient.GetByGUID(taskGUID, false)
+	jobs, err := d.jobClient.GetByGUID(taskGUID, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get job")
 	}
 
-	switch len(jobs) {
-	case 0:
+	if len(jobs) == 0 {
 		return nil, eirini.ErrNotFound
-	case 1:
-		return toTask(jobs[0]), nil
-	default:
-		return nil, fmt.Errorf("multiple jobs found for task GUID %q", taskGUID)
 	}
+
+	job, err := LatestTaskAttempt(jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := d.podsGetter.GetByTaskGUID(taskGUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get task pods")
+	}
+
+	return toTask(job, pods), nil
+}
+
+func (d *TaskDesirer) GetStatus(taskGUID string) (opi.TaskStatus, error) {
+	jobs, err := d.jobClient.GetByGUID(taskGUID, true)
+	if err != nil {
+		return opi.TaskStatus{}, errors.Wrap(err, "failed to get job")
+	}
+
+	if len(jobs) == 0 {
+		return opi.TaskStatus{}, eirini.ErrNotFound
+	}
+
+	job, err := LatestTaskAttempt(jobs)
+	if err != nil {
+		return opi.TaskStatus{}, err
+	}
+
+	pods, err := d.podsGetter.GetByTaskGUID(taskGUID)
+	if err != nil {
+		return opi.TaskStatus{}, errors.Wrap(err, "failed to get task pods")
+	}
+
+	return getTaskStatus(job, pods), nil
 }
 
+// List returns all tasks, including the ones that have completed.
 func (d *TaskDesirer) List() ([]*opi.Task, error) {
-	jobs, err := d.jobClient.List(false)
+	jobs, err := d.jobClient.List(true)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list jobs")
 	}
 
+	pods, err := d.podsGetter.GetAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list task pods")
+	}
+
+	podsByGUID := map[string][]corev1.Pod{}
+
+	for _, pod := range pods {
+		if pod.Labels[LabelSourceType] != taskSourceType {
+			continue
+		}
+
+		guid := pod.Labels[LabelGUID]
+		podsByGUID[guid] = append(podsByGUID[guid], pod)
+	}
+
 	tasks := make([]*opi.Task, 0, len(jobs))
-	for _, job := range jobs {
-		tasks = append(tasks, toTask(job))
+	for _, job := range latestTaskAttempts(jobs) {
+		if job.Labels[LabelScheduledTask] == ScheduledTaskTrue {
+			continue
+		}
+
+		tasks = append(tasks, toTask(job, podsByGUID[job.Labels[LabelGUID]]))
 	}
 
 	return tasks, nil
 }
 
-func (d *TaskDesirer) toTaskJob(task *opi.Task) *batch.Job {
+// latestTaskAttempts leaves out the jobs of the attempts of a task that were
+// followed by another attempt.
+func latestTaskAttempts(jobs []batch.Job) []batch.Job {
+	latest := map[string]int{}
+
+	for i := range jobs {
+		if attempt := TaskAttempt(&jobs[i]); attempt > latest[TaskRun(jobs[i])] {
+			latest[TaskRun(jobs[i])] = attempt
+		}
+	}
+
+	latestJobs := []batch.Job{}
+
+	for i := range jobs {
+		if TaskAttempt(&jobs[i]) == latest[TaskRun(jobs[i])] {
+			latestJobs = append(latestJobs, jobs[i])
+		}
+	}
+
+	return latestJobs
+}
+
+func (d *TaskDesirer) toTaskJob(task *opi.Task) (*batch.Job, error) {
 	job := d.toJob(task)
+
 	job.Spec.Template.Spec.ServiceAccountName = d.serviceAccountName
 	job.Labels[LabelSourceType] = taskSourceType
 	job.Labels[LabelName] = task.Name
@@ -159,7 +386,22 @@ func (d *TaskDesirer) toTaskJob(task *opi.Task) *batch.Job {
 	job.Spec.Template.Annotations[AnnotationOpiTaskContainerName] = opiTaskContainerName
 	job.Spec.Template.Annotations[AnnotationCompletionCallback] = task.CompletionCallback
 
-	envs := getEnvs(task)
+	// The deadline is set on the pod rather than the job: the job controller
+	// deletes the pods of jobs past their deadline, and the task reporter
+	// needs the failed pod to report it.
+	if timeout := d.timeouts.forTask(task); timeout > 0 {
+		job.Spec.Template.Spec.ActiveDeadlineSeconds = &timeout
+	}
+
+	if err := applyRetryPolicy(job, task.RetryPolicy); err != nil {
+		return nil, errors.Wrap(err, "failed to apply retry policy")
+	}
+
+	envs, err := getEnvs(task, d.sensitiveEnv, envSecretName(job.Name))
+	if err != nil {
+		return nil, err
+	}
+
 	containers := []corev1.Container{
 		{
 			Name:            opiTaskContainerName,
@@ -167,24 +409,70 @@ func (d *TaskDesirer) toTaskJob(task *opi.Task) *batch.Job {
 			ImagePullPolicy: corev1.PullAlways,
 			Env:             envs,
 			Command:         task.Command,
+			Resources:       getContainerResources(task.CPUWeight, task.MemoryMB, task.DiskMB),
 		},
 	}
 
+	// The kubelet reads the result file into the termination message of the
+	// container (truncated to 4KB), which is where the task reporter picks
+	// it up from.
+	if task.ResultFile != "" {
+		containers[0].TerminationMessagePath = task.ResultFile
+		containers[0].TerminationMessagePolicy = corev1.TerminationMessageReadFile
+	}
+
 	job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
 		{
 			Name: d.registrySecretName,
 		},
 	}
 
+	if task.PrivateRegistry != nil && task.PrivateRegistry.SecretName != "" {
+		job.Spec.Template.Spec.ImagePullSecrets = append(job.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{
+			Name: task.PrivateRegistry.SecretName,
+		})
+	}
+
+	if task.DropletDownload != nil {
+		downloader, volumes, volumeMount := getDropletDownloadSpecs(
+			task.DropletDownload,
+			getContainerResources(task.CPUWeight, task.MemoryMB, task.DiskMB),
+		)
+		containers[0].VolumeMounts = append(containers[0].VolumeMounts, volumeMount)
+		job.Spec.Template.Spec.InitContainers = []corev1.Container{downloader}
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, volumes...)
+	}
+
 	job.Spec.Template.Spec.Containers = containers
 
-	return job
+	return job, nil
 }
 
-func (d *TaskDesirer) createTaskSecret(namespace string, task *opi.Task) (*corev1.Secret, error) {
+func (d *TaskDesirer) createEnvSecret(namespace, jobName string, task *opi.Task, opts ...DesireOption) error {
+	data := map[string][]byte{}
+	d.sensitiveEnv.Collect(withoutVCAPApplication(task.Env), "", data)
+
+	secret := toEnvSecret(envSecretName(jobName), data)
+	if secret == nil {
+		return nil
+	}
+
+	secret.Namespace = namespace
+
+	if err := applyOpts(secret, opts...); err != nil {
+		return err
+	}
+
+	_, err := d.secretsCreator.Create(namespace, secret)
+
+	return errors.Wrap(err, "failed to create env secret")
+}
+
+func (d *TaskDesirer) createTaskSecret(namespace string, task *opi.Task, opts ...DesireOption) (*corev1.Secret, error) {
 	secret := &corev1.Secret{}
 
-	secret.GenerateName = dockerImagePullSecretNamePrefix(task.AppName, task.SpaceName, task.GUID)
+	secret.Name = dockerImagePullSecretName(task)
+	secret.Namespace = namespace
 	secret.Type = corev1.SecretTypeDockerConfigJson
 
 	dockerConfig := dockerutils.NewDockerConfig(
@@ -202,11 +490,20 @@ func (d *TaskDesirer) createTaskSecret(namespace string, task *opi.Task) (*corev
 		dockerutils.DockerConfigKey: dockerConfigJSON,
 	}
 
+	if err := applyOpts(secret, opts...); err != nil {
+		return nil, err
+	}
+
 	return d.secretsCreator.Create(namespace, secret)
 }
 
-func getEnvs(task *opi.Task) []corev1.EnvVar {
-	envs := MapToEnvVar(task.Env)
+func getEnvs(task *opi.Task, sensitiveEnv SensitiveEnv, secretName string) ([]corev1.EnvVar, error) {
+	vcapApplication, err := taskVCAPApplication(task)
+	if err != nil {
+		return nil, err
+	}
+
+	envs := sensitiveEnv.EnvVars(withoutVCAPApplication(task.Env), secretName, "")
 	fieldEnvs := []corev1.EnvVar{
 		{
 			Name: eirini.EnvPodName,
@@ -246,8 +543,9 @@ func getEnvs(task *opi.Task) []corev1.EnvVar {
 	}
 
 	envs = append(envs, fieldEnvs...)
+	envs = append(envs, vcapApplication)
 
-	return envs
+	return envs, nil
 }
 
 func (d *TaskDesirer) toJob(task *opi.Task) *batch.Job {
@@ -301,29 +599,44 @@ func (d *TaskDesirer) toJob(task *opi.Task) *batch.Job {
 	job.Spec.Template.Labels = job.Labels
 	job.Spec.Template.Annotations = job.Annotations
 
+	d.nodePlacements.Apply(d.logger, &job.Spec.Template.Spec, task.PlacementTags)
+
 	return job
 }
 
-func (d *TaskDesirer) addImagePullSecret(namespace string, task *opi.Task, job *batch.Job) error {
-	createdSecret, err := d.createTaskSecret(namespace, task)
-	if err != nil {
-		return errors.Wrap(err, "failed to create task secret")
-	}
-
-	spec := &job.Spec.Template.Spec
-	spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{
-		Name: createdSecret.Name,
-	})
-
-	return nil
+// dockerImagePullSecretName names the image pull secret after the task, so
+// that desiring a task never leaves more than one behind.
+func dockerImagePullSecretName(task *opi.Task) string {
+	return dockerImagePullSecretNamePrefix(task.AppName, task.SpaceName, task.GUID) + task.GUID
 }
 
 func imageInPrivateRegistry(task *opi.Task) bool {
-	return task.PrivateRegistry != nil && task.PrivateRegistry.Username != "" && task.PrivateRegistry.Password != ""
+	return task.PrivateRegistry != nil &&
+		task.PrivateRegistry.SecretName == "" &&
+		task.PrivateRegistry.Username != "" &&
+		task.PrivateRegistry.Password != ""
 }
 
-func toTask(job batch.Job) *opi.Task {
-	return &opi.Task{
-		GUID: job.Labels[LabelGUID],
+func toTask(job batch.Job, pods []corev1.Pod) *opi.Task {
+	task := &opi.Task{
+		GUID:               job.Labels[LabelGUID],
+		Name:               job.Labels[LabelName],
+		CompletionCallback: job.Annotations[AnnotationCompletionCallback],
+		AppName:            job.Annotations[AnnotationAppName],
+		AppGUID:            job.Annotations[AnnotationAppID],
+		OrgName:            job.Annotations[AnnotationOrgName],
+		OrgGUID:            job.Annotations[AnnotationOrgGUID],
+		SpaceName:          job.Annotations[AnnotationSpaceName],
+		SpaceGUID:          job.Annotations[AnnotationSpaceGUID],
+		Status:             getTaskStatus(job, pods),
 	}
+
+	for _, container := range job.Spec.Template.Spec.Containers {
+		if container.Name == opiTaskContainerName {
+			task.Image = container.Image
+			task.Command = container.Command
+		}
+	}
+
+	return task
 }
@@ -3,6 +3,7 @@ package k8s_test
 import (
 	"encoding/base64"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/eirini"
 	. "code.cloudfoundry.org/eirini/k8s"
@@ -15,6 +16,8 @@ import (
 	"github.com/pkg/errors"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,10 +32,15 @@ var _ = Describe("TaskDesirer", func() {
 		task               *opi.Task
 		desirer            *TaskDesirer
 		fakeJobClient      *k8sfakes.FakeJobCreatingClient
+		fakePodsGetter     *k8sfakes.FakeTaskPodsGetter
 		fakeSecretsCreator *k8sfakes.FakeSecretsCreator
+		fakePolicyClient   *k8sfakes.FakeNetworkPolicyClient
 		job                *batch.Job
 		jobNamespace       string
 		desireOpts         []DesireOption
+		nodePlacements     NodePlacements
+		sensitiveEnv       SensitiveEnv
+		timeouts           TaskTimeouts
 	)
 
 	assertGeneralSpec := func(job *batch.Job) {
@@ -63,8 +71,24 @@ var _ = Describe("TaskDesirer", func() {
 
 	BeforeEach(func() {
 		fakeJobClient = new(k8sfakes.FakeJobCreatingClient)
+		fakeJobClient.CreateStub = func(_ string, job *batch.Job) (*batch.Job, error) {
+			createdJob := job.DeepCopy()
+			createdJob.UID = "job-uid"
+
+			return createdJob, nil
+		}
+		fakePodsGetter = new(k8sfakes.FakeTaskPodsGetter)
 		fakeSecretsCreator = new(k8sfakes.FakeSecretsCreator)
+		fakePolicyClient = new(k8sfakes.FakeNetworkPolicyClient)
 		desireOpts = []DesireOption{}
+		sensitiveEnv = SensitiveEnv{"*_PASSWORD"}
+		timeouts = TaskTimeouts{}
+		nodePlacements = NodePlacements{
+			"isolated": {
+				NodeSelector: map[string]string{"segment": "isolated"},
+				Tolerations:  []eirini.Toleration{{Key: "segment", Operator: "Equal", Value: "isolated", Effect: "NoSchedule"}},
+			},
+		}
 		task = &opi.Task{
 			Image:              Image,
 			CompletionCallback: "cloud-countroller.io/task/completed",
@@ -89,10 +113,15 @@ var _ = Describe("TaskDesirer", func() {
 		desirer = NewTaskDesirer(
 			lagertest.NewTestLogger("desiretask"),
 			fakeJobClient,
+			fakePodsGetter,
 			fakeSecretsCreator,
+			fakePolicyClient,
 			"service-account",
 			"registry-secret",
 			false,
+			nodePlacements,
+			sensitiveEnv,
+			timeouts,
 		)
 	})
 
@@ -124,6 +153,19 @@ var _ = Describe("TaskDesirer", func() {
 			assertContainer(containers[0], "opi-task")
 			Expect(containers[0].Command).To(ConsistOf("/lifecycle/launch"))
 
+			By("setting the task container resources", func() {
+				Expect(containers[0].Resources).To(Equal(corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory:           *resource.NewScaledQuantity(1, resource.Mega),
+						corev1.ResourceEphemeralStorage: *resource.NewScaledQuantity(3, resource.Mega),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceMemory: *resource.NewScaledQuantity(1, resource.Mega),
+						corev1.ResourceCPU:    *resource.NewScaledQuantity(20, resource.Milli),
+					},
+				}))
+			})
+
 			By("setting the job's namespace to the app's namespace", func() {
 				Expect(jobNamespace).To(Equal("app-namespace"))
 			})
@@ -174,15 +216,264 @@ var _ = Describe("TaskDesirer", func() {
 			})
 		})
 
+		It("builds VCAP_APPLICATION for the task pod", func() {
+			_, job := fakeJobClient.CreateArgsForCall(0)
+			env := job.Spec.Template.Spec.Containers[0].Env
+
+			vcapEnv := env[len(env)-1]
+			Expect(vcapEnv.Name).To(Equal("VCAP_APPLICATION"))
+			Expect(vcapEnv.Value).To(MatchJSON(`{
+				"application_id": "my-app-guid",
+				"application_name": "my-app",
+				"name": "my-app",
+				"organization_id": "org-id",
+				"organization_name": "my-org",
+				"space_id": "space-id",
+				"space_name": "my-space",
+				"instance_id": "$(CF_INSTANCE_GUID)",
+				"limits": {"mem": 1, "disk": 3}
+			}`))
+		})
+
+		It("does not create an env secret when the env is not sensitive", func() {
+			Expect(fakeSecretsCreator.CreateCallCount()).To(BeZero())
+		})
+
+		When("the task has sensitive env", func() {
+			BeforeEach(func() {
+				task.Env["VCAP_SERVICES"] = "credentials"
+				task.Env["DB_PASSWORD"] = "s3cr3t"
+			})
+
+			It("stores it in a secret named after the job", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeSecretsCreator.CreateCallCount()).To(Equal(1))
+				namespace, secret := fakeSecretsCreator.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("app-namespace"))
+
+				_, job := fakeJobClient.CreateArgsForCall(0)
+				Expect(secret.Name).To(Equal(job.Name + "-env"))
+				Expect(secret.OwnerReferences).To(ConsistOf(metav1.OwnerReference{
+					APIVersion: "batch/v1",
+					Kind:       "Job",
+					Name:       job.Name,
+					UID:        "job-uid",
+				}))
+				Expect(secret.Data).To(Equal(map[string][]byte{
+					"VCAP_SERVICES": []byte("credentials"),
+					"DB_PASSWORD":   []byte("s3cr3t"),
+				}))
+			})
+
+			It("references it from the task container", func() {
+				_, job := fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+					corev1.EnvVar{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef(job.Name+"-env", "VCAP_SERVICES")},
+					corev1.EnvVar{Name: "DB_PASSWORD", ValueFrom: expectedSecretKeyRef(job.Name+"-env", "DB_PASSWORD")},
+					corev1.EnvVar{Name: eirini.EnvAppID, Value: "env-app-id"},
+				))
+			})
+
+			When("creating the secret fails", func() {
+				BeforeEach(func() {
+					fakeSecretsCreator.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("deletes the job again, so that the task can be desired again", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to create env secret")))
+					Expect(fakeJobClient.CreateCallCount()).To(Equal(1))
+					_, job := fakeJobClient.CreateArgsForCall(0)
+
+					Expect(fakeJobClient.DeleteCallCount()).To(Equal(1))
+					namespace, name := fakeJobClient.DeleteArgsForCall(0)
+					Expect(namespace).To(Equal("app-namespace"))
+					Expect(name).To(Equal(job.Name))
+				})
+			})
+		})
+
+		When("Cloud Controller sends a VCAP_APPLICATION", func() {
+			BeforeEach(func() {
+				task.Env["VCAP_APPLICATION"] = `{"cf_api":"https://api.example.com","application_name":"old-name"}`
+			})
+
+			It("replaces it with the one built for the task pod", func() {
+				_, job := fakeJobClient.CreateArgsForCall(0)
+
+				var vcapEnvs []corev1.EnvVar
+				for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+					if e.Name == "VCAP_APPLICATION" {
+						vcapEnvs = append(vcapEnvs, e)
+					}
+				}
+				Expect(vcapEnvs).To(HaveLen(1))
+				Expect(vcapEnvs[0].Value).To(ContainSubstring(`"cf_api":"https://api.example.com"`))
+				Expect(vcapEnvs[0].Value).To(ContainSubstring(`"application_name":"my-app"`))
+			})
+		})
+
+		When("the task uses a buildpack droplet", func() {
+			BeforeEach(func() {
+				task.DropletDownload = &opi.DropletDownload{
+					URL:             "https://cc.example.com/droplet",
+					DownloaderImage: "eirini/downloader",
+				}
+			})
+
+			It("downloads the droplet in an init container", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, job = fakeJobClient.CreateArgsForCall(0)
+
+				initContainers := job.Spec.Template.Spec.InitContainers
+				Expect(initContainers).To(HaveLen(1))
+				Expect(initContainers[0].Name).To(Equal(DropletDownloaderContainerName))
+				Expect(initContainers[0].Image).To(Equal("eirini/downloader"))
+				Expect(initContainers[0].Env).To(ContainElement(corev1.EnvVar{Name: eirini.EnvDownloadURL, Value: "https://cc.example.com/droplet"}))
+				Expect(initContainers[0].VolumeMounts).To(ConsistOf(
+					corev1.VolumeMount{Name: DropletVolumeName, MountPath: DropletMountPath},
+					corev1.VolumeMount{Name: "cc-uploader-certs", MountPath: "/etc/cf-api/certs", ReadOnly: true},
+				))
+			})
+
+			It("gives the init container the resources of the task container", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+
+				spec := job.Spec.Template.Spec
+				Expect(spec.InitContainers[0].Resources).To(Equal(spec.Containers[0].Resources))
+			})
+
+			It("shares the droplet volume with the task container", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+
+				Expect(job.Spec.Template.Spec.Volumes).To(ConsistOf(
+					corev1.Volume{
+						Name: DropletVolumeName,
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					},
+					corev1.Volume{
+						Name: "cc-uploader-certs",
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{SecretName: eirini.CCUploaderSecretName},
+						},
+					},
+				))
+				Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(
+					corev1.VolumeMount{Name: DropletVolumeName, MountPath: DropletMountPath},
+				))
+			})
+		})
+
+		When("the task runs in an isolation segment", func() {
+			BeforeEach(func() {
+				task.PlacementTags = []string{"isolated"}
+			})
+
+			It("should schedule the job on the nodes of the segment", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"segment": "isolated"}))
+				Expect(job.Spec.Template.Spec.Tolerations).To(ConsistOf(corev1.Toleration{
+					Key:      "segment",
+					Operator: corev1.TolerationOpEqual,
+					Value:    "isolated",
+					Effect:   corev1.TaintEffectNoSchedule,
+				}))
+			})
+
+			When("the segment has no configured placement", func() {
+				BeforeEach(func() {
+					task.PlacementTags = []string{"unknown"}
+				})
+
+				It("creates the job without constraining its nodes", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeJobClient.CreateCallCount()).To(Equal(1))
+					_, job = fakeJobClient.CreateArgsForCall(0)
+					Expect(job.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+				})
+			})
+		})
+
+		It("does not create a network policy", func() {
+			Expect(fakePolicyClient.CreateCallCount()).To(BeZero())
+		})
+
+		When("the task has egress rules", func() {
+			BeforeEach(func() {
+				task.EgressRules = []opi.EgressRule{
+					{Protocol: "tcp", Destinations: []string{"10.0.0.0/24"}, Ports: []int32{443}},
+				}
+			})
+
+			It("restricts the egress traffic of the task pod", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, job = fakeJobClient.CreateArgsForCall(0)
+
+				Expect(fakePolicyClient.CreateCallCount()).To(Equal(1))
+				namespace, policy := fakePolicyClient.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("app-namespace"))
+				Expect(policy.Name).To(Equal(job.Name))
+				Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+					LabelGUID:       taskGUID,
+					LabelSourceType: "TASK",
+				}))
+				Expect(policy.Spec.Egress).To(HaveLen(1))
+				Expect(policy.Spec.Egress[0].To[0].IPBlock.CIDR).To(Equal("10.0.0.0/24"))
+			})
+
+			When("desire options are passed", func() {
+				var desireOpt *k8sfakes.FakeDesireOption
+
+				BeforeEach(func() {
+					desireOpt = new(k8sfakes.FakeDesireOption)
+					desireOpts = []DesireOption{desireOpt.Spy}
+				})
+
+				It("applies them to the network policy too", func() {
+					Expect(desireOpt.CallCount()).To(Equal(2))
+					Expect(desireOpt.ArgsForCall(1)).To(BeAssignableToTypeOf(&networkingv1.NetworkPolicy{}))
+				})
+			})
+
+			When("creating the network policy fails", func() {
+				BeforeEach(func() {
+					fakePolicyClient.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to create network policy")))
+				})
+			})
+
+			When("all egress rules are ICMP rules", func() {
+				BeforeEach(func() {
+					task.EgressRules = []opi.EgressRule{
+						{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &opi.ICMPInfo{Type: 8, Code: 0}},
+					}
+				})
+
+				It("does not create a network policy that denies all egress", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakePolicyClient.CreateCallCount()).To(BeZero())
+				})
+			})
+		})
+
 		When("allowAutomountServiceAccountToken is true", func() {
 			BeforeEach(func() {
 				desirer = NewTaskDesirerWithEiriniInstance(
 					lagertest.NewTestLogger("desiretask"),
 					fakeJobClient,
+					fakePodsGetter,
 					fakeSecretsCreator,
+					fakePolicyClient,
 					"service-account",
 					"registry-secret",
 					true,
+					nodePlacements,
+					sensitiveEnv,
+					timeouts,
 				)
 			})
 
@@ -262,12 +553,18 @@ var _ = Describe("TaskDesirer", func() {
 
 		Context("and the job already exists", func() {
 			BeforeEach(func() {
+				fakeJobClient.CreateStub = nil
 				fakeJobClient.CreateReturns(nil, errors.New("job already exists"))
 			})
 
 			It("should return an error", func() {
 				Expect(err).To(MatchError(ContainSubstring("job already exists")))
 			})
+
+			It("does not create the secrets of the job", func() {
+				Expect(fakeSecretsCreator.CreateCallCount()).To(BeZero())
+				Expect(fakeJobClient.DeleteCallCount()).To(BeZero())
+			})
 		})
 
 		Context("when the job uses a private registry", func() {
@@ -277,14 +574,13 @@ var _ = Describe("TaskDesirer", func() {
 					Username: "username",
 					Password: "password",
 				}
-				fakeSecretsCreator.CreateReturns(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "the-generated-secret-name"}}, nil)
 			})
 
 			It("creates a secret with the registry credentials", func() {
 				Expect(fakeSecretsCreator.CreateCallCount()).To(Equal(1))
 				namespace, actualSecret := fakeSecretsCreator.CreateArgsForCall(0)
 				Expect(namespace).To(Equal("app-namespace"))
-				Expect(actualSecret.GenerateName).To(Equal("my-app-my-space-registry-secret-"))
+				Expect(actualSecret.Name).To(Equal("my-app-my-space-registry-secret-" + taskGUID))
 				Expect(actualSecret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
 				Expect(actualSecret.StringData).To(
 					HaveKeyWithValue(
@@ -301,10 +597,20 @@ var _ = Describe("TaskDesirer", func() {
 
 				Expect(job.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
 					corev1.LocalObjectReference{Name: "registry-secret"},
-					corev1.LocalObjectReference{Name: "the-generated-secret-name"},
+					corev1.LocalObjectReference{Name: "my-app-my-space-registry-secret-" + taskGUID},
 				))
 			})
 
+			It("makes the job the owner of the secret", func() {
+				_, actualSecret := fakeSecretsCreator.CreateArgsForCall(0)
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(actualSecret.OwnerReferences).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Kind": Equal("Job"),
+					"Name": Equal(job.Name),
+					"UID":  BeEquivalentTo("job-uid"),
+				})))
+			})
+
 			Context("when creating the secret fails", func() {
 				BeforeEach(func() {
 					fakeSecretsCreator.CreateReturns(nil, errors.New("create-secret-err"))
@@ -313,19 +619,187 @@ var _ = Describe("TaskDesirer", func() {
 				It("returns an error", func() {
 					Expect(err).To(MatchError(ContainSubstring("create-secret-err")))
 				})
+
+				It("deletes the job again", func() {
+					Expect(fakeJobClient.DeleteCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		It("does not set a deadline on the task pod", func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+			Expect(job.Spec.Template.Spec.ActiveDeadlineSeconds).To(BeNil())
+		})
+
+		Context("when the task has a timeout", func() {
+			BeforeEach(func() {
+				task.TimeoutSeconds = 60
+			})
+
+			It("sets it as the deadline of the task pod", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.Template.Spec.ActiveDeadlineSeconds).To(PointTo(BeNumerically("==", 60)))
+				Expect(job.Spec.ActiveDeadlineSeconds).To(BeNil())
+			})
+		})
+
+		It("keeps the default termination message path", func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+			Expect(job.Spec.Template.Spec.Containers[0].TerminationMessagePath).To(BeEmpty())
+		})
+
+		Context("when the task has a result file", func() {
+			BeforeEach(func() {
+				task.ResultFile = "/tmp/result.json"
+			})
+
+			It("reads the result file into the termination message", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				container := job.Spec.Template.Spec.Containers[0]
+				Expect(container.TerminationMessagePath).To(Equal("/tmp/result.json"))
+				Expect(container.TerminationMessagePolicy).To(Equal(corev1.TerminationMessageReadFile))
+			})
+		})
+
+		It("does not retry the task", func() {
+			_, job = fakeJobClient.CreateArgsForCall(0)
+			Expect(job.Spec.BackoffLimit).To(PointTo(BeNumerically("==", 0)))
+			Expect(job.Annotations).NotTo(HaveKey(AnnotationTaskRetryBackoffSeconds))
+			Expect(job.Annotations).NotTo(HaveKey(AnnotationTaskRetryExitCodes))
+		})
+
+		Context("when the task has a retry policy", func() {
+			BeforeEach(func() {
+				task.RetryPolicy = &opi.TaskRetryPolicy{
+					MaxAttempts:      3,
+					BackoffSeconds:   30,
+					RetryOnExitCodes: []int32{75, 111},
+				}
+			})
+
+			It("runs the first attempt only", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.BackoffLimit).To(PointTo(BeNumerically("==", 0)))
+				Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+				Expect(job.Annotations).To(HaveKeyWithValue(AnnotationTaskAttempt, "1"))
+				Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(AnnotationTaskAttempt, "1"))
+			})
+
+			It("keeps the retry policy in annotations", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Annotations).To(HaveKeyWithValue(AnnotationTaskMaxAttempts, "3"))
+				Expect(job.Annotations).To(HaveKeyWithValue(AnnotationTaskRetryBackoffSeconds, "30"))
+				Expect(job.Annotations).To(HaveKeyWithValue(AnnotationTaskRetryExitCodes, "[75,111]"))
+			})
+		})
+
+		Context("when operator timeouts are configured", func() {
+			BeforeEach(func() {
+				desirer = NewTaskDesirer(
+					lagertest.NewTestLogger("desiretask"),
+					fakeJobClient,
+					fakePodsGetter,
+					fakeSecretsCreator,
+					fakePolicyClient,
+					"service-account",
+					"registry-secret",
+					false,
+					nodePlacements,
+					sensitiveEnv,
+					TaskTimeouts{DefaultSeconds: 10, MaxSeconds: 30},
+				)
+			})
+
+			It("uses the default timeout for tasks without one", func() {
+				_, job = fakeJobClient.CreateArgsForCall(0)
+				Expect(job.Spec.Template.Spec.ActiveDeadlineSeconds).To(PointTo(BeNumerically("==", 10)))
+			})
+
+			Context("and the task has a timeout within the maximum", func() {
+				BeforeEach(func() {
+					task.TimeoutSeconds = 20
+				})
+
+				It("uses the task timeout", func() {
+					_, job = fakeJobClient.CreateArgsForCall(0)
+					Expect(job.Spec.Template.Spec.ActiveDeadlineSeconds).To(PointTo(BeNumerically("==", 20)))
+				})
+			})
+
+			Context("and the task timeout exceeds the maximum", func() {
+				BeforeEach(func() {
+					task.TimeoutSeconds = 60
+				})
+
+				It("caps the deadline at the maximum", func() {
+					_, job = fakeJobClient.CreateArgsForCall(0)
+					Expect(job.Spec.Template.Spec.ActiveDeadlineSeconds).To(PointTo(BeNumerically("==", 30)))
+				})
+			})
+		})
+
+		Context("when the job references an existing registry secret", func() {
+			BeforeEach(func() {
+				task.PrivateRegistry = &opi.PrivateRegistry{
+					SecretName: "my-registry-secret",
+				}
+			})
+
+			It("uses the referenced secret without creating one", func() {
+				Expect(fakeSecretsCreator.CreateCallCount()).To(BeZero())
+
+				Expect(fakeJobClient.CreateCallCount()).To(Equal(1))
+				_, job = fakeJobClient.CreateArgsForCall(0)
+
+				Expect(job.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
+					corev1.LocalObjectReference{Name: "registry-secret"},
+					corev1.LocalObjectReference{Name: "my-registry-secret"},
+				))
 			})
 		})
 	})
 
 	Describe("Get", func() {
-		var err error
+		var (
+			err       error
+			startTime metav1.Time
+			endTime   metav1.Time
+		)
 
 		BeforeEach(func() {
+			startTime = metav1.NewTime(time.Unix(1000, 0))
+			endTime = metav1.NewTime(time.Unix(2000, 0))
+
 			job = &batch.Job{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
 						LabelGUID: taskGUID,
+						LabelName: "task-name",
 					},
+					Annotations: map[string]string{
+						AnnotationAppName:   "my-app",
+						AnnotationAppID:     "my-app-guid",
+						AnnotationOrgName:   "my-org",
+						AnnotationOrgGUID:   "org-id",
+						AnnotationSpaceName: "my-space",
+						AnnotationSpaceGUID: "space-id",
+					},
+				},
+				Spec: batch.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:    "opi-task",
+									Image:   Image,
+									Command: []string{"/lifecycle/launch"},
+								},
+							},
+						},
+					},
+				},
+				Status: batch.JobStatus{
+					StartTime: &startTime,
 				},
 			}
 
@@ -340,17 +814,441 @@ var _ = Describe("TaskDesirer", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("requests uncompleted jobs from the jobs client", func() {
+		It("requests completed jobs too from the jobs client", func() {
 			Expect(fakeJobClient.GetByGUIDCallCount()).To(Equal(1))
 			actualGUID, actualIncludeCompleted := fakeJobClient.GetByGUIDArgsForCall(0)
 			Expect(actualGUID).To(Equal(task.GUID))
-			Expect(actualIncludeCompleted).To(BeFalse())
+			Expect(actualIncludeCompleted).To(BeTrue())
 		})
 
 		It("returns the task with the specified task guid", func() {
 			Expect(task.GUID).To(Equal(taskGUID))
 		})
 
+		It("returns the task metadata", func() {
+			Expect(task.Name).To(Equal("task-name"))
+			Expect(task.AppName).To(Equal("my-app"))
+			Expect(task.AppGUID).To(Equal("my-app-guid"))
+			Expect(task.OrgName).To(Equal("my-org"))
+			Expect(task.OrgGUID).To(Equal("org-id"))
+			Expect(task.SpaceName).To(Equal("my-space"))
+			Expect(task.SpaceGUID).To(Equal("space-id"))
+			Expect(task.Image).To(Equal(Image))
+			Expect(task.Command).To(ConsistOf("/lifecycle/launch"))
+		})
+
+		It("gets the task pods", func() {
+			Expect(fakePodsGetter.GetByTaskGUIDCallCount()).To(Equal(1))
+			Expect(fakePodsGetter.GetByTaskGUIDArgsForCall(0)).To(Equal(taskGUID))
+		})
+
+		When("the task has no pod yet", func() {
+			It("returns a pending task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+				Expect(task.Status.StartedAt).To(Equal(startTime.UnixNano()))
+				Expect(task.Status.ExitCode).To(BeNil())
+			})
+		})
+
+		When("the task container is running", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: startTime},
+					}),
+				}, nil)
+			})
+
+			It("returns a running task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskRunningState))
+				Expect(task.Status.StartedAt).To(Equal(startTime.UnixNano()))
+				Expect(task.Status.FinishedAt).To(BeZero())
+			})
+		})
+
+		When("the task container has succeeded", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   0,
+							StartedAt:  startTime,
+							FinishedAt: endTime,
+						},
+					}),
+				}, nil)
+			})
+
+			It("returns a succeeded task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskSucceededState))
+				Expect(task.Status.StartedAt).To(Equal(startTime.UnixNano()))
+				Expect(task.Status.FinishedAt).To(Equal(endTime.UnixNano()))
+				Expect(task.Status.ExitCode).To(PointTo(BeEquivalentTo(0)))
+				Expect(task.Status.FailureReason).To(BeEmpty())
+			})
+
+			When("the task reporter has marked the job as completed", func() {
+				BeforeEach(func() {
+					job.Labels[LabelTaskCompleted] = TaskCompletedTrue
+					fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+				})
+
+				It("still returns the task with its status", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(task.GUID).To(Equal(taskGUID))
+					Expect(task.Status.State).To(Equal(opi.TaskSucceededState))
+					Expect(task.Status.ExitCode).To(PointTo(BeEquivalentTo(0)))
+				})
+			})
+		})
+
+		When("the task container has failed", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   42,
+							Reason:     "Error",
+							StartedAt:  startTime,
+							FinishedAt: endTime,
+						},
+					}),
+				}, nil)
+			})
+
+			It("returns a failed task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FinishedAt).To(Equal(endTime.UnixNano()))
+				Expect(task.Status.ExitCode).To(PointTo(BeEquivalentTo(42)))
+				Expect(task.Status.FailureReason).To(Equal("Error (exit code 42)"))
+			})
+		})
+
+		When("the task container has been killed for running out of memory", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 137,
+							Reason:   "OOMKilled",
+							Message:  "  allocating the universe\n",
+						},
+					}),
+				}, nil)
+			})
+
+			It("includes the reason, exit code and termination message in the failure reason", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("OOMKilled (exit code 137): allocating the universe"))
+			})
+		})
+
+		When("the task image cannot be pulled", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{
+							Reason:  "ImagePullBackOff",
+							Message: "Back-off pulling image \"eirini/nope\"",
+						},
+					}),
+				}, nil)
+			})
+
+			It("returns a failed task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.ExitCode).To(BeNil())
+				Expect(task.Status.FailureReason).To(Equal(`ImagePullBackOff: Back-off pulling image "eirini/nope"`))
+			})
+		})
+
+		When("pulling the task image has only just failed", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "i/o timeout"},
+				})
+				now := metav1.Now()
+				pod.Status.StartTime = &now
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a pending task, as the kubelet keeps pulling the image", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+				Expect(task.Status.FailureReason).To(BeEmpty())
+			})
+		})
+
+		When("the task image name is invalid", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "InvalidImageName", Message: "couldn't parse image reference"},
+				})
+				now := metav1.Now()
+				pod.Status.StartTime = &now
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a failed task straight away", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("InvalidImageName: couldn't parse image reference"))
+			})
+		})
+
+		When("the image of an init container cannot be pulled", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+				})
+				pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name: "opi-task-downloader",
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"},
+						},
+					},
+				}
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a failed task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("ErrImagePull: not found"))
+			})
+		})
+
+		When("the task pod cannot be scheduled", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{})
+				pod.Status.ContainerStatuses = nil
+				pod.Status.Conditions = []corev1.PodCondition{
+					{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/3 nodes are available: 3 Insufficient memory.",
+					},
+				}
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a failed task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("Unschedulable: 0/3 nodes are available: 3 Insufficient memory."))
+			})
+		})
+
+		When("the task pod has only just been found unschedulable", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{})
+				pod.Status.ContainerStatuses = nil
+				pod.Status.Conditions = []corev1.PodCondition{
+					{
+						Type:               corev1.PodScheduled,
+						Status:             corev1.ConditionFalse,
+						Reason:             corev1.PodReasonUnschedulable,
+						Message:            "0/3 nodes are available: 3 Insufficient memory.",
+						LastTransitionTime: metav1.Now(),
+					},
+				}
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a pending task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+				Expect(task.Status.FailureReason).To(BeEmpty())
+			})
+		})
+
+		When("the task container is waiting for another reason", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+					}),
+				}, nil)
+			})
+
+			It("returns a pending task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+				Expect(task.Status.FailureReason).To(BeEmpty())
+			})
+		})
+
+		When("a failed attempt is going to be retried", func() {
+			BeforeEach(func() {
+				job.Annotations[AnnotationTaskMaxAttempts] = "2"
+				job.Status.Conditions = []batch.JobCondition{
+					{Type: batch.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+				}
+				fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+					taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   42,
+							Reason:     "Error",
+							StartedAt:  startTime,
+							FinishedAt: endTime,
+						},
+					}),
+				}, nil)
+			})
+
+			It("returns a pending task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+				Expect(task.Status.ExitCode).To(BeNil())
+				Expect(task.Status.FailureReason).To(BeEmpty())
+			})
+
+			It("keeps the failed attempt in the history", func() {
+				Expect(task.Status.Attempts).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"StartedAt":     Equal(startTime.UnixNano()),
+					"FinishedAt":    Equal(endTime.UnixNano()),
+					"ExitCode":      PointTo(BeEquivalentTo(42)),
+					"FailureReason": Equal("Error (exit code 42)"),
+				})))
+			})
+
+			When("the exit code is not one to retry on", func() {
+				BeforeEach(func() {
+					job.Annotations[AnnotationTaskRetryExitCodes] = "[75]"
+					fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+				})
+
+				It("returns a failed task", func() {
+					Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+					Expect(task.Status.ExitCode).To(PointTo(BeEquivalentTo(42)))
+				})
+			})
+
+			When("the attempt has exceeded the task timeout", func() {
+				BeforeEach(func() {
+					pod := taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "Error", StartedAt: startTime, FinishedAt: endTime},
+					})
+					pod.Status.Reason = PodDeadlineExceededReason
+					fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+				})
+
+				It("returns a failed task", func() {
+					Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+					Expect(task.Status.FailureReason).To(ContainSubstring(TaskTimeoutFailureReason))
+				})
+			})
+		})
+
+		When("the task has been attempted several times", func() {
+			var secondAttempt corev1.Pod
+
+			BeforeEach(func() {
+				job.Name = "my-job"
+				job.Annotations[AnnotationTaskMaxAttempts] = "2"
+				job.Annotations[AnnotationTaskAttempt] = "1"
+				job.Labels[LabelTaskCompleted] = TaskCompletedTrue
+				nextJob := NextTaskAttempt(*job)
+				fakeJobClient.GetByGUIDReturns([]batch.Job{*nextJob, *job}, nil)
+
+				firstAttempt := taskPod(corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 42, Reason: "Error", StartedAt: startTime, FinishedAt: startTime},
+				})
+				firstAttempt.Annotations = map[string]string{AnnotationTaskAttempt: "1"}
+				firstAttempt.CreationTimestamp = startTime
+				secondAttempt = taskPod(corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, StartedAt: endTime, FinishedAt: endTime},
+				})
+				secondAttempt.Annotations = map[string]string{AnnotationTaskAttempt: "2"}
+				secondAttempt.CreationTimestamp = startTime
+
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{secondAttempt, firstAttempt}, nil)
+			})
+
+			It("returns the status of the latest attempt", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskSucceededState))
+			})
+
+			It("returns the attempts oldest first, whenever their pods were created", func() {
+				Expect(task.Status.Attempts).To(HaveLen(2))
+				Expect(task.Status.Attempts[0].ExitCode).To(PointTo(BeEquivalentTo(42)))
+				Expect(task.Status.Attempts[1].ExitCode).To(PointTo(BeEquivalentTo(0)))
+			})
+
+			When("the pod of the latest attempt has not been created yet", func() {
+				BeforeEach(func() {
+					firstAttempt := taskPod(corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 42, Reason: "Error", StartedAt: startTime, FinishedAt: startTime},
+					})
+					firstAttempt.Annotations = map[string]string{AnnotationTaskAttempt: "1"}
+					fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{firstAttempt}, nil)
+				})
+
+				It("returns a pending task", func() {
+					Expect(task.Status.State).To(Equal(opi.TaskPendingState))
+					Expect(task.Status.Attempts).To(HaveLen(1))
+				})
+			})
+		})
+
+		When("the task pod has exceeded its deadline", func() {
+			BeforeEach(func() {
+				pod := taskPod(corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode:   137,
+						Reason:     "Error",
+						StartedAt:  startTime,
+						FinishedAt: endTime,
+					},
+				})
+				pod.Status.Phase = corev1.PodFailed
+				pod.Status.Reason = "DeadlineExceeded"
+				fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{pod}, nil)
+			})
+
+			It("returns a failed task with the timeout as failure reason", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("DeadlineExceeded (exit code 137): task exceeded its timeout"))
+			})
+		})
+
+		When("the job has failed without a terminated container", func() {
+			BeforeEach(func() {
+				job.Status.Conditions = []batch.JobCondition{
+					{
+						Type:               batch.JobFailed,
+						Status:             corev1.ConditionTrue,
+						Reason:             "BackoffLimitExceeded",
+						LastTransitionTime: endTime,
+					},
+				}
+				fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+			})
+
+			It("returns a failed task with the job condition reason", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskFailedState))
+				Expect(task.Status.FailureReason).To(Equal("BackoffLimitExceeded"))
+				Expect(task.Status.FinishedAt).To(Equal(endTime.UnixNano()))
+			})
+		})
+
+		When("the job is being deleted", func() {
+			BeforeEach(func() {
+				job.DeletionTimestamp = &endTime
+				fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+			})
+
+			It("returns a cancelled task", func() {
+				Expect(task.Status.State).To(Equal(opi.TaskCancelledState))
+			})
+		})
+
+		When("getting the task pods fails", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns(nil, errors.New("get-pods-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("get-pods-error")))
+			})
+		})
+
 		When("getting the task fails", func() {
 			BeforeEach(func() {
 				fakeJobClient.GetByGUIDReturns(nil, errors.New("get-task-error"))
@@ -390,6 +1288,82 @@ var _ = Describe("TaskDesirer", func() {
 		})
 	})
 
+	Describe("GetStatus", func() {
+		var (
+			status opi.TaskStatus
+			err    error
+		)
+
+		BeforeEach(func() {
+			job = &batch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						LabelGUID: taskGUID,
+					},
+				},
+			}
+
+			fakeJobClient.GetByGUIDReturns([]batch.Job{*job}, nil)
+			fakePodsGetter.GetByTaskGUIDReturns([]corev1.Pod{
+				taskPod(corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 2, Reason: "Error"},
+				}),
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			status, err = desirer.GetStatus(taskGUID)
+		})
+
+		It("succeeds", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("includes completed jobs", func() {
+			Expect(fakeJobClient.GetByGUIDCallCount()).To(Equal(1))
+			actualGUID, actualIncludeCompleted := fakeJobClient.GetByGUIDArgsForCall(0)
+			Expect(actualGUID).To(Equal(taskGUID))
+			Expect(actualIncludeCompleted).To(BeTrue())
+		})
+
+		It("returns the status computed from the task pod", func() {
+			Expect(fakePodsGetter.GetByTaskGUIDArgsForCall(0)).To(Equal(taskGUID))
+			Expect(status.State).To(Equal(opi.TaskFailedState))
+			Expect(status.ExitCode).To(PointTo(BeEquivalentTo(2)))
+			Expect(status.FailureReason).To(Equal("Error (exit code 2)"))
+		})
+
+		When("there are no jobs for that task GUID", func() {
+			BeforeEach(func() {
+				fakeJobClient.GetByGUIDReturns([]batch.Job{}, nil)
+			})
+
+			It("returns not found error", func() {
+				Expect(err).To(Equal(eirini.ErrNotFound))
+			})
+		})
+
+		When("getting the job fails", func() {
+			BeforeEach(func() {
+				fakeJobClient.GetByGUIDReturns(nil, errors.New("get-job-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("get-job-error")))
+			})
+		})
+
+		When("getting the task pods fails", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetByTaskGUIDReturns(nil, errors.New("get-pods-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("get-pods-error")))
+			})
+		})
+	})
+
 	Describe("List", func() {
 		var (
 			tasks []*opi.Task
@@ -416,9 +1390,9 @@ var _ = Describe("TaskDesirer", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("excludes completed tasks", func() {
+		It("includes completed tasks", func() {
 			Expect(fakeJobClient.ListCallCount()).To(Equal(1))
-			Expect(fakeJobClient.ListArgsForCall(0)).To(BeFalse())
+			Expect(fakeJobClient.ListArgsForCall(0)).To(BeTrue())
 		})
 
 		It("returns all tasks", func() {
@@ -432,6 +1406,73 @@ var _ = Describe("TaskDesirer", func() {
 			Expect(taskGUIDs).To(ContainElement(taskGUID))
 		})
 
+		It("matches the task pods to the jobs", func() {
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].Status.State).To(Equal(opi.TaskPendingState))
+		})
+
+		When("there are runs of a scheduled task", func() {
+			BeforeEach(func() {
+				run := batch.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							LabelGUID:          "scheduled-task-guid",
+							LabelScheduledTask: ScheduledTaskTrue,
+						},
+					},
+				}
+
+				fakeJobClient.ListReturns([]batch.Job{*job, run}, nil)
+			})
+
+			It("does not list them as tasks", func() {
+				Expect(tasks).To(HaveLen(1))
+				Expect(tasks[0].GUID).To(Equal(taskGUID))
+			})
+		})
+
+		When("the task has been retried", func() {
+			BeforeEach(func() {
+				job.Name = "my-job"
+				job.Annotations = map[string]string{AnnotationTaskAttempt: "1", AnnotationTaskMaxAttempts: "2"}
+				fakeJobClient.ListReturns([]batch.Job{*job, *NextTaskAttempt(*job)}, nil)
+			})
+
+			It("lists the task once", func() {
+				Expect(tasks).To(HaveLen(1))
+				Expect(tasks[0].GUID).To(Equal(taskGUID))
+			})
+		})
+
+		When("a task pod is running", func() {
+			BeforeEach(func() {
+				lrpPod := taskPod(corev1.ContainerState{})
+				lrpPod.Labels[LabelSourceType] = "APP"
+
+				fakePodsGetter.GetAllReturns([]corev1.Pod{
+					lrpPod,
+					taskPod(corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					}),
+				}, nil)
+			})
+
+			It("returns the task state", func() {
+				Expect(tasks).To(HaveLen(1))
+				Expect(tasks[0].Status.State).To(Equal(opi.TaskRunningState))
+			})
+		})
+
+		When("listing the task pods fails", func() {
+			BeforeEach(func() {
+				fakePodsGetter.GetAllReturns(nil, errors.New("list-pods-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("list-pods-error")))
+			})
+		})
+
 		When("listing the task fails", func() {
 			BeforeEach(func() {
 				fakeJobClient.ListReturns(nil, errors.New("list-tasks-error"))
@@ -444,6 +1485,25 @@ var _ = Describe("TaskDesirer", func() {
 	})
 })
 
+func taskPod(state corev1.ContainerState) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				LabelGUID:       "task-123",
+				LabelSourceType: "TASK",
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "opi-task",
+					State: state,
+				},
+			},
+		},
+	}
+}
+
 func int32ptr(i int) *int32 {
 	u := int32(i)
 
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/opi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DropletDownloaderContainerName = "droplet-downloader"
+	DropletVolumeName              = "droplet"
+	DropletMountPath               = "/home/vcap"
+)
+
+// getDropletDownloadSpecs returns the init container that downloads the
+// droplet into a shared volume, together with the volumes it needs and the
+// mount the app container needs to see the downloaded droplet. Like the
+// staging downloader, it trusts Cloud Controller with the CC uploader certs.
+func getDropletDownloadSpecs(download *opi.DropletDownload, resources corev1.ResourceRequirements) (corev1.Container, []corev1.Volume, corev1.VolumeMount) {
+	allowPrivilegeEscalation := false
+
+	volumeMount := corev1.VolumeMount{
+		Name:      DropletVolumeName,
+		MountPath: DropletMountPath,
+	}
+	ccUploaderCertsMount := corev1.VolumeMount{Name: ccUploaderCertsVolumeName, MountPath: ccUploaderCertsMountPath, ReadOnly: true}
+
+	container := corev1.Container{
+		Name:            DropletDownloaderContainerName,
+		Image:           download.DownloaderImage,
+		ImagePullPolicy: corev1.PullAlways,
+		Env: []corev1.EnvVar{
+			{Name: eirini.EnvDownloadURL, Value: download.URL},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		},
+		Resources:    resources,
+		VolumeMounts: []corev1.VolumeMount{volumeMount, ccUploaderCertsMount},
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: DropletVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		{
+			Name: ccUploaderCertsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: eirini.CCUploaderSecretName},
+			},
+		},
+	}
+
+	return container, volumes, volumeMount
+}
@@ -0,0 +1,154 @@
+package staging
+
+import (
+	"context"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//counterfeiter:generate . StagingCompleter
+//counterfeiter:generate . Deleter
+
+type StagingCompleter interface {
+	CompleteStaging(cf.StagingCompletedRequest) error
+}
+
+type Deleter interface {
+	Delete(guid string) (string, error)
+}
+
+// Reconciler reports failed staging jobs to Cloud Controller. Successful
+// staging is reported by the uploader container, which never runs when the
+// staging fails before it, e.g. when the executor fails or the pod is evicted.
+type Reconciler struct {
+	logger        lager.Logger
+	runtimeClient client.Client
+	completer     StagingCompleter
+	deleter       Deleter
+}
+
+func NewReconciler(
+	logger lager.Logger,
+	runtimeClient client.Client,
+	completer StagingCompleter,
+	deleter Deleter,
+) *Reconciler {
+	return &Reconciler{
+		logger:        logger,
+		runtimeClient: runtimeClient,
+		completer:     completer,
+		deleter:       deleter,
+	}
+}
+
+func (r Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.Session("staging-failure-reconciler", lager.Data{"namespace": request.Namespace, "job-name": request.Name})
+
+	job := &batchv1.Job{}
+	if err := r.runtimeClient.Get(context.Background(), request.NamespacedName, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Debug("job-does-not-exist")
+
+			return reconcile.Result{}, nil
+		}
+
+		logger.Error("failed-to-get-job", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to get job")
+	}
+
+	failed, ok := getFailedCondition(job)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	guid := job.Labels[k8s.LabelGUID]
+	logger = logger.WithData(lager.Data{"guid": guid})
+
+	reason, err := r.failureReason(job, failed)
+	if err != nil {
+		logger.Error("failed-to-get-failure-reason", err)
+
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("staging-failed", lager.Data{"failure-reason": reason})
+
+	err = r.completer.CompleteStaging(cf.StagingCompletedRequest{
+		TaskGUID:      guid,
+		Failed:        true,
+		FailureReason: reason,
+		Annotation:    fmt.Sprintf(`{"completion_callback": "%s"}`, job.Annotations[k8s.AnnotationCompletionCallback]),
+	})
+	if err != nil {
+		logger.Error("failed-to-notify-cloud-controller", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to report staging failure")
+	}
+
+	if _, err = r.deleter.Delete(guid); err != nil {
+		logger.Error("failed-to-delete-staging-job", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to delete staging job")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// failureReason describes the container that failed the staging or the pod
+// eviction, falling back to the job condition when no pod is left, e.g. when
+// the staging timed out.
+func (r Reconciler) failureReason(job *batchv1.Job, failed batchv1.JobCondition) (string, error) {
+	pods := &corev1.PodList{}
+
+	err := r.runtimeClient.List(context.Background(), pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{k8s.LabelGUID: job.Labels[k8s.LabelGUID]},
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list staging pods")
+	}
+
+	for _, pod := range pods.Items {
+		if reason, ok := podFailureReason(pod); ok {
+			return reason, nil
+		}
+	}
+
+	return fmt.Sprintf("staging failed: %s", failed.Message), nil
+}
+
+func podFailureReason(pod corev1.Pod) (string, bool) {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Sprintf("%s failed with exit code %d: %s", status.Name, terminated.ExitCode, terminated.Reason), true
+		}
+	}
+
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason != "" {
+		return fmt.Sprintf("staging pod failed: %s: %s", pod.Status.Reason, pod.Status.Message), true
+	}
+
+	return "", false
+}
+
+func getFailedCondition(job *batchv1.Job) (batchv1.JobCondition, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return condition, true
+		}
+	}
+
+	return batchv1.JobCondition{}, false
+}
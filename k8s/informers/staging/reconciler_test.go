@@ -0,0 +1,244 @@
+package staging_test
+
+import (
+	"context"
+	"errors"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/informers/staging"
+	"code.cloudfoundry.org/eirini/k8s/informers/staging/stagingfakes"
+	"code.cloudfoundry.org/eirini/k8s/reconciler/reconcilerfakes"
+	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Staging Failure Reconciler", func() {
+	var (
+		reconcileRes  reconcile.Result
+		reconcileErr  error
+		runtimeClient *reconcilerfakes.FakeClient
+		completer     *stagingfakes.FakeStagingCompleter
+		deleter       *stagingfakes.FakeDeleter
+		reconciler    *staging.Reconciler
+		job           *batchv1.Job
+		pods          []corev1.Pod
+	)
+
+	BeforeEach(func() {
+		runtimeClient = new(reconcilerfakes.FakeClient)
+		completer = new(stagingfakes.FakeStagingCompleter)
+		deleter = new(stagingfakes.FakeDeleter)
+		reconciler = staging.NewReconciler(lagertest.NewTestLogger("staging-reconciler"), runtimeClient, completer, deleter)
+
+		job = &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "the-staging-job",
+				Namespace: "workloads",
+				Labels: map[string]string{
+					k8s.LabelGUID:       "the-staging-guid",
+					k8s.LabelSourceType: k8s.StagingSourceType,
+				},
+				Annotations: map[string]string{
+					k8s.AnnotationCompletionCallback: "http://cc/staging/completed",
+				},
+			},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:    batchv1.JobFailed,
+						Status:  corev1.ConditionTrue,
+						Reason:  "BackoffLimitExceeded",
+						Message: "Job has reached the specified backoff limit",
+					},
+				},
+			},
+		}
+
+		pods = []corev1.Pod{
+			{
+				Status: corev1.PodStatus{
+					Phase: corev1.PodFailed,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "opi-staging-downloader",
+							State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+						},
+						{
+							Name:  "opi-staging-executor",
+							State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 222, Reason: "Error"}},
+						},
+					},
+				},
+			},
+		}
+
+		runtimeClient.GetStub = func(_ context.Context, _ k8stypes.NamespacedName, obj runtime.Object) error {
+			job.DeepCopyInto(obj.(*batchv1.Job))
+
+			return nil
+		}
+
+		runtimeClient.ListStub = func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+			list.(*corev1.PodList).Items = pods
+
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		reconcileRes, reconcileErr = reconciler.Reconcile(reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Namespace: "workloads", Name: "the-staging-job"},
+		})
+	})
+
+	It("succeeds", func() {
+		Expect(reconcileErr).NotTo(HaveOccurred())
+		Expect(reconcileRes).To(Equal(reconcile.Result{}))
+	})
+
+	It("gets the job", func() {
+		Expect(runtimeClient.GetCallCount()).To(Equal(1))
+		_, namespacedName, _ := runtimeClient.GetArgsForCall(0)
+		Expect(namespacedName).To(Equal(k8stypes.NamespacedName{Namespace: "workloads", Name: "the-staging-job"}))
+	})
+
+	It("lists the pods of the staging", func() {
+		Expect(runtimeClient.ListCallCount()).To(Equal(1))
+		_, _, opts := runtimeClient.ListArgsForCall(0)
+		Expect(opts).To(ConsistOf(
+			client.InNamespace("workloads"),
+			client.MatchingLabels{k8s.LabelGUID: "the-staging-guid"},
+		))
+	})
+
+	It("reports the failed container to cloud controller", func() {
+		Expect(completer.CompleteStagingCallCount()).To(Equal(1))
+		Expect(completer.CompleteStagingArgsForCall(0)).To(Equal(cf.StagingCompletedRequest{
+			TaskGUID:      "the-staging-guid",
+			Failed:        true,
+			FailureReason: "opi-staging-executor failed with exit code 222: Error",
+			Annotation:    `{"completion_callback": "http://cc/staging/completed"}`,
+		}))
+	})
+
+	It("deletes the staging job", func() {
+		Expect(deleter.DeleteCallCount()).To(Equal(1))
+		Expect(deleter.DeleteArgsForCall(0)).To(Equal("the-staging-guid"))
+	})
+
+	When("the pod was evicted", func() {
+		BeforeEach(func() {
+			pods = []corev1.Pod{
+				{
+					Status: corev1.PodStatus{
+						Phase:   corev1.PodFailed,
+						Reason:  "Evicted",
+						Message: "The node was low on resource: memory.",
+					},
+				},
+			}
+		})
+
+		It("reports the eviction", func() {
+			request := completer.CompleteStagingArgsForCall(0)
+			Expect(request.Failed).To(BeTrue())
+			Expect(request.FailureReason).To(Equal("staging pod failed: Evicted: The node was low on resource: memory."))
+		})
+	})
+
+	When("no pod of the staging is left", func() {
+		BeforeEach(func() {
+			pods = nil
+			job.Status.Conditions[0].Reason = "DeadlineExceeded"
+			job.Status.Conditions[0].Message = "Job was active longer than specified deadline"
+		})
+
+		It("reports the job failure", func() {
+			request := completer.CompleteStagingArgsForCall(0)
+			Expect(request.Failed).To(BeTrue())
+			Expect(request.FailureReason).To(Equal("staging failed: Job was active longer than specified deadline"))
+		})
+	})
+
+	When("the job has not failed", func() {
+		BeforeEach(func() {
+			job.Status.Conditions = nil
+		})
+
+		It("neither reports nor deletes it", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(completer.CompleteStagingCallCount()).To(BeZero())
+			Expect(deleter.DeleteCallCount()).To(BeZero())
+		})
+	})
+
+	When("the job does not exist", func() {
+		BeforeEach(func() {
+			runtimeClient.GetStub = nil
+			runtimeClient.GetReturns(apierrors.NewNotFound(schema.GroupResource{}, "the-staging-job"))
+		})
+
+		It("does nothing", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(completer.CompleteStagingCallCount()).To(BeZero())
+		})
+	})
+
+	When("getting the job fails", func() {
+		BeforeEach(func() {
+			runtimeClient.GetStub = nil
+			runtimeClient.GetReturns(errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("failed to get job")))
+		})
+	})
+
+	When("listing the pods fails", func() {
+		BeforeEach(func() {
+			runtimeClient.ListStub = nil
+			runtimeClient.ListReturns(errors.New("boom"))
+		})
+
+		It("returns an error without reporting", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("failed to list staging pods")))
+			Expect(completer.CompleteStagingCallCount()).To(BeZero())
+		})
+	})
+
+	When("reporting to cloud controller fails", func() {
+		BeforeEach(func() {
+			completer.CompleteStagingReturns(errors.New("boom"))
+		})
+
+		It("returns an error so that it is retried", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("failed to report staging failure")))
+		})
+
+		It("keeps the job", func() {
+			Expect(deleter.DeleteCallCount()).To(BeZero())
+		})
+	})
+
+	When("deleting the job fails", func() {
+		BeforeEach(func() {
+			deleter.DeleteReturns("", errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("failed to delete staging job")))
+		})
+	})
+})
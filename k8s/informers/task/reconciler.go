@@ -12,6 +12,8 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -22,12 +24,14 @@ import (
 //counterfeiter:generate . Deleter
 
 type Reporter interface {
-	Report(*corev1.Pod) error
+	Report(pod *corev1.Pod, attempts int) error
 }
 
 type JobsClient interface {
 	GetByGUID(guid string, includeCompleted bool) ([]batchv1.Job, error)
 	SetLabel(job *batchv1.Job, key, value string) (*batchv1.Job, error)
+	Create(namespace string, job *batchv1.Job) (*batchv1.Job, error)
+	Delete(namespace, name string) error
 }
 
 type Deleter interface {
@@ -87,7 +91,16 @@ func (r Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, erro
 		return reconcile.Result{}, errors.Wrap(err, "failed to get pod")
 	}
 
-	if !r.taskContainerHasTerminated(logger, pod) {
+	// Nothing may change about a pod that cannot start, so it is checked
+	// again once its grace period is over
+	if remaining, failingToStart := k8s.TaskStartGraceRemaining(pod); failingToStart {
+		logger.Debug("task-pod-failing-to-start", lager.Data{"grace-period-remaining": remaining.String()})
+
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	_, failedToStart := k8s.TaskStartFailureReason(pod)
+	if !failedToStart && !r.taskContainerHasTerminated(logger, pod) {
 		return reconcile.Result{}, nil
 	}
 
@@ -107,16 +120,39 @@ func (r Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, erro
 		return reconcile.Result{}, nil
 	}
 
-	if err = r.reportIfRequired(pod); err != nil {
+	job := getJobOfPod(pod, jobsForPods)
+	attempts := k8s.TaskAttempt(job)
+
+	if !isLatestAttempt(job, jobsForPods) {
+		logger.Debug("ignoring-previous-attempt")
+
+		return reconcile.Result{}, nil
+	}
+
+	if !failedToStart && k8s.TaskAttemptWillBeRetried(*job, pod) {
+		return r.retry(logger, job, getTerminatedState(pod))
+	}
+
+	if err = r.reportIfRequired(pod, attempts); err != nil {
 		logger.Error("completion-callback-failed", err, lager.Data{"tries": pod.Annotations[k8s.AnnotationOpiTaskCompletionReportCounter]})
 
 		return reconcile.Result{}, err
 	}
 
-	if _, err = r.jobs.SetLabel(&jobsForPods[0], k8s.LabelTaskCompleted, k8s.TaskCompletedTrue); err != nil {
+	if _, err = r.jobs.SetLabel(job, k8s.LabelTaskCompleted, k8s.TaskCompletedTrue); err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to label the job as completed")
 	}
 
+	if failedToStart {
+		return reconcile.Result{}, r.deleteUnstartedTask(logger, guid, job)
+	}
+
+	if job.Labels[k8s.LabelScheduledTask] == k8s.ScheduledTaskTrue {
+		logger.Debug("leaving-scheduled-run-to-cron-job-history-limits")
+
+		return reconcile.Result{}, nil
+	}
+
 	if !r.taskHasExpired(logger, pod) {
 		logger.Debug("task-hasnt-expired-yet")
 
@@ -130,7 +166,120 @@ func (r Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, erro
 	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) reportIfRequired(pod *corev1.Pod) error {
+// retry creates the job of the next attempt once the retry backoff has passed
+// since the failed attempt, and makes it an owner of the resources shared by
+// the attempts. The job of the failed attempt is then labelled as completed,
+// so that only the job of the latest attempt is active.
+func (r Reconciler) retry(logger lager.Logger, job *batchv1.Job, terminated *corev1.ContainerStateTerminated) (reconcile.Result, error) {
+	attempts := k8s.TaskAttempt(job)
+	retryAt := terminated.FinishedAt.Add(k8s.TaskRetryBackoff(*job))
+
+	if wait := time.Until(retryAt); wait > 0 {
+		logger.Info("waiting-for-next-attempt", lager.Data{"attempts": attempts, "retry-at": retryAt})
+
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	logger.Info("retrying-task", lager.Data{"attempts": attempts})
+
+	next, err := r.createNextAttempt(job)
+	if err != nil {
+		logger.Error("failed-to-create-next-attempt", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to create the next attempt")
+	}
+
+	if err = r.addTaskResourcesOwner(job, next); err != nil {
+		logger.Error("failed-to-own-task-resources", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to make the next attempt own the task resources")
+	}
+
+	if _, err = r.jobs.SetLabel(job, k8s.LabelTaskCompleted, k8s.TaskCompletedTrue); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to label the failed attempt as completed")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r Reconciler) createNextAttempt(job *batchv1.Job) (*batchv1.Job, error) {
+	next := k8s.NextTaskAttempt(*job)
+
+	created, err := r.jobs.Create(job.Namespace, next)
+	if !apierrors.IsAlreadyExists(err) {
+		return created, err
+	}
+
+	existing := &batchv1.Job{}
+	err = r.runtimeClient.Get(context.Background(), types.NamespacedName{Namespace: next.Namespace, Name: next.Name}, existing)
+
+	return existing, errors.Wrap(err, "failed to get the existing job")
+}
+
+// addTaskResourcesOwner adds the job of the next attempt to the owners of the
+// resources shared by the attempts, so that deleting the jobs of the previous
+// attempts does not garbage collect them.
+func (r Reconciler) addTaskResourcesOwner(job, next *batchv1.Job) error {
+	owner := metav1.OwnerReference{
+		APIVersion: batchv1.SchemeGroupVersion.String(),
+		Kind:       "Job",
+		Name:       next.Name,
+		UID:        next.UID,
+	}
+
+	for _, resource := range k8s.TaskAttemptResources(*job) {
+		obj := resource.(metav1.Object)
+		key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+		if err := r.runtimeClient.Get(context.Background(), key, resource); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return errors.Wrapf(err, "failed to get %s", key)
+		}
+
+		if hasOwner(obj, owner.UID) {
+			continue
+		}
+
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), owner))
+
+		if err := r.runtimeClient.Update(context.Background(), resource); err != nil {
+			return errors.Wrapf(err, "failed to update %s", key)
+		}
+	}
+
+	return nil
+}
+
+func hasOwner(obj metav1.Object, uid types.UID) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteUnstartedTask deletes a task reported as failed to start straight
+// away: otherwise the scheduler or the kubelet keep trying, and the task could
+// still run after it was reported. Only the job of a scheduled run is deleted,
+// as the secrets belong to the scheduled task.
+func (r Reconciler) deleteUnstartedTask(logger lager.Logger, guid string, job *batchv1.Job) error {
+	logger.Info("deleting-task-that-failed-to-start")
+
+	if job.Labels[k8s.LabelScheduledTask] == k8s.ScheduledTaskTrue {
+		return errors.Wrap(r.jobs.Delete(job.Namespace, job.Name), "failed to delete scheduled run")
+	}
+
+	_, err := r.deleter.Delete(guid)
+
+	return errors.Wrap(err, "failed to delete job")
+}
+
+func (r *Reconciler) reportIfRequired(pod *corev1.Pod, attempts int) error {
 	if pod.Annotations[k8s.AnnotationCCAckedTaskCompletion] == k8s.TaskCompletedTrue {
 		return nil
 	}
@@ -140,7 +289,7 @@ func (r *Reconciler) reportIfRequired(pod *corev1.Pod) error {
 		return nil
 	}
 
-	if err := r.reporter.Report(pod); err != nil {
+	if err := r.reporter.Report(pod, attempts); err != nil {
 		resultErr := multierror.Append(err)
 
 		if _, updateErr := r.pods.SetAnnotation(pod, k8s.AnnotationOpiTaskCompletionReportCounter, strconv.Itoa(completionCounter+1)); updateErr != nil {
@@ -181,6 +330,41 @@ func (r Reconciler) taskHasExpired(logger lager.Logger, pod *corev1.Pod) bool {
 	return status.State.Terminated.FinishedAt.Time.Before(ttlExpire)
 }
 
+func getTerminatedState(pod *corev1.Pod) *corev1.ContainerStateTerminated {
+	status, _ := getTaskContainerStatus(pod)
+
+	return status.State.Terminated
+}
+
+// All runs of a scheduled task share its GUID, so the job of a pod is the one
+// controlling it.
+func getJobOfPod(pod *corev1.Pod, jobs []batchv1.Job) *batchv1.Job {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return &jobs[0]
+	}
+
+	for i := range jobs {
+		if jobs[i].UID == owner.UID {
+			return &jobs[i]
+		}
+	}
+
+	return &jobs[0]
+}
+
+// Each attempt of a task runs in a job of its own, so the attempt of the job
+// is the latest unless another job of the same run has a later one.
+func isLatestAttempt(job *batchv1.Job, jobs []batchv1.Job) bool {
+	for i := range jobs {
+		if k8s.TaskRun(jobs[i]) == k8s.TaskRun(*job) && k8s.TaskAttempt(&jobs[i]) > k8s.TaskAttempt(job) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func parseIntOrZero(s string) int {
 	value, err := strconv.Atoi(s)
 	if err != nil {
@@ -3,6 +3,7 @@ package task_test
 import (
 	"context"
 	"errors"
+	"reflect"
 	"time"
 
 	"code.cloudfoundry.org/eirini/k8s"
@@ -14,6 +15,7 @@ import (
 	. "github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,6 +37,7 @@ var _ = Describe("Task Completion Reconciler", func() {
 		reconciler    *task.Reconciler
 		pod           *corev1.Pod
 		job           batchv1.Job
+		objects       map[string]runtime.Object
 		ttl           int
 	)
 
@@ -80,12 +83,23 @@ var _ = Describe("Task Completion Reconciler", func() {
 			},
 		}
 
+		objects = map[string]runtime.Object{}
 		runtimeClient.GetStub = func(c context.Context, nn k8stypes.NamespacedName, o runtime.Object) error {
-			p := o.(*corev1.Pod)
-			pod.DeepCopyInto(p)
-			pod = p
+			if p, ok := o.(*corev1.Pod); ok {
+				pod.DeepCopyInto(p)
+				pod = p
 
-			p.Labels[k8s.LabelGUID] = nn.Name + "-guid"
+				p.Labels[k8s.LabelGUID] = nn.Name + "-guid"
+
+				return nil
+			}
+
+			obj, ok := objects[nn.Name]
+			if !ok || reflect.TypeOf(obj) != reflect.TypeOf(o) {
+				return apierrors.NewNotFound(schema.GroupResource{}, nn.Name)
+			}
+
+			reflect.ValueOf(o).Elem().Set(reflect.ValueOf(obj.DeepCopyObject()).Elem())
 
 			return nil
 		}
@@ -127,7 +141,9 @@ var _ = Describe("Task Completion Reconciler", func() {
 
 	It("reports the task pod", func() {
 		Expect(taskReporter.ReportCallCount()).To(Equal(1))
-		Expect(taskReporter.ReportArgsForCall(0).Name).To(Equal(pod.Name))
+		reportedPod, attempts := taskReporter.ReportArgsForCall(0)
+		Expect(reportedPod.Name).To(Equal(pod.Name))
+		Expect(attempts).To(Equal(1))
 		Expect(podsClient.SetAnnotationCallCount()).To(Equal(1))
 		actualPod, key, value := podsClient.SetAnnotationArgsForCall(0)
 		Expect(actualPod).To(Equal(pod))
@@ -147,6 +163,382 @@ var _ = Describe("Task Completion Reconciler", func() {
 		Expect(value).To(Equal(k8s.TaskCompletedTrue))
 	})
 
+	It("does not create another attempt of a task without retries", func() {
+		Expect(jobsClient.CreateCallCount()).To(BeZero())
+	})
+
+	When("the task can be retried", func() {
+		BeforeEach(func() {
+			job.Name = "the-job"
+			job.Namespace = "space"
+			job.Annotations = map[string]string{
+				k8s.AnnotationTaskAttempt:             "1",
+				k8s.AnnotationTaskMaxAttempts:         "3",
+				k8s.AnnotationTaskRetryBackoffSeconds: "30",
+			}
+			job.Spec.Template.Annotations = map[string]string{k8s.AnnotationTaskAttempt: "1"}
+			jobsClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+
+			pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = 1
+			pod.Status.ContainerStatuses[0].State.Terminated.FinishedAt = metav1.NewTime(time.Now().Add(-10 * time.Second))
+		})
+
+		When("the failed attempt is retried", func() {
+			It("waits until the backoff has passed", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+				Expect(reconcileRes.RequeueAfter).To(BeNumerically("~", 20*time.Second, time.Second))
+			})
+
+			It("does not report the task", func() {
+				Expect(taskReporter.ReportCallCount()).To(BeZero())
+				Expect(jobsClient.SetLabelCallCount()).To(BeZero())
+				Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+			})
+
+			When("the backoff has passed", func() {
+				BeforeEach(func() {
+					pod.Status.ContainerStatuses[0].State.Terminated.FinishedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+					jobsClient.CreateReturns(&batchv1.Job{
+						ObjectMeta: metav1.ObjectMeta{Name: "the-job-2", Namespace: "space", UID: "next-attempt-uid"},
+					}, nil)
+				})
+
+				It("creates the job of the next attempt", func() {
+					Expect(reconcileErr).NotTo(HaveOccurred())
+					Expect(jobsClient.CreateCallCount()).To(Equal(1))
+					namespace, nextJob := jobsClient.CreateArgsForCall(0)
+					Expect(namespace).To(Equal("space"))
+					Expect(nextJob.Name).To(Equal("the-job-2"))
+					Expect(k8s.TaskAttempt(nextJob)).To(Equal(2))
+					Expect(reconcileRes.IsZero()).To(BeTrue())
+				})
+
+				It("labels the job of the failed attempt as completed", func() {
+					Expect(jobsClient.SetLabelCallCount()).To(Equal(1))
+					labelledJob, label, value := jobsClient.SetLabelArgsForCall(0)
+					Expect(labelledJob.Name).To(Equal("the-job"))
+					Expect(label).To(Equal(k8s.LabelTaskCompleted))
+					Expect(value).To(Equal(k8s.TaskCompletedTrue))
+				})
+
+				It("does not report the task", func() {
+					Expect(taskReporter.ReportCallCount()).To(BeZero())
+					Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+				})
+
+				When("the attempts share resources", func() {
+					firstAttemptOwner := metav1.OwnerReference{APIVersion: "batch/v1", Kind: "Job", Name: "the-job", UID: "first-attempt-uid"}
+
+					BeforeEach(func() {
+						job.Spec.Template.Spec.Containers = []corev1.Container{
+							{
+								Name: "opi-task",
+								Env: []corev1.EnvVar{
+									{
+										Name: "VCAP_SERVICES",
+										ValueFrom: &corev1.EnvVarSource{
+											SecretKeyRef: &corev1.SecretKeySelector{
+												LocalObjectReference: corev1.LocalObjectReference{Name: "the-job-env"},
+												Key:                  "VCAP_SERVICES",
+											},
+										},
+									},
+								},
+							},
+						}
+						jobsClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+
+						objects["the-job"] = &networkingv1.NetworkPolicy{
+							ObjectMeta: metav1.ObjectMeta{Name: "the-job", Namespace: "space", OwnerReferences: []metav1.OwnerReference{firstAttemptOwner}},
+						}
+						objects["the-job-env"] = &corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{Name: "the-job-env", Namespace: "space", OwnerReferences: []metav1.OwnerReference{firstAttemptOwner}},
+						}
+					})
+
+					It("makes the job of the next attempt own them too", func() {
+						Expect(reconcileErr).NotTo(HaveOccurred())
+						Expect(runtimeClient.UpdateCallCount()).To(Equal(2))
+
+						nextAttemptOwner := metav1.OwnerReference{APIVersion: "batch/v1", Kind: "Job", Name: "the-job-2", UID: "next-attempt-uid"}
+						for i := 0; i < 2; i++ {
+							_, obj, _ := runtimeClient.UpdateArgsForCall(i)
+							Expect(obj.(metav1.Object).GetOwnerReferences()).To(ConsistOf(firstAttemptOwner, nextAttemptOwner))
+						}
+					})
+
+					When("the next attempt already owns them", func() {
+						BeforeEach(func() {
+							nextAttemptOwner := metav1.OwnerReference{Kind: "Job", Name: "the-job-2", UID: "next-attempt-uid"}
+							objects["the-job"].(metav1.Object).SetOwnerReferences([]metav1.OwnerReference{firstAttemptOwner, nextAttemptOwner})
+							objects["the-job-env"].(metav1.Object).SetOwnerReferences([]metav1.OwnerReference{firstAttemptOwner, nextAttemptOwner})
+						})
+
+						It("leaves them alone", func() {
+							Expect(reconcileErr).NotTo(HaveOccurred())
+							Expect(runtimeClient.UpdateCallCount()).To(BeZero())
+						})
+					})
+
+					When("updating the owners fails", func() {
+						BeforeEach(func() {
+							runtimeClient.UpdateReturns(errors.New("boom"))
+						})
+
+						It("returns the error", func() {
+							Expect(reconcileErr).To(MatchError(ContainSubstring("failed to make the next attempt own the task resources")))
+							Expect(jobsClient.SetLabelCallCount()).To(BeZero())
+						})
+					})
+				})
+
+				When("the job of the next attempt already exists", func() {
+					BeforeEach(func() {
+						jobsClient.CreateReturns(nil, apierrors.NewAlreadyExists(schema.GroupResource{}, "the-job-2"))
+						objects["the-job-2"] = &batchv1.Job{
+							ObjectMeta: metav1.ObjectMeta{Name: "the-job-2", Namespace: "space", UID: "existing-uid"},
+						}
+						objects["the-job"] = &networkingv1.NetworkPolicy{
+							ObjectMeta: metav1.ObjectMeta{Name: "the-job", Namespace: "space"},
+						}
+					})
+
+					It("labels the job of the failed attempt as completed", func() {
+						Expect(reconcileErr).NotTo(HaveOccurred())
+						Expect(jobsClient.SetLabelCallCount()).To(Equal(1))
+					})
+
+					It("makes the existing job own the shared resources", func() {
+						Expect(runtimeClient.UpdateCallCount()).To(Equal(1))
+						_, obj, _ := runtimeClient.UpdateArgsForCall(0)
+						Expect(obj.(metav1.Object).GetOwnerReferences()).To(ConsistOf(
+							metav1.OwnerReference{APIVersion: "batch/v1", Kind: "Job", Name: "the-job-2", UID: "existing-uid"},
+						))
+					})
+				})
+
+				When("creating the job of the next attempt fails", func() {
+					BeforeEach(func() {
+						jobsClient.CreateReturns(nil, errors.New("boom"))
+					})
+
+					It("returns the error", func() {
+						Expect(reconcileErr).To(MatchError(ContainSubstring("failed to create the next attempt: boom")))
+						Expect(jobsClient.SetLabelCallCount()).To(BeZero())
+					})
+				})
+			})
+		})
+
+		When("the exit code is not one to retry on", func() {
+			BeforeEach(func() {
+				job.Annotations[k8s.AnnotationTaskRetryExitCodes] = "[75]"
+				jobsClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+			})
+
+			It("does not retry the task", func() {
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("reports the task with the number of attempts", func() {
+				Expect(taskReporter.ReportCallCount()).To(Equal(1))
+				_, attempts := taskReporter.ReportArgsForCall(0)
+				Expect(attempts).To(Equal(1))
+			})
+		})
+
+		When("the attempt has exceeded the task timeout", func() {
+			BeforeEach(func() {
+				pod.Status.Reason = k8s.PodDeadlineExceededReason
+				pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = 137
+			})
+
+			It("does not retry the task", func() {
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("reports the task", func() {
+				Expect(taskReporter.ReportCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the last attempt has failed", func() {
+			BeforeEach(func() {
+				firstAttempt := job
+				secondAttempt := *k8s.NextTaskAttempt(firstAttempt)
+				lastAttempt := *k8s.NextTaskAttempt(secondAttempt)
+				lastAttempt.UID = "last-attempt-uid"
+				jobsClient.GetByGUIDReturns([]batchv1.Job{secondAttempt, lastAttempt, firstAttempt}, nil)
+
+				isController := true
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{Kind: "Job", Name: lastAttempt.Name, UID: lastAttempt.UID, Controller: &isController},
+				}
+			})
+
+			It("does not retry the task", func() {
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("reports the task with the number of attempts", func() {
+				Expect(taskReporter.ReportCallCount()).To(Equal(1))
+				_, attempts := taskReporter.ReportArgsForCall(0)
+				Expect(attempts).To(Equal(3))
+			})
+		})
+
+		When("the pod is not of the latest attempt", func() {
+			BeforeEach(func() {
+				firstAttempt := job
+				firstAttempt.UID = "first-attempt-uid"
+				jobsClient.GetByGUIDReturns([]batchv1.Job{firstAttempt, *k8s.NextTaskAttempt(firstAttempt)}, nil)
+
+				isController := true
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{Kind: "Job", Name: firstAttempt.Name, UID: firstAttempt.UID, Controller: &isController},
+				}
+			})
+
+			It("does nothing", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+				Expect(taskReporter.ReportCallCount()).To(BeZero())
+				Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	When("pulling the task image has failed once only", func() {
+		BeforeEach(func() {
+			now := metav1.Now()
+			pod.Status.StartTime = &now
+			pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "i/o timeout"},
+			}
+		})
+
+		It("does not fail the task", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(taskReporter.ReportCallCount()).To(BeZero())
+			Expect(jobsClient.SetLabelCallCount()).To(BeZero())
+			Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+		})
+
+		It("checks the pod again once the grace period is over", func() {
+			Expect(reconcileRes.RequeueAfter).To(BeNumerically("~", k8s.TaskStartGracePeriod, time.Second))
+		})
+	})
+
+	When("the task pod will never start", func() {
+		BeforeEach(func() {
+			pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"},
+			}
+		})
+
+		It("reports the task pod", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(taskReporter.ReportCallCount()).To(Equal(1))
+			_, attempts := taskReporter.ReportArgsForCall(0)
+			Expect(attempts).To(Equal(1))
+		})
+
+		It("labels the task as completed", func() {
+			Expect(jobsClient.SetLabelCallCount()).To(Equal(1))
+			_, label, value := jobsClient.SetLabelArgsForCall(0)
+			Expect(label).To(Equal(k8s.LabelTaskCompleted))
+			Expect(value).To(Equal(k8s.TaskCompletedTrue))
+		})
+
+		It("deletes the task without waiting for the TTL", func() {
+			Expect(taskDeleter.DeleteCallCount()).To(Equal(1))
+			Expect(taskDeleter.DeleteArgsForCall(0)).To(Equal("the-task-pod-guid"))
+			Expect(reconcileRes.IsZero()).To(BeTrue())
+		})
+
+		When("deleting the task fails", func() {
+			BeforeEach(func() {
+				taskDeleter.DeleteReturns("", errors.New("delete-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(reconcileErr).To(MatchError(ContainSubstring("delete-error")))
+			})
+		})
+
+		When("the task can be retried", func() {
+			BeforeEach(func() {
+				job.Annotations = map[string]string{k8s.AnnotationTaskMaxAttempts: "3"}
+				jobsClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+			})
+
+			It("does not retry it", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(jobsClient.CreateCallCount()).To(BeZero())
+				Expect(taskReporter.ReportCallCount()).To(Equal(1))
+				Expect(taskDeleter.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the task pod is a run of a scheduled task", func() {
+			BeforeEach(func() {
+				job.Name = "scheduled-run"
+				job.Namespace = "space"
+				job.Labels[k8s.LabelScheduledTask] = k8s.ScheduledTaskTrue
+				jobsClient.GetByGUIDReturns([]batchv1.Job{job}, nil)
+			})
+
+			It("deletes the job of the run only", func() {
+				Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+				Expect(jobsClient.DeleteCallCount()).To(Equal(1))
+				namespace, name := jobsClient.DeleteArgsForCall(0)
+				Expect(namespace).To(Equal("space"))
+				Expect(name).To(Equal("scheduled-run"))
+			})
+		})
+	})
+
+	When("the pod is a run of a scheduled task", func() {
+		var scheduledRun batchv1.Job
+
+		BeforeEach(func() {
+			scheduledRun = batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scheduled-run",
+					UID:  "scheduled-run-uid",
+					Labels: map[string]string{
+						k8s.LabelScheduledTask: k8s.ScheduledTaskTrue,
+					},
+				},
+			}
+			jobsClient.GetByGUIDReturns([]batchv1.Job{job, scheduledRun}, nil)
+
+			isController := true
+			pod.OwnerReferences = []metav1.OwnerReference{
+				{Kind: "Job", Name: "scheduled-run", UID: "scheduled-run-uid", Controller: &isController},
+			}
+		})
+
+		It("reports the task pod", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(taskReporter.ReportCallCount()).To(Equal(1))
+		})
+
+		It("labels the run owning the pod as completed", func() {
+			Expect(jobsClient.SetLabelCallCount()).To(Equal(1))
+			labelledJob, label, value := jobsClient.SetLabelArgsForCall(0)
+			Expect(labelledJob.Name).To(Equal("scheduled-run"))
+			Expect(label).To(Equal(k8s.LabelTaskCompleted))
+			Expect(value).To(Equal(k8s.TaskCompletedTrue))
+		})
+
+		It("leaves deleting the run to the cron job", func() {
+			Expect(taskDeleter.DeleteCallCount()).To(BeZero())
+			Expect(reconcileRes.IsZero()).To(BeTrue())
+		})
+	})
+
 	When("TTL has not yet expired", func() {
 		BeforeEach(func() {
 			pod.Status.ContainerStatuses[0].State.Terminated.FinishedAt = metav1.NewTime(time.Now())
@@ -154,7 +546,8 @@ var _ = Describe("Task Completion Reconciler", func() {
 
 		It("notifies CC, but does not delete yet", func() {
 			Expect(taskReporter.ReportCallCount()).To(Equal(1))
-			Expect(taskReporter.ReportArgsForCall(0).Name).To(Equal(pod.Name))
+			reportedPod, _ := taskReporter.ReportArgsForCall(0)
+			Expect(reportedPod.Name).To(Equal(pod.Name))
 
 			Expect(taskDeleter.DeleteCallCount()).To(Equal(0))
 
@@ -16,7 +16,7 @@ type StateReporter struct {
 	Logger lager.Logger
 }
 
-func (r StateReporter) Report(pod *corev1.Pod) error {
+func (r StateReporter) Report(pod *corev1.Pod, attempts int) error {
 	taskGUID := pod.Annotations[k8s.AnnotationGUID]
 	uri := pod.Annotations[k8s.AnnotationCompletionCallback]
 
@@ -24,6 +24,7 @@ func (r StateReporter) Report(pod *corev1.Pod) error {
 
 	logger.Debug("sending completion notification")
 	req := r.generateTaskCompletedRequest(logger, taskGUID, pod)
+	req.Attempts = attempts
 
 	if err := utils.Post(r.Client, uri, req); err != nil {
 		logger.Error("cannot-send-task-status-response", err)
@@ -38,22 +39,61 @@ func (r StateReporter) generateTaskCompletedRequest(logger lager.Logger, guid st
 	res := cf.TaskCompletedRequest{
 		TaskGUID: guid,
 	}
+
+	if reason, ok := k8s.TaskStartFailureReason(pod); ok {
+		res.Failed = true
+		res.FailureReason = reason
+
+		logger.Error("job-failed-to-start", nil, lager.Data{"failure-reason": reason})
+
+		return res
+	}
+
 	taskContainerStatus, _ := getTaskContainerStatus(pod)
 	terminated := taskContainerStatus.State.Terminated
 
+	if pod.Status.Reason == k8s.PodDeadlineExceededReason {
+		res.Failed = true
+		res.FailureReason = k8s.TaskFailureReason(pod, terminated)
+
+		logger.Error("job-timed-out", nil, lager.Data{"failure-message": pod.Status.Message})
+
+		return res
+	}
+
 	if terminated.ExitCode != 0 {
 		res.Failed = true
-		res.FailureReason = terminated.Reason
+		res.FailureReason = k8s.TaskFailureReason(pod, terminated)
 
 		logger.Error("job-failed", nil, lager.Data{
 			"failure-reason":  terminated.Reason,
 			"failure-message": terminated.Message,
 		})
+
+		return res
+	}
+
+	if hasResultFile(pod) {
+		res.Result = terminated.Message
 	}
 
 	return res
 }
 
+// hasResultFile tells whether the task container writes a result file. Only
+// then does its termination message hold the result of the task.
+func hasResultFile(pod *corev1.Pod) bool {
+	taskContainerName := pod.Annotations[k8s.AnnotationOpiTaskContainerName]
+	for _, container := range pod.Spec.Containers {
+		if container.Name == taskContainerName {
+			return container.TerminationMessagePath != "" &&
+				container.TerminationMessagePath != corev1.TerminationMessagePathDefault
+		}
+	}
+
+	return false
+}
+
 func getTaskContainerStatus(pod *corev1.Pod) (corev1.ContainerStatus, bool) {
 	taskContainerName := pod.Annotations[k8s.AnnotationOpiTaskContainerName]
 	for _, status := range pod.Status.ContainerStatuses {
@@ -22,6 +22,7 @@ var _ = Describe("Reporter", func() {
 		logger   *lagertest.TestLogger
 		pod      *corev1.Pod
 		handlers []http.HandlerFunc
+		attempts int
 		err      error
 	)
 
@@ -56,12 +57,14 @@ var _ = Describe("Reporter", func() {
 
 	BeforeEach(func() {
 		logger = lagertest.NewTestLogger("task-reporter-test")
+		attempts = 1
 
 		server = ghttp.NewServer()
 		handlers = []http.HandlerFunc{
 			ghttp.VerifyRequest("POST", "/the-callback-url"),
 			ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
 				TaskGUID: "the-task-guid",
+				Attempts: 1,
 			}),
 		}
 
@@ -82,7 +85,7 @@ var _ = Describe("Reporter", func() {
 			ghttp.CombineHandlers(handlers...),
 		)
 
-		err = reporter.Report(pod)
+		err = reporter.Report(pod, attempts)
 	})
 
 	AfterEach(func() {
@@ -121,8 +124,9 @@ var _ = Describe("Reporter", func() {
 				ghttp.VerifyRequest("POST", "/the-callback-url"),
 				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
 					TaskGUID:      "the-task-guid",
+					Attempts:      1,
 					Failed:        true,
-					FailureReason: "because",
+					FailureReason: "because (exit code 42)",
 				}),
 			}
 		})
@@ -133,6 +137,187 @@ var _ = Describe("Reporter", func() {
 
 	})
 
+	When("the task container was killed for running out of memory", func() {
+		BeforeEach(func() {
+			pod = createPod(corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 137,
+					Reason:   "OOMKilled",
+					Message:  "memory hog\n",
+				},
+			})
+
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID:      "the-task-guid",
+					Attempts:      1,
+					Failed:        true,
+					FailureReason: "OOMKilled (exit code 137): memory hog",
+				}),
+			}
+		})
+
+		It("includes the exit code and the termination message in the failure reason", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the task image cannot be pulled", func() {
+		BeforeEach(func() {
+			pod = createPod(corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ErrImagePull",
+					Message: "manifest unknown",
+				},
+			})
+
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID:      "the-task-guid",
+					Attempts:      1,
+					Failed:        true,
+					FailureReason: "ErrImagePull: manifest unknown",
+				}),
+			}
+		})
+
+		It("reports the task as failed", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the task pod cannot be scheduled", func() {
+		BeforeEach(func() {
+			pod = createPod(corev1.ContainerState{})
+			pod.Status.ContainerStatuses = nil
+			pod.Status.Conditions = []corev1.PodCondition{
+				{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/1 nodes are available: 1 Insufficient cpu.",
+				},
+			}
+
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID:      "the-task-guid",
+					Attempts:      1,
+					Failed:        true,
+					FailureReason: "Unschedulable: 0/1 nodes are available: 1 Insufficient cpu.",
+				}),
+			}
+		})
+
+		It("reports the task as failed", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the task pod exceeded its deadline", func() {
+		BeforeEach(func() {
+			pod = createPod(corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 137,
+					Reason:   "Error",
+				},
+			})
+			pod.Status.Phase = corev1.PodFailed
+			pod.Status.Reason = "DeadlineExceeded"
+
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID:      "the-task-guid",
+					Attempts:      1,
+					Failed:        true,
+					FailureReason: "DeadlineExceeded (exit code 137): task exceeded its timeout",
+				}),
+			}
+		})
+
+		It("reports the timeout to the cloud controller", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the task container writes a result file", func() {
+		BeforeEach(func() {
+			pod.Spec.Containers = []corev1.Container{
+				{Name: "opi-task", TerminationMessagePath: "/tmp/result.json"},
+				{Name: "some-sidecar"},
+			}
+			pod.Status.ContainerStatuses[0].State.Terminated.Message = `{"answer": 42}`
+
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID: "the-task-guid",
+					Attempts: 1,
+					Result:   `{"answer": 42}`,
+				}),
+			}
+		})
+
+		It("includes the result", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		When("the task container failed", func() {
+			BeforeEach(func() {
+				pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = 1
+				pod.Status.ContainerStatuses[0].State.Terminated.Reason = "Error"
+
+				handlers = []http.HandlerFunc{
+					ghttp.VerifyRequest("POST", "/the-callback-url"),
+					ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+						TaskGUID:      "the-task-guid",
+						Attempts:      1,
+						Failed:        true,
+						FailureReason: `Error (exit code 1): {"answer": 42}`,
+					}),
+				}
+			})
+
+			It("does not include the result", func() {
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	When("the task container has a termination message but no result file", func() {
+		BeforeEach(func() {
+			pod.Spec.Containers = []corev1.Container{
+				{Name: "opi-task", TerminationMessagePath: corev1.TerminationMessagePathDefault},
+			}
+			pod.Status.ContainerStatuses[0].State.Terminated.Message = "bye"
+		})
+
+		It("does not include the result", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the task has been attempted several times", func() {
+		BeforeEach(func() {
+			attempts = 3
+			handlers = []http.HandlerFunc{
+				ghttp.VerifyRequest("POST", "/the-callback-url"),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+					TaskGUID: "the-task-guid",
+					Attempts: 3,
+				}),
+			}
+		})
+
+		It("includes the number of attempts", func() {
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
 	When("the cloud controller returns an unexpected status code", func() {
 		BeforeEach(func() {
 			server.Reset()
@@ -0,0 +1,132 @@
+package instanceid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"code.cloudfoundry.org/eirini"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const serialNumberBits = 128
+
+// An Identity describes the app instance a certificate is issued for. The
+// GUIDs end up in the organizational units of the certificate subject, in
+// the same format Diego uses.
+type Identity struct {
+	InstanceName string
+	OrgGUID      string
+	SpaceGUID    string
+	AppGUID      string
+}
+
+type Credentials struct {
+	CertPEM  []byte
+	KeyPEM   []byte
+	NotAfter time.Time
+}
+
+type CA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     crypto.Signer
+}
+
+func NewCA(certPEM, keyPEM []byte) (*CA, error) {
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse instance identity CA")
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse instance identity CA certificate")
+	}
+
+	if !cert.IsCA {
+		return nil, errors.New("instance identity CA certificate is not a CA")
+	}
+
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("instance identity CA key cannot sign certificates")
+	}
+
+	return &CA{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		key:     key,
+	}, nil
+}
+
+// NewCAFromSecret loads the CA from the tls.crt and tls.key entries of a
+// TLS secret.
+func NewCAFromSecret(secret *corev1.Secret) (*CA, error) {
+	return NewCA(secret.Data[eirini.TLSSecretCert], secret.Data[eirini.TLSSecretKey])
+}
+
+// Issue creates a new key and a client and server certificate for the
+// identity, valid from now for the given duration. The CA certificate is
+// appended to the returned certificate so that peers only need to trust the
+// CA.
+func (c *CA) Issue(identity Identity, now time.Time, validity time.Duration) (Credentials, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Credentials{}, errors.Wrap(err, "failed to generate instance key")
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return Credentials{}, errors.Wrap(err, "failed to generate serial number")
+	}
+
+	notAfter := now.Add(validity)
+	if notAfter.After(c.cert.NotAfter) {
+		notAfter = c.cert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: identity.InstanceName,
+			OrganizationalUnit: []string{
+				fmt.Sprintf("organization:%s", identity.OrgGUID),
+				fmt.Sprintf("space:%s", identity.SpaceGUID),
+				fmt.Sprintf("app:%s", identity.AppGUID),
+			},
+		},
+		DNSNames:    []string{identity.InstanceName},
+		NotBefore:   now.Add(-time.Minute),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	if err != nil {
+		return Credentials{}, errors.Wrap(err, "failed to create instance certificate")
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return Credentials{}, errors.Wrap(err, "failed to marshal instance key")
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+
+	return Credentials{
+		CertPEM:  append(certPEM, c.certPEM...),
+		KeyPEM:   pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		NotAfter: notAfter,
+	}, nil
+}
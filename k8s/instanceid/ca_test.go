@@ -0,0 +1,108 @@
+package instanceid_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("CA", func() {
+	var (
+		caCertPEM, caKeyPEM []byte
+		caNotAfter          time.Time
+		ca                  *instanceid.CA
+		identity            instanceid.Identity
+		now                 time.Time
+		credentials         instanceid.Credentials
+		issueErr            error
+	)
+
+	BeforeEach(func() {
+		caNotAfter = time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		caCertPEM, caKeyPEM = generateCA(true, caNotAfter)
+		now = time.Now().Truncate(time.Second)
+		identity = instanceid.Identity{
+			InstanceName: "app-space-abcd-0",
+			OrgGUID:      "org-guid",
+			SpaceGUID:    "space-guid",
+			AppGUID:      "app-guid",
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		ca, err = instanceid.NewCAFromSecret(&corev1.Secret{
+			Data: map[string][]byte{
+				"tls.crt": caCertPEM,
+				"tls.key": caKeyPEM,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		credentials, issueErr = ca.Issue(identity, now, time.Hour)
+	})
+
+	It("issues a certificate for the identity", func() {
+		Expect(issueErr).NotTo(HaveOccurred())
+
+		certs := parseCertificates(credentials.CertPEM)
+		Expect(certs).To(HaveLen(2))
+
+		cert := certs[0]
+		Expect(cert.Subject.CommonName).To(Equal("app-space-abcd-0"))
+		Expect(cert.Subject.OrganizationalUnit).To(ConsistOf("organization:org-guid", "space:space-guid", "app:app-guid"))
+		Expect(cert.DNSNames).To(ConsistOf("app-space-abcd-0"))
+		Expect(cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth))
+		Expect(cert.NotAfter).To(BeTemporally("==", now.Add(time.Hour)))
+		Expect(credentials.NotAfter).To(BeTemporally("==", now.Add(time.Hour)))
+	})
+
+	It("signs the certificate with the CA", func() {
+		certs := parseCertificates(credentials.CertPEM)
+		roots := x509.NewCertPool()
+		roots.AddCert(certs[1])
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:       roots,
+			CurrentTime: now,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns a key matching the certificate", func() {
+		_, err := tls.X509KeyPair(credentials.CertPEM, credentials.KeyPEM)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the CA expires before the requested validity", func() {
+		BeforeEach(func() {
+			now = caNotAfter.Add(-time.Minute)
+		})
+
+		It("does not outlive the CA", func() {
+			Expect(issueErr).NotTo(HaveOccurred())
+			Expect(credentials.NotAfter).To(BeTemporally("==", caNotAfter))
+			Expect(parseCertificates(credentials.CertPEM)[0].NotAfter).To(BeTemporally("==", caNotAfter))
+		})
+	})
+
+	Describe("NewCA", func() {
+		It("fails when the certificate is not a CA", func() {
+			certPEM, keyPEM := generateCA(false, caNotAfter)
+			_, err := instanceid.NewCA(certPEM, keyPEM)
+			Expect(err).To(MatchError(ContainSubstring("is not a CA")))
+		})
+
+		It("fails when the key does not match the certificate", func() {
+			_, otherKeyPEM := generateCA(true, caNotAfter)
+			_, err := instanceid.NewCA(caCertPEM, otherKeyPEM)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse instance identity CA")))
+		})
+	})
+})
@@ -0,0 +1,5 @@
+// Package instanceid issues the instance identity credentials that Diego
+// exposes to app instances as CF_INSTANCE_CERT and CF_INSTANCE_KEY.
+package instanceid
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
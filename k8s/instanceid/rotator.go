@@ -0,0 +1,143 @@
+package instanceid
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// orphanGracePeriod is how long a secret may exist without its pod. The
+// injector stores the secret before the pod it is issued for is created.
+const orphanGracePeriod = 5 * time.Minute
+
+//counterfeiter:generate . SecretsClient
+//counterfeiter:generate . PodsClient
+//counterfeiter:generate . Issuer
+
+type SecretsClient interface {
+	List(namespace, labelSelector string) ([]corev1.Secret, error)
+	Update(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+	Delete(namespace, name string) error
+}
+
+type PodsClient interface {
+	Get(namespace, name string) (*corev1.Pod, error)
+}
+
+type Issuer interface {
+	Issue(identity Identity, now time.Time, validity time.Duration) (Credentials, error)
+}
+
+// Rotator reissues instance identity certificates that are about to expire.
+// Kubelet propagates the updated secret to the volumes of running pods. The
+// secrets are owned by the statefulset of their pod, so the rotator deletes
+// the ones left behind by pods that are gone, e.g. after scaling down.
+type Rotator struct {
+	logger      lager.Logger
+	secrets     SecretsClient
+	pods        PodsClient
+	issuer      Issuer
+	namespace   string
+	validity    time.Duration
+	renewBefore time.Duration
+}
+
+func NewRotator(
+	logger lager.Logger,
+	secrets SecretsClient,
+	pods PodsClient,
+	issuer Issuer,
+	namespace string,
+	validity time.Duration,
+	renewBefore time.Duration,
+) *Rotator {
+	return &Rotator{
+		logger:      logger,
+		secrets:     secrets,
+		pods:        pods,
+		issuer:      issuer,
+		namespace:   namespace,
+		validity:    validity,
+		renewBefore: renewBefore,
+	}
+}
+
+// Rotate renews every certificate expiring within the renewal window. A
+// certificate that cannot be read is renewed as well. Failing to renew one
+// certificate does not keep the others from being renewed.
+func (r *Rotator) Rotate() error {
+	logger := r.logger.Session("rotate")
+
+	secrets, err := r.secrets.List(r.namespace, fmt.Sprintf("%s=%s", LabelInstanceIdentity, InstanceIdentityEnabled))
+	if err != nil {
+		return errors.Wrap(err, "failed to list instance identity secrets")
+	}
+
+	now := time.Now()
+
+	var errs *multierror.Error
+
+	for i := range secrets {
+		errs = multierror.Append(errs, r.rotate(logger, &secrets[i], now))
+	}
+
+	return errs.ErrorOrNil()
+}
+
+func (r *Rotator) rotate(logger lager.Logger, secret *corev1.Secret, now time.Time) error {
+	logger = logger.WithData(lager.Data{"name": secret.Name, "namespace": secret.Namespace})
+	identity := IdentityFromSecret(secret)
+	cert, certErr := certificateFromSecret(secret)
+
+	_, err := r.pods.Get(secret.Namespace, identity.InstanceName)
+	if k8serrors.IsNotFound(err) {
+		if certErr == nil && now.Sub(cert.NotBefore) < orphanGracePeriod {
+			return nil
+		}
+
+		err = r.secrets.Delete(secret.Namespace, secret.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error("failed-to-delete-secret", err)
+
+			return errors.Wrapf(err, "failed to delete instance identity %s", secret.Name)
+		}
+
+		logger.Debug("deleted-orphaned-instance-identity")
+
+		return nil
+	}
+
+	if err != nil {
+		logger.Error("failed-to-get-pod", err)
+
+		return errors.Wrapf(err, "failed to get the pod of instance identity %s", secret.Name)
+	}
+
+	if certErr == nil && cert.NotAfter.Sub(now) > r.renewBefore {
+		return nil
+	}
+
+	credentials, err := r.issuer.Issue(identity, now, r.validity)
+	if err != nil {
+		logger.Error("failed-to-issue-certificate", err)
+
+		return errors.Wrapf(err, "failed to renew instance identity %s", secret.Name)
+	}
+
+	secret.Data = ToSecret(identity, credentials).Data
+
+	if _, err := r.secrets.Update(secret.Namespace, secret); err != nil {
+		logger.Error("failed-to-update-secret", err)
+
+		return errors.Wrapf(err, "failed to update instance identity %s", secret.Name)
+	}
+
+	logger.Debug("renewed-instance-identity", lager.Data{"not-after": credentials.NotAfter})
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package instanceid_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
+	"code.cloudfoundry.org/eirini/k8s/instanceid/instanceidfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Rotator", func() {
+	var (
+		ca             *instanceid.CA
+		secrets        *instanceidfakes.FakeSecretsClient
+		pods           *instanceidfakes.FakePodsClient
+		issuer         *instanceidfakes.FakeIssuer
+		rotator        *instanceid.Rotator
+		identity       instanceid.Identity
+		expiringSecret corev1.Secret
+		freshSecret    corev1.Secret
+		renewed        instanceid.Credentials
+		err            error
+	)
+
+	issueSecret := func(name string, issuedAt time.Time, validity time.Duration) corev1.Secret {
+		id := identity
+		id.InstanceName = name
+
+		credentials, issueErr := ca.Issue(id, issuedAt, validity)
+		Expect(issueErr).NotTo(HaveOccurred())
+
+		secret := instanceid.ToSecret(id, credentials)
+		secret.Namespace = "workloads"
+
+		return *secret
+	}
+
+	BeforeEach(func() {
+		var caErr error
+		ca, caErr = instanceid.NewCA(generateCA(true, time.Now().Add(24*time.Hour)))
+		Expect(caErr).NotTo(HaveOccurred())
+
+		identity = instanceid.Identity{
+			OrgGUID:   "org-guid",
+			SpaceGUID: "space-guid",
+			AppGUID:   "app-guid",
+		}
+		expiringSecret = issueSecret("expiring-0", time.Now(), 5*time.Minute)
+		freshSecret = issueSecret("fresh-0", time.Now(), time.Hour)
+
+		secrets = new(instanceidfakes.FakeSecretsClient)
+		secrets.ListReturns([]corev1.Secret{expiringSecret, freshSecret}, nil)
+
+		renewed = instanceid.Credentials{CertPEM: []byte("new-cert"), KeyPEM: []byte("new-key")}
+		issuer = new(instanceidfakes.FakeIssuer)
+		issuer.IssueReturns(renewed, nil)
+
+		pods = new(instanceidfakes.FakePodsClient)
+		pods.GetReturns(&corev1.Pod{}, nil)
+
+		rotator = instanceid.NewRotator(lagertest.NewTestLogger("rotator"), secrets, pods, issuer, "workloads", time.Hour, 10*time.Minute)
+	})
+
+	JustBeforeEach(func() {
+		err = rotator.Rotate()
+	})
+
+	It("lists the instance identity secrets", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secrets.ListCallCount()).To(Equal(1))
+		namespace, selector := secrets.ListArgsForCall(0)
+		Expect(namespace).To(Equal("workloads"))
+		Expect(selector).To(Equal("cloudfoundry.org/instance_identity=true"))
+	})
+
+	It("looks up the pod of each secret", func() {
+		Expect(pods.GetCallCount()).To(Equal(2))
+		namespace, name := pods.GetArgsForCall(0)
+		Expect(namespace).To(Equal("workloads"))
+		Expect(name).To(Equal("expiring-0"))
+		_, name = pods.GetArgsForCall(1)
+		Expect(name).To(Equal("fresh-0"))
+	})
+
+	It("renews only the certificates about to expire", func() {
+		Expect(issuer.IssueCallCount()).To(Equal(1))
+		actualIdentity, _, validity := issuer.IssueArgsForCall(0)
+		Expect(actualIdentity).To(Equal(instanceid.Identity{
+			InstanceName: "expiring-0",
+			OrgGUID:      "org-guid",
+			SpaceGUID:    "space-guid",
+			AppGUID:      "app-guid",
+		}))
+		Expect(validity).To(Equal(time.Hour))
+
+		Expect(secrets.UpdateCallCount()).To(Equal(1))
+		namespace, secret := secrets.UpdateArgsForCall(0)
+		Expect(namespace).To(Equal("workloads"))
+		Expect(secret.Name).To(Equal(expiringSecret.Name))
+		Expect(secret.Data).To(Equal(map[string][]byte{
+			"instance.crt": []byte("new-cert"),
+			"instance.key": []byte("new-key"),
+		}))
+	})
+
+	When("a secret does not contain a valid certificate", func() {
+		BeforeEach(func() {
+			freshSecret.Data["instance.crt"] = []byte("garbage")
+			secrets.ListReturns([]corev1.Secret{freshSecret}, nil)
+		})
+
+		It("renews it", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets.UpdateCallCount()).To(Equal(1))
+		})
+	})
+
+	When("listing the secrets fails", func() {
+		BeforeEach(func() {
+			secrets.ListReturns(nil, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("boom")))
+		})
+	})
+
+	When("the pod of a secret is gone", func() {
+		var orphanedSecret corev1.Secret
+
+		BeforeEach(func() {
+			orphanedSecret = issueSecret("orphaned-0", time.Now().Add(-time.Hour), 2*time.Hour)
+			secrets.ListReturns([]corev1.Secret{orphanedSecret, freshSecret}, nil)
+			pods.GetStub = func(_, name string) (*corev1.Pod, error) {
+				if name == "orphaned-0" {
+					return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+				}
+
+				return &corev1.Pod{}, nil
+			}
+		})
+
+		It("deletes the secret", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets.DeleteCallCount()).To(Equal(1))
+			namespace, name := secrets.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("workloads"))
+			Expect(name).To(Equal(orphanedSecret.Name))
+			Expect(issuer.IssueCallCount()).To(Equal(0))
+		})
+
+		When("the secret was issued only recently", func() {
+			BeforeEach(func() {
+				orphanedSecret = issueSecret("orphaned-0", time.Now(), 5*time.Minute)
+				secrets.ListReturns([]corev1.Secret{orphanedSecret}, nil)
+			})
+
+			It("keeps it for the pod that is being created", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secrets.DeleteCallCount()).To(Equal(0))
+				Expect(issuer.IssueCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the secret does not contain a valid certificate", func() {
+			BeforeEach(func() {
+				orphanedSecret.Data["instance.crt"] = []byte("garbage")
+				secrets.ListReturns([]corev1.Secret{orphanedSecret}, nil)
+			})
+
+			It("deletes it", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secrets.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the secret has already been deleted", func() {
+			BeforeEach(func() {
+				secrets.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, orphanedSecret.Name))
+			})
+
+			It("does not return an error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("deleting the secret fails", func() {
+			BeforeEach(func() {
+				secrets.DeleteReturns(errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to delete instance identity")))
+			})
+		})
+	})
+
+	When("getting the pod of a secret fails", func() {
+		BeforeEach(func() {
+			pods.GetReturnsOnCall(0, nil, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("failed to get the pod of instance identity")))
+			Expect(issuer.IssueCallCount()).To(Equal(0))
+			Expect(secrets.DeleteCallCount()).To(Equal(0))
+		})
+	})
+
+	When("issuing a certificate fails", func() {
+		BeforeEach(func() {
+			issuer.IssueReturns(instanceid.Credentials{}, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("failed to renew instance identity")))
+			Expect(secrets.UpdateCallCount()).To(Equal(0))
+		})
+	})
+
+	When("updating the secret fails", func() {
+		BeforeEach(func() {
+			secrets.UpdateReturns(nil, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("failed to update instance identity")))
+		})
+	})
+
+	When("renewing one of several certificates fails", func() {
+		BeforeEach(func() {
+			otherExpiringSecret := issueSecret("expiring-1", time.Now(), 5*time.Minute)
+			secrets.ListReturns([]corev1.Secret{expiringSecret, otherExpiringSecret}, nil)
+			issuer.IssueReturnsOnCall(0, instanceid.Credentials{}, errors.New("boom"))
+		})
+
+		It("renews the others", func() {
+			Expect(issuer.IssueCallCount()).To(Equal(2))
+			Expect(secrets.UpdateCallCount()).To(Equal(1))
+			_, secret := secrets.UpdateArgsForCall(0)
+			Expect(secret.Name).To(Equal("expiring-1-instance-identity"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError(ContainSubstring("failed to renew instance identity expiring-0-instance-identity")))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package instanceid
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	LabelInstanceIdentity   = "cloudfoundry.org/instance_identity"
+	AnnotationInstanceName  = "cloudfoundry.org/instance_name"
+	InstanceIdentityEnabled = "true"
+
+	CertFileName = "instance.crt"
+	KeyFileName  = "instance.key"
+	MountPath    = "/etc/cf-instance-credentials"
+	VolumeName   = "instance-identity"
+)
+
+func SecretName(instanceName string) string {
+	return fmt.Sprintf("%s-instance-identity", instanceName)
+}
+
+// ToSecret stores the credentials of an instance together with its
+// identity, so that they can be reissued without looking at the pod.
+func ToSecret(identity Identity, credentials Credentials) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: SecretName(identity.InstanceName),
+			Labels: map[string]string{
+				LabelInstanceIdentity: InstanceIdentityEnabled,
+			},
+			Annotations: map[string]string{
+				AnnotationInstanceName:  identity.InstanceName,
+				k8s.AnnotationOrgGUID:   identity.OrgGUID,
+				k8s.AnnotationSpaceGUID: identity.SpaceGUID,
+				k8s.AnnotationAppID:     identity.AppGUID,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			CertFileName: credentials.CertPEM,
+			KeyFileName:  credentials.KeyPEM,
+		},
+	}
+}
+
+func IdentityFromSecret(secret *corev1.Secret) Identity {
+	return Identity{
+		InstanceName: secret.Annotations[AnnotationInstanceName],
+		OrgGUID:      secret.Annotations[k8s.AnnotationOrgGUID],
+		SpaceGUID:    secret.Annotations[k8s.AnnotationSpaceGUID],
+		AppGUID:      secret.Annotations[k8s.AnnotationAppID],
+	}
+}
+
+func certificateFromSecret(secret *corev1.Secret) (*x509.Certificate, error) {
+	block, _ := pem.Decode(secret.Data[CertFileName])
+	if block == nil {
+		return nil, errors.New("no certificate found in instance identity secret")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+
+	return cert, errors.Wrap(err, "failed to parse instance certificate")
+}
@@ -21,3 +21,12 @@ func expectedValFrom(fieldPath string) *v1.EnvVarSource {
 		},
 	}
 }
+
+func expectedSecretKeyRef(secretName, key string) *v1.EnvVarSource {
+	return &v1.EnvVarSource{
+		SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+			Key:                  key,
+		},
+	}
+}
@@ -0,0 +1,355 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package k8sfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	v1 "k8s.io/api/networking/v1"
+)
+
+type FakeAppPolicyClient struct {
+	CreateStub        func(string, *v1.NetworkPolicy) (*v1.NetworkPolicy, error)
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		arg1 string
+		arg2 *v1.NetworkPolicy
+	}
+	createReturns struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}
+	createReturnsOnCall map[int]struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}
+	DeleteStub        func(string, string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetBySourceTypeStub        func(string) ([]v1.NetworkPolicy, error)
+	getBySourceTypeMutex       sync.RWMutex
+	getBySourceTypeArgsForCall []struct {
+		arg1 string
+	}
+	getBySourceTypeReturns struct {
+		result1 []v1.NetworkPolicy
+		result2 error
+	}
+	getBySourceTypeReturnsOnCall map[int]struct {
+		result1 []v1.NetworkPolicy
+		result2 error
+	}
+	UpdateStub        func(string, *v1.NetworkPolicy) (*v1.NetworkPolicy, error)
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 string
+		arg2 *v1.NetworkPolicy
+	}
+	updateReturns struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAppPolicyClient) Create(arg1 string, arg2 *v1.NetworkPolicy) (*v1.NetworkPolicy, error) {
+	fake.createMutex.Lock()
+	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		arg1 string
+		arg2 *v1.NetworkPolicy
+	}{arg1, arg2})
+	stub := fake.CreateStub
+	fakeReturns := fake.createReturns
+	fake.recordInvocation("Create", []interface{}{arg1, arg2})
+	fake.createMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppPolicyClient) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *FakeAppPolicyClient) CreateCalls(stub func(string, *v1.NetworkPolicy) (*v1.NetworkPolicy, error)) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = stub
+}
+
+func (fake *FakeAppPolicyClient) CreateArgsForCall(i int) (string, *v1.NetworkPolicy) {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	argsForCall := fake.createArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAppPolicyClient) CreateReturns(result1 *v1.NetworkPolicy, result2 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) CreateReturnsOnCall(i int, result1 *v1.NetworkPolicy, result2 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	if fake.createReturnsOnCall == nil {
+		fake.createReturnsOnCall = make(map[int]struct {
+			result1 *v1.NetworkPolicy
+			result2 error
+		})
+	}
+	fake.createReturnsOnCall[i] = struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) Delete(arg1 string, arg2 string) error {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeAppPolicyClient) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeAppPolicyClient) DeleteCalls(stub func(string, string) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeAppPolicyClient) DeleteArgsForCall(i int) (string, string) {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAppPolicyClient) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyClient) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceType(arg1 string) ([]v1.NetworkPolicy, error) {
+	fake.getBySourceTypeMutex.Lock()
+	ret, specificReturn := fake.getBySourceTypeReturnsOnCall[len(fake.getBySourceTypeArgsForCall)]
+	fake.getBySourceTypeArgsForCall = append(fake.getBySourceTypeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetBySourceTypeStub
+	fakeReturns := fake.getBySourceTypeReturns
+	fake.recordInvocation("GetBySourceType", []interface{}{arg1})
+	fake.getBySourceTypeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceTypeCallCount() int {
+	fake.getBySourceTypeMutex.RLock()
+	defer fake.getBySourceTypeMutex.RUnlock()
+	return len(fake.getBySourceTypeArgsForCall)
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceTypeCalls(stub func(string) ([]v1.NetworkPolicy, error)) {
+	fake.getBySourceTypeMutex.Lock()
+	defer fake.getBySourceTypeMutex.Unlock()
+	fake.GetBySourceTypeStub = stub
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceTypeArgsForCall(i int) string {
+	fake.getBySourceTypeMutex.RLock()
+	defer fake.getBySourceTypeMutex.RUnlock()
+	argsForCall := fake.getBySourceTypeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceTypeReturns(result1 []v1.NetworkPolicy, result2 error) {
+	fake.getBySourceTypeMutex.Lock()
+	defer fake.getBySourceTypeMutex.Unlock()
+	fake.GetBySourceTypeStub = nil
+	fake.getBySourceTypeReturns = struct {
+		result1 []v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) GetBySourceTypeReturnsOnCall(i int, result1 []v1.NetworkPolicy, result2 error) {
+	fake.getBySourceTypeMutex.Lock()
+	defer fake.getBySourceTypeMutex.Unlock()
+	fake.GetBySourceTypeStub = nil
+	if fake.getBySourceTypeReturnsOnCall == nil {
+		fake.getBySourceTypeReturnsOnCall = make(map[int]struct {
+			result1 []v1.NetworkPolicy
+			result2 error
+		})
+	}
+	fake.getBySourceTypeReturnsOnCall[i] = struct {
+		result1 []v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) Update(arg1 string, arg2 *v1.NetworkPolicy) (*v1.NetworkPolicy, error) {
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 string
+		arg2 *v1.NetworkPolicy
+	}{arg1, arg2})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1, arg2})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppPolicyClient) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeAppPolicyClient) UpdateCalls(stub func(string, *v1.NetworkPolicy) (*v1.NetworkPolicy, error)) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeAppPolicyClient) UpdateArgsForCall(i int) (string, *v1.NetworkPolicy) {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAppPolicyClient) UpdateReturns(result1 *v1.NetworkPolicy, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) UpdateReturnsOnCall(i int, result1 *v1.NetworkPolicy, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 *v1.NetworkPolicy
+			result2 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 *v1.NetworkPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppPolicyClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.getBySourceTypeMutex.RLock()
+	defer fake.getBySourceTypeMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAppPolicyClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ k8s.AppPolicyClient = new(FakeAppPolicyClient)
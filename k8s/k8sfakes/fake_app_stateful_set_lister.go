@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package k8sfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	v1 "k8s.io/api/apps/v1"
+)
+
+type FakeAppStatefulSetLister struct {
+	GetByAppGUIDStub        func(string) ([]v1.StatefulSet, error)
+	getByAppGUIDMutex       sync.RWMutex
+	getByAppGUIDArgsForCall []struct {
+		arg1 string
+	}
+	getByAppGUIDReturns struct {
+		result1 []v1.StatefulSet
+		result2 error
+	}
+	getByAppGUIDReturnsOnCall map[int]struct {
+		result1 []v1.StatefulSet
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUID(arg1 string) ([]v1.StatefulSet, error) {
+	fake.getByAppGUIDMutex.Lock()
+	ret, specificReturn := fake.getByAppGUIDReturnsOnCall[len(fake.getByAppGUIDArgsForCall)]
+	fake.getByAppGUIDArgsForCall = append(fake.getByAppGUIDArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetByAppGUIDStub
+	fakeReturns := fake.getByAppGUIDReturns
+	fake.recordInvocation("GetByAppGUID", []interface{}{arg1})
+	fake.getByAppGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUIDCallCount() int {
+	fake.getByAppGUIDMutex.RLock()
+	defer fake.getByAppGUIDMutex.RUnlock()
+	return len(fake.getByAppGUIDArgsForCall)
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUIDCalls(stub func(string) ([]v1.StatefulSet, error)) {
+	fake.getByAppGUIDMutex.Lock()
+	defer fake.getByAppGUIDMutex.Unlock()
+	fake.GetByAppGUIDStub = stub
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUIDArgsForCall(i int) string {
+	fake.getByAppGUIDMutex.RLock()
+	defer fake.getByAppGUIDMutex.RUnlock()
+	argsForCall := fake.getByAppGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUIDReturns(result1 []v1.StatefulSet, result2 error) {
+	fake.getByAppGUIDMutex.Lock()
+	defer fake.getByAppGUIDMutex.Unlock()
+	fake.GetByAppGUIDStub = nil
+	fake.getByAppGUIDReturns = struct {
+		result1 []v1.StatefulSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppStatefulSetLister) GetByAppGUIDReturnsOnCall(i int, result1 []v1.StatefulSet, result2 error) {
+	fake.getByAppGUIDMutex.Lock()
+	defer fake.getByAppGUIDMutex.Unlock()
+	fake.GetByAppGUIDStub = nil
+	if fake.getByAppGUIDReturnsOnCall == nil {
+		fake.getByAppGUIDReturnsOnCall = make(map[int]struct {
+			result1 []v1.StatefulSet
+			result2 error
+		})
+	}
+	fake.getByAppGUIDReturnsOnCall[i] = struct {
+		result1 []v1.StatefulSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppStatefulSetLister) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getByAppGUIDMutex.RLock()
+	defer fake.getByAppGUIDMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAppStatefulSetLister) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ k8s.AppStatefulSetLister = new(FakeAppStatefulSetLister)
@@ -0,0 +1,425 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package k8sfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"k8s.io/api/batch/v1beta1"
+)
+
+type FakeCronJobClient struct {
+	CreateStub        func(string, *v1beta1.CronJob) (*v1beta1.CronJob, error)
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		arg1 string
+		arg2 *v1beta1.CronJob
+	}
+	createReturns struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}
+	createReturnsOnCall map[int]struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}
+	DeleteStub        func(string, string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetByGUIDStub        func(string) ([]v1beta1.CronJob, error)
+	getByGUIDMutex       sync.RWMutex
+	getByGUIDArgsForCall []struct {
+		arg1 string
+	}
+	getByGUIDReturns struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}
+	getByGUIDReturnsOnCall map[int]struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}
+	ListStub        func() ([]v1beta1.CronJob, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+	}
+	listReturns struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}
+	listReturnsOnCall map[int]struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}
+	UpdateStub        func(string, *v1beta1.CronJob) (*v1beta1.CronJob, error)
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 string
+		arg2 *v1beta1.CronJob
+	}
+	updateReturns struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCronJobClient) Create(arg1 string, arg2 *v1beta1.CronJob) (*v1beta1.CronJob, error) {
+	fake.createMutex.Lock()
+	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		arg1 string
+		arg2 *v1beta1.CronJob
+	}{arg1, arg2})
+	stub := fake.CreateStub
+	fakeReturns := fake.createReturns
+	fake.recordInvocation("Create", []interface{}{arg1, arg2})
+	fake.createMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCronJobClient) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *FakeCronJobClient) CreateCalls(stub func(string, *v1beta1.CronJob) (*v1beta1.CronJob, error)) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = stub
+}
+
+func (fake *FakeCronJobClient) CreateArgsForCall(i int) (string, *v1beta1.CronJob) {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	argsForCall := fake.createArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCronJobClient) CreateReturns(result1 *v1beta1.CronJob, result2 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) CreateReturnsOnCall(i int, result1 *v1beta1.CronJob, result2 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	if fake.createReturnsOnCall == nil {
+		fake.createReturnsOnCall = make(map[int]struct {
+			result1 *v1beta1.CronJob
+			result2 error
+		})
+	}
+	fake.createReturnsOnCall[i] = struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) Delete(arg1 string, arg2 string) error {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCronJobClient) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeCronJobClient) DeleteCalls(stub func(string, string) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeCronJobClient) DeleteArgsForCall(i int) (string, string) {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCronJobClient) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCronJobClient) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCronJobClient) GetByGUID(arg1 string) ([]v1beta1.CronJob, error) {
+	fake.getByGUIDMutex.Lock()
+	ret, specificReturn := fake.getByGUIDReturnsOnCall[len(fake.getByGUIDArgsForCall)]
+	fake.getByGUIDArgsForCall = append(fake.getByGUIDArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetByGUIDStub
+	fakeReturns := fake.getByGUIDReturns
+	fake.recordInvocation("GetByGUID", []interface{}{arg1})
+	fake.getByGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCronJobClient) GetByGUIDCallCount() int {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	return len(fake.getByGUIDArgsForCall)
+}
+
+func (fake *FakeCronJobClient) GetByGUIDCalls(stub func(string) ([]v1beta1.CronJob, error)) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = stub
+}
+
+func (fake *FakeCronJobClient) GetByGUIDArgsForCall(i int) string {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	argsForCall := fake.getByGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCronJobClient) GetByGUIDReturns(result1 []v1beta1.CronJob, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	fake.getByGUIDReturns = struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) GetByGUIDReturnsOnCall(i int, result1 []v1beta1.CronJob, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	if fake.getByGUIDReturnsOnCall == nil {
+		fake.getByGUIDReturnsOnCall = make(map[int]struct {
+			result1 []v1beta1.CronJob
+			result2 error
+		})
+	}
+	fake.getByGUIDReturnsOnCall[i] = struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) List() ([]v1beta1.CronJob, error) {
+	fake.listMutex.Lock()
+	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+	}{})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
+	fake.recordInvocation("List", []interface{}{})
+	fake.listMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCronJobClient) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeCronJobClient) ListCalls(stub func() ([]v1beta1.CronJob, error)) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = stub
+}
+
+func (fake *FakeCronJobClient) ListReturns(result1 []v1beta1.CronJob, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) ListReturnsOnCall(i int, result1 []v1beta1.CronJob, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	if fake.listReturnsOnCall == nil {
+		fake.listReturnsOnCall = make(map[int]struct {
+			result1 []v1beta1.CronJob
+			result2 error
+		})
+	}
+	fake.listReturnsOnCall[i] = struct {
+		result1 []v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) Update(arg1 string, arg2 *v1beta1.CronJob) (*v1beta1.CronJob, error) {
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 string
+		arg2 *v1beta1.CronJob
+	}{arg1, arg2})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1, arg2})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCronJobClient) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeCronJobClient) UpdateCalls(stub func(string, *v1beta1.CronJob) (*v1beta1.CronJob, error)) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeCronJobClient) UpdateArgsForCall(i int) (string, *v1beta1.CronJob) {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCronJobClient) UpdateReturns(result1 *v1beta1.CronJob, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) UpdateReturnsOnCall(i int, result1 *v1beta1.CronJob, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 *v1beta1.CronJob
+			result2 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 *v1beta1.CronJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCronJobClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCronJobClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ k8s.CronJobClient = new(FakeCronJobClient)
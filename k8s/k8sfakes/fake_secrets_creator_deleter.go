@@ -1,195 +0,0 @@
-// Code generated by counterfeiter. DO NOT EDIT.
-package k8sfakes
-
-import (
-	"sync"
-
-	"code.cloudfoundry.org/eirini/k8s"
-	v1 "k8s.io/api/core/v1"
-)
-
-type FakeSecretsCreatorDeleter struct {
-	CreateStub        func(string, *v1.Secret) (*v1.Secret, error)
-	createMutex       sync.RWMutex
-	createArgsForCall []struct {
-		arg1 string
-		arg2 *v1.Secret
-	}
-	createReturns struct {
-		result1 *v1.Secret
-		result2 error
-	}
-	createReturnsOnCall map[int]struct {
-		result1 *v1.Secret
-		result2 error
-	}
-	DeleteStub        func(string, string) error
-	deleteMutex       sync.RWMutex
-	deleteArgsForCall []struct {
-		arg1 string
-		arg2 string
-	}
-	deleteReturns struct {
-		result1 error
-	}
-	deleteReturnsOnCall map[int]struct {
-		result1 error
-	}
-	invocations      map[string][][]interface{}
-	invocationsMutex sync.RWMutex
-}
-
-func (fake *FakeSecretsCreatorDeleter) Create(arg1 string, arg2 *v1.Secret) (*v1.Secret, error) {
-	fake.createMutex.Lock()
-	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
-	fake.createArgsForCall = append(fake.createArgsForCall, struct {
-		arg1 string
-		arg2 *v1.Secret
-	}{arg1, arg2})
-	stub := fake.CreateStub
-	fakeReturns := fake.createReturns
-	fake.recordInvocation("Create", []interface{}{arg1, arg2})
-	fake.createMutex.Unlock()
-	if stub != nil {
-		return stub(arg1, arg2)
-	}
-	if specificReturn {
-		return ret.result1, ret.result2
-	}
-	return fakeReturns.result1, fakeReturns.result2
-}
-
-func (fake *FakeSecretsCreatorDeleter) CreateCallCount() int {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	return len(fake.createArgsForCall)
-}
-
-func (fake *FakeSecretsCreatorDeleter) CreateCalls(stub func(string, *v1.Secret) (*v1.Secret, error)) {
-	fake.createMutex.Lock()
-	defer fake.createMutex.Unlock()
-	fake.CreateStub = stub
-}
-
-func (fake *FakeSecretsCreatorDeleter) CreateArgsForCall(i int) (string, *v1.Secret) {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	argsForCall := fake.createArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
-}
-
-func (fake *FakeSecretsCreatorDeleter) CreateReturns(result1 *v1.Secret, result2 error) {
-	fake.createMutex.Lock()
-	defer fake.createMutex.Unlock()
-	fake.CreateStub = nil
-	fake.createReturns = struct {
-		result1 *v1.Secret
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeSecretsCreatorDeleter) CreateReturnsOnCall(i int, result1 *v1.Secret, result2 error) {
-	fake.createMutex.Lock()
-	defer fake.createMutex.Unlock()
-	fake.CreateStub = nil
-	if fake.createReturnsOnCall == nil {
-		fake.createReturnsOnCall = make(map[int]struct {
-			result1 *v1.Secret
-			result2 error
-		})
-	}
-	fake.createReturnsOnCall[i] = struct {
-		result1 *v1.Secret
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeSecretsCreatorDeleter) Delete(arg1 string, arg2 string) error {
-	fake.deleteMutex.Lock()
-	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
-	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		arg1 string
-		arg2 string
-	}{arg1, arg2})
-	stub := fake.DeleteStub
-	fakeReturns := fake.deleteReturns
-	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
-	fake.deleteMutex.Unlock()
-	if stub != nil {
-		return stub(arg1, arg2)
-	}
-	if specificReturn {
-		return ret.result1
-	}
-	return fakeReturns.result1
-}
-
-func (fake *FakeSecretsCreatorDeleter) DeleteCallCount() int {
-	fake.deleteMutex.RLock()
-	defer fake.deleteMutex.RUnlock()
-	return len(fake.deleteArgsForCall)
-}
-
-func (fake *FakeSecretsCreatorDeleter) DeleteCalls(stub func(string, string) error) {
-	fake.deleteMutex.Lock()
-	defer fake.deleteMutex.Unlock()
-	fake.DeleteStub = stub
-}
-
-func (fake *FakeSecretsCreatorDeleter) DeleteArgsForCall(i int) (string, string) {
-	fake.deleteMutex.RLock()
-	defer fake.deleteMutex.RUnlock()
-	argsForCall := fake.deleteArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
-}
-
-func (fake *FakeSecretsCreatorDeleter) DeleteReturns(result1 error) {
-	fake.deleteMutex.Lock()
-	defer fake.deleteMutex.Unlock()
-	fake.DeleteStub = nil
-	fake.deleteReturns = struct {
-		result1 error
-	}{result1}
-}
-
-func (fake *FakeSecretsCreatorDeleter) DeleteReturnsOnCall(i int, result1 error) {
-	fake.deleteMutex.Lock()
-	defer fake.deleteMutex.Unlock()
-	fake.DeleteStub = nil
-	if fake.deleteReturnsOnCall == nil {
-		fake.deleteReturnsOnCall = make(map[int]struct {
-			result1 error
-		})
-	}
-	fake.deleteReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
-}
-
-func (fake *FakeSecretsCreatorDeleter) Invocations() map[string][][]interface{} {
-	fake.invocationsMutex.RLock()
-	defer fake.invocationsMutex.RUnlock()
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	fake.deleteMutex.RLock()
-	defer fake.deleteMutex.RUnlock()
-	copiedInvocations := map[string][][]interface{}{}
-	for key, value := range fake.invocations {
-		copiedInvocations[key] = value
-	}
-	return copiedInvocations
-}
-
-func (fake *FakeSecretsCreatorDeleter) recordInvocation(key string, args []interface{}) {
-	fake.invocationsMutex.Lock()
-	defer fake.invocationsMutex.Unlock()
-	if fake.invocations == nil {
-		fake.invocations = map[string][][]interface{}{}
-	}
-	if fake.invocations[key] == nil {
-		fake.invocations[key] = [][]interface{}{}
-	}
-	fake.invocations[key] = append(fake.invocations[key], args)
-}
-
-var _ k8s.SecretsCreatorDeleter = new(FakeSecretsCreatorDeleter)
@@ -0,0 +1,187 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package k8sfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+type FakeTaskPodsGetter struct {
+	GetAllStub        func() ([]v1.Pod, error)
+	getAllMutex       sync.RWMutex
+	getAllArgsForCall []struct {
+	}
+	getAllReturns struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	getAllReturnsOnCall map[int]struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	GetByTaskGUIDStub        func(string) ([]v1.Pod, error)
+	getByTaskGUIDMutex       sync.RWMutex
+	getByTaskGUIDArgsForCall []struct {
+		arg1 string
+	}
+	getByTaskGUIDReturns struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	getByTaskGUIDReturnsOnCall map[int]struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTaskPodsGetter) GetAll() ([]v1.Pod, error) {
+	fake.getAllMutex.Lock()
+	ret, specificReturn := fake.getAllReturnsOnCall[len(fake.getAllArgsForCall)]
+	fake.getAllArgsForCall = append(fake.getAllArgsForCall, struct {
+	}{})
+	stub := fake.GetAllStub
+	fakeReturns := fake.getAllReturns
+	fake.recordInvocation("GetAll", []interface{}{})
+	fake.getAllMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTaskPodsGetter) GetAllCallCount() int {
+	fake.getAllMutex.RLock()
+	defer fake.getAllMutex.RUnlock()
+	return len(fake.getAllArgsForCall)
+}
+
+func (fake *FakeTaskPodsGetter) GetAllCalls(stub func() ([]v1.Pod, error)) {
+	fake.getAllMutex.Lock()
+	defer fake.getAllMutex.Unlock()
+	fake.GetAllStub = stub
+}
+
+func (fake *FakeTaskPodsGetter) GetAllReturns(result1 []v1.Pod, result2 error) {
+	fake.getAllMutex.Lock()
+	defer fake.getAllMutex.Unlock()
+	fake.GetAllStub = nil
+	fake.getAllReturns = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTaskPodsGetter) GetAllReturnsOnCall(i int, result1 []v1.Pod, result2 error) {
+	fake.getAllMutex.Lock()
+	defer fake.getAllMutex.Unlock()
+	fake.GetAllStub = nil
+	if fake.getAllReturnsOnCall == nil {
+		fake.getAllReturnsOnCall = make(map[int]struct {
+			result1 []v1.Pod
+			result2 error
+		})
+	}
+	fake.getAllReturnsOnCall[i] = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUID(arg1 string) ([]v1.Pod, error) {
+	fake.getByTaskGUIDMutex.Lock()
+	ret, specificReturn := fake.getByTaskGUIDReturnsOnCall[len(fake.getByTaskGUIDArgsForCall)]
+	fake.getByTaskGUIDArgsForCall = append(fake.getByTaskGUIDArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetByTaskGUIDStub
+	fakeReturns := fake.getByTaskGUIDReturns
+	fake.recordInvocation("GetByTaskGUID", []interface{}{arg1})
+	fake.getByTaskGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUIDCallCount() int {
+	fake.getByTaskGUIDMutex.RLock()
+	defer fake.getByTaskGUIDMutex.RUnlock()
+	return len(fake.getByTaskGUIDArgsForCall)
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUIDCalls(stub func(string) ([]v1.Pod, error)) {
+	fake.getByTaskGUIDMutex.Lock()
+	defer fake.getByTaskGUIDMutex.Unlock()
+	fake.GetByTaskGUIDStub = stub
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUIDArgsForCall(i int) string {
+	fake.getByTaskGUIDMutex.RLock()
+	defer fake.getByTaskGUIDMutex.RUnlock()
+	argsForCall := fake.getByTaskGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUIDReturns(result1 []v1.Pod, result2 error) {
+	fake.getByTaskGUIDMutex.Lock()
+	defer fake.getByTaskGUIDMutex.Unlock()
+	fake.GetByTaskGUIDStub = nil
+	fake.getByTaskGUIDReturns = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTaskPodsGetter) GetByTaskGUIDReturnsOnCall(i int, result1 []v1.Pod, result2 error) {
+	fake.getByTaskGUIDMutex.Lock()
+	defer fake.getByTaskGUIDMutex.Unlock()
+	fake.GetByTaskGUIDStub = nil
+	if fake.getByTaskGUIDReturnsOnCall == nil {
+		fake.getByTaskGUIDReturnsOnCall = make(map[int]struct {
+			result1 []v1.Pod
+			result2 error
+		})
+	}
+	fake.getByTaskGUIDReturnsOnCall[i] = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTaskPodsGetter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getAllMutex.RLock()
+	defer fake.getAllMutex.RUnlock()
+	fake.getByTaskGUIDMutex.RLock()
+	defer fake.getByTaskGUIDMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTaskPodsGetter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ k8s.TaskPodsGetter = new(FakeTaskPodsGetter)
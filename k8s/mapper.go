@@ -2,13 +2,21 @@ package k8s
 
 import (
 	"encoding/json"
+	"strings"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/opi"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+const eiriniAnnotationPrefix = "cloudfoundry.org/"
+
+// StatefulSetToLRP rebuilds the LRP a statefulset was created from. Private
+// registry credentials are kept in a separate secret and are not part of the
+// returned LRP.
 func StatefulSetToLRP(s appsv1.StatefulSet) (*opi.LRP, error) {
 	stRoutes := s.Annotations[AnnotationRegisteredRoutes]
 
@@ -19,8 +27,40 @@ func StatefulSetToLRP(s appsv1.StatefulSet) (*opi.LRP, error) {
 		return nil, errors.Wrap(err, "failed to unmarshal uris")
 	}
 
-	ports := []int32{}
 	container := s.Spec.Template.Spec.Containers[0]
+	health := healthCheckFromProbe(container.LivenessProbe)
+
+	if healthCheck, ok := s.Annotations[AnnotationHealthCheck]; ok {
+		if err = json.Unmarshal([]byte(healthCheck), &health); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal health check")
+		}
+	}
+
+	var placementTags []string
+
+	if tags, ok := s.Annotations[AnnotationPlacementTags]; ok {
+		if err = json.Unmarshal([]byte(tags), &placementTags); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal placement tags")
+		}
+	}
+
+	var egressRules []opi.EgressRule
+
+	if rules, ok := s.Annotations[AnnotationEgressRules]; ok {
+		if err = json.Unmarshal([]byte(rules), &egressRules); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal egress rules")
+		}
+	}
+
+	var internalRoutes []string
+
+	if routes, ok := s.Annotations[AnnotationInternalRoutes]; ok {
+		if err = json.Unmarshal([]byte(routes), &internalRoutes); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal internal routes")
+		}
+	}
+
+	ports := []int32{}
 
 	for _, port := range container.Ports {
 		ports = append(ports, port.ContainerPort)
@@ -28,9 +68,14 @@ func StatefulSetToLRP(s appsv1.StatefulSet) (*opi.LRP, error) {
 
 	memory := container.Resources.Requests.Memory().ScaledValue(resource.Mega)
 	disk := container.Resources.Limits.StorageEphemeral().ScaledValue(resource.Mega)
+	cpuWeight := uint8(container.Resources.Requests.Cpu().MilliValue() / 10) //nolint:gomnd
 	volMounts := []opi.VolumeMount{}
 
 	for _, vol := range container.VolumeMounts {
+		if vol.Name == DropletVolumeName {
+			continue
+		}
+
 		volMounts = append(volMounts, opi.VolumeMount{
 			ClaimName: vol.Name,
 			MountPath: vol.MountPath,
@@ -42,18 +87,161 @@ func StatefulSetToLRP(s appsv1.StatefulSet) (*opi.LRP, error) {
 			GUID:    s.Labels[LabelGUID],
 			Version: s.Annotations[AnnotationVersion],
 		},
-		AppName:          s.Annotations[AnnotationAppName],
-		SpaceName:        s.Annotations[AnnotationSpaceName],
-		Image:            container.Image,
-		Command:          container.Command,
-		RunningInstances: int(s.Status.ReadyReplicas),
-		TargetInstances:  int(*s.Spec.Replicas),
-		Ports:            ports,
-		LastUpdated:      s.Annotations[AnnotationLastUpdated],
-		AppURIs:          uris,
-		AppGUID:          s.Annotations[AnnotationAppID],
-		MemoryMB:         memory,
-		DiskMB:           disk,
-		VolumeMounts:     volMounts,
+		ProcessType:            s.Labels[LabelProcessType],
+		AppName:                s.Annotations[AnnotationAppName],
+		AppGUID:                s.Annotations[AnnotationAppID],
+		OrgName:                s.Annotations[AnnotationOrgName],
+		OrgGUID:                s.Annotations[AnnotationOrgGUID],
+		SpaceName:              s.Annotations[AnnotationSpaceName],
+		SpaceGUID:              s.Annotations[AnnotationSpaceGUID],
+		Image:                  container.Image,
+		Command:                container.Command,
+		Sidecars:               toSidecars(s.Spec.Template.Spec.Containers[1:]),
+		DropletDownload:        toDropletDownload(s.Spec.Template.Spec.InitContainers),
+		Env:                    lrpEnv(container.Env, s.Annotations),
+		Health:                 health,
+		RunningInstances:       int(s.Status.ReadyReplicas),
+		TargetInstances:        int(*s.Spec.Replicas),
+		Ports:                  ports,
+		MemoryMB:               memory,
+		DiskMB:                 disk,
+		RunsAsRoot:             s.Spec.Template.Spec.SecurityContext == nil,
+		CPUWeight:              cpuWeight,
+		VolumeMounts:           volMounts,
+		PlacementTags:          placementTags,
+		EgressRules:            egressRules,
+		LRP:                    s.Annotations[AnnotationOriginalRequest],
+		AppURIs:                uris,
+		InternalRoutes:         internalRoutes,
+		LastUpdated:            s.Annotations[AnnotationLastUpdated],
+		UserDefinedAnnotations: userDefinedAnnotations(s.Annotations),
 	}, nil
 }
+
+// healthCheckFromProbe recovers the health check of statefulsets created
+// before it was stored in the health check annotation.
+func healthCheckFromProbe(probe *corev1.Probe) opi.Healtcheck {
+	switch {
+	case probe == nil:
+		return opi.Healtcheck{}
+	case probe.HTTPGet != nil:
+		return opi.Healtcheck{
+			Type:      "http",
+			Port:      probe.HTTPGet.Port.IntVal,
+			Endpoint:  probe.HTTPGet.Path,
+			TimeoutMs: uint(probe.InitialDelaySeconds) * 1000, //nolint:gomnd
+		}
+	case probe.TCPSocket != nil:
+		return opi.Healtcheck{
+			Type:      "port",
+			Port:      probe.TCPSocket.Port.IntVal,
+			TimeoutMs: uint(probe.InitialDelaySeconds) * 1000, //nolint:gomnd
+		}
+	default:
+		return opi.Healtcheck{}
+	}
+}
+
+func toSidecars(containers []corev1.Container) []opi.Sidecar {
+	var sidecars []opi.Sidecar
+
+	for _, c := range containers {
+		sidecars = append(sidecars, opi.Sidecar{
+			Name:     c.Name,
+			Command:  c.Command,
+			MemoryMB: c.Resources.Limits.Memory().ScaledValue(resource.Mega),
+			Env:      envVarsToMap(c.Env),
+			Ports:    toSidecarPorts(c.Ports),
+		})
+	}
+
+	return sidecars
+}
+
+func toSidecarPorts(containerPorts []corev1.ContainerPort) []int32 {
+	var ports []int32
+
+	for _, port := range containerPorts {
+		ports = append(ports, port.ContainerPort)
+	}
+
+	return ports
+}
+
+func toDropletDownload(initContainers []corev1.Container) *opi.DropletDownload {
+	for _, c := range initContainers {
+		if c.Name != DropletDownloaderContainerName {
+			continue
+		}
+
+		return &opi.DropletDownload{
+			URL:             envVarsToMap(c.Env)[eirini.EnvDownloadURL],
+			DownloaderImage: c.Image,
+		}
+	}
+
+	return nil
+}
+
+// lrpEnv returns the plain env vars of the app container. The
+// VCAP_APPLICATION in the container is built by eirini, so the one sent by
+// Cloud Controller is restored from the statefulset annotations instead.
+func lrpEnv(envVars []corev1.EnvVar, annotations map[string]string) map[string]string {
+	env := envVarsToMap(envVars)
+
+	vcapApplication, ok := annotations[AnnotationVCAPApplication]
+	if !ok {
+		return env
+	}
+
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	env[eirini.EnvVCAPApplication] = vcapApplication
+
+	return env
+}
+
+// envVarsToMap returns the plain env vars of a container, skipping the ones
+// eirini populates from the downward API and VCAP_APPLICATION, which eirini
+// builds itself.
+func envVarsToMap(envVars []corev1.EnvVar) map[string]string {
+	var env map[string]string
+
+	for _, e := range envVars {
+		if e.ValueFrom != nil || e.Name == eirini.EnvVCAPApplication {
+			continue
+		}
+
+		if env == nil {
+			env = map[string]string{}
+		}
+
+		env[e.Name] = e.Value
+	}
+
+	return env
+}
+
+func userDefinedAnnotations(annotations map[string]string) map[string]string {
+	var userDefined map[string]string
+
+	for k, v := range annotations {
+		if !isUserDefinedAnnotation(k) {
+			continue
+		}
+
+		if userDefined == nil {
+			userDefined = map[string]string{}
+		}
+
+		userDefined[k] = v
+	}
+
+	return userDefined
+}
+
+func isUserDefinedAnnotation(key string) bool {
+	return !strings.HasPrefix(key, eiriniAnnotationPrefix) && key != corev1.SeccompPodAnnotationKey
+}
@@ -1,14 +1,22 @@
 package k8s_test
 
 import (
+	"fmt"
+	"math/rand"
+
 	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/k8sfakes"
 	"code.cloudfoundry.org/eirini/opi"
+	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 var _ = Describe("Mapper", func() {
@@ -137,6 +145,47 @@ var _ = Describe("Mapper", func() {
 		}))
 	})
 
+	When("the statefulset has no health check annotation", func() {
+		It("should recover the health check from the liveness probe", func() {
+			statefulset := appsv1.StatefulSet{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: map[string]string{
+						AnnotationRegisteredRoutes: `[]`,
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: int32ptr(1),
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									LivenessProbe: &corev1.Probe{
+										Handler: corev1.Handler{
+											HTTPGet: &corev1.HTTPGetAction{
+												Path: "/healthz",
+												Port: intstr.FromInt(8080),
+											},
+										},
+										InitialDelaySeconds: 3,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			lrp, err := StatefulSetToLRP(statefulset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lrp.Health).To(Equal(opi.Healtcheck{
+				Type:      "http",
+				Port:      8080,
+				Endpoint:  "/healthz",
+				TimeoutMs: 3000,
+			}))
+		})
+	})
+
 	When("route marshalling fails", func() {
 		It("should return the error", func() {
 			statefulset := appsv1.StatefulSet{
@@ -151,3 +200,229 @@ var _ = Describe("Mapper", func() {
 		})
 	})
 })
+
+var _ = Describe("Mapper round-trip", func() {
+	var (
+		statefulSetClient *k8sfakes.FakeStatefulSetClient
+		secretsClient     *k8sfakes.FakeSecretsClient
+		serviceClient     *k8sfakes.FakeServiceClient
+		desirer           *StatefulSetDesirer
+		random            *rand.Rand
+	)
+
+	BeforeEach(func() {
+		statefulSetClient = new(k8sfakes.FakeStatefulSetClient)
+		secretsClient = new(k8sfakes.FakeSecretsClient)
+		serviceClient = new(k8sfakes.FakeServiceClient)
+		serviceClient.GetReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "service"))
+		desirer = &StatefulSetDesirer{
+			Pods:                   new(k8sfakes.FakePodClient),
+			Secrets:                secretsClient,
+			StatefulSets:           statefulSetClient,
+			PodDisruptionBudgets:   new(k8sfakes.FakePodDisruptionBudgetClient),
+			NetworkPolicies:        new(k8sfakes.FakeNetworkPolicyClient),
+			Services:               serviceClient,
+			EventsClient:           new(k8sfakes.FakeEventsClient),
+			StatefulSetToLRPMapper: StatefulSetToLRP,
+			RegistrySecretName:     "registry-secret",
+			LivenessProbeCreator:   CreateLivenessProbe,
+			ReadinessProbeCreator:  CreateReadinessProbe,
+			StartupProbeCreator:    CreateStartupProbe,
+			NodePlacements: NodePlacements{
+				"gpu":      {NodeSelector: map[string]string{"gpu": "true"}},
+				"isolated": {NodeSelector: map[string]string{"segment": "isolated"}},
+			},
+			Logger: lagertest.NewTestLogger("mapper-test"),
+		}
+		random = rand.New(rand.NewSource(GinkgoRandomSeed())) //nolint:gosec
+	})
+
+	It("should map a desired statefulset back to the original LRP", func() {
+		for i := 0; i < 100; i++ {
+			lrp := randomLRP(random)
+
+			Expect(desirer.Desire("some-namespace", lrp)).To(Succeed())
+			Expect(statefulSetClient.CreateCallCount()).To(Equal(i + 1))
+			_, statefulSet := statefulSetClient.CreateArgsForCall(i)
+
+			mappedLRP, err := StatefulSetToLRP(*statefulSet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappedLRP).To(Equal(lrp), fmt.Sprintf("iteration %d", i))
+		}
+	})
+
+	When("the app has sensitive env", func() {
+		BeforeEach(func() {
+			desirer.SensitiveEnv = SensitiveEnv{"*_PASSWORD"}
+		})
+
+		It("should get the original LRP back with the env from the secret", func() {
+			for i := 0; i < 100; i++ {
+				lrp := randomLRP(random)
+				lrp.Env = mergeEnv(lrp.Env, map[string]string{
+					"VCAP_SERVICES": fmt.Sprintf(`{"db":[{"credentials":{"password":"%d"}}]}`, random.Int63()),
+					"DB_PASSWORD":   fmt.Sprintf("%d", random.Int63()),
+				})
+				for j := range lrp.Sidecars {
+					lrp.Sidecars[j].Env = mergeEnv(lrp.Sidecars[j].Env, map[string]string{
+						"SIDECAR_PASSWORD": fmt.Sprintf("%d", random.Int63()),
+					})
+				}
+
+				Expect(desirer.Desire("some-namespace", lrp)).To(Succeed())
+				Expect(secretsClient.CreateCallCount()).To(Equal(i + 1))
+				_, secret := secretsClient.CreateArgsForCall(i)
+				_, statefulSet := statefulSetClient.CreateArgsForCall(i)
+
+				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{*statefulSet}, nil)
+				secretsClient.GetReturns(secret, nil)
+
+				gotLRP, err := desirer.Get(lrp.LRPIdentifier)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gotLRP).To(Equal(lrp), fmt.Sprintf("iteration %d", i))
+			}
+		})
+	})
+})
+
+func mergeEnv(env, extra map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range env {
+		merged[k] = v
+	}
+
+	for k, v := range extra {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+func randomLRP(random *rand.Rand) *opi.LRP {
+	str := func(prefix string) string {
+		return fmt.Sprintf("%s-%d", prefix, random.Int63())
+	}
+
+	strMap := func(prefix string) map[string]string {
+		n := random.Intn(4)
+		if n == 0 {
+			return nil
+		}
+
+		m := map[string]string{}
+		for i := 0; i < n; i++ {
+			m[str(prefix)] = str("value")
+		}
+
+		return m
+	}
+
+	ports := append([]int32{}, randomPorts(random)...)
+
+	volumeMounts := []opi.VolumeMount{}
+	for i, n := 0, random.Intn(3); i < n; i++ {
+		volumeMounts = append(volumeMounts, opi.VolumeMount{ClaimName: str("claim"), MountPath: "/" + str("path")})
+	}
+
+	var sidecars []opi.Sidecar
+	for i, n := 0, random.Intn(3); i < n; i++ {
+		sidecars = append(sidecars, opi.Sidecar{
+			Name:     str("sidecar"),
+			Command:  []string{str("cmd"), str("arg")},
+			MemoryMB: random.Int63n(4096),
+			Env:      strMap("SIDECAR_ENV"),
+			Ports:    randomPorts(random),
+		})
+	}
+
+	env := strMap("ENV")
+	if random.Intn(2) == 0 {
+		env = mergeEnv(env, map[string]string{
+			"VCAP_APPLICATION": fmt.Sprintf(`{"cf_api":"https://%s","application_uris":["%s"]}`, str("api"), str("route")),
+		})
+	}
+
+	var appURIs []opi.Route
+	for i, n := 0, random.Intn(3); i < n; i++ {
+		appURIs = append(appURIs, opi.Route{Hostname: str("host"), Port: int32(random.Intn(65535) + 1)})
+	}
+
+	var dropletDownload *opi.DropletDownload
+	if random.Intn(2) == 0 {
+		dropletDownload = &opi.DropletDownload{URL: str("https://droplet"), DownloaderImage: str("downloader")}
+	}
+
+	healthCheckTypes := []string{"http", "port", "process", "none", ""}
+
+	var placementTags []string
+	for _, tag := range []string{"gpu", "isolated"} {
+		if random.Intn(2) == 0 {
+			placementTags = append(placementTags, tag)
+		}
+	}
+
+	var egressRules []opi.EgressRule
+	for i, n := 0, random.Intn(3); i < n; i++ {
+		egressRules = append(egressRules, opi.EgressRule{
+			Protocol:     "tcp",
+			Destinations: []string{fmt.Sprintf("10.%d.0.0/16", random.Intn(256))},
+			Ports:        randomPorts(random),
+		})
+	}
+
+	var internalRoutes []string
+	for i, n := 0, random.Intn(3); i < n; i++ {
+		internalRoutes = append(internalRoutes, str("app")+".apps.internal")
+	}
+
+	return &opi.LRP{
+		LRPIdentifier: opi.LRPIdentifier{
+			GUID:    str("guid"),
+			Version: str("version"),
+		},
+		ProcessType:     str("web"),
+		AppName:         str("app"),
+		AppGUID:         str("app-guid"),
+		OrgName:         str("org"),
+		OrgGUID:         str("org-guid"),
+		SpaceName:       str("space"),
+		SpaceGUID:       str("space-guid"),
+		Image:           str("image"),
+		Command:         []string{str("cmd"), str("arg")},
+		Sidecars:        sidecars,
+		DropletDownload: dropletDownload,
+		Env:             env,
+		Health: opi.Healtcheck{
+			Type:             healthCheckTypes[random.Intn(len(healthCheckTypes))],
+			Port:             int32(random.Intn(65535) + 1),
+			Endpoint:         "/" + str("health"),
+			TimeoutMs:        uint(random.Intn(600000)),
+			StartTimeoutMs:   uint(random.Intn(600000)),
+			IntervalMs:       uint(random.Intn(60000)),
+			FailureThreshold: int32(random.Intn(10)),
+		},
+		Ports:                  ports,
+		TargetInstances:        random.Intn(10),
+		MemoryMB:               random.Int63n(16384),
+		DiskMB:                 random.Int63n(16384),
+		RunsAsRoot:             random.Intn(2) == 0,
+		CPUWeight:              uint8(random.Intn(101)),
+		VolumeMounts:           volumeMounts,
+		PlacementTags:          placementTags,
+		EgressRules:            egressRules,
+		LRP:                    str("original-request"),
+		AppURIs:                appURIs,
+		InternalRoutes:         internalRoutes,
+		LastUpdated:            str("last-updated"),
+		UserDefinedAnnotations: strMap("prometheus.io/annotation"),
+	}
+}
+
+func randomPorts(random *rand.Rand) []int32 {
+	var ports []int32
+	for i, n := 0, random.Intn(4); i < n; i++ {
+		ports = append(ports, int32(random.Intn(65535)+1))
+	}
+
+	return ports
+}
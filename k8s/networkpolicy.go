@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"strings"
+
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	minPort = 1
+	maxPort = 65535
+
+	// MaxEgressPortRange is the widest port range an egress rule may have.
+	// NetworkPolicies cannot express port ranges, so every port in the range
+	// becomes an entry of the policy.
+	MaxEgressPortRange = 1024
+)
+
+//counterfeiter:generate . NetworkPolicyClient
+
+type NetworkPolicyClient interface {
+	Create(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	Update(namespace string, networkPolicy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	Delete(namespace string, name string) error
+}
+
+// ToNetworkPolicy builds the policy that restricts the egress traffic of the
+// selected pods to the given application security group rules. NetworkPolicies
+// cannot match ICMP traffic, so ICMP rules are left out rather than opening
+// every protocol to their destinations. When no rule is left there is no
+// policy, as an egress policy without rules would deny all egress traffic.
+func ToNetworkPolicy(name string, podLabels map[string]string, rules []opi.EgressRule) (*networkingv1.NetworkPolicy, error) {
+	egress := []networkingv1.NetworkPolicyEgressRule{}
+
+	for _, rule := range rules {
+		if rule.Protocol == "icmp" {
+			continue
+		}
+
+		policyRule, err := toNetworkPolicyEgressRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		egress = append(egress, policyRule)
+	}
+
+	if len(egress) == 0 {
+		return nil, nil
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}, nil
+}
+
+func toNetworkPolicyEgressRule(rule opi.EgressRule) (networkingv1.NetworkPolicyEgressRule, error) {
+	policyRule := networkingv1.NetworkPolicyEgressRule{}
+
+	for _, destination := range rule.Destinations {
+		cidrs, err := destinationToCIDRs(destination)
+		if err != nil {
+			return networkingv1.NetworkPolicyEgressRule{}, err
+		}
+
+		for _, cidr := range cidrs {
+			policyRule.To = append(policyRule.To, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+	}
+
+	ports, err := toNetworkPolicyPorts(rule)
+	if err != nil {
+		return networkingv1.NetworkPolicyEgressRule{}, err
+	}
+
+	policyRule.Ports = ports
+
+	return policyRule, nil
+}
+
+func toNetworkPolicyPorts(rule opi.EgressRule) ([]networkingv1.NetworkPolicyPort, error) {
+	if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+		return nil, nil
+	}
+
+	protocol := corev1.Protocol(strings.ToUpper(rule.Protocol))
+	portNumbers := append([]int32{}, rule.Ports...)
+
+	if r := rule.PortRange; r != nil {
+		if r.Start < minPort || r.End > maxPort || r.Start > r.End {
+			return nil, fmt.Errorf("invalid port range %d-%d", r.Start, r.End)
+		}
+
+		if r.Start == minPort && r.End == maxPort {
+			return []networkingv1.NetworkPolicyPort{{Protocol: &protocol}}, nil
+		}
+
+		if r.End-r.Start >= MaxEgressPortRange {
+			return nil, fmt.Errorf("port range %d-%d is wider than %d ports", r.Start, r.End, MaxEgressPortRange)
+		}
+
+		for p := r.Start; p <= r.End; p++ {
+			portNumbers = append(portNumbers, p)
+		}
+	}
+
+	if len(portNumbers) == 0 {
+		return []networkingv1.NetworkPolicyPort{{Protocol: &protocol}}, nil
+	}
+
+	ports := make([]networkingv1.NetworkPolicyPort, 0, len(portNumbers))
+
+	for _, p := range portNumbers {
+		port := intstr.FromInt(int(p))
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+
+	return ports, nil
+}
+
+// destinationToCIDRs accepts CIDRs, single IPs and IPv4 ranges such as
+// "10.0.0.1-10.0.0.10". Ranges are split into the smallest set of CIDRs
+// covering them.
+func destinationToCIDRs(destination string) ([]string, error) {
+	if strings.Contains(destination, "/") {
+		_, ipNet, err := net.ParseCIDR(destination)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid egress destination %q", destination)
+		}
+
+		return []string{ipNet.String()}, nil
+	}
+
+	if strings.Contains(destination, "-") {
+		bounds := strings.SplitN(destination, "-", 2) //nolint:gomnd
+		start := net.ParseIP(strings.TrimSpace(bounds[0])).To4()
+		end := net.ParseIP(strings.TrimSpace(bounds[1])).To4()
+
+		if start == nil || end == nil || binary.BigEndian.Uint32(start) > binary.BigEndian.Uint32(end) {
+			return nil, fmt.Errorf("invalid egress destination %q", destination)
+		}
+
+		return ipRangeToCIDRs(binary.BigEndian.Uint32(start), binary.BigEndian.Uint32(end)), nil
+	}
+
+	ip := net.ParseIP(destination)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid egress destination %q", destination)
+	}
+
+	if ip.To4() != nil {
+		return []string{ip.String() + "/32"}, nil
+	}
+
+	return []string{ip.String() + "/128"}, nil
+}
+
+func ipRangeToCIDRs(start, end uint32) []string {
+	var cidrs []string
+
+	for {
+		hostBits := bits.TrailingZeros32(start)
+		for hostBits > 0 && uint64(start)+(uint64(1)<<hostBits)-1 > uint64(end) {
+			hostBits--
+		}
+
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, start)
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", ip, 32-hostBits)) //nolint:gomnd
+
+		next := uint64(start) + uint64(1)<<hostBits
+		if next > uint64(end) {
+			return cidrs
+		}
+
+		start = uint32(next)
+	}
+}
@@ -0,0 +1,185 @@
+package k8s_test
+
+import (
+	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/opi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("ToNetworkPolicy", func() {
+	var (
+		rules     []opi.EgressRule
+		policy    *networkingv1.NetworkPolicy
+		policyErr error
+	)
+
+	BeforeEach(func() {
+		rules = []opi.EgressRule{
+			{Protocol: "tcp", Destinations: []string{"10.0.0.0/24", "10.1.0.1"}, Ports: []int32{80, 443}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		policy, policyErr = ToNetworkPolicy("my-app", map[string]string{"app": "my-app"}, rules)
+	})
+
+	It("selects the pods by their labels", func() {
+		Expect(policyErr).NotTo(HaveOccurred())
+		Expect(policy.Name).To(Equal("my-app"))
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app": "my-app"}))
+		Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeEgress))
+	})
+
+	It("allows the rule ports to the rule destinations", func() {
+		tcp := corev1.ProtocolTCP
+		port80, port443 := intstr.FromInt(80), intstr.FromInt(443)
+
+		Expect(policy.Spec.Egress).To(ConsistOf(networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}},
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.1.0.1/32"}},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &port80},
+				{Protocol: &tcp, Port: &port443},
+			},
+		}))
+	})
+
+	When("the rule allows all protocols", func() {
+		BeforeEach(func() {
+			rules = []opi.EgressRule{{Protocol: "all", Destinations: []string{"0.0.0.0/0"}}}
+		})
+
+		It("does not restrict the ports", func() {
+			Expect(policy.Spec.Egress).To(HaveLen(1))
+			Expect(policy.Spec.Egress[0].Ports).To(BeEmpty())
+		})
+	})
+
+	When("the rule has no ports", func() {
+		BeforeEach(func() {
+			rules = []opi.EgressRule{{Protocol: "udp", Destinations: []string{"10.0.0.53"}}}
+		})
+
+		It("allows every port of the protocol", func() {
+			udp := corev1.ProtocolUDP
+			Expect(policy.Spec.Egress[0].Ports).To(ConsistOf(networkingv1.NetworkPolicyPort{Protocol: &udp}))
+		})
+	})
+
+	When("the rule has a port range", func() {
+		BeforeEach(func() {
+			rules = []opi.EgressRule{{Protocol: "tcp", Destinations: []string{"10.0.0.1"}, PortRange: &opi.PortRange{Start: 8080, End: 8082}}}
+		})
+
+		It("allows every port in the range", func() {
+			Expect(policy.Spec.Egress[0].Ports).To(HaveLen(3))
+			Expect(policy.Spec.Egress[0].Ports[2].Port).To(PointTo(Equal(intstr.FromInt(8082))))
+		})
+
+		When("the range covers all ports", func() {
+			BeforeEach(func() {
+				rules[0].PortRange = &opi.PortRange{Start: 1, End: 65535}
+			})
+
+			It("does not restrict the ports", func() {
+				Expect(policy.Spec.Egress[0].Ports).To(HaveLen(1))
+				Expect(policy.Spec.Egress[0].Ports[0].Port).To(BeNil())
+			})
+		})
+
+		When("the range is too wide", func() {
+			BeforeEach(func() {
+				rules[0].PortRange = &opi.PortRange{Start: 1000, End: 9000}
+			})
+
+			It("fails", func() {
+				Expect(policyErr).To(MatchError("port range 1000-9000 is wider than 1024 ports"))
+			})
+		})
+
+		When("the range is invalid", func() {
+			BeforeEach(func() {
+				rules[0].PortRange = &opi.PortRange{Start: 9000, End: 8000}
+			})
+
+			It("fails", func() {
+				Expect(policyErr).To(MatchError("invalid port range 9000-8000"))
+			})
+		})
+	})
+
+	When("the rule is an ICMP rule", func() {
+		BeforeEach(func() {
+			rules = []opi.EgressRule{{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &opi.ICMPInfo{Type: 0, Code: 0}}}
+		})
+
+		It("returns no policy rather than one denying all egress", func() {
+			Expect(policyErr).NotTo(HaveOccurred())
+			Expect(policy).To(BeNil())
+		})
+
+		When("there are other rules", func() {
+			BeforeEach(func() {
+				rules = append(rules, opi.EgressRule{Protocol: "udp", Destinations: []string{"10.0.0.53"}, Ports: []int32{53}})
+			})
+
+			It("leaves it out of the policy", func() {
+				Expect(policyErr).NotTo(HaveOccurred())
+				Expect(policy.Spec.Egress).To(HaveLen(1))
+				Expect(policy.Spec.Egress[0].To).To(ConsistOf(networkingv1.NetworkPolicyPeer{
+					IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.53/32"},
+				}))
+			})
+		})
+	})
+
+	When("there are no rules", func() {
+		BeforeEach(func() {
+			rules = nil
+		})
+
+		It("returns no policy", func() {
+			Expect(policyErr).NotTo(HaveOccurred())
+			Expect(policy).To(BeNil())
+		})
+	})
+
+	DescribeTable("destinations",
+		func(destination string, cidrs ...string) {
+			policy, err := ToNetworkPolicy("my-app", nil, []opi.EgressRule{{Protocol: "all", Destinations: []string{destination}}})
+			Expect(err).NotTo(HaveOccurred())
+
+			var actual []string
+			for _, peer := range policy.Spec.Egress[0].To {
+				actual = append(actual, peer.IPBlock.CIDR)
+			}
+
+			Expect(actual).To(Equal(cidrs))
+		},
+		Entry("single IPv4", "10.0.0.1", "10.0.0.1/32"),
+		Entry("single IPv6", "fd00::1", "fd00::1/128"),
+		Entry("CIDR", "10.0.0.7/24", "10.0.0.0/24"),
+		Entry("aligned range", "10.0.0.0-10.0.0.255", "10.0.0.0/24"),
+		Entry("unaligned range", "10.0.0.1-10.0.0.6", "10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"),
+		Entry("whole address space", "0.0.0.0-255.255.255.255", "0.0.0.0/0"),
+	)
+
+	DescribeTable("invalid destinations",
+		func(destination string) {
+			_, err := ToNetworkPolicy("my-app", nil, []opi.EgressRule{{Protocol: "all", Destinations: []string{destination}}})
+			Expect(err).To(MatchError(ContainSubstring("invalid egress destination")))
+		},
+		Entry("garbage", "not-an-ip"),
+		Entry("bad CIDR", "10.0.0.0/33"),
+		Entry("reversed range", "10.0.0.9-10.0.0.1"),
+		Entry("IPv6 range", "fd00::1-fd00::9"),
+	)
+})
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/lager"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodePlacements maps placement tags and isolation segments to the nodes the
+// pods carrying them are scheduled on.
+type NodePlacements map[string]eirini.NodePlacement
+
+// Apply constrains the pod to the nodes of all the given placement tags. Tags
+// that have no configured placement are logged and ignored, as in CF, where
+// isolation segments without dedicated cells share the default ones.
+func (p NodePlacements) Apply(logger lager.Logger, podSpec *corev1.PodSpec, placementTags []string) {
+	var matchExpressions []corev1.NodeSelectorRequirement
+
+	for _, tag := range placementTags {
+		if tag == "" {
+			continue
+		}
+
+		placement, ok := p[tag]
+		if !ok {
+			logger.Info("ignoring-unknown-placement-tag", lager.Data{"placement-tag": tag})
+
+			continue
+		}
+
+		for k, v := range placement.NodeSelector {
+			if podSpec.NodeSelector == nil {
+				podSpec.NodeSelector = map[string]string{}
+			}
+
+			podSpec.NodeSelector[k] = v
+		}
+
+		for _, r := range placement.NodeAffinity {
+			matchExpressions = append(matchExpressions, corev1.NodeSelectorRequirement{
+				Key:      r.Key,
+				Operator: corev1.NodeSelectorOperator(r.Operator),
+				Values:   r.Values,
+			})
+		}
+
+		for _, t := range placement.Tolerations {
+			podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{
+				Key:      t.Key,
+				Operator: corev1.TolerationOperator(t.Operator),
+				Value:    t.Value,
+				Effect:   corev1.TaintEffect(t.Effect),
+			})
+		}
+	}
+
+	if len(matchExpressions) == 0 {
+		return
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+
+	podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: matchExpressions},
+			},
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package k8s_test
+
+import (
+	"code.cloudfoundry.org/eirini"
+	. "code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("NodePlacements", func() {
+	var (
+		placements    NodePlacements
+		podSpec       *corev1.PodSpec
+		placementTags []string
+		logger        *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		placements = NodePlacements{
+			"isolated": {
+				NodeSelector: map[string]string{"segment": "isolated"},
+				Tolerations: []eirini.Toleration{
+					{Key: "segment", Operator: "Equal", Value: "isolated", Effect: "NoSchedule"},
+				},
+			},
+			"gpu": {
+				NodeSelector: map[string]string{"accelerator": "gpu"},
+				NodeAffinity: []eirini.NodeSelectorRequirement{
+					{Key: "gpu-model", Operator: "In", Values: []string{"a100", "v100"}},
+				},
+			},
+		}
+		podSpec = &corev1.PodSpec{}
+		placementTags = []string{"isolated", "gpu"}
+		logger = lagertest.NewTestLogger("placement")
+	})
+
+	JustBeforeEach(func() {
+		placements.Apply(logger, podSpec, placementTags)
+	})
+
+	It("merges the node selectors of all tags", func() {
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{
+			"segment":     "isolated",
+			"accelerator": "gpu",
+		}))
+	})
+
+	It("adds the tolerations of all tags", func() {
+		Expect(podSpec.Tolerations).To(ConsistOf(corev1.Toleration{
+			Key:      "segment",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "isolated",
+			Effect:   corev1.TaintEffectNoSchedule,
+		}))
+	})
+
+	It("requires the node affinity of all tags", func() {
+		Expect(podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "gpu-model", Operator: corev1.NodeSelectorOpIn, Values: []string{"a100", "v100"}},
+				},
+			},
+		))
+	})
+
+	When("the pod already has an affinity", func() {
+		BeforeEach(func() {
+			podSpec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+		})
+
+		It("keeps it", func() {
+			Expect(podSpec.Affinity.PodAntiAffinity).NotTo(BeNil())
+			Expect(podSpec.Affinity.NodeAffinity).NotTo(BeNil())
+		})
+	})
+
+	When("no tag requires a node affinity", func() {
+		BeforeEach(func() {
+			placementTags = []string{"isolated"}
+		})
+
+		It("does not set an affinity", func() {
+			Expect(podSpec.Affinity).To(BeNil())
+		})
+	})
+
+	When("there are no placement tags", func() {
+		BeforeEach(func() {
+			placementTags = nil
+		})
+
+		It("leaves the pod spec untouched", func() {
+			Expect(podSpec).To(Equal(&corev1.PodSpec{}))
+		})
+	})
+
+	When("a tag has no configured placement", func() {
+		BeforeEach(func() {
+			placementTags = []string{"isolated", "unknown"}
+		})
+
+		It("applies the placement of the other tags", func() {
+			Expect(podSpec.NodeSelector).To(Equal(map[string]string{"segment": "isolated"}))
+		})
+
+		It("logs the ignored tag", func() {
+			Expect(logger).To(gbytes.Say("ignoring-unknown-placement-tag.*unknown"))
+		})
+	})
+
+	When("no tag has a configured placement", func() {
+		BeforeEach(func() {
+			placementTags = []string{"unknown"}
+		})
+
+		It("leaves the pod spec untouched", func() {
+			Expect(podSpec).To(Equal(&corev1.PodSpec{}))
+		})
+	})
+})
@@ -6,47 +6,109 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func CreateLivenessProbe(lrp *opi.LRP) *v1.Probe {
-	checkType := lrp.Health.Type
-	initialDelay := toSeconds(lrp.Health.TimeoutMs)
+const (
+	HealthCheckTypeHTTP    = "http"
+	HealthCheckTypePort    = "port"
+	HealthCheckTypeProcess = "process"
+	HealthCheckTypeNone    = "none"
 
-	if checkType == "http" {
-		return createHTTPProbe(lrp, initialDelay, 4)
-	} else if checkType == "port" {
-		return createPortProbe(lrp, initialDelay, 4)
-	}
+	defaultLivenessFailureThreshold  = 4
+	defaultReadinessFailureThreshold = 1
+	defaultStartTimeoutSeconds       = 60
+	defaultStartupPeriodSeconds      = 2
+)
+
+// ProbeDefaults are the operator defaults used for the probe settings an
+// LRP does not specify. Zero values leave the kubernetes defaults in place.
+type ProbeDefaults struct {
+	TimeoutSeconds      int32
+	PeriodSeconds       int32
+	FailureThreshold    int32
+	StartTimeoutSeconds int32
+}
 
-	return nil
+func CreateLivenessProbe(lrp *opi.LRP) *v1.Probe {
+	return NewLivenessProbeCreator(ProbeDefaults{})(lrp)
 }
 
 func CreateReadinessProbe(lrp *opi.LRP) *v1.Probe {
-	checkType := lrp.Health.Type
-	if checkType == "http" {
-		return createHTTPProbe(lrp, 0, 1)
-	} else if checkType == "port" {
-		return createPortProbe(lrp, 0, 1)
+	return NewReadinessProbeCreator(ProbeDefaults{})(lrp)
+}
+
+func CreateStartupProbe(lrp *opi.LRP) *v1.Probe {
+	return NewStartupProbeCreator(ProbeDefaults{})(lrp)
+}
+
+// NewLivenessProbeCreator returns a ProbeCreator for probes that restart the
+// app once it stops responding. It only takes effect after the startup probe
+// has succeeded, so it needs no initial delay.
+func NewLivenessProbeCreator(defaults ProbeDefaults) ProbeCreator {
+	return func(lrp *opi.LRP) *v1.Probe {
+		probe := createProbe(lrp, defaults)
+		if probe == nil {
+			return nil
+		}
+
+		probe.FailureThreshold = firstNonZero(lrp.Health.FailureThreshold, defaults.FailureThreshold, defaultLivenessFailureThreshold)
+
+		return probe
 	}
+}
+
+// NewReadinessProbeCreator returns a ProbeCreator for probes that take the
+// app out of the routing table as soon as a single check fails.
+func NewReadinessProbeCreator(defaults ProbeDefaults) ProbeCreator {
+	return func(lrp *opi.LRP) *v1.Probe {
+		probe := createProbe(lrp, defaults)
+		if probe == nil {
+			return nil
+		}
+
+		probe.FailureThreshold = defaultReadinessFailureThreshold
 
-	return nil
+		return probe
+	}
 }
 
-func createPortProbe(lrp *opi.LRP, initialDelay, failureThreshold int32) *v1.Probe {
-	return &v1.Probe{
-		Handler: v1.Handler{
-			TCPSocket: tcpSocketAction(lrp),
-		},
-		InitialDelaySeconds: initialDelay,
-		FailureThreshold:    failureThreshold,
+// NewStartupProbeCreator returns a ProbeCreator for probes that give the app
+// its whole start timeout to come up before the liveness probe kicks in.
+func NewStartupProbeCreator(defaults ProbeDefaults) ProbeCreator {
+	return func(lrp *opi.LRP) *v1.Probe {
+		probe := createProbe(lrp, defaults)
+		if probe == nil {
+			return nil
+		}
+
+		startTimeout := firstNonZero(toSeconds(lrp.Health.StartTimeoutMs), defaults.StartTimeoutSeconds, defaultStartTimeoutSeconds)
+		period := firstNonZero(probe.PeriodSeconds, defaultStartupPeriodSeconds)
+
+		probe.PeriodSeconds = period
+		probe.FailureThreshold = ceilDiv(startTimeout, period)
+
+		return probe
 	}
 }
 
-func createHTTPProbe(lrp *opi.LRP, initialDelay, failureThreshold int32) *v1.Probe {
+func createProbe(lrp *opi.LRP, defaults ProbeDefaults) *v1.Probe {
+	var handler v1.Handler
+
+	switch lrp.Health.Type {
+	case HealthCheckTypeHTTP:
+		handler.HTTPGet = httpGetAction(lrp)
+	case HealthCheckTypePort:
+		handler.TCPSocket = tcpSocketAction(lrp)
+	case HealthCheckTypeProcess, HealthCheckTypeNone:
+		// kubernetes restarts the container when the process exits, which
+		// is all a process health check does
+		return nil
+	default:
+		return nil
+	}
+
 	return &v1.Probe{
-		Handler: v1.Handler{
-			HTTPGet: httpGetAction(lrp),
-		},
-		InitialDelaySeconds: initialDelay,
-		FailureThreshold:    failureThreshold,
+		Handler:        handler,
+		TimeoutSeconds: firstNonZero(toSeconds(lrp.Health.TimeoutMs), defaults.TimeoutSeconds),
+		PeriodSeconds:  firstNonZero(toSeconds(lrp.Health.IntervalMs), defaults.PeriodSeconds),
 	}
 }
 
@@ -62,3 +124,17 @@ func tcpSocketAction(lrp *opi.LRP) *v1.TCPSocketAction {
 		Port: intstr.IntOrString{Type: intstr.Int, IntVal: lrp.Health.Port},
 	}
 }
+
+func firstNonZero(values ...int32) int32 {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+
+	return 0
+}
+
+func ceilDiv(a, b int32) int32 {
+	return (a + b - 1) / b
+}
@@ -4,6 +4,7 @@ import (
 	. "code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/opi"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -12,8 +13,9 @@ import (
 
 var _ = Describe("PrrobeCreator", func() {
 	var (
-		probe *v1.Probe
-		lrp   *opi.LRP
+		probe    *v1.Probe
+		lrp      *opi.LRP
+		defaults ProbeDefaults
 	)
 
 	BeforeEach(func() {
@@ -24,11 +26,12 @@ var _ = Describe("PrrobeCreator", func() {
 				TimeoutMs: 3000,
 			},
 		}
+		defaults = ProbeDefaults{}
 	})
 
 	Context("LivenessProbeCreator", func() {
 		JustBeforeEach(func() {
-			probe = CreateLivenessProbe(lrp)
+			probe = NewLivenessProbeCreator(defaults)(lrp)
 		})
 
 		Context("When healthcheck type is HTTP", func() {
@@ -44,8 +47,8 @@ var _ = Describe("PrrobeCreator", func() {
 							Port: intstr.IntOrString{Type: intstr.Int, IntVal: 8080},
 						},
 					},
-					InitialDelaySeconds: 3,
-					FailureThreshold:    4,
+					TimeoutSeconds:   3,
+					FailureThreshold: 4,
 				}))
 			})
 		})
@@ -62,8 +65,8 @@ var _ = Describe("PrrobeCreator", func() {
 							Port: intstr.IntOrString{Type: intstr.Int, IntVal: 8080},
 						},
 					},
-					InitialDelaySeconds: 3,
-					FailureThreshold:    4,
+					TimeoutSeconds:   3,
+					FailureThreshold: 4,
 				}))
 			})
 		})
@@ -75,24 +78,53 @@ var _ = Describe("PrrobeCreator", func() {
 			})
 
 			It("rounds it down", func() {
-				Expect(probe.InitialDelaySeconds).To(Equal(int32(5)))
+				Expect(probe.TimeoutSeconds).To(Equal(int32(5)))
 			})
 		})
 
-		Context("When healthcheck information is missing", func() {
+		Context("When the LRP sets the interval and the failure threshold", func() {
 			BeforeEach(func() {
-				lrp = &opi.LRP{}
+				lrp.Health.Type = "port"
+				lrp.Health.IntervalMs = 15000
+				lrp.Health.FailureThreshold = 7
+				defaults = ProbeDefaults{TimeoutSeconds: 10, PeriodSeconds: 20, FailureThreshold: 9}
 			})
 
-			It("returns nil", func() {
-				Expect(probe).To(BeNil())
+			It("prefers the LRP settings over the operator defaults", func() {
+				Expect(probe.TimeoutSeconds).To(Equal(int32(3)))
+				Expect(probe.PeriodSeconds).To(Equal(int32(15)))
+				Expect(probe.FailureThreshold).To(Equal(int32(7)))
+			})
+		})
+
+		Context("When only operator defaults are set", func() {
+			BeforeEach(func() {
+				lrp.Health.Type = "port"
+				lrp.Health.TimeoutMs = 0
+				defaults = ProbeDefaults{TimeoutSeconds: 10, PeriodSeconds: 20, FailureThreshold: 9}
+			})
+
+			It("uses the operator defaults", func() {
+				Expect(probe.TimeoutSeconds).To(Equal(int32(10)))
+				Expect(probe.PeriodSeconds).To(Equal(int32(20)))
+				Expect(probe.FailureThreshold).To(Equal(int32(9)))
 			})
 		})
+
+		DescribeTable("health check types without a probe",
+			func(healthCheckType string) {
+				lrp.Health.Type = healthCheckType
+				Expect(NewLivenessProbeCreator(defaults)(lrp)).To(BeNil())
+			},
+			Entry("process", "process"),
+			Entry("none", "none"),
+			Entry("missing", ""),
+		)
 	})
 
 	Context("ReadinessProbeCreator", func() {
 		JustBeforeEach(func() {
-			probe = CreateReadinessProbe(lrp)
+			probe = NewReadinessProbeCreator(defaults)(lrp)
 		})
 
 		Context("When Healtcheck type is HTTP", func() {
@@ -108,8 +140,8 @@ var _ = Describe("PrrobeCreator", func() {
 							Port: intstr.IntOrString{Type: intstr.Int, IntVal: 8080},
 						},
 					},
-					InitialDelaySeconds: 0,
-					FailureThreshold:    1,
+					TimeoutSeconds:   3,
+					FailureThreshold: 1,
 				}))
 			})
 		})
@@ -126,12 +158,23 @@ var _ = Describe("PrrobeCreator", func() {
 							Port: intstr.IntOrString{Type: intstr.Int, IntVal: 8080},
 						},
 					},
-					InitialDelaySeconds: 0,
-					FailureThreshold:    1,
+					TimeoutSeconds:   3,
+					FailureThreshold: 1,
 				}))
 			})
 		})
 
+		Context("When the LRP sets a failure threshold", func() {
+			BeforeEach(func() {
+				lrp.Health.Type = "port"
+				lrp.Health.FailureThreshold = 7
+			})
+
+			It("still takes the instance out of rotation on the first failure", func() {
+				Expect(probe.FailureThreshold).To(Equal(int32(1)))
+			})
+		})
+
 		Context("When healthcheck information is missing", func() {
 			BeforeEach(func() {
 				lrp = &opi.LRP{}
@@ -142,4 +185,71 @@ var _ = Describe("PrrobeCreator", func() {
 			})
 		})
 	})
+
+	Context("StartupProbeCreator", func() {
+		BeforeEach(func() {
+			lrp.Health.Type = "http"
+			lrp.Health.StartTimeoutMs = 120000
+		})
+
+		JustBeforeEach(func() {
+			probe = NewStartupProbeCreator(defaults)(lrp)
+		})
+
+		It("gives the app its whole start timeout to pass the first check", func() {
+			Expect(probe).To(Equal(&v1.Probe{
+				Handler: v1.Handler{
+					HTTPGet: &v1.HTTPGetAction{
+						Path: "/healthz",
+						Port: intstr.IntOrString{Type: intstr.Int, IntVal: 8080},
+					},
+				},
+				TimeoutSeconds:   3,
+				PeriodSeconds:    2,
+				FailureThreshold: 60,
+			}))
+		})
+
+		Context("When the LRP sets an interval", func() {
+			BeforeEach(func() {
+				lrp.Health.IntervalMs = 7000
+			})
+
+			It("rounds the number of attempts up", func() {
+				Expect(probe.PeriodSeconds).To(Equal(int32(7)))
+				Expect(probe.FailureThreshold).To(Equal(int32(18)))
+			})
+		})
+
+		Context("When the LRP has no start timeout", func() {
+			BeforeEach(func() {
+				lrp.Health.StartTimeoutMs = 0
+			})
+
+			It("defaults the start timeout to 60 seconds", func() {
+				Expect(probe.FailureThreshold).To(Equal(int32(30)))
+			})
+
+			Context("and the operator set a default start timeout", func() {
+				BeforeEach(func() {
+					defaults = ProbeDefaults{StartTimeoutSeconds: 300, PeriodSeconds: 5}
+				})
+
+				It("uses the operator default", func() {
+					Expect(probe.PeriodSeconds).To(Equal(int32(5)))
+					Expect(probe.FailureThreshold).To(Equal(int32(60)))
+				})
+			})
+		})
+
+		DescribeTable("health check types without a probe",
+			func(healthCheckType string) {
+				lrp.Health.Type = healthCheckType
+				Expect(NewStartupProbeCreator(defaults)(lrp)).To(BeNil())
+			},
+			Entry("process", "process"),
+			Entry("none", "none"),
+			Entry("missing", ""),
+		)
+	})
 })
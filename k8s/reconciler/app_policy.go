@@ -0,0 +1,66 @@
+package reconciler
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//counterfeiter:generate . AppPolicyRefresher
+
+type AppPolicyRefresher interface {
+	Refresh(appGUID string) error
+}
+
+// AppPolicy keeps the container-to-container NetworkPolicies of an app in
+// line with its StatefulSets, which are replaced whenever the app is
+// restaged.
+type AppPolicy struct {
+	logger       lager.Logger
+	statefulSets client.Client
+	refresher    AppPolicyRefresher
+}
+
+func NewAppPolicy(logger lager.Logger, client client.Client, refresher AppPolicyRefresher) *AppPolicy {
+	return &AppPolicy{
+		logger:       logger,
+		statefulSets: client,
+		refresher:    refresher,
+	}
+}
+
+func (r *AppPolicy) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.Session("app-policy-reconciler", lager.Data{"namespace": request.Namespace, "name": request.Name})
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := r.statefulSets.Get(context.Background(), request.NamespacedName, statefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Debug("statefulset-does-not-exist")
+
+			return reconcile.Result{}, nil
+		}
+
+		logger.Error("failed-to-get-statefulset", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to get statefulset")
+	}
+
+	appGUID := statefulSet.Labels[k8s.LabelAppGUID]
+	if appGUID == "" {
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.refresher.Refresh(appGUID); err != nil {
+		logger.Error("failed-to-refresh-app-policies", err, lager.Data{"app-guid": appGUID})
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to refresh app policies")
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,86 @@
+package reconciler_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/reconciler"
+	"code.cloudfoundry.org/eirini/k8s/reconciler/reconcilerfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("K8s/Reconciler/AppPolicy", func() {
+	var (
+		controllerClient    *reconcilerfakes.FakeClient
+		refresher           *reconcilerfakes.FakeAppPolicyRefresher
+		appPolicyReconciler *reconciler.AppPolicy
+		resultErr           error
+	)
+
+	BeforeEach(func() {
+		controllerClient = new(reconcilerfakes.FakeClient)
+		refresher = new(reconcilerfakes.FakeAppPolicyRefresher)
+		appPolicyReconciler = reconciler.NewAppPolicy(lagertest.NewTestLogger("app-policy-reconciler"), controllerClient, refresher)
+
+		controllerClient.GetStub = func(_ context.Context, _ types.NamespacedName, obj runtime.Object) error {
+			statefulSet := obj.(*appsv1.StatefulSet)
+			statefulSet.Labels = map[string]string{k8s.LabelAppGUID: "app-guid"}
+
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		_, resultErr = appPolicyReconciler.Reconcile(reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: "some-ns", Name: "app-sts"},
+		})
+	})
+
+	It("refreshes the policies of the statefulset app", func() {
+		Expect(resultErr).NotTo(HaveOccurred())
+		Expect(refresher.RefreshCallCount()).To(Equal(1))
+		Expect(refresher.RefreshArgsForCall(0)).To(Equal("app-guid"))
+	})
+
+	When("the statefulset no longer exists", func() {
+		BeforeEach(func() {
+			controllerClient.GetReturns(apierrors.NewNotFound(schema.GroupResource{}, "app-sts"))
+			controllerClient.GetStub = nil
+		})
+
+		It("does nothing", func() {
+			Expect(resultErr).NotTo(HaveOccurred())
+			Expect(refresher.RefreshCallCount()).To(BeZero())
+		})
+	})
+
+	When("getting the statefulset fails", func() {
+		BeforeEach(func() {
+			controllerClient.GetReturns(errors.New("boom"))
+			controllerClient.GetStub = nil
+		})
+
+		It("returns an error", func() {
+			Expect(resultErr).To(MatchError(ContainSubstring("failed to get statefulset")))
+		})
+	})
+
+	When("refreshing fails", func() {
+		BeforeEach(func() {
+			refresher.RefreshReturns(errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(resultErr).To(MatchError(ContainSubstring("failed to refresh app policies")))
+		})
+	})
+})
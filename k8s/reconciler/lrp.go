@@ -2,6 +2,9 @@ package reconciler
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
 	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s"
@@ -13,7 +16,9 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,6 +34,7 @@ import (
 type LRPDesirer interface {
 	Desire(namespace string, lrp *opi.LRP, opts ...k8s.DesireOption) error
 	Get(identifier opi.LRPIdentifier) (*opi.LRP, error)
+	GetInstances(identifier opi.LRPIdentifier) ([]*opi.Instance, error)
 	Update(lrp *opi.LRP) error
 }
 
@@ -74,6 +80,12 @@ func (r *LRP) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 		return reconcile.Result{}, errors.Wrap(err, "failed to get lrp")
 	}
 
+	if err := lrp.Spec.PrivateRegistry.Validate(); err != nil {
+		logger.Error("invalid-lrp-spec", err)
+
+		return reconcile.Result{}, nil
+	}
+
 	err := r.do(lrp)
 	if err != nil {
 		logger.Error("failed-to-reconcile", err)
@@ -93,7 +105,11 @@ func (r *LRP) do(lrp *eiriniv1.LRP) error {
 			return errors.Wrap(parseErr, "failed to parse the crd spec to the lrp model")
 		}
 
-		return errors.Wrap(r.desirer.Desire(lrp.Namespace, appLRP, r.setOwnerFn(lrp)), "failed to desire lrp")
+		if err = r.desirer.Desire(lrp.Namespace, appLRP, r.setOwnerFn(lrp)); err != nil {
+			return r.setFailedStatus(lrp, "DesireFailed", errors.Wrap(err, "failed to desire lrp"))
+		}
+
+		return nil
 	}
 
 	if err != nil {
@@ -105,15 +121,36 @@ func (r *LRP) do(lrp *eiriniv1.LRP) error {
 		return errors.Wrap(err, "failed to parse the crd spec to the lrp model")
 	}
 
-	var errs *multierror.Error
+	if err = r.desirer.Update(appLRP); err != nil {
+		return r.setFailedStatus(lrp, "UpdateFailed", errors.Wrap(err, "failed to update app"))
+	}
+
+	return errors.Wrap(r.updateStatus(lrp, appLRP), "failed to update lrp status")
+}
+
+// setFailedStatus marks the LRP as degraded when its spec could not be
+// applied, and returns the failure. The observed generation is left alone, as
+// the spec of that generation has not taken effect.
+func (r *LRP) setFailedStatus(lrp *eiriniv1.LRP, reason string, failure error) error {
+	status := *lrp.Status.DeepCopy()
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    eiriniv1.LRPDegradedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: failure.Error(),
+	})
+
+	if equality.Semantic.DeepEqual(status, lrp.Status) {
+		return failure
+	}
 
-	err = r.updateStatus(lrp, appLRP)
-	errs = multierror.Append(errs, errors.Wrap(err, "failed to update lrp status"))
+	lrp.Status = status
 
-	err = r.desirer.Update(appLRP)
-	errs = multierror.Append(errs, errors.Wrap(err, "failed to update app"))
+	if err := r.lrps.Status().Update(context.Background(), lrp); err != nil {
+		return multierror.Append(failure, errors.Wrap(err, "failed to update lrp status"))
+	}
 
-	return errs.ErrorOrNil()
+	return failure
 }
 
 func (r *LRP) updateStatus(lrp *eiriniv1.LRP, appLRP *opi.LRP) error {
@@ -127,7 +164,17 @@ func (r *LRP) updateStatus(lrp *eiriniv1.LRP, appLRP *opi.LRP) error {
 		return errors.Wrap(err, "failed to get stateful set")
 	}
 
-	lrp.Status.Replicas = st.Status.ReadyReplicas
+	instances, err := r.desirer.GetInstances(appLRP.LRPIdentifier)
+	if err != nil {
+		return errors.Wrap(err, "failed to get instances")
+	}
+
+	status := toLRPStatus(lrp, st, instances)
+	if equality.Semantic.DeepEqual(status, lrp.Status) {
+		return nil
+	}
+
+	lrp.Status = status
 
 	return r.lrps.Status().Update(context.Background(), lrp)
 }
@@ -151,9 +198,125 @@ func toOpiLrp(lrp *eiriniv1.LRP) (*opi.LRP, error) {
 
 	opiLrp.TargetInstances = lrp.Spec.Instances
 
+	if registry := lrp.Spec.PrivateRegistry; registry != nil && registry.SecretRef != nil {
+		opiLrp.PrivateRegistry = &opi.PrivateRegistry{SecretName: registry.SecretRef.Name}
+	}
+
 	if err := copier.Copy(&opiLrp.AppURIs, lrp.Spec.AppRoutes); err != nil {
 		return nil, errors.Wrap(err, "failed to copy app routes")
 	}
 
 	return opiLrp, nil
 }
+
+func toLRPStatus(lrp *eiriniv1.LRP, st *appsv1.StatefulSet, instances []*opi.Instance) eiriniv1.LRPStatus {
+	status := eiriniv1.LRPStatus{
+		ObservedGeneration: lrp.Generation,
+		Replicas:           st.Status.ReadyReplicas,
+		DesiredInstances:   int32(lrp.Spec.Instances),
+		ReadyInstances:     st.Status.ReadyReplicas,
+		Instances:          toInstanceStatuses(instances),
+		Conditions:         append([]metav1.Condition{}, lrp.Status.Conditions...),
+	}
+
+	setReadyCondition(&status)
+	degraded := setDegradedCondition(&status)
+	setProgressingCondition(&status, st, degraded)
+
+	return status
+}
+
+func toInstanceStatuses(instances []*opi.Instance) []eiriniv1.InstanceStatus {
+	statuses := make([]eiriniv1.InstanceStatus, 0, len(instances))
+
+	for _, instance := range instances {
+		status := eiriniv1.InstanceStatus{
+			Index:          instance.Index,
+			State:          instance.State,
+			Since:          toMetaTime(instance.Since),
+			PlacementError: instance.PlacementError,
+		}
+
+		if instance.LastCrash != nil {
+			status.LastCrash = &eiriniv1.InstanceCrash{
+				Reason:     instance.LastCrash.Reason,
+				ExitStatus: instance.LastCrash.ExitStatus,
+				CrashCount: instance.LastCrash.CrashCount,
+				Timestamp:  metav1.NewTime(time.Unix(0, instance.LastCrash.Timestamp)).Rfc3339Copy(),
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Index < statuses[j].Index
+	})
+
+	return statuses
+}
+
+func setReadyCondition(status *eiriniv1.LRPStatus) {
+	condition := metav1.Condition{
+		Type:    eiriniv1.LRPReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AllInstancesReady",
+		Message: fmt.Sprintf("%d/%d instances ready", status.ReadyInstances, status.DesiredInstances),
+	}
+
+	if status.ReadyInstances < status.DesiredInstances {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InstancesNotReady"
+	}
+
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+func setDegradedCondition(status *eiriniv1.LRPStatus) bool {
+	condition := metav1.Condition{
+		Type:   eiriniv1.LRPDegradedConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "InstancesHealthy",
+	}
+
+	for _, instance := range status.Instances {
+		if instance.PlacementError != "" {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "InstancesUnschedulable"
+			condition.Message = fmt.Sprintf("instance %d: %s", instance.Index, instance.PlacementError)
+
+			break
+		}
+
+		if instance.State == opi.CrashedState {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "InstancesCrashed"
+			condition.Message = fmt.Sprintf("instance %d has crashed", instance.Index)
+
+			break
+		}
+	}
+
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	return condition.Status == metav1.ConditionTrue
+}
+
+func setProgressingCondition(status *eiriniv1.LRPStatus, st *appsv1.StatefulSet, degraded bool) {
+	condition := metav1.Condition{
+		Type:   eiriniv1.LRPProgressingConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "RolloutComplete",
+	}
+
+	rollingOut := st.Status.ObservedGeneration < st.Generation ||
+		st.Status.UpdateRevision != st.Status.CurrentRevision ||
+		status.ReadyInstances < status.DesiredInstances
+
+	if rollingOut && !degraded {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RolloutInProgress"
+	}
+
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -2,6 +2,7 @@ package reconciler_test
 
 import (
 	"context"
+	"time"
 
 	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s/reconciler"
@@ -15,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -47,6 +49,7 @@ var _ = Describe("reconciler.LRP", func() {
 			lrp := o.(*eiriniv1.LRP)
 			lrp.Name = "some-lrp"
 			lrp.Namespace = "some-ns"
+			lrp.Generation = 3
 			lrp.Spec.GUID = "the-lrp-guid"
 			lrp.Spec.Version = "the-lrp-version"
 			lrp.Spec.Command = []string{"ls", "-la"}
@@ -54,6 +57,11 @@ var _ = Describe("reconciler.LRP", func() {
 			lrp.Spec.AppRoutes = []eiriniv1.Route{
 				{Hostname: "foo.io", Port: 8080}, {Hostname: "bar.io", Port: 9090},
 			}
+			lrp.Spec.PlacementTags = []string{"isolated"}
+			lrp.Spec.EgressRules = []eiriniv1.EgressRule{
+				{Protocol: "udp", Destinations: []string{"10.0.0.53"}, Ports: []int32{53}},
+			}
+			lrp.Spec.InternalRoutes = []string{"app.apps.internal"}
 
 			return nil
 		}
@@ -87,6 +95,11 @@ var _ = Describe("reconciler.LRP", func() {
 			opi.Route{Hostname: "foo.io", Port: 8080},
 			opi.Route{Hostname: "bar.io", Port: 9090},
 		))
+		Expect(lrp.PlacementTags).To(Equal([]string{"isolated"}))
+		Expect(lrp.EgressRules).To(Equal([]opi.EgressRule{
+			{Protocol: "udp", Destinations: []string{"10.0.0.53"}, Ports: []int32{53}},
+		}))
+		Expect(lrp.InternalRoutes).To(Equal([]string{"app.apps.internal"}))
 	})
 
 	It("sets an owner reference in the statefulset", func() {
@@ -131,6 +144,19 @@ var _ = Describe("reconciler.LRP", func() {
 
 			desirer.GetReturns(nil, nil)
 			statefulsetGetter.GetReturns(&appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 9}}, nil)
+			desirer.GetInstancesReturns([]*opi.Instance{
+				{
+					Index: 1,
+					State: opi.CrashedState,
+					LastCrash: &opi.InstanceCrash{
+						Reason:     "Error",
+						ExitStatus: 1,
+						CrashCount: 4,
+						Timestamp:  time.Unix(456, 0).UnixNano(),
+					},
+				},
+				{Index: 0, State: opi.RunningState, Since: time.Unix(123, 0).UnixNano()},
+			}, nil)
 		})
 
 		It("the CRD status is updated accordingly", func() {
@@ -139,9 +165,147 @@ var _ = Describe("reconciler.LRP", func() {
 			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
 			_, obj, _ := statusWriter.UpdateArgsForCall(0)
 			lrp := obj.(*eiriniv1.LRP)
-			Expect(lrp.Status).To(Equal(eiriniv1.LRPStatus{
-				Replicas: 9,
+			Expect(lrp.Status.Replicas).To(BeNumerically("==", 9))
+			Expect(lrp.Status.ReadyInstances).To(BeNumerically("==", 9))
+			Expect(lrp.Status.DesiredInstances).To(BeNumerically("==", 10))
+			Expect(lrp.Status.ObservedGeneration).To(BeNumerically("==", 3))
+		})
+
+		It("sets the per-instance status", func() {
+			Expect(desirer.GetInstancesCallCount()).To(Equal(1))
+			Expect(desirer.GetInstancesArgsForCall(0)).To(Equal(opi.LRPIdentifier{
+				GUID:    "the-lrp-guid",
+				Version: "the-lrp-version",
 			}))
+
+			_, obj, _ := statusWriter.UpdateArgsForCall(0)
+			lrp := obj.(*eiriniv1.LRP)
+			Expect(lrp.Status.Instances).To(HaveLen(2))
+
+			Expect(lrp.Status.Instances[0].Index).To(Equal(0))
+			Expect(lrp.Status.Instances[0].State).To(Equal(opi.RunningState))
+			Expect(lrp.Status.Instances[0].Since.Time).To(BeTemporally("==", time.Unix(123, 0)))
+			Expect(lrp.Status.Instances[0].LastCrash).To(BeNil())
+
+			Expect(lrp.Status.Instances[1].Index).To(Equal(1))
+			Expect(lrp.Status.Instances[1].State).To(Equal(opi.CrashedState))
+			lastCrash := lrp.Status.Instances[1].LastCrash
+			Expect(lastCrash).NotTo(BeNil())
+			Expect(lastCrash.Reason).To(Equal("Error"))
+			Expect(lastCrash.ExitStatus).To(Equal(1))
+			Expect(lastCrash.CrashCount).To(Equal(4))
+			Expect(lastCrash.Timestamp.Time).To(BeTemporally("==", time.Unix(456, 0)))
+		})
+
+		It("sets the conditions", func() {
+			_, obj, _ := statusWriter.UpdateArgsForCall(0)
+			lrp := obj.(*eiriniv1.LRP)
+
+			ready := meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPReadyConditionType)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(v1.ConditionFalse))
+			Expect(ready.Message).To(Equal("9/10 instances ready"))
+
+			degraded := meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(v1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("InstancesCrashed"))
+
+			Expect(meta.IsStatusConditionFalse(lrp.Status.Conditions, eiriniv1.LRPProgressingConditionType)).To(BeTrue())
+		})
+
+		When("all instances are ready", func() {
+			BeforeEach(func() {
+				statefulsetGetter.GetReturns(&appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 10}}, nil)
+				desirer.GetInstancesReturns([]*opi.Instance{{Index: 0, State: opi.RunningState}}, nil)
+			})
+
+			It("marks the LRP as ready and not degraded", func() {
+				_, obj, _ := statusWriter.UpdateArgsForCall(0)
+				lrp := obj.(*eiriniv1.LRP)
+
+				Expect(meta.IsStatusConditionTrue(lrp.Status.Conditions, eiriniv1.LRPReadyConditionType)).To(BeTrue())
+				Expect(meta.IsStatusConditionFalse(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)).To(BeTrue())
+				Expect(meta.IsStatusConditionFalse(lrp.Status.Conditions, eiriniv1.LRPProgressingConditionType)).To(BeTrue())
+			})
+		})
+
+		When("the instances are still starting", func() {
+			BeforeEach(func() {
+				desirer.GetInstancesReturns([]*opi.Instance{{Index: 0, State: opi.PendingState}}, nil)
+			})
+
+			It("marks the LRP as progressing", func() {
+				_, obj, _ := statusWriter.UpdateArgsForCall(0)
+				lrp := obj.(*eiriniv1.LRP)
+
+				Expect(meta.IsStatusConditionTrue(lrp.Status.Conditions, eiriniv1.LRPProgressingConditionType)).To(BeTrue())
+				Expect(meta.IsStatusConditionFalse(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)).To(BeTrue())
+			})
+		})
+
+		When("an instance cannot be placed", func() {
+			BeforeEach(func() {
+				desirer.GetInstancesReturns([]*opi.Instance{
+					{Index: 0, State: opi.ErrorState, PlacementError: opi.InsufficientMemoryError},
+				}, nil)
+			})
+
+			It("marks the LRP as degraded", func() {
+				_, obj, _ := statusWriter.UpdateArgsForCall(0)
+				lrp := obj.(*eiriniv1.LRP)
+
+				degraded := meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)
+				Expect(degraded.Status).To(Equal(v1.ConditionTrue))
+				Expect(degraded.Reason).To(Equal("InstancesUnschedulable"))
+				Expect(lrp.Status.Instances[0].PlacementError).To(Equal(opi.InsufficientMemoryError))
+			})
+		})
+
+		When("the status has not changed", func() {
+			BeforeEach(func() {
+				desirer.GetInstancesReturns([]*opi.Instance{}, nil)
+				statefulsetGetter.GetReturns(&appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 10}}, nil)
+
+				getLRP := controllerClient.GetStub
+				controllerClient.GetStub = func(c context.Context, nn types.NamespacedName, o runtime.Object) error {
+					Expect(getLRP(c, nn, o)).To(Succeed())
+
+					lrp := o.(*eiriniv1.LRP)
+					lrp.Status = eiriniv1.LRPStatus{
+						ObservedGeneration: 3,
+						Replicas:           10,
+						DesiredInstances:   10,
+						ReadyInstances:     10,
+						Instances:          []eiriniv1.InstanceStatus{},
+						Conditions: []v1.Condition{
+							{Type: eiriniv1.LRPReadyConditionType, Status: v1.ConditionTrue, Reason: "AllInstancesReady", Message: "10/10 instances ready"},
+							{Type: eiriniv1.LRPDegradedConditionType, Status: v1.ConditionFalse, Reason: "InstancesHealthy"},
+							{Type: eiriniv1.LRPProgressingConditionType, Status: v1.ConditionFalse, Reason: "RolloutComplete"},
+						},
+					}
+
+					return nil
+				}
+			})
+
+			It("does not update the status", func() {
+				Expect(resultErr).NotTo(HaveOccurred())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			})
+		})
+
+		When("getting the instances fails", func() {
+			BeforeEach(func() {
+				desirer.GetInstancesReturns(nil, errors.New("instances-error"))
+			})
+
+			It("does not update the status", func() {
+				Expect(resultErr).To(MatchError(ContainSubstring("instances-error")))
+
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+				Expect(desirer.UpdateCallCount()).To(Equal(1))
+			})
 		})
 
 		When("statefulset getter fails to get the statefulset", func() {
@@ -213,6 +377,19 @@ var _ = Describe("reconciler.LRP", func() {
 		It("returns an error", func() {
 			Expect(resultErr).To(MatchError("failed to desire lrp: boom"))
 		})
+
+		It("marks the LRP as degraded without observing its generation", func() {
+			Expect(statusClient.UpdateCallCount()).To(Equal(1))
+			_, obj, _ := statusClient.UpdateArgsForCall(0)
+			lrp := obj.(*eiriniv1.LRP)
+
+			Expect(lrp.Status.ObservedGeneration).To(BeZero())
+			degraded := meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(v1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("DesireFailed"))
+			Expect(degraded.Message).To(Equal("failed to desire lrp: boom"))
+		})
 	})
 
 	When("the lrp desirer fails to update the app", func() {
@@ -222,7 +399,74 @@ var _ = Describe("reconciler.LRP", func() {
 		})
 
 		It("returns an error", func() {
-			Expect(resultErr).To(MatchError(ContainSubstring("boom")))
+			Expect(resultErr).To(MatchError("failed to update app: boom"))
+		})
+
+		It("marks the LRP as degraded without observing its generation", func() {
+			Expect(statusClient.UpdateCallCount()).To(Equal(1))
+			_, obj, _ := statusClient.UpdateArgsForCall(0)
+			lrp := obj.(*eiriniv1.LRP)
+
+			Expect(lrp.Status.ObservedGeneration).To(BeZero())
+			Expect(meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPReadyConditionType)).To(BeNil())
+			Expect(meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPProgressingConditionType)).To(BeNil())
+			degraded := meta.FindStatusCondition(lrp.Status.Conditions, eiriniv1.LRPDegradedConditionType)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(v1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("UpdateFailed"))
+			Expect(degraded.Message).To(Equal("failed to update app: boom"))
+		})
+
+		It("does not look at the statefulset", func() {
+			Expect(statefulsetGetter.GetCallCount()).To(BeZero())
+		})
+
+		When("updating the LRP status fails as well", func() {
+			BeforeEach(func() {
+				statusClient.UpdateReturns(errors.New("status-boom"))
+			})
+
+			It("returns both errors", func() {
+				Expect(resultErr).To(MatchError(ContainSubstring("failed to update app: boom")))
+				Expect(resultErr).To(MatchError(ContainSubstring("status-boom")))
+			})
+		})
+	})
+
+	When("the LRP references a registry secret", func() {
+		var registry *eiriniv1.PrivateRegistry
+
+		BeforeEach(func() {
+			registry = &eiriniv1.PrivateRegistry{
+				SecretRef: &eiriniv1.SecretReference{Name: "my-registry-secret"},
+			}
+
+			getLRP := controllerClient.GetStub
+			controllerClient.GetStub = func(c context.Context, nn types.NamespacedName, o runtime.Object) error {
+				Expect(getLRP(c, nn, o)).To(Succeed())
+				o.(*eiriniv1.LRP).Spec.PrivateRegistry = registry
+
+				return nil
+			}
+		})
+
+		It("passes the secret name to the desirer", func() {
+			Expect(resultErr).NotTo(HaveOccurred())
+			_, lrp, _ := desirer.DesireArgsForCall(0)
+			Expect(lrp.PrivateRegistry).To(Equal(&opi.PrivateRegistry{SecretName: "my-registry-secret"}))
+		})
+
+		When("the LRP also sets registry credentials", func() {
+			BeforeEach(func() {
+				registry.Username = "user"
+				registry.Password = "password"
+			})
+
+			It("rejects the LRP without requeuing it", func() {
+				Expect(resultErr).NotTo(HaveOccurred())
+				Expect(desirer.DesireCallCount()).To(BeZero())
+				Expect(desirer.UpdateCallCount()).To(BeZero())
+			})
 		})
 	})
 })
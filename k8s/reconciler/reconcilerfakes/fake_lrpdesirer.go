@@ -36,6 +36,19 @@ type FakeLRPDesirer struct {
 		result1 *opi.LRP
 		result2 error
 	}
+	GetInstancesStub        func(opi.LRPIdentifier) ([]*opi.Instance, error)
+	getInstancesMutex       sync.RWMutex
+	getInstancesArgsForCall []struct {
+		arg1 opi.LRPIdentifier
+	}
+	getInstancesReturns struct {
+		result1 []*opi.Instance
+		result2 error
+	}
+	getInstancesReturnsOnCall map[int]struct {
+		result1 []*opi.Instance
+		result2 error
+	}
 	UpdateStub        func(*opi.LRP) error
 	updateMutex       sync.RWMutex
 	updateArgsForCall []struct {
@@ -178,6 +191,70 @@ func (fake *FakeLRPDesirer) GetReturnsOnCall(i int, result1 *opi.LRP, result2 er
 	}{result1, result2}
 }
 
+func (fake *FakeLRPDesirer) GetInstances(arg1 opi.LRPIdentifier) ([]*opi.Instance, error) {
+	fake.getInstancesMutex.Lock()
+	ret, specificReturn := fake.getInstancesReturnsOnCall[len(fake.getInstancesArgsForCall)]
+	fake.getInstancesArgsForCall = append(fake.getInstancesArgsForCall, struct {
+		arg1 opi.LRPIdentifier
+	}{arg1})
+	stub := fake.GetInstancesStub
+	fakeReturns := fake.getInstancesReturns
+	fake.recordInvocation("GetInstances", []interface{}{arg1})
+	fake.getInstancesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLRPDesirer) GetInstancesCallCount() int {
+	fake.getInstancesMutex.RLock()
+	defer fake.getInstancesMutex.RUnlock()
+	return len(fake.getInstancesArgsForCall)
+}
+
+func (fake *FakeLRPDesirer) GetInstancesCalls(stub func(opi.LRPIdentifier) ([]*opi.Instance, error)) {
+	fake.getInstancesMutex.Lock()
+	defer fake.getInstancesMutex.Unlock()
+	fake.GetInstancesStub = stub
+}
+
+func (fake *FakeLRPDesirer) GetInstancesArgsForCall(i int) opi.LRPIdentifier {
+	fake.getInstancesMutex.RLock()
+	defer fake.getInstancesMutex.RUnlock()
+	argsForCall := fake.getInstancesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLRPDesirer) GetInstancesReturns(result1 []*opi.Instance, result2 error) {
+	fake.getInstancesMutex.Lock()
+	defer fake.getInstancesMutex.Unlock()
+	fake.GetInstancesStub = nil
+	fake.getInstancesReturns = struct {
+		result1 []*opi.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLRPDesirer) GetInstancesReturnsOnCall(i int, result1 []*opi.Instance, result2 error) {
+	fake.getInstancesMutex.Lock()
+	defer fake.getInstancesMutex.Unlock()
+	fake.GetInstancesStub = nil
+	if fake.getInstancesReturnsOnCall == nil {
+		fake.getInstancesReturnsOnCall = make(map[int]struct {
+			result1 []*opi.Instance
+			result2 error
+		})
+	}
+	fake.getInstancesReturnsOnCall[i] = struct {
+		result1 []*opi.Instance
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLRPDesirer) Update(arg1 *opi.LRP) error {
 	fake.updateMutex.Lock()
 	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
@@ -246,6 +323,8 @@ func (fake *FakeLRPDesirer) Invocations() map[string][][]interface{} {
 	defer fake.desireMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.getInstancesMutex.RLock()
+	defer fake.getInstancesMutex.RUnlock()
 	fake.updateMutex.RLock()
 	defer fake.updateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
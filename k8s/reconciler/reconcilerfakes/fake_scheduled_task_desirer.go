@@ -0,0 +1,270 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package reconcilerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/reconciler"
+	"code.cloudfoundry.org/eirini/opi"
+)
+
+type FakeScheduledTaskDesirer struct {
+	DesireStub        func(string, *opi.ScheduledTask, ...k8s.DesireOption) error
+	desireMutex       sync.RWMutex
+	desireArgsForCall []struct {
+		arg1 string
+		arg2 *opi.ScheduledTask
+		arg3 []k8s.DesireOption
+	}
+	desireReturns struct {
+		result1 error
+	}
+	desireReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetStub        func(string) (*opi.ScheduledTask, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 string
+	}
+	getReturns struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}
+	UpdateStub        func(*opi.ScheduledTask) error
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 *opi.ScheduledTask
+	}
+	updateReturns struct {
+		result1 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeScheduledTaskDesirer) Desire(arg1 string, arg2 *opi.ScheduledTask, arg3 ...k8s.DesireOption) error {
+	fake.desireMutex.Lock()
+	ret, specificReturn := fake.desireReturnsOnCall[len(fake.desireArgsForCall)]
+	fake.desireArgsForCall = append(fake.desireArgsForCall, struct {
+		arg1 string
+		arg2 *opi.ScheduledTask
+		arg3 []k8s.DesireOption
+	}{arg1, arg2, arg3})
+	stub := fake.DesireStub
+	fakeReturns := fake.desireReturns
+	fake.recordInvocation("Desire", []interface{}{arg1, arg2, arg3})
+	fake.desireMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireCallCount() int {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	return len(fake.desireArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireCalls(stub func(string, *opi.ScheduledTask, ...k8s.DesireOption) error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireArgsForCall(i int) (string, *opi.ScheduledTask, []k8s.DesireOption) {
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	argsForCall := fake.desireArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireReturns(result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	fake.desireReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) DesireReturnsOnCall(i int, result1 error) {
+	fake.desireMutex.Lock()
+	defer fake.desireMutex.Unlock()
+	fake.DesireStub = nil
+	if fake.desireReturnsOnCall == nil {
+		fake.desireReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.desireReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) Get(arg1 string) (*opi.ScheduledTask, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeScheduledTaskDesirer) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) GetCalls(stub func(string) (*opi.ScheduledTask, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskDesirer) GetReturns(result1 *opi.ScheduledTask, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) GetReturnsOnCall(i int, result1 *opi.ScheduledTask, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 *opi.ScheduledTask
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 *opi.ScheduledTask
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScheduledTaskDesirer) Update(arg1 *opi.ScheduledTask) error {
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 *opi.ScheduledTask
+	}{arg1})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateCalls(stub func(*opi.ScheduledTask) error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateArgsForCall(i int) *opi.ScheduledTask {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateReturns(result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) UpdateReturnsOnCall(i int, result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScheduledTaskDesirer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.desireMutex.RLock()
+	defer fake.desireMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeScheduledTaskDesirer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ reconciler.ScheduledTaskDesirer = new(FakeScheduledTaskDesirer)
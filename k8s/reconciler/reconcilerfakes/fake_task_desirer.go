@@ -23,6 +23,19 @@ type FakeTaskDesirer struct {
 	desireReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetStatusStub        func(string) (opi.TaskStatus, error)
+	getStatusMutex       sync.RWMutex
+	getStatusArgsForCall []struct {
+		arg1 string
+	}
+	getStatusReturns struct {
+		result1 opi.TaskStatus
+		result2 error
+	}
+	getStatusReturnsOnCall map[int]struct {
+		result1 opi.TaskStatus
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -90,11 +103,77 @@ func (fake *FakeTaskDesirer) DesireReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeTaskDesirer) GetStatus(arg1 string) (opi.TaskStatus, error) {
+	fake.getStatusMutex.Lock()
+	ret, specificReturn := fake.getStatusReturnsOnCall[len(fake.getStatusArgsForCall)]
+	fake.getStatusArgsForCall = append(fake.getStatusArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStatusStub
+	fakeReturns := fake.getStatusReturns
+	fake.recordInvocation("GetStatus", []interface{}{arg1})
+	fake.getStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTaskDesirer) GetStatusCallCount() int {
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
+	return len(fake.getStatusArgsForCall)
+}
+
+func (fake *FakeTaskDesirer) GetStatusCalls(stub func(string) (opi.TaskStatus, error)) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = stub
+}
+
+func (fake *FakeTaskDesirer) GetStatusArgsForCall(i int) string {
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
+	argsForCall := fake.getStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTaskDesirer) GetStatusReturns(result1 opi.TaskStatus, result2 error) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = nil
+	fake.getStatusReturns = struct {
+		result1 opi.TaskStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTaskDesirer) GetStatusReturnsOnCall(i int, result1 opi.TaskStatus, result2 error) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = nil
+	if fake.getStatusReturnsOnCall == nil {
+		fake.getStatusReturnsOnCall = make(map[int]struct {
+			result1 opi.TaskStatus
+			result2 error
+		})
+	}
+	fake.getStatusReturnsOnCall[i] = struct {
+		result1 opi.TaskStatus
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeTaskDesirer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.desireMutex.RLock()
 	defer fake.desireMutex.RUnlock()
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,164 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/opi"
+	eiriniv1 "code.cloudfoundry.org/eirini/pkg/apis/eirini/v1"
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//counterfeiter:generate . ScheduledTaskDesirer
+
+type ScheduledTask struct {
+	client               client.Client
+	scheduledTaskDesirer ScheduledTaskDesirer
+	scheme               *runtime.Scheme
+	logger               lager.Logger
+}
+
+func NewScheduledTask(logger lager.Logger, client client.Client, scheduledTaskDesirer ScheduledTaskDesirer, scheme *runtime.Scheme) *ScheduledTask {
+	return &ScheduledTask{
+		client:               client,
+		scheduledTaskDesirer: scheduledTaskDesirer,
+		scheme:               scheme,
+		logger:               logger,
+	}
+}
+
+type ScheduledTaskDesirer interface {
+	Desire(namespace string, task *opi.ScheduledTask, opts ...k8s.DesireOption) error
+	Update(task *opi.ScheduledTask) error
+	Get(guid string) (*opi.ScheduledTask, error)
+}
+
+func (s *ScheduledTask) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	scheduledTask := &eiriniv1.ScheduledTask{}
+	logger := s.logger.Session("reconcile-scheduled-task", lager.Data{"request": request})
+	logger.Debug("start")
+
+	err := s.client.Get(context.Background(), request.NamespacedName, scheduledTask)
+	if apierrors.IsNotFound(err) {
+		logger.Error("no-such-scheduled-task", err)
+
+		return reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		logger.Error("scheduled-task-get-failed", err)
+
+		return reconcile.Result{}, fmt.Errorf("could not fetch scheduled task: %w", err)
+	}
+
+	if err = scheduledTask.Spec.Task.PrivateRegistry.Validate(); err != nil {
+		logger.Error("invalid-scheduled-task-spec", err)
+
+		return reconcile.Result{}, nil
+	}
+
+	desired := toOpiScheduledTask(scheduledTask)
+
+	current, err := s.scheduledTaskDesirer.Get(desired.GUID)
+	if errors.Is(err, eirini.ErrNotFound) {
+		if err = s.scheduledTaskDesirer.Desire(scheduledTask.Namespace, desired, s.setOwnerFn(scheduledTask)); err != nil {
+			logger.Error("desire-scheduled-task-failed", err)
+
+			return reconcile.Result{}, errors.Wrap(err, "failed to desire scheduled task")
+		}
+
+		logger.Debug("scheduled-task-desired-successfully")
+
+		return reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		logger.Error("get-scheduled-task-failed", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to get scheduled task")
+	}
+
+	if scheduleChanged(current, desired) {
+		if err = s.scheduledTaskDesirer.Update(desired); err != nil {
+			logger.Error("update-scheduled-task-failed", err)
+
+			return reconcile.Result{}, errors.Wrap(err, "failed to update scheduled task")
+		}
+
+		logger.Debug("scheduled-task-updated-successfully")
+	}
+
+	if err = s.updateStatus(scheduledTask, current); err != nil {
+		logger.Error("update-scheduled-task-status-failed", err)
+
+		return reconcile.Result{}, errors.Wrap(err, "failed to update scheduled task status")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (s *ScheduledTask) updateStatus(scheduledTask *eiriniv1.ScheduledTask, current *opi.ScheduledTask) error {
+	status := eiriniv1.ScheduledTaskStatus{
+		LastScheduleTime: toMetaTime(current.LastScheduledAt),
+		ActiveRuns:       int32(current.ActiveRuns),
+	}
+
+	if equality.Semantic.DeepEqual(status, scheduledTask.Status) {
+		return nil
+	}
+
+	scheduledTask.Status = status
+
+	return s.client.Status().Update(context.Background(), scheduledTask)
+}
+
+func (s *ScheduledTask) setOwnerFn(scheduledTask *eiriniv1.ScheduledTask) func(interface{}) error {
+	return func(resource interface{}) error {
+		obj := resource.(metav1.Object)
+		if err := ctrl.SetControllerReference(scheduledTask, obj, s.scheme); err != nil {
+			return errors.Wrap(err, "failed to set controller reference")
+		}
+
+		return nil
+	}
+}
+
+// Kubernetes defaults the concurrency policy and history limits, so unset
+// ones do not count as changes.
+func scheduleChanged(current, desired *opi.ScheduledTask) bool {
+	concurrencyPolicy := desired.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = opi.AllowConcurrentRuns
+	}
+
+	return current.Schedule != desired.Schedule ||
+		current.ConcurrencyPolicy != concurrencyPolicy ||
+		current.Suspend != desired.Suspend ||
+		historyLimitChanged(current.SuccessfulRunsHistoryLimit, desired.SuccessfulRunsHistoryLimit) ||
+		historyLimitChanged(current.FailedRunsHistoryLimit, desired.FailedRunsHistoryLimit)
+}
+
+func historyLimitChanged(current, desired *int32) bool {
+	return desired != nil && (current == nil || *current != *desired)
+}
+
+func toOpiScheduledTask(scheduledTask *eiriniv1.ScheduledTask) *opi.ScheduledTask {
+	return &opi.ScheduledTask{
+		Task:                       toOpiTask(&eiriniv1.Task{Spec: scheduledTask.Spec.Task}),
+		Schedule:                   scheduledTask.Spec.Schedule,
+		ConcurrencyPolicy:          string(scheduledTask.Spec.ConcurrencyPolicy),
+		Suspend:                    scheduledTask.Spec.Suspend,
+		SuccessfulRunsHistoryLimit: scheduledTask.Spec.SuccessfulRunsHistoryLimit,
+		FailedRunsHistoryLimit:     scheduledTask.Spec.FailedRunsHistoryLimit,
+	}
+}
@@ -0,0 +1,320 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/k8s/reconciler"
+	"code.cloudfoundry.org/eirini/k8s/reconciler/reconcilerfakes"
+	"code.cloudfoundry.org/eirini/opi"
+	eiriniv1 "code.cloudfoundry.org/eirini/pkg/apis/eirini/v1"
+	eiriniv1scheme "code.cloudfoundry.org/eirini/pkg/generated/clientset/versioned/scheme"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("ScheduledTask", func() {
+	var (
+		scheduledTaskReconciler *reconciler.ScheduledTask
+		reconcileResult         reconcile.Result
+		reconcileErr            error
+		controllerClient        *reconcilerfakes.FakeClient
+		namespacedName          types.NamespacedName
+		scheduledTaskDesirer    *reconcilerfakes.FakeScheduledTaskDesirer
+		statusWriter            *reconcilerfakes.FakeStatusWriter
+		historyLimit            int32
+	)
+
+	BeforeEach(func() {
+		controllerClient = new(reconcilerfakes.FakeClient)
+		namespacedName = types.NamespacedName{
+			Namespace: "my-namespace",
+			Name:      "my-name",
+		}
+		scheduledTaskDesirer = new(reconcilerfakes.FakeScheduledTaskDesirer)
+		statusWriter = new(reconcilerfakes.FakeStatusWriter)
+		controllerClient.StatusReturns(statusWriter)
+		historyLimit = 3
+
+		controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+			scheduledTask, ok := obj.(*eiriniv1.ScheduledTask)
+			Expect(ok).To(BeTrue())
+
+			scheduledTask.Name = namespacedName.Name
+			scheduledTask.Namespace = namespacedName.Namespace
+			scheduledTask.Spec.Schedule = "*/5 * * * *"
+			scheduledTask.Spec.ConcurrencyPolicy = eiriniv1.ForbidConcurrent
+			scheduledTask.Spec.SuccessfulRunsHistoryLimit = &historyLimit
+			scheduledTask.Spec.Task = eiriniv1.TaskSpec{
+				GUID:               "my-task-guid",
+				Name:               "my-task-name",
+				Image:              "my-task-image",
+				CompletionCallback: "my-task-completion-callback",
+				Command:            []string{"beam", "me", "up"},
+				AppName:            "arthur",
+				IsolationSegment:   "isolated",
+			}
+
+			return nil
+		}
+
+		scheduledTaskDesirer.GetReturns(nil, eirini.ErrNotFound)
+
+		logger := lagertest.NewTestLogger("scheduled-task-reconciler")
+		scheduledTaskReconciler = reconciler.NewScheduledTask(logger, controllerClient, scheduledTaskDesirer, eiriniv1scheme.Scheme)
+	})
+
+	JustBeforeEach(func() {
+		reconcileResult, reconcileErr = scheduledTaskReconciler.Reconcile(reconcile.Request{NamespacedName: namespacedName})
+	})
+
+	When("the scheduled task has not been desired yet", func() {
+		It("desires it in the CR's namespace", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+
+			Expect(scheduledTaskDesirer.GetCallCount()).To(Equal(1))
+			Expect(scheduledTaskDesirer.GetArgsForCall(0)).To(Equal("my-task-guid"))
+
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(Equal(1))
+			namespace, scheduledTask, _ := scheduledTaskDesirer.DesireArgsForCall(0)
+			Expect(namespace).To(Equal("my-namespace"))
+			Expect(scheduledTask.Schedule).To(Equal("*/5 * * * *"))
+			Expect(scheduledTask.ConcurrencyPolicy).To(Equal(opi.ForbidConcurrentRuns))
+			Expect(scheduledTask.Suspend).To(BeFalse())
+			Expect(scheduledTask.SuccessfulRunsHistoryLimit).To(Equal(&historyLimit))
+			Expect(scheduledTask.FailedRunsHistoryLimit).To(BeNil())
+		})
+
+		It("converts the task template", func() {
+			_, scheduledTask, _ := scheduledTaskDesirer.DesireArgsForCall(0)
+			Expect(scheduledTask.GUID).To(Equal("my-task-guid"))
+			Expect(scheduledTask.Name).To(Equal("my-task-name"))
+			Expect(scheduledTask.Image).To(Equal("my-task-image"))
+			Expect(scheduledTask.CompletionCallback).To(Equal("my-task-completion-callback"))
+			Expect(scheduledTask.Command).To(Equal([]string{"beam", "me", "up"}))
+			Expect(scheduledTask.AppName).To(Equal("arthur"))
+			Expect(scheduledTask.PlacementTags).To(Equal([]string{"isolated"}))
+		})
+
+		It("sets an owner reference in the cron job", func() {
+			_, _, setOwnerFns := scheduledTaskDesirer.DesireArgsForCall(0)
+			Expect(setOwnerFns).To(HaveLen(1))
+
+			cronJob := &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace"}}
+			Expect(setOwnerFns[0](cronJob)).To(Succeed())
+			Expect(cronJob.OwnerReferences).To(HaveLen(1))
+			Expect(cronJob.OwnerReferences[0].Kind).To(Equal("ScheduledTask"))
+			Expect(cronJob.OwnerReferences[0].Name).To(Equal("my-name"))
+		})
+
+		It("does not update the schedule", func() {
+			Expect(scheduledTaskDesirer.UpdateCallCount()).To(BeZero())
+		})
+
+		When("desiring the scheduled task fails", func() {
+			BeforeEach(func() {
+				scheduledTaskDesirer.DesireReturns(fmt.Errorf("some-error"))
+			})
+
+			It("returns an error", func() {
+				Expect(reconcileErr).To(MatchError(ContainSubstring("some-error")))
+			})
+		})
+	})
+
+	When("the scheduled task already exists", func() {
+		var (
+			current          *opi.ScheduledTask
+			lastScheduleTime time.Time
+		)
+
+		BeforeEach(func() {
+			lastScheduleTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			current = &opi.ScheduledTask{
+				Task:                       &opi.Task{GUID: "my-task-guid"},
+				Schedule:                   "*/5 * * * *",
+				ConcurrencyPolicy:          opi.ForbidConcurrentRuns,
+				SuccessfulRunsHistoryLimit: &historyLimit,
+				FailedRunsHistoryLimit:     &historyLimit,
+				LastScheduledAt:            lastScheduleTime.UnixNano(),
+				ActiveRuns:                 2,
+			}
+			scheduledTaskDesirer.GetReturns(current, nil)
+		})
+
+		It("does not desire it again", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(BeZero())
+		})
+
+		It("does not update an unchanged schedule", func() {
+			Expect(scheduledTaskDesirer.UpdateCallCount()).To(BeZero())
+		})
+
+		It("updates the status of the scheduled task", func() {
+			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+			_, obj, _ := statusWriter.UpdateArgsForCall(0)
+			scheduledTask := obj.(*eiriniv1.ScheduledTask)
+			Expect(scheduledTask.Status.ActiveRuns).To(BeNumerically("==", 2))
+			Expect(scheduledTask.Status.LastScheduleTime.Time).To(BeTemporally("==", lastScheduleTime))
+		})
+
+		When("the schedule has changed", func() {
+			BeforeEach(func() {
+				current.Schedule = "@daily"
+			})
+
+			It("updates it", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(scheduledTaskDesirer.UpdateCallCount()).To(Equal(1))
+				scheduledTask := scheduledTaskDesirer.UpdateArgsForCall(0)
+				Expect(scheduledTask.GUID).To(Equal("my-task-guid"))
+				Expect(scheduledTask.Schedule).To(Equal("*/5 * * * *"))
+			})
+
+			When("updating the schedule fails", func() {
+				BeforeEach(func() {
+					scheduledTaskDesirer.UpdateReturns(fmt.Errorf("update-error"))
+				})
+
+				It("returns an error", func() {
+					Expect(reconcileErr).To(MatchError(ContainSubstring("update-error")))
+				})
+			})
+		})
+
+		When("the scheduled task has been suspended", func() {
+			BeforeEach(func() {
+				getStub := controllerClient.GetStub
+				controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+					Expect(getStub(ctx, namespacedName, obj)).To(Succeed())
+					obj.(*eiriniv1.ScheduledTask).Spec.Suspend = true
+
+					return nil
+				}
+			})
+
+			It("suspends the cron job", func() {
+				Expect(scheduledTaskDesirer.UpdateCallCount()).To(Equal(1))
+				Expect(scheduledTaskDesirer.UpdateArgsForCall(0).Suspend).To(BeTrue())
+			})
+		})
+
+		When("the CR leaves defaulted fields unset", func() {
+			BeforeEach(func() {
+				current.ConcurrencyPolicy = opi.AllowConcurrentRuns
+
+				getStub := controllerClient.GetStub
+				controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+					Expect(getStub(ctx, namespacedName, obj)).To(Succeed())
+					obj.(*eiriniv1.ScheduledTask).Spec.ConcurrencyPolicy = ""
+					obj.(*eiriniv1.ScheduledTask).Spec.SuccessfulRunsHistoryLimit = nil
+
+					return nil
+				}
+			})
+
+			It("does not update the schedule", func() {
+				Expect(scheduledTaskDesirer.UpdateCallCount()).To(BeZero())
+			})
+		})
+
+		When("the status is up to date", func() {
+			BeforeEach(func() {
+				getStub := controllerClient.GetStub
+				controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+					Expect(getStub(ctx, namespacedName, obj)).To(Succeed())
+					scheduleTime := metav1.NewTime(lastScheduleTime)
+					obj.(*eiriniv1.ScheduledTask).Status = eiriniv1.ScheduledTaskStatus{
+						LastScheduleTime: &scheduleTime,
+						ActiveRuns:       2,
+					}
+
+					return nil
+				}
+			})
+
+			It("does not update it", func() {
+				Expect(statusWriter.UpdateCallCount()).To(BeZero())
+			})
+		})
+
+		When("updating the status fails", func() {
+			BeforeEach(func() {
+				statusWriter.UpdateReturns(fmt.Errorf("status-error"))
+			})
+
+			It("returns an error", func() {
+				Expect(reconcileErr).To(MatchError(ContainSubstring("status-error")))
+			})
+		})
+	})
+
+	When("getting the scheduled task fails", func() {
+		BeforeEach(func() {
+			scheduledTaskDesirer.GetReturns(nil, fmt.Errorf("get-error"))
+		})
+
+		It("returns an error", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("get-error")))
+		})
+
+		It("does not desire the scheduled task", func() {
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(BeZero())
+		})
+	})
+
+	When("the scheduled task spec is invalid", func() {
+		BeforeEach(func() {
+			getStub := controllerClient.GetStub
+			controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+				Expect(getStub(ctx, namespacedName, obj)).To(Succeed())
+				obj.(*eiriniv1.ScheduledTask).Spec.Task.PrivateRegistry = &eiriniv1.PrivateRegistry{
+					Username:  "user",
+					SecretRef: &eiriniv1.SecretReference{Name: "my-secret"},
+				}
+
+				return nil
+			}
+		})
+
+		It("rejects it without requeuing", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(reconcileResult.Requeue).To(BeFalse())
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(BeZero())
+		})
+	})
+
+	When("the scheduled task does not exist", func() {
+		BeforeEach(func() {
+			controllerClient.GetReturns(errors.NewNotFound(schema.GroupResource{}, "my-name"))
+			controllerClient.GetStub = nil
+		})
+
+		It("does not return an error", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(scheduledTaskDesirer.DesireCallCount()).To(BeZero())
+		})
+	})
+
+	When("getting the scheduled task resource fails", func() {
+		BeforeEach(func() {
+			controllerClient.GetReturns(fmt.Errorf("boom"))
+			controllerClient.GetStub = nil
+		})
+
+		It("returns an error", func() {
+			Expect(reconcileErr).To(MatchError(ContainSubstring("boom")))
+		})
+	})
+})
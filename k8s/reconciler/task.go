@@ -3,13 +3,17 @@ package reconciler
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/opi"
 	eiriniv1 "code.cloudfoundry.org/eirini/pkg/apis/eirini/v1"
 	"code.cloudfoundry.org/lager"
 	exterrors "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -17,6 +21,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// TaskStatusRequeueInterval is how often the status of a task that has not
+// completed yet is refreshed. Only changes to the job trigger a reconciliation,
+// and the job does not change when its pod starts running.
+const TaskStatusRequeueInterval = 10 * time.Second
+
 //counterfeiter:generate . TaskDesirer
 
 type Task struct {
@@ -37,6 +46,7 @@ func NewTask(logger lager.Logger, client client.Client, taskDesirer TaskDesirer,
 
 type TaskDesirer interface {
 	Desire(namespace string, task *opi.Task, opts ...k8s.DesireOption) error
+	GetStatus(taskGUID string) (opi.TaskStatus, error)
 }
 
 func (t *Task) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -57,24 +67,67 @@ func (t *Task) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 		return reconcile.Result{}, fmt.Errorf("could not fetch task: %w", err)
 	}
 
-	err = t.taskDesirer.Desire(task.Namespace, toOpiTask(task), t.setOwnerFn(task))
-	if errors.IsAlreadyExists(err) {
-		logger.Info("task-already-exists")
+	if err = task.Spec.PrivateRegistry.Validate(); err != nil {
+		logger.Error("invalid-task-spec", err)
+
+		return reconcile.Result{}, nil
+	}
+
+	// The job of a completed task is deleted once its TTL has passed, and
+	// must not be desired again then.
+	if isTerminalTaskPhase(task.Status.Phase) {
+		logger.Debug("task-already-completed", lager.Data{"phase": task.Status.Phase})
+
+		return reconcile.Result{}, nil
+	}
+
+	opiStatus, err := t.taskDesirer.GetStatus(task.Spec.GUID)
+	if exterrors.Is(err, eirini.ErrNotFound) {
+		if err = t.taskDesirer.Desire(task.Namespace, toOpiTask(task), t.setOwnerFn(task)); err != nil {
+			logger.Error("desire-task-failed", err)
+
+			return reconcile.Result{}, exterrors.Wrap(err, "failed to desire task")
+		}
+
+		logger.Debug("task-desired-successfully")
 
 		return reconcile.Result{}, nil
 	}
 
 	if err != nil {
-		logger.Error("desire-task-failed", err)
+		logger.Error("get-task-status-failed", err)
+
+		return reconcile.Result{}, exterrors.Wrap(err, "failed to get task status")
+	}
+
+	if err = t.updateStatus(task, opiStatus); err != nil {
+		logger.Error("update-task-status-failed", err)
 
-		return reconcile.Result{}, exterrors.Wrap(err, "failed to desire task")
+		return reconcile.Result{}, exterrors.Wrap(err, "failed to update task status")
 	}
 
-	logger.Debug("task-desired-successfully")
+	if !isTerminalTaskPhase(task.Status.Phase) {
+		return reconcile.Result{RequeueAfter: TaskStatusRequeueInterval}, nil
+	}
 
 	return reconcile.Result{}, nil
 }
 
+func isTerminalTaskPhase(phase eiriniv1.TaskPhase) bool {
+	return phase == eiriniv1.TaskSucceeded || phase == eiriniv1.TaskFailed || phase == eiriniv1.TaskCancelled
+}
+
+func (t *Task) updateStatus(task *eiriniv1.Task, opiStatus opi.TaskStatus) error {
+	status := toTaskStatus(task.Status, opiStatus)
+	if equality.Semantic.DeepEqual(status, task.Status) {
+		return nil
+	}
+
+	task.Status = status
+
+	return t.client.Status().Update(context.Background(), task)
+}
+
 func (t *Task) setOwnerFn(task *eiriniv1.Task) func(interface{}) error {
 	return func(resource interface{}) error {
 		obj := resource.(metav1.Object)
@@ -103,6 +156,8 @@ func toOpiTask(task *eiriniv1.Task) *opi.Task {
 		MemoryMB:           task.Spec.MemoryMB,
 		DiskMB:             task.Spec.DiskMB,
 		CPUWeight:          task.Spec.CPUWeight,
+		TimeoutSeconds:     task.Spec.TimeoutSeconds,
+		ResultFile:         task.Spec.ResultFile,
 	}
 
 	if task.Spec.PrivateRegistry != nil {
@@ -111,7 +166,118 @@ func toOpiTask(task *eiriniv1.Task) *opi.Task {
 			Username: task.Spec.PrivateRegistry.Username,
 			Password: task.Spec.PrivateRegistry.Password,
 		}
+
+		if task.Spec.PrivateRegistry.SecretRef != nil {
+			opiTask.PrivateRegistry.SecretName = task.Spec.PrivateRegistry.SecretRef.Name
+		}
+	}
+
+	if task.Spec.RetryPolicy != nil {
+		opiTask.RetryPolicy = &opi.TaskRetryPolicy{
+			MaxAttempts:      task.Spec.RetryPolicy.MaxAttempts,
+			BackoffSeconds:   task.Spec.RetryPolicy.BackoffSeconds,
+			RetryOnExitCodes: task.Spec.RetryPolicy.RetryOnExitCodes,
+		}
+	}
+
+	for _, rule := range task.Spec.EgressRules {
+		opiRule := opi.EgressRule{
+			Protocol:     rule.Protocol,
+			Destinations: rule.Destinations,
+			Ports:        rule.Ports,
+		}
+
+		if rule.PortRange != nil {
+			opiRule.PortRange = &opi.PortRange{Start: rule.PortRange.Start, End: rule.PortRange.End}
+		}
+
+		if rule.IcmpInfo != nil {
+			opiRule.IcmpInfo = &opi.ICMPInfo{Type: rule.IcmpInfo.Type, Code: rule.IcmpInfo.Code}
+		}
+
+		opiTask.EgressRules = append(opiTask.EgressRules, opiRule)
+	}
+
+	if task.Spec.IsolationSegment != "" {
+		opiTask.PlacementTags = []string{task.Spec.IsolationSegment}
 	}
 
 	return opiTask
 }
+
+func toTaskStatus(currentStatus eiriniv1.TaskStatus, opiStatus opi.TaskStatus) eiriniv1.TaskStatus {
+	status := eiriniv1.TaskStatus{
+		Phase:         toTaskPhase(opiStatus.State),
+		StartTime:     toMetaTime(opiStatus.StartedAt),
+		EndTime:       toMetaTime(opiStatus.FinishedAt),
+		ExitCode:      opiStatus.ExitCode,
+		FailureReason: opiStatus.FailureReason,
+		Attempts:      toTaskAttempts(opiStatus.Attempts),
+		Conditions:    append([]metav1.Condition{}, currentStatus.Conditions...),
+	}
+
+	setTaskCondition(&status, eiriniv1.TaskStartedConditionType, status.Phase != eiriniv1.TaskPending)
+	setTaskCondition(&status, eiriniv1.TaskSucceededConditionType, status.Phase == eiriniv1.TaskSucceeded)
+	setTaskCondition(&status, eiriniv1.TaskFailedConditionType,
+		status.Phase == eiriniv1.TaskFailed || status.Phase == eiriniv1.TaskCancelled)
+
+	return status
+}
+
+func toTaskAttempts(opiAttempts []opi.TaskAttempt) []eiriniv1.TaskAttempt {
+	if len(opiAttempts) == 0 {
+		return nil
+	}
+
+	attempts := make([]eiriniv1.TaskAttempt, 0, len(opiAttempts))
+	for _, attempt := range opiAttempts {
+		attempts = append(attempts, eiriniv1.TaskAttempt{
+			StartTime:     toMetaTime(attempt.StartedAt),
+			EndTime:       toMetaTime(attempt.FinishedAt),
+			ExitCode:      attempt.ExitCode,
+			FailureReason: attempt.FailureReason,
+		})
+	}
+
+	return attempts
+}
+
+func setTaskCondition(status *eiriniv1.TaskStatus, conditionType string, value bool) {
+	condition := metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(status.Phase),
+		Message: status.FailureReason,
+	}
+
+	if value {
+		condition.Status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+func toTaskPhase(state string) eiriniv1.TaskPhase {
+	switch state {
+	case opi.TaskRunningState:
+		return eiriniv1.TaskRunning
+	case opi.TaskSucceededState:
+		return eiriniv1.TaskSucceeded
+	case opi.TaskFailedState:
+		return eiriniv1.TaskFailed
+	case opi.TaskCancelledState:
+		return eiriniv1.TaskCancelled
+	default:
+		return eiriniv1.TaskPending
+	}
+}
+
+func toMetaTime(unixNano int64) *metav1.Time {
+	if unixNano == 0 {
+		return nil
+	}
+
+	t := metav1.NewTime(time.Unix(0, unixNano)).Rfc3339Copy()
+
+	return &t
+}
@@ -3,7 +3,9 @@ package reconciler_test
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s/reconciler"
 	"code.cloudfoundry.org/eirini/k8s/reconciler/reconcilerfakes"
 	"code.cloudfoundry.org/eirini/opi"
@@ -14,6 +16,7 @@ import (
 	. "github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -29,6 +32,7 @@ var _ = Describe("Task", func() {
 		controllerClient *reconcilerfakes.FakeClient
 		namespacedName   types.NamespacedName
 		taskDesirer      *reconcilerfakes.FakeTaskDesirer
+		statusWriter     *reconcilerfakes.FakeStatusWriter
 		scheme           *runtime.Scheme
 	)
 
@@ -39,6 +43,8 @@ var _ = Describe("Task", func() {
 			Name:      "my-name",
 		}
 		taskDesirer = new(reconcilerfakes.FakeTaskDesirer)
+		statusWriter = new(reconcilerfakes.FakeStatusWriter)
+		controllerClient.StatusReturns(statusWriter)
 
 		scheme = eiriniv1scheme.Scheme
 		logger := lagertest.NewTestLogger("task-reconciler")
@@ -77,9 +83,28 @@ var _ = Describe("Task", func() {
 				task.Spec.MemoryMB = 1234
 				task.Spec.DiskMB = 4312
 				task.Spec.CPUWeight = 14
+				task.Spec.IsolationSegment = "isolated"
+				task.Spec.TimeoutSeconds = 600
+				task.Spec.ResultFile = "/tmp/result"
+				task.Spec.RetryPolicy = &eiriniv1.TaskRetryPolicy{MaxAttempts: 3, BackoffSeconds: 10, RetryOnExitCodes: []int32{75}}
+				task.Spec.EgressRules = []eiriniv1.EgressRule{
+					{Protocol: "tcp", Destinations: []string{"10.0.0.0/8"}, PortRange: &eiriniv1.PortRange{Start: 80, End: 90}},
+					{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &eiriniv1.ICMPInfo{Type: 8, Code: 0}},
+				}
 
 				return nil
 			}
+
+			taskDesirer.GetStatusReturns(opi.TaskStatus{}, eirini.ErrNotFound)
+		})
+
+		It("looks the job up by the task GUID first", func() {
+			Expect(taskDesirer.GetStatusCallCount()).To(Equal(1))
+			Expect(taskDesirer.GetStatusArgsForCall(0)).To(Equal("my-task-guid"))
+		})
+
+		It("leaves the task status to the reconciliation triggered by the new job", func() {
+			Expect(statusWriter.UpdateCallCount()).To(BeZero())
 		})
 
 		It("creates the job in the CR's namespace", func() {
@@ -118,6 +143,14 @@ var _ = Describe("Task", func() {
 				Expect(opiTask.MemoryMB).To(BeNumerically("==", 1234))
 				Expect(opiTask.DiskMB).To(BeNumerically("==", 4312))
 				Expect(opiTask.CPUWeight).To(BeNumerically("==", 14))
+				Expect(opiTask.PlacementTags).To(Equal([]string{"isolated"}))
+				Expect(opiTask.TimeoutSeconds).To(BeNumerically("==", 600))
+				Expect(opiTask.ResultFile).To(Equal("/tmp/result"))
+				Expect(opiTask.RetryPolicy).To(Equal(&opi.TaskRetryPolicy{MaxAttempts: 3, BackoffSeconds: 10, RetryOnExitCodes: []int32{75}}))
+				Expect(opiTask.EgressRules).To(Equal([]opi.EgressRule{
+					{Protocol: "tcp", Destinations: []string{"10.0.0.0/8"}, PortRange: &opi.PortRange{Start: 80, End: 90}},
+					{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &opi.ICMPInfo{Type: 8, Code: 0}},
+				}))
 			})
 
 			By("sets an owner reference in the statefulset", func() {
@@ -133,6 +166,208 @@ var _ = Describe("Task", func() {
 				Expect(job.ObjectMeta.OwnerReferences[0].Name).To(Equal("my-name"))
 			})
 		})
+
+		When("the task references a registry secret", func() {
+			BeforeEach(func() {
+				controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+					task := obj.(*eiriniv1.Task)
+					task.Namespace = namespacedName.Namespace
+					task.Spec.PrivateRegistry = &eiriniv1.PrivateRegistry{
+						SecretRef: &eiriniv1.SecretReference{Name: "my-registry-secret"},
+					}
+
+					return nil
+				}
+			})
+
+			It("passes the secret name to the task desirer", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(taskDesirer.DesireCallCount()).To(Equal(1))
+				_, opiTask, _ := taskDesirer.DesireArgsForCall(0)
+				Expect(opiTask.PrivateRegistry).To(Equal(&opi.PrivateRegistry{SecretName: "my-registry-secret"}))
+			})
+
+			When("the task also sets registry credentials", func() {
+				BeforeEach(func() {
+					controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+						task := obj.(*eiriniv1.Task)
+						task.Spec.PrivateRegistry = &eiriniv1.PrivateRegistry{
+							Username:  "pr-username",
+							Password:  "pr-password",
+							SecretRef: &eiriniv1.SecretReference{Name: "my-registry-secret"},
+						}
+
+						return nil
+					}
+				})
+
+				It("rejects the task without requeuing it", func() {
+					Expect(reconcileErr).NotTo(HaveOccurred())
+					Expect(reconcileResult.Requeue).To(BeFalse())
+					Expect(taskDesirer.DesireCallCount()).To(BeZero())
+				})
+			})
+		})
+	})
+
+	Context("updating the task status", func() {
+		var (
+			startedAt  time.Time
+			finishedAt time.Time
+		)
+
+		BeforeEach(func() {
+			startedAt = time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+			finishedAt = time.Date(2020, 1, 2, 3, 5, 5, 6, time.UTC)
+
+			controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+				task, ok := obj.(*eiriniv1.Task)
+				Expect(ok).To(BeTrue())
+
+				task.Name = namespacedName.Name
+				task.Namespace = namespacedName.Namespace
+				task.Spec.GUID = "my-task-guid"
+
+				return nil
+			}
+
+			exitCode := int32(1)
+			taskDesirer.GetStatusReturns(opi.TaskStatus{
+				State:         opi.TaskFailedState,
+				StartedAt:     startedAt.UnixNano(),
+				FinishedAt:    finishedAt.UnixNano(),
+				ExitCode:      &exitCode,
+				FailureReason: "Error",
+			}, nil)
+		})
+
+		It("gets the status of the task", func() {
+			Expect(taskDesirer.GetStatusCallCount()).To(Equal(1))
+			Expect(taskDesirer.GetStatusArgsForCall(0)).To(Equal("my-task-guid"))
+		})
+
+		It("updates the task status", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+
+			_, obj, _ := statusWriter.UpdateArgsForCall(0)
+			task, ok := obj.(*eiriniv1.Task)
+			Expect(ok).To(BeTrue())
+
+			Expect(task.Status.Phase).To(Equal(eiriniv1.TaskFailed))
+			Expect(task.Status.StartTime.Time).To(BeTemporally("~", startedAt, time.Second))
+			Expect(task.Status.EndTime.Time).To(BeTemporally("~", finishedAt, time.Second))
+			Expect(*task.Status.ExitCode).To(BeNumerically("==", 1))
+			Expect(task.Status.FailureReason).To(Equal("Error"))
+		})
+
+		It("sets the task conditions", func() {
+			_, obj, _ := statusWriter.UpdateArgsForCall(0)
+			task := obj.(*eiriniv1.Task)
+
+			Expect(meta.IsStatusConditionTrue(task.Status.Conditions, eiriniv1.TaskStartedConditionType)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(task.Status.Conditions, eiriniv1.TaskSucceededConditionType)).To(BeTrue())
+
+			failed := meta.FindStatusCondition(task.Status.Conditions, eiriniv1.TaskFailedConditionType)
+			Expect(failed).NotTo(BeNil())
+			Expect(failed.Status).To(Equal(metav1.ConditionTrue))
+			Expect(failed.Reason).To(Equal("Failed"))
+			Expect(failed.Message).To(Equal("Error"))
+		})
+
+		When("the task has been attempted several times", func() {
+			BeforeEach(func() {
+				firstExitCode := int32(75)
+				taskDesirer.GetStatusReturns(opi.TaskStatus{
+					State:     opi.TaskRunningState,
+					StartedAt: finishedAt.UnixNano(),
+					Attempts: []opi.TaskAttempt{
+						{StartedAt: startedAt.UnixNano(), FinishedAt: finishedAt.UnixNano(), ExitCode: &firstExitCode, FailureReason: "Error"},
+						{StartedAt: finishedAt.UnixNano()},
+					},
+				}, nil)
+			})
+
+			It("records the attempt history", func() {
+				_, obj, _ := statusWriter.UpdateArgsForCall(0)
+				task := obj.(*eiriniv1.Task)
+
+				Expect(task.Status.Attempts).To(HaveLen(2))
+				Expect(task.Status.Attempts[0].StartTime.Time).To(BeTemporally("~", startedAt, time.Second))
+				Expect(task.Status.Attempts[0].EndTime.Time).To(BeTemporally("~", finishedAt, time.Second))
+				Expect(*task.Status.Attempts[0].ExitCode).To(BeNumerically("==", 75))
+				Expect(task.Status.Attempts[0].FailureReason).To(Equal("Error"))
+				Expect(task.Status.Attempts[1].EndTime).To(BeNil())
+			})
+		})
+
+		When("the task is pending", func() {
+			BeforeEach(func() {
+				taskDesirer.GetStatusReturns(opi.TaskStatus{State: opi.TaskPendingState}, nil)
+			})
+
+			It("sets the pending phase without any true conditions", func() {
+				_, obj, _ := statusWriter.UpdateArgsForCall(0)
+				task := obj.(*eiriniv1.Task)
+
+				Expect(task.Status.Phase).To(Equal(eiriniv1.TaskPending))
+				Expect(task.Status.StartTime).To(BeNil())
+				Expect(task.Status.Conditions).To(HaveLen(3))
+
+				for _, condition := range task.Status.Conditions {
+					Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				}
+			})
+		})
+
+		When("the task status has not changed", func() {
+			BeforeEach(func() {
+				taskDesirer.GetStatusReturns(opi.TaskStatus{State: opi.TaskRunningState, StartedAt: startedAt.UnixNano()}, nil)
+
+				controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+					task := obj.(*eiriniv1.Task)
+					startTime := metav1.NewTime(startedAt).Rfc3339Copy()
+					task.Status.Phase = eiriniv1.TaskRunning
+					task.Status.StartTime = &startTime
+					task.Status.Conditions = []metav1.Condition{
+						{Type: eiriniv1.TaskStartedConditionType, Status: metav1.ConditionTrue, Reason: "Running"},
+						{Type: eiriniv1.TaskSucceededConditionType, Status: metav1.ConditionFalse, Reason: "Running"},
+						{Type: eiriniv1.TaskFailedConditionType, Status: metav1.ConditionFalse, Reason: "Running"},
+					}
+
+					return nil
+				}
+			})
+
+			It("does not update the status", func() {
+				Expect(reconcileErr).NotTo(HaveOccurred())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			})
+		})
+
+		When("getting the task status fails", func() {
+			BeforeEach(func() {
+				taskDesirer.GetStatusReturns(opi.TaskStatus{}, fmt.Errorf("status-error"))
+			})
+
+			It("returns an error", func() {
+				Expect(reconcileErr).To(MatchError(ContainSubstring("status-error")))
+			})
+
+			It("does not update the status", func() {
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			})
+		})
+
+		When("updating the task status fails", func() {
+			BeforeEach(func() {
+				statusWriter.UpdateReturns(fmt.Errorf("update-error"))
+			})
+
+			It("returns an error", func() {
+				Expect(reconcileErr).To(MatchError(ContainSubstring("update-error")))
+			})
+		})
 	})
 
 	When("the task cannot be found", func() {
@@ -158,6 +393,7 @@ var _ = Describe("Task", func() {
 
 	When("desiring the task returns an error", func() {
 		BeforeEach(func() {
+			taskDesirer.GetStatusReturns(opi.TaskStatus{}, eirini.ErrNotFound)
 			taskDesirer.DesireReturns(fmt.Errorf("some error"))
 		})
 
@@ -166,14 +402,79 @@ var _ = Describe("Task", func() {
 		})
 	})
 
-	When("the task already exists", func() {
+	When("the job of the task already exists", func() {
 		BeforeEach(func() {
-			taskDesirer.DesireReturns(errors.NewAlreadyExists(schema.GroupResource{}, "the-task"))
+			taskDesirer.GetStatusReturns(opi.TaskStatus{State: opi.TaskRunningState}, nil)
 		})
 
-		It("does not error or requeue", func() {
-			Expect(reconcileResult.Requeue).To(BeFalse())
+		It("does not error", func() {
 			Expect(reconcileErr).ToNot(HaveOccurred())
 		})
+
+		It("requeues the task to follow its pod", func() {
+			Expect(reconcileResult.RequeueAfter).To(Equal(reconciler.TaskStatusRequeueInterval))
+		})
+
+		It("does not desire the task again", func() {
+			Expect(taskDesirer.DesireCallCount()).To(BeZero())
+		})
+
+		It("updates the task status", func() {
+			Expect(taskDesirer.GetStatusCallCount()).To(Equal(1))
+			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+		})
+
+		When("the task completes", func() {
+			BeforeEach(func() {
+				taskDesirer.GetStatusReturns(opi.TaskStatus{State: opi.TaskSucceededState}, nil)
+			})
+
+			It("does not requeue it", func() {
+				Expect(reconcileErr).ToNot(HaveOccurred())
+				Expect(reconcileResult).To(Equal(reconcile.Result{}))
+			})
+		})
+	})
+
+	When("the task has already completed", func() {
+		var phase eiriniv1.TaskPhase
+
+		BeforeEach(func() {
+			phase = eiriniv1.TaskSucceeded
+
+			controllerClient.GetStub = func(ctx context.Context, namespacedName types.NamespacedName, obj runtime.Object) error {
+				task := obj.(*eiriniv1.Task)
+				task.Spec.GUID = "my-task-guid"
+				task.Status.Phase = phase
+
+				return nil
+			}
+
+			taskDesirer.GetStatusReturns(opi.TaskStatus{}, eirini.ErrNotFound)
+		})
+
+		It("does not desire it again once its job is gone", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+			Expect(taskDesirer.DesireCallCount()).To(BeZero())
+		})
+
+		It("does not change its status", func() {
+			Expect(statusWriter.UpdateCallCount()).To(BeZero())
+		})
+
+		It("does not requeue it", func() {
+			Expect(reconcileResult).To(Equal(reconcile.Result{}))
+		})
+
+		When("it failed", func() {
+			BeforeEach(func() {
+				phase = eiriniv1.TaskFailed
+			})
+
+			It("does not desire it again", func() {
+				Expect(taskDesirer.DesireCallCount()).To(BeZero())
+				Expect(statusWriter.UpdateCallCount()).To(BeZero())
+			})
+		})
 	})
 })
@@ -3,6 +3,7 @@ package k8s
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"code.cloudfoundry.org/eirini/models/cf"
 	"code.cloudfoundry.org/eirini/route"
@@ -53,15 +54,17 @@ func (c RouteCollector) Collect() ([]route.Message, error) {
 			continue
 		}
 
-		for _, r := range routes {
+		hostnamesByPort := groupHostnamesByPort(routes)
+
+		for _, port := range sortedPorts(hostnamesByPort) {
 			routeMessage := route.Message{
 				InstanceID: p.Name,
 				Name:       p.Labels[LabelGUID],
 				Address:    p.Status.PodIP,
-				Port:       uint32(r.Port),
+				Port:       uint32(port),
 				TLSPort:    0,
 				Routes: route.Routes{
-					RegisteredRoutes: []string{r.Hostname},
+					RegisteredRoutes: hostnamesByPort[port],
 				},
 			}
 			routeMessages = append(routeMessages, routeMessage)
@@ -71,6 +74,29 @@ func (c RouteCollector) Collect() ([]route.Message, error) {
 	return routeMessages, nil
 }
 
+// groupHostnamesByPort groups the routes of an app by the port they target,
+// so that every port the app exposes gets its own route message.
+func groupHostnamesByPort(routes []cf.Route) map[int32][]string {
+	hostnamesByPort := map[int32][]string{}
+
+	for _, r := range routes {
+		hostnamesByPort[r.Port] = append(hostnamesByPort[r.Port], r.Hostname)
+	}
+
+	return hostnamesByPort
+}
+
+func sortedPorts(hostnamesByPort map[int32][]string) []int32 {
+	ports := make([]int32, 0, len(hostnamesByPort))
+	for port := range hostnamesByPort {
+		ports = append(ports, port)
+	}
+
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+
+	return ports
+}
+
 func (c RouteCollector) getRoutes(pod corev1.Pod, statefulsets map[string]appsv1.StatefulSet) ([]cf.Route, error) {
 	if !podReady(pod) {
 		return nil, fmt.Errorf("pod %s is not ready", pod.Name)
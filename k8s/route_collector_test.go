@@ -184,6 +184,44 @@ var _ = Describe("RouteCollector", func() {
 			})
 		})
 
+		Context("and the app exposes routes on several ports", func() {
+			BeforeEach(func() {
+				routes, marshalErr := json.Marshal([]cf.Route{
+					{Hostname: "app.example.com", Port: 8080},
+					{Hostname: "admin.example.com", Port: 9090},
+					{Hostname: "www.app.example.com", Port: 8080},
+				})
+				Expect(marshalErr).ToNot(HaveOccurred())
+				statefulsets[0].Annotations[AnnotationRegisteredRoutes] = string(routes)
+			})
+
+			It("should return one route message per port with all its hostnames", func() {
+				Expect(routeMessages).To(ContainElements(
+					route.Message{
+						InstanceID: "pod-11",
+						Name:       "pod-11-guid",
+						Address:    "10.0.0.1",
+						Port:       8080,
+						TLSPort:    0,
+						Routes: route.Routes{
+							RegisteredRoutes: []string{"app.example.com", "www.app.example.com"},
+						},
+					},
+					route.Message{
+						InstanceID: "pod-11",
+						Name:       "pod-11-guid",
+						Address:    "10.0.0.1",
+						Port:       9090,
+						TLSPort:    0,
+						Routes: route.Routes{
+							RegisteredRoutes: []string{"admin.example.com"},
+						},
+					},
+				))
+				Expect(routeMessages).To(HaveLen(4))
+			})
+		})
+
 		Context("and there are pods that are not ready", func() {
 			BeforeEach(func() {
 				pods[0].Status.Conditions[0].Status = corev1.ConditionFalse
@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"code.cloudfoundry.org/eirini/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EnvVCAPServices = "VCAP_SERVICES"
+
+	AnnotationEnvSecretChecksum = "cloudfoundry.org/env_secret_checksum"
+)
+
+// SensitiveEnv holds the patterns of the env var names whose values must not
+// appear in pod specs, in path.Match syntax (e.g. "*_PASSWORD"). Their values
+// are stored in a secret next to the workload and referenced from the
+// containers. VCAP_SERVICES is always sensitive, as it carries the service
+// binding credentials.
+type SensitiveEnv []string
+
+func (s SensitiveEnv) IsSensitive(name string) bool {
+	if name == EnvVCAPServices {
+		return true
+	}
+
+	for _, pattern := range s {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnvVars maps the env to container env vars, referencing the sensitive
+// ones from the given secret. Each container stores its env under its own
+// key prefix, as containers of the same pod share the secret.
+func (s SensitiveEnv) EnvVars(env map[string]string, secretName, keyPrefix string) []corev1.EnvVar {
+	envVars := MapToEnvVar(env)
+
+	for i, envVar := range envVars {
+		if !s.IsSensitive(envVar.Name) {
+			continue
+		}
+
+		envVars[i] = corev1.EnvVar{
+			Name: envVar.Name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  keyPrefix + envVar.Name,
+				},
+			},
+		}
+	}
+
+	return envVars
+}
+
+// Collect adds the sensitive env to the secret data, under the keys EnvVars
+// references them by.
+func (s SensitiveEnv) Collect(env map[string]string, keyPrefix string, data map[string][]byte) {
+	for name, value := range env {
+		if s.IsSensitive(name) {
+			data[keyPrefix+name] = []byte(value)
+		}
+	}
+}
+
+// Redact removes the sensitive env from a desire LRP request, so that it can
+// be recorded next to the workload. Requests that are not JSON objects are
+// returned as they are.
+func (s SensitiveEnv) Redact(request string) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(request), &fields); err != nil || fields == nil {
+		return request
+	}
+
+	s.redactEnvironment(fields)
+
+	if sidecars, ok := fields["sidecars"].([]interface{}); ok {
+		for _, sidecar := range sidecars {
+			if sidecarFields, ok := sidecar.(map[string]interface{}); ok {
+				s.redactEnvironment(sidecarFields)
+			}
+		}
+	}
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return request
+	}
+
+	return string(redacted)
+}
+
+func (s SensitiveEnv) redactEnvironment(fields map[string]interface{}) {
+	env, ok := fields["environment"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name := range env {
+		if s.IsSensitive(name) {
+			delete(env, name)
+		}
+	}
+}
+
+func envSecretName(workloadName string) string {
+	return fmt.Sprintf("%s-env", workloadName)
+}
+
+func sidecarEnvKeyPrefix(sidecarName string) string {
+	return fmt.Sprintf("%s.", sidecarName)
+}
+
+// toEnvSecret returns nil when there is no sensitive env, so that workloads
+// without it do not get a secret.
+func toEnvSecret(name string, data map[string][]byte) *corev1.Secret {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// envSecretChecksum is set on pod templates, so that changing a sensitive
+// value rolls out new pods even though the env var references stay the same.
+func envSecretChecksum(secret *corev1.Secret) (string, error) {
+	dataJSON, err := json.Marshal(secret.Data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal env secret data")
+	}
+
+	return util.Hash(string(dataJSON))
+}
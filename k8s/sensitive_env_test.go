@@ -0,0 +1,50 @@
+package k8s_test
+
+import (
+	"code.cloudfoundry.org/eirini/k8s"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SensitiveEnv", func() {
+	var sensitiveEnv k8s.SensitiveEnv
+
+	BeforeEach(func() {
+		sensitiveEnv = k8s.SensitiveEnv{"*_PASSWORD", "API_KEY"}
+	})
+
+	It("always treats VCAP_SERVICES as sensitive", func() {
+		Expect(k8s.SensitiveEnv{}.IsSensitive("VCAP_SERVICES")).To(BeTrue())
+	})
+
+	It("matches the names against the patterns", func() {
+		Expect(sensitiveEnv.IsSensitive("DB_PASSWORD")).To(BeTrue())
+		Expect(sensitiveEnv.IsSensitive("API_KEY")).To(BeTrue())
+		Expect(sensitiveEnv.IsSensitive("API_KEY_ID")).To(BeFalse())
+		Expect(sensitiveEnv.IsSensitive("PASSWORD_FILE")).To(BeFalse())
+	})
+
+	It("ignores malformed patterns", func() {
+		Expect(k8s.SensitiveEnv{"[PASSWORD"}.IsSensitive("[PASSWORD")).To(BeFalse())
+	})
+
+	Describe("Redact", func() {
+		It("removes the sensitive env of the app and its sidecars from the request", func() {
+			request := `{
+				"app_name": "app",
+				"environment": {"VCAP_SERVICES": "creds", "DB_PASSWORD": "s3cr3t", "FOO": "foo"},
+				"sidecars": [{"name": "sidecar", "environment": {"SIDECAR_PASSWORD": "hush", "BAR": "bar"}}]
+			}`
+
+			Expect(sensitiveEnv.Redact(request)).To(MatchJSON(`{
+				"app_name": "app",
+				"environment": {"FOO": "foo"},
+				"sidecars": [{"name": "sidecar", "environment": {"BAR": "bar"}}]
+			}`))
+		})
+
+		It("returns requests that are not JSON objects unchanged", func() {
+			Expect(sensitiveEnv.Redact("original request")).To(Equal("original request"))
+		})
+	})
+})
@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	InternalServiceTypeHeadless  = "headless"
+	InternalServiceTypeClusterIP = "ClusterIP"
+
+	// AnnotationInternalRoutes lists the internal route hostnames of the LRP
+	// on its internal Service, for the cluster DNS to be configured from.
+	AnnotationInternalRoutes = "cloudfoundry.org/internal_routes"
+)
+
+//counterfeiter:generate . ServiceClient
+
+type ServiceClient interface {
+	Create(namespace string, service *corev1.Service) (*corev1.Service, error)
+	Get(namespace, name string) (*corev1.Service, error)
+	Update(namespace string, service *corev1.Service) (*corev1.Service, error)
+	Delete(namespace string, name string) error
+}
+
+// ToInternalService builds the Service that internal routes of the LRP
+// resolve to. It selects the pods of the given LRP version only, and is
+// pointed at the new version whenever the LRP is desired again. A Service
+// without ports cannot have a cluster IP, so such LRPs always get a headless
+// one.
+//
+// The Service is reachable at <name>.<namespace>.svc only. Eirini does not
+// make the internal route hostnames, e.g. myapp.apps.internal, resolve to it:
+// that takes a cluster DNS configuration, as described in
+// docs/internal-routes.md.
+func ToInternalService(name string, lrp *opi.LRP, serviceType string) (*corev1.Service, error) {
+	if serviceType != "" && serviceType != InternalServiceTypeHeadless && serviceType != InternalServiceTypeClusterIP {
+		return nil, fmt.Errorf("unsupported internal service type %q", serviceType)
+	}
+
+	internalRoutes, err := json.Marshal(lrp.InternalRoutes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal internal routes")
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(lrp.Ports))
+	for _, port := range lrp.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:       fmt.Sprintf("port-%d", port),
+			Protocol:   corev1.ProtocolTCP,
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+		})
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				LabelGUID:       lrp.GUID,
+				LabelAppGUID:    lrp.AppGUID,
+				LabelSourceType: AppSourceType,
+			},
+			Annotations: map[string]string{
+				AnnotationInternalRoutes: string(internalRoutes),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				LabelGUID:       lrp.GUID,
+				LabelVersion:    lrp.Version,
+				LabelSourceType: AppSourceType,
+			},
+			Ports: ports,
+		},
+	}
+
+	if serviceType != InternalServiceTypeClusterIP || len(ports) == 0 {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+
+	return service, nil
+}
@@ -3,6 +3,8 @@ package k8s
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"code.cloudfoundry.org/eirini"
@@ -37,6 +39,9 @@ const (
 	AnnotationLastUpdated                    = "cloudfoundry.org/last_updated"
 	AnnotationProcessGUID                    = "cloudfoundry.org/process_guid"
 	AnnotationRegisteredRoutes               = "cloudfoundry.org/routes"
+	AnnotationHealthCheck                    = "cloudfoundry.org/health_check"
+	AnnotationPlacementTags                  = "cloudfoundry.org/placement_tags"
+	AnnotationEgressRules                    = "cloudfoundry.org/egress_rules"
 	AnnotationOriginalRequest                = "cloudfoundry.org/original_request"
 	AnnotationCompletionCallback             = "cloudfoundry.org/completion_callback"
 	AnnotationOpiTaskContainerName           = "cloudfoundry.org/opi-task-container-name"
@@ -45,6 +50,8 @@ const (
 	AnnotationLastReportedAppCrash           = "cloudfoundry.org/last_reported_app_crash"
 	AnnotationLastReportedLRPCrash           = "cloudfoundry.org/last_reported_lrp_crash"
 	AnnotationGUID                           = "cloudfoundry.org/guid"
+	AnnotationPodTemplateChecksum            = "cloudfoundry.org/pod_template_checksum"
+	AnnotationVCAPApplication                = "cloudfoundry.org/vcap_application"
 
 	AppSourceType = "APP"
 
@@ -62,6 +69,9 @@ const (
 	LabelTaskCompleted = "cloudfoundry.org/task_completed"
 	TaskCompletedTrue  = "true"
 
+	LabelScheduledTask = "cloudfoundry.org/scheduled_task"
+	ScheduledTaskTrue  = "true"
+
 	OPIContainerName = "opi"
 
 	PdbMinAvailableInstances = 1
@@ -71,7 +81,7 @@ const (
 //counterfeiter:generate . PodClient
 //counterfeiter:generate . PodDisruptionBudgetClient
 //counterfeiter:generate . StatefulSetClient
-//counterfeiter:generate . SecretsCreatorDeleter
+//counterfeiter:generate . SecretsClient
 //counterfeiter:generate . EventsClient
 //counterfeiter:generate . LRPMapper
 //counterfeiter:generate . ProbeCreator
@@ -97,8 +107,10 @@ type StatefulSetClient interface {
 	GetByLRPIdentifier(id opi.LRPIdentifier) ([]appsv1.StatefulSet, error)
 }
 
-type SecretsCreatorDeleter interface {
+type SecretsClient interface {
+	Get(namespace, name string) (*corev1.Secret, error)
 	Create(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+	Update(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
 	Delete(namespace string, name string) error
 }
 
@@ -110,17 +122,23 @@ type LRPMapper func(s appsv1.StatefulSet) (*opi.LRP, error)
 
 type StatefulSetDesirer struct {
 	Pods                              PodClient
-	Secrets                           SecretsCreatorDeleter
+	Secrets                           SecretsClient
 	StatefulSets                      StatefulSetClient
 	PodDisruptionBudgets              PodDisruptionBudgetClient
+	NetworkPolicies                   NetworkPolicyClient
+	Services                          ServiceClient
 	EventsClient                      EventsClient
 	StatefulSetToLRPMapper            LRPMapper
 	RegistrySecretName                string
 	LivenessProbeCreator              ProbeCreator
 	ReadinessProbeCreator             ProbeCreator
+	StartupProbeCreator               ProbeCreator
+	NodePlacements                    NodePlacements
 	Logger                            lager.Logger
 	ApplicationServiceAccount         string
 	AllowAutomountServiceAccountToken bool
+	InternalServiceType               string
+	SensitiveEnv                      SensitiveEnv
 }
 
 type ProbeCreator func(lrp *opi.LRP) *corev1.Probe
@@ -135,13 +153,19 @@ func (m *StatefulSetDesirer) Desire(namespace string, lrp *opi.LRP, opts ...Desi
 		return err
 	}
 
-	if lrp.PrivateRegistry != nil {
+	if hasRegistryCredentials(lrp) {
 		err = m.createRegistryCredsSecret(namespace, statefulSetName, lrp)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err = m.desireEnvSecret(namespace, statefulSetName, lrp, opts...); err != nil {
+		logger.Error("failed-to-desire-env-secret", err)
+
+		return err
+	}
+
 	st, err := m.toStatefulSet(statefulSetName, lrp)
 	if err != nil {
 		return err
@@ -171,6 +195,18 @@ func (m *StatefulSetDesirer) Desire(namespace string, lrp *opi.LRP, opts ...Desi
 		return errors.Wrap(err, "failed to create pod disruption budget")
 	}
 
+	if err := m.createNetworkPolicy(namespace, statefulSetName, lrp, opts...); err != nil {
+		logger.Error("failed-to-create-network-policy", err)
+
+		return err
+	}
+
+	if err := m.desireInternalService(namespace, lrp, nil, opts...); err != nil {
+		logger.Error("failed-to-desire-internal-service", err)
+
+		return err
+	}
+
 	return nil
 }
 
@@ -225,6 +261,19 @@ func (m *StatefulSetDesirer) stop(identifier opi.LRPIdentifier) error {
 		return errors.Wrap(err, "failed to delete pod disruption budget")
 	}
 
+	err = m.NetworkPolicies.Delete(statefulSet.Namespace, statefulSet.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-network-policy", err)
+
+		return errors.Wrap(err, "failed to delete network policy")
+	}
+
+	if err := m.deleteInternalService(statefulSet, identifier.Version); err != nil {
+		logger.Error("failed-to-delete-internal-service", err)
+
+		return err
+	}
+
 	err = m.deletePrivateRegistrySecret(statefulSet)
 	if err != nil && !k8serrors.IsNotFound(err) {
 		logger.Error("failed-to-delete-private-registry-secret", err)
@@ -232,6 +281,13 @@ func (m *StatefulSetDesirer) stop(identifier opi.LRPIdentifier) error {
 		return err
 	}
 
+	err = m.deleteEnvSecret(statefulSet)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error("failed-to-delete-env-secret", err)
+
+		return errors.Wrap(err, "failed to delete env secret")
+	}
+
 	if err := m.StatefulSets.Delete(statefulSet.Namespace, statefulSet.Name); err != nil {
 		logger.Error("failed-to-delete-statefulset", err)
 
@@ -251,6 +307,14 @@ func (m *StatefulSetDesirer) deletePrivateRegistrySecret(statefulSet *appsv1.Sta
 	return nil
 }
 
+func (m *StatefulSetDesirer) deleteEnvSecret(statefulSet *appsv1.StatefulSet) error {
+	if _, ok := statefulSet.Spec.Template.Annotations[AnnotationEnvSecretChecksum]; !ok {
+		return nil
+	}
+
+	return m.Secrets.Delete(statefulSet.Namespace, envSecretName(statefulSet.Name))
+}
+
 func (m *StatefulSetDesirer) StopInstance(identifier opi.LRPIdentifier, index uint) error {
 	logger := m.Logger.Session("stopInstance", lager.Data{"guid": identifier.GUID, "version": identifier.Version, "index": index})
 	statefulset, err := m.getStatefulSet(identifier)
@@ -299,12 +363,22 @@ func (m *StatefulSetDesirer) update(lrp *opi.LRP) error {
 		return err
 	}
 
-	updatedStatefulSet, err := m.getUpdatedStatefulSetObj(statefulSet,
-		lrp.AppURIs,
-		lrp.TargetInstances,
-		lrp.LastUpdated,
-		lrp.Image,
-	)
+	if hasRegistryCredentials(lrp) {
+		err = m.createOrUpdateRegistryCredsSecret(statefulSet, lrp)
+		if err != nil {
+			logger.Error("failed-to-update-registry-secret", err, lager.Data{"namespace": statefulSet.Namespace})
+
+			return err
+		}
+	}
+
+	if err = m.updateEnvSecret(statefulSet, lrp); err != nil {
+		logger.Error("failed-to-update-env-secret", err, lager.Data{"namespace": statefulSet.Namespace})
+
+		return err
+	}
+
+	updatedStatefulSet, err := m.getUpdatedStatefulSetObj(statefulSet, lrp)
 	if err != nil {
 		logger.Error("failed-to-get-updated-statefulset", err)
 
@@ -318,17 +392,49 @@ func (m *StatefulSetDesirer) update(lrp *opi.LRP) error {
 		return errors.Wrap(err, "failed to update statefulset")
 	}
 
-	return m.handlePodDisruptionBudget(logger,
-		statefulSet.Namespace,
-		statefulSet.Name,
-		lrp,
-	)
+	if err := m.handlePodDisruptionBudget(logger, statefulSet.Namespace, statefulSet.Name, lrp); err != nil {
+		return err
+	}
+
+	if err := m.handleNetworkPolicy(logger, statefulSet, lrp); err != nil {
+		return err
+	}
+
+	if err := m.desireInternalService(statefulSet.Namespace, lrp, statefulSet.OwnerReferences); err != nil {
+		logger.Error("failed-to-desire-internal-service", err, lager.Data{"namespace": statefulSet.Namespace})
+
+		return err
+	}
+
+	return nil
 }
 
+// Get returns the LRP with its complete env, including the sensitive env kept
+// in the env secret, so that it can be updated without losing it.
 func (m *StatefulSetDesirer) Get(identifier opi.LRPIdentifier) (*opi.LRP, error) {
 	logger := m.Logger.Session("get", lager.Data{"guid": identifier.GUID, "version": identifier.Version})
 
-	return m.getLRP(logger, identifier)
+	statefulSet, err := m.getStatefulSet(identifier)
+	if err != nil {
+		logger.Error("failed-to-get-statefulset", err)
+
+		return nil, err
+	}
+
+	lrp, err := m.StatefulSetToLRPMapper(*statefulSet)
+	if err != nil {
+		logger.Error("failed-to-map-statefulset-to-lrp", err)
+
+		return nil, err
+	}
+
+	if err = m.resolveSensitiveEnv(statefulSet, lrp); err != nil {
+		logger.Error("failed-to-resolve-sensitive-env", err)
+
+		return nil, err
+	}
+
+	return lrp, nil
 }
 
 func (m *StatefulSetDesirer) getLRP(logger lager.Logger, identifier opi.LRPIdentifier) (*opi.LRP, error) {
@@ -416,6 +522,7 @@ func (m *StatefulSetDesirer) GetInstances(identifier opi.LRPIdentifier) ([]*opi.
 			Index:          index,
 			State:          state,
 			PlacementError: placementError,
+			LastCrash:      getLastCrash(pod),
 		}
 		instances = append(instances, &instance)
 	}
@@ -453,6 +560,35 @@ func hasInsufficientMemory(events []corev1.Event) bool {
 		strings.Contains(event.Message, "Insufficient memory")
 }
 
+func getLastCrash(pod corev1.Pod) *opi.InstanceCrash {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != OPIContainerName {
+			continue
+		}
+
+		crashCount := int(status.RestartCount)
+		terminated := status.LastTerminationState.Terminated
+
+		if status.State.Terminated != nil {
+			terminated = status.State.Terminated
+			crashCount++
+		}
+
+		if terminated == nil {
+			return nil
+		}
+
+		return &opi.InstanceCrash{
+			Reason:     terminated.Reason,
+			ExitStatus: int(terminated.ExitCode),
+			CrashCount: crashCount,
+			Timestamp:  terminated.FinishedAt.UnixNano(),
+		}
+	}
+
+	return nil
+}
+
 func (m *StatefulSetDesirer) statefulSetsToLRPs(statefulSets []appsv1.StatefulSet) ([]*opi.LRP, error) {
 	lrps := []*opi.LRP{}
 
@@ -500,7 +636,13 @@ func (m *StatefulSetDesirer) calculateImagePullSecrets(statefulSetName string, l
 		{Name: m.RegistrySecretName},
 	}
 
-	if lrp.PrivateRegistry != nil {
+	switch {
+	case lrp.PrivateRegistry == nil:
+	case lrp.PrivateRegistry.SecretName != "":
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{
+			Name: lrp.PrivateRegistry.SecretName,
+		})
+	default:
 		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{
 			Name: m.privateRegistrySecretName(statefulSetName),
 		})
@@ -509,52 +651,26 @@ func (m *StatefulSetDesirer) calculateImagePullSecrets(statefulSetName string, l
 	return imagePullSecrets
 }
 
+// hasRegistryCredentials tells whether a registry secret has to be generated
+// for the LRP. Secrets referenced by name are managed by whoever created them.
+func hasRegistryCredentials(lrp *opi.LRP) bool {
+	return lrp.PrivateRegistry != nil && lrp.PrivateRegistry.SecretName == ""
+}
+
 func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP) (*appsv1.StatefulSet, error) { //nolint:funlen // this is a boilerplate function, its length is fine
-	envs := MapToEnvVar(lrp.Env)
-	fieldEnvs := []corev1.EnvVar{
-		{
-			Name: eirini.EnvPodName,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "metadata.name",
-				},
-			},
-		},
-		{
-			Name: eirini.EnvCFInstanceGUID,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "metadata.uid",
-				},
-			},
-		},
-		{
-			Name: eirini.EnvCFInstanceIP,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "status.hostIP",
-				},
-			},
-		},
-		{
-			Name: eirini.EnvCFInstanceInternalIP,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "status.podIP",
-				},
-			},
-		},
+	vcapApplication, err := lrpVCAPApplication(lrp)
+	if err != nil {
+		return nil, err
 	}
 
-	envs = append(envs, fieldEnvs...)
-	ports := []corev1.ContainerPort{}
-
-	for _, port := range lrp.Ports {
-		ports = append(ports, corev1.ContainerPort{ContainerPort: port})
-	}
+	envSecret := m.toEnvSecret(statefulSetName, lrp)
+	envs := append(m.SensitiveEnv.EnvVars(withoutVCAPApplication(lrp.Env), envSecretName(statefulSetName), ""), getFieldEnvs()...)
+	envs = append(envs, vcapApplication)
+	ports := toContainerPorts(lrp.Ports)
 
 	livenessProbe := m.LivenessProbeCreator(lrp)
 	readinessProbe := m.ReadinessProbeCreator(lrp)
+	startupProbe := m.StartupProbeCreator(lrp)
 
 	volumes, volumeMounts := getVolumeSpecs(lrp.VolumeMounts)
 	allowPrivilegeEscalation := false
@@ -574,11 +690,28 @@ func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP)
 			Resources:      getContainerResources(lrp.CPUWeight, lrp.MemoryMB, lrp.DiskMB),
 			LivenessProbe:  livenessProbe,
 			ReadinessProbe: readinessProbe,
+			StartupProbe:   startupProbe,
 			VolumeMounts:   volumeMounts,
 		},
 	}
 
-	sidecarContainers := getSidecarContainers(lrp)
+	var (
+		initContainers      []corev1.Container
+		sidecarVolumeMounts []corev1.VolumeMount
+	)
+
+	if lrp.DropletDownload != nil {
+		downloader, downloaderVolumes, volumeMount := getDropletDownloadSpecs(
+			lrp.DropletDownload,
+			getContainerResources(lrp.CPUWeight, lrp.MemoryMB, lrp.DiskMB),
+		)
+		initContainers = append(initContainers, downloader)
+		volumes = append(volumes, downloaderVolumes...)
+		containers[0].VolumeMounts = append(containers[0].VolumeMounts, volumeMount)
+		sidecarVolumeMounts = append(sidecarVolumeMounts, volumeMount)
+	}
+
+	sidecarContainers := m.getSidecarContainers(statefulSetName, lrp, sidecarVolumeMounts, vcapApplication)
 	containers = append(containers, sidecarContainers...)
 	statefulSet := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -589,6 +722,7 @@ func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP)
 			Replicas:            int32ptr(lrp.TargetInstances),
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
+					InitContainers:     initContainers,
 					Containers:         containers,
 					ImagePullSecrets:   imagePullSecrets,
 					SecurityContext:    m.getGetSecurityContext(lrp),
@@ -623,6 +757,8 @@ func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP)
 		},
 	}
 
+	m.NodePlacements.Apply(m.Logger, &statefulSet.Spec.Template.Spec, lrp.PlacementTags)
+
 	labels := map[string]string{
 		LabelOrgGUID:     lrp.OrgGUID,
 		LabelOrgName:     lrp.OrgName,
@@ -643,11 +779,35 @@ func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP)
 		return nil, errors.Wrap(err, "failed to marshal app uris")
 	}
 
+	healthCheck, err := json.Marshal(lrp.Health)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal health check")
+	}
+
+	placementTags, err := json.Marshal(lrp.PlacementTags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal placement tags")
+	}
+
+	egressRules, err := json.Marshal(lrp.EgressRules)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal egress rules")
+	}
+
+	internalRoutes, err := json.Marshal(lrp.InternalRoutes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal internal routes")
+	}
+
 	annotations := map[string]string{
 		AnnotationSpaceName:        lrp.SpaceName,
 		AnnotationSpaceGUID:        lrp.SpaceGUID,
-		AnnotationOriginalRequest:  lrp.LRP,
+		AnnotationOriginalRequest:  m.SensitiveEnv.Redact(lrp.LRP),
 		AnnotationRegisteredRoutes: string(uris),
+		AnnotationHealthCheck:      string(healthCheck),
+		AnnotationPlacementTags:    string(placementTags),
+		AnnotationEgressRules:      string(egressRules),
+		AnnotationInternalRoutes:   string(internalRoutes),
 		AnnotationAppID:            lrp.AppGUID,
 		AnnotationVersion:          lrp.Version,
 		AnnotationLastUpdated:      lrp.LastUpdated,
@@ -661,13 +821,67 @@ func (m *StatefulSetDesirer) toStatefulSet(statefulSetName string, lrp *opi.LRP)
 		annotations[k] = v
 	}
 
+	if vcapApplication, ok := lrp.Env[eirini.EnvVCAPApplication]; ok {
+		annotations[AnnotationVCAPApplication] = vcapApplication
+	}
+
+	if envSecret != nil {
+		checksum, err := envSecretChecksum(envSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		annotations[AnnotationEnvSecretChecksum] = checksum
+	}
+
 	statefulSet.Annotations = annotations
-	statefulSet.Spec.Template.Annotations = annotations
-	statefulSet.Spec.Template.Annotations[corev1.SeccompPodAnnotationKey] = corev1.SeccompProfileRuntimeDefault
+	statefulSet.Spec.Template.Annotations = podAnnotations(annotations)
+
+	checksum, err := podTemplateChecksum(statefulSet.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSet.Spec.Template.Annotations[AnnotationPodTemplateChecksum] = checksum
 
 	return statefulSet, nil
 }
 
+// podAnnotations returns the statefulset annotations that belong on its pods.
+// Routes, the last update and the original request change without affecting
+// the app instances, so they are left out to not restart them. The
+// VCAP_APPLICATION sent by Cloud Controller is already in the container env.
+func podAnnotations(annotations map[string]string) map[string]string {
+	podAnnotations := map[string]string{}
+
+	for k, v := range annotations {
+		podAnnotations[k] = v
+	}
+
+	delete(podAnnotations, AnnotationRegisteredRoutes)
+	delete(podAnnotations, AnnotationLastUpdated)
+	delete(podAnnotations, AnnotationOriginalRequest)
+	delete(podAnnotations, AnnotationVCAPApplication)
+
+	podAnnotations[corev1.SeccompPodAnnotationKey] = corev1.SeccompProfileRuntimeDefault
+
+	return podAnnotations
+}
+
+// podTemplateChecksum identifies the pods a template describes. The image pull
+// secrets are left out, as they are only replaced when the LRP carries
+// registry credentials.
+func podTemplateChecksum(template corev1.PodTemplateSpec) (string, error) {
+	template.Spec.ImagePullSecrets = nil
+
+	templateJSON, err := json.Marshal(template)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal pod template")
+	}
+
+	return util.Hash(string(templateJSON))
+}
+
 func getContainerResources(cpuWeight uint8, memoryMB, diskMB int64) corev1.ResourceRequirements {
 	memory := *resource.NewScaledQuantity(memoryMB, resource.Mega)
 	cpu := toCPUMillicores(cpuWeight)
@@ -697,19 +911,77 @@ func toLabelSelectorRequirements(selector *metav1.LabelSelector) []metav1.LabelS
 		})
 	}
 
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].Key < reqs[j].Key
+	})
+
 	return reqs
 }
 
-func getSidecarContainers(lrp *opi.LRP) []corev1.Container {
+// getFieldEnvs returns the CF_INSTANCE_* env vars that are only known once
+// the pod is scheduled.
+func getFieldEnvs() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name: eirini.EnvPodName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name: eirini.EnvCFInstanceGUID,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.uid",
+				},
+			},
+		},
+		{
+			Name: eirini.EnvCFInstanceIP,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "status.hostIP",
+				},
+			},
+		},
+		{
+			Name: eirini.EnvCFInstanceInternalIP,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "status.podIP",
+				},
+			},
+		},
+	}
+}
+
+func (m *StatefulSetDesirer) getSidecarContainers(
+	statefulSetName string,
+	lrp *opi.LRP,
+	volumeMounts []corev1.VolumeMount,
+	vcapApplication corev1.EnvVar,
+) []corev1.Container {
 	containers := []corev1.Container{}
+	allowPrivilegeEscalation := false
 
 	for _, s := range lrp.Sidecars {
+		env := m.SensitiveEnv.EnvVars(withoutVCAPApplication(s.Env), envSecretName(statefulSetName), sidecarEnvKeyPrefix(s.Name))
+		env = append(env, getFieldEnvs()...)
+		env = append(env, vcapApplication)
+
 		c := corev1.Container{
-			Name:      s.Name,
-			Command:   s.Command,
-			Image:     lrp.Image,
-			Env:       MapToEnvVar(s.Env),
-			Resources: getContainerResources(lrp.CPUWeight, s.MemoryMB, lrp.DiskMB),
+			Name:    s.Name,
+			Command: s.Command,
+			Image:   lrp.Image,
+			Env:     env,
+			Ports:   toContainerPorts(s.Ports),
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			},
+			Resources:    getContainerResources(lrp.CPUWeight, s.MemoryMB, lrp.DiskMB),
+			VolumeMounts: volumeMounts,
 		}
 		containers = append(containers, c)
 	}
@@ -717,6 +989,16 @@ func getSidecarContainers(lrp *opi.LRP) []corev1.Container {
 	return containers
 }
 
+func toContainerPorts(ports []int32) []corev1.ContainerPort {
+	containerPorts := []corev1.ContainerPort{}
+
+	for _, port := range ports {
+		containerPorts = append(containerPorts, corev1.ContainerPort{ContainerPort: port})
+	}
+
+	return containerPorts
+}
+
 func getVolumeSpecs(lrpVolumeMounts []opi.VolumeMount) ([]corev1.Volume, []corev1.VolumeMount) {
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
@@ -785,28 +1067,334 @@ func (m *StatefulSetDesirer) handlePodDisruptionBudget(logger lager.Logger, name
 	return nil
 }
 
-func (m *StatefulSetDesirer) getUpdatedStatefulSetObj(sts *appsv1.StatefulSet, routes []opi.Route, instances int, lastUpdated, image string) (*appsv1.StatefulSet, error) {
+func (m *StatefulSetDesirer) createNetworkPolicy(namespace, statefulSetName string, lrp *opi.LRP, opts ...DesireOption) error {
+	networkPolicy, err := ToNetworkPolicy(statefulSetName, m.labelSelector(lrp).MatchLabels, lrp.EgressRules)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate network policy")
+	}
+
+	if networkPolicy == nil {
+		return nil
+	}
+
+	networkPolicy.Namespace = namespace
+
+	if err = applyOpts(networkPolicy, opts...); err != nil {
+		return err
+	}
+
+	_, err = m.NetworkPolicies.Create(namespace, networkPolicy)
+
+	return errors.Wrap(err, "failed to create network policy")
+}
+
+// handleNetworkPolicy brings the network policy of the statefulset in line
+// with the egress rules of the LRP. The policy has the same owners as the
+// statefulset so that it is garbage collected together with it.
+func (m *StatefulSetDesirer) handleNetworkPolicy(logger lager.Logger, statefulSet *appsv1.StatefulSet, lrp *opi.LRP) error {
+	networkPolicy, err := ToNetworkPolicy(statefulSet.Name, m.labelSelector(lrp).MatchLabels, lrp.EgressRules)
+	if err != nil {
+		logger.Error("failed-to-generate-network-policy", err)
+
+		return errors.Wrap(err, "failed to generate network policy")
+	}
+
+	if networkPolicy == nil {
+		err = m.NetworkPolicies.Delete(statefulSet.Namespace, statefulSet.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error("failed-to-delete-network-policy", err, lager.Data{"namespace": statefulSet.Namespace})
+
+			return errors.Wrap(err, "failed to delete network policy")
+		}
+
+		return nil
+	}
+
+	networkPolicy.Namespace = statefulSet.Namespace
+	networkPolicy.OwnerReferences = statefulSet.OwnerReferences
+
+	_, err = m.NetworkPolicies.Update(statefulSet.Namespace, networkPolicy)
+	if k8serrors.IsNotFound(err) {
+		_, err = m.NetworkPolicies.Create(statefulSet.Namespace, networkPolicy)
+	}
+
+	if err != nil {
+		logger.Error("failed-to-update-network-policy", err, lager.Data{"namespace": statefulSet.Namespace})
+
+		return errors.Wrap(err, "failed to update network policy")
+	}
+
+	return nil
+}
+
+// desireInternalService creates the Service for the internal routes of the
+// LRP, or points the existing one at the pods of this LRP version.
+func (m *StatefulSetDesirer) desireInternalService(namespace string, lrp *opi.LRP, ownerReferences []metav1.OwnerReference, opts ...DesireOption) error {
+	if len(lrp.InternalRoutes) == 0 {
+		return nil
+	}
+
+	name, err := utils.GetInternalServiceName(lrp.AppName, lrp.SpaceName, lrp.GUID)
+	if err != nil {
+		return err
+	}
+
+	service, err := ToInternalService(name, lrp, m.InternalServiceType)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate internal service")
+	}
+
+	service.Namespace = namespace
+	service.OwnerReferences = ownerReferences
+
+	if err = applyOpts(service, opts...); err != nil {
+		return err
+	}
+
+	existing, err := m.Services.Get(namespace, name)
+	if k8serrors.IsNotFound(err) {
+		_, err = m.Services.Create(namespace, service)
+
+		return errors.Wrap(err, "failed to create internal service")
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to get internal service")
+	}
+
+	existing.Spec.Selector = service.Spec.Selector
+	existing.Spec.Ports = service.Spec.Ports
+	existing.Annotations = updateAnnotations(existing.Annotations, service.Annotations)
+
+	_, err = m.Services.Update(namespace, existing)
+
+	return errors.Wrap(err, "failed to update internal service")
+}
+
+// deleteInternalService deletes the internal Service of the statefulset app
+// unless it already selects the pods of another version.
+func (m *StatefulSetDesirer) deleteInternalService(statefulSet *appsv1.StatefulSet, version string) error {
+	name, err := utils.GetInternalServiceName(
+		statefulSet.Annotations[AnnotationAppName],
+		statefulSet.Annotations[AnnotationSpaceName],
+		statefulSet.Labels[LabelGUID],
+	)
+	if err != nil {
+		return err
+	}
+
+	service, err := m.Services.Get(statefulSet.Namespace, name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to get internal service")
+	}
+
+	if service.Spec.Selector[LabelVersion] != version {
+		return nil
+	}
+
+	err = m.Services.Delete(statefulSet.Namespace, name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete internal service")
+	}
+
+	return nil
+}
+
+// getUpdatedStatefulSetObj rebuilds the pod template of an existing
+// statefulset from the LRP. The template is only replaced when the pods it
+// describes change, as the statefulset controller restarts every instance
+// then. An empty image keeps the current one, and the image pull secrets are
+// only replaced when the LRP carries registry credentials.
+func (m *StatefulSetDesirer) getUpdatedStatefulSetObj(sts *appsv1.StatefulSet, lrp *opi.LRP) (*appsv1.StatefulSet, error) {
+	desiredLRP := *lrp
+	if desiredLRP.Image == "" {
+		desiredLRP.Image = getOPIContainerImage(sts)
+	}
+
+	desiredSts, err := m.toStatefulSet(sts.Name, &desiredLRP)
+	if err != nil {
+		return nil, err
+	}
+
 	updatedSts := sts.DeepCopy()
+	updatedSts.Spec.Replicas = desiredSts.Spec.Replicas
+	updatedSts.Annotations = updateAnnotations(sts.Annotations, desiredSts.Annotations)
+
+	podSpec := desiredSts.Spec.Template.Spec
+	if lrp.PrivateRegistry == nil {
+		podSpec.ImagePullSecrets = sts.Spec.Template.Spec.ImagePullSecrets
+	}
+
+	if sts.Spec.Template.Annotations[AnnotationPodTemplateChecksum] == desiredSts.Spec.Template.Annotations[AnnotationPodTemplateChecksum] &&
+		reflect.DeepEqual(podSpec.ImagePullSecrets, sts.Spec.Template.Spec.ImagePullSecrets) {
+		return updatedSts, nil
+	}
+
+	updatedSts.Spec.Template.Spec = podSpec
+	updatedSts.Spec.Template.Annotations = podAnnotations(updateAnnotations(sts.Spec.Template.Annotations, desiredSts.Spec.Template.Annotations))
+
+	return updatedSts, nil
+}
+
+func getOPIContainerImage(sts *appsv1.StatefulSet) string {
+	for _, container := range sts.Spec.Template.Spec.Containers {
+		if container.Name == OPIContainerName {
+			return container.Image
+		}
+	}
+
+	return ""
+}
+
+// updateAnnotations sets the desired annotations on top of the current ones
+// and drops user defined annotations that are no longer desired. Annotations
+// owned by eirini are kept.
+func updateAnnotations(current, desired map[string]string) map[string]string {
+	updated := map[string]string{}
+
+	for k, v := range current {
+		if !isUserDefinedAnnotation(k) {
+			updated[k] = v
+		}
+	}
+
+	for k, v := range desired {
+		updated[k] = v
+	}
+
+	return updated
+}
+
+// createOrUpdateRegistryCredsSecret rotates the registry credentials in
+// place. The image pull secret name stays the same, so running instances are
+// not restarted and pods rescheduled later pull with the new credentials.
+func (m *StatefulSetDesirer) createOrUpdateRegistryCredsSecret(statefulSet *appsv1.StatefulSet, lrp *opi.LRP) error {
+	secret, err := m.generateRegistryCredsSecret(statefulSet.Name, lrp)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate private registry secret for statefulset")
+	}
+
+	secret.OwnerReferences = statefulSet.OwnerReferences
+
+	_, err = m.Secrets.Update(statefulSet.Namespace, secret)
+	if k8serrors.IsNotFound(err) {
+		_, err = m.Secrets.Create(statefulSet.Namespace, secret)
+	}
+
+	return errors.Wrap(err, "failed to update private registry secret for statefulset")
+}
+
+func (m *StatefulSetDesirer) toEnvSecret(statefulSetName string, lrp *opi.LRP) *corev1.Secret {
+	data := map[string][]byte{}
 
-	uris, err := json.Marshal(routes)
+	m.SensitiveEnv.Collect(withoutVCAPApplication(lrp.Env), "", data)
+
+	for _, sidecar := range lrp.Sidecars {
+		m.SensitiveEnv.Collect(withoutVCAPApplication(sidecar.Env), sidecarEnvKeyPrefix(sidecar.Name), data)
+	}
+
+	return toEnvSecret(envSecretName(statefulSetName), data)
+}
+
+func (m *StatefulSetDesirer) desireEnvSecret(namespace, statefulSetName string, lrp *opi.LRP, opts ...DesireOption) error {
+	secret := m.toEnvSecret(statefulSetName, lrp)
+	if secret == nil {
+		return nil
+	}
+
+	secret.Namespace = namespace
+
+	if err := applyOpts(secret, opts...); err != nil {
+		return err
+	}
+
+	_, err := m.Secrets.Create(namespace, secret)
+	if k8serrors.IsAlreadyExists(err) {
+		_, err = m.Secrets.Update(namespace, secret)
+	}
+
+	return errors.Wrap(err, "failed to create env secret for statefulset")
+}
+
+// updateEnvSecret replaces the sensitive env of the LRP. A secret that is no
+// longer needed is kept until the LRP is stopped, as pods of the previous
+// pod template may still reference it.
+func (m *StatefulSetDesirer) updateEnvSecret(statefulSet *appsv1.StatefulSet, lrp *opi.LRP) error {
+	secret := m.toEnvSecret(statefulSet.Name, lrp)
+	if secret == nil {
+		return nil
+	}
+
+	secret.Namespace = statefulSet.Namespace
+	secret.OwnerReferences = statefulSet.OwnerReferences
+
+	_, err := m.Secrets.Update(statefulSet.Namespace, secret)
+	if k8serrors.IsNotFound(err) {
+		_, err = m.Secrets.Create(statefulSet.Namespace, secret)
+	}
+
+	return errors.Wrap(err, "failed to update env secret for statefulset")
+}
+
+// resolveSensitiveEnv adds the env the containers reference from the env
+// secret to the LRP and its sidecars.
+func (m *StatefulSetDesirer) resolveSensitiveEnv(statefulSet *appsv1.StatefulSet, lrp *opi.LRP) error {
+	secretName := envSecretName(statefulSet.Name)
+	containers := statefulSet.Spec.Template.Spec.Containers
+
+	if !referencesSecret(containers, secretName) {
+		return nil
+	}
+
+	secret, err := m.Secrets.Get(statefulSet.Namespace, secretName)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal routes")
+		return errors.Wrap(err, "failed to get env secret")
 	}
 
-	count := int32(instances)
-	updatedSts.Spec.Replicas = &count
-	updatedSts.Annotations[AnnotationLastUpdated] = lastUpdated
-	updatedSts.Annotations[AnnotationRegisteredRoutes] = string(uris)
+	for _, container := range containers {
+		if container.Name == OPIContainerName {
+			lrp.Env = withSecretEnv(lrp.Env, container.Env, secret)
+
+			continue
+		}
 
-	if image != "" {
-		for i, container := range updatedSts.Spec.Template.Spec.Containers {
-			if container.Name == OPIContainerName {
-				updatedSts.Spec.Template.Spec.Containers[i].Image = image
+		for i := range lrp.Sidecars {
+			if lrp.Sidecars[i].Name == container.Name {
+				lrp.Sidecars[i].Env = withSecretEnv(lrp.Sidecars[i].Env, container.Env, secret)
 			}
 		}
 	}
 
-	return updatedSts, nil
+	return nil
+}
+
+func withSecretEnv(env map[string]string, envVars []corev1.EnvVar, secret *corev1.Secret) map[string]string {
+	for _, envVar := range envVars {
+		ref := secretKeyRef(envVar, secret.Name)
+		if ref == nil {
+			continue
+		}
+
+		if env == nil {
+			env = map[string]string{}
+		}
+
+		env[envVar.Name] = string(secret.Data[ref.Key])
+	}
+
+	return env
+}
+
+func secretKeyRef(envVar corev1.EnvVar, secretName string) *corev1.SecretKeySelector {
+	if envVar.ValueFrom == nil || envVar.ValueFrom.SecretKeyRef == nil || envVar.ValueFrom.SecretKeyRef.Name != secretName {
+		return nil
+	}
+
+	return envVar.ValueFrom.SecretKeyRef
 }
 
 func (m *StatefulSetDesirer) createRegistryCredsSecret(namespace, statefulSetName string, lrp *opi.LRP) error {
@@ -820,9 +1408,9 @@ func (m *StatefulSetDesirer) createRegistryCredsSecret(namespace, statefulSetNam
 	return errors.Wrap(err, "failed to create private registry secret for statefulset")
 }
 
-func applyOpts(statefulset *appsv1.StatefulSet, opts ...DesireOption) error {
+func applyOpts(resource metav1.Object, opts ...DesireOption) error {
 	for _, opt := range opts {
-		if err := opt(statefulset); err != nil {
+		if err := opt(resource); err != nil {
 			return errors.Wrap(err, "failed to apply options")
 		}
 	}
@@ -3,6 +3,7 @@ package k8s_test
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -19,6 +20,7 @@ import (
 	. "github.com/onsi/gomega/gstruct"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,26 +37,33 @@ var _ = Describe("Statefulset Desirer", func() {
 	var (
 		podsClient            *k8sfakes.FakePodClient
 		eventsClient          *k8sfakes.FakeEventsClient
-		secretsClient         *k8sfakes.FakeSecretsCreatorDeleter
+		secretsClient         *k8sfakes.FakeSecretsClient
 		statefulSetClient     *k8sfakes.FakeStatefulSetClient
 		statefulSetDesirer    *k8s.StatefulSetDesirer
 		livenessProbeCreator  *k8sfakes.FakeProbeCreator
 		readinessProbeCreator *k8sfakes.FakeProbeCreator
+		startupProbeCreator   *k8sfakes.FakeProbeCreator
 		logger                *lagertest.TestLogger
 		mapper                *k8sfakes.FakeLRPMapper
 		pdbClient             *k8sfakes.FakePodDisruptionBudgetClient
+		networkPolicyClient   *k8sfakes.FakeNetworkPolicyClient
+		serviceClient         *k8sfakes.FakeServiceClient
 	)
 
 	BeforeEach(func() {
 		podsClient = new(k8sfakes.FakePodClient)
 		statefulSetClient = new(k8sfakes.FakeStatefulSetClient)
-		secretsClient = new(k8sfakes.FakeSecretsCreatorDeleter)
+		secretsClient = new(k8sfakes.FakeSecretsClient)
 		eventsClient = new(k8sfakes.FakeEventsClient)
 
 		livenessProbeCreator = new(k8sfakes.FakeProbeCreator)
 		readinessProbeCreator = new(k8sfakes.FakeProbeCreator)
+		startupProbeCreator = new(k8sfakes.FakeProbeCreator)
 		mapper = new(k8sfakes.FakeLRPMapper)
 		pdbClient = new(k8sfakes.FakePodDisruptionBudgetClient)
+		networkPolicyClient = new(k8sfakes.FakeNetworkPolicyClient)
+		serviceClient = new(k8sfakes.FakeServiceClient)
+		serviceClient.GetReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "service"))
 
 		logger = lagertest.NewTestLogger("handler-test")
 		statefulSetDesirer = &k8s.StatefulSetDesirer{
@@ -62,9 +71,12 @@ var _ = Describe("Statefulset Desirer", func() {
 			Secrets:                   secretsClient,
 			StatefulSets:              statefulSetClient,
 			PodDisruptionBudgets:      pdbClient,
+			NetworkPolicies:           networkPolicyClient,
+			Services:                  serviceClient,
 			RegistrySecretName:        registrySecretName,
 			LivenessProbeCreator:      livenessProbeCreator.Spy,
 			ReadinessProbeCreator:     readinessProbeCreator.Spy,
+			StartupProbeCreator:       startupProbeCreator.Spy,
 			Logger:                    logger,
 			StatefulSetToLRPMapper:    mapper.Spy,
 			EventsClient:              eventsClient,
@@ -83,6 +95,7 @@ var _ = Describe("Statefulset Desirer", func() {
 			lrp = createLRP("Baldur", []opi.Route{{Hostname: "my.example.route", Port: 1000}})
 			livenessProbeCreator.Returns(&corev1.Probe{})
 			readinessProbeCreator.Returns(&corev1.Probe{})
+			startupProbeCreator.Returns(&corev1.Probe{PeriodSeconds: 2})
 			desireOptOne = new(k8sfakes.FakeDesireOption)
 			desireOptTwo = new(k8sfakes.FakeDesireOption)
 		})
@@ -107,6 +120,14 @@ var _ = Describe("Statefulset Desirer", func() {
 			Expect(readinessProbeCreator.CallCount()).To(Equal(1))
 		})
 
+		It("should create a startup probe", func() {
+			Expect(startupProbeCreator.CallCount()).To(Equal(1))
+			Expect(startupProbeCreator.ArgsForCall(0)).To(Equal(lrp))
+
+			_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].StartupProbe).To(Equal(&corev1.Probe{PeriodSeconds: 2}))
+		})
+
 		It("should invoke the opts with the StatefulSet", func() {
 			Expect(desireOptOne.CallCount()).To(Equal(1))
 			Expect(desireOptTwo.CallCount()).To(Equal(1))
@@ -131,6 +152,7 @@ var _ = Describe("Statefulset Desirer", func() {
 			Entry("SpaceGUID", k8s.AnnotationSpaceGUID, "space-guid"),
 			Entry("OrgName", k8s.AnnotationOrgName, "org-foo"),
 			Entry("OrgGUID", k8s.AnnotationOrgGUID, "org-guid"),
+			Entry("HealthCheck", k8s.AnnotationHealthCheck, `{"type":"","port":0,"endpoint":"","timeout_ms":0}`),
 		)
 
 		DescribeTable("Statefulset Template Annotations",
@@ -142,14 +164,19 @@ var _ = Describe("Statefulset Desirer", func() {
 			Entry("AppName", k8s.AnnotationAppName, "Baldur"),
 			Entry("AppID", k8s.AnnotationAppID, "premium_app_guid_1234"),
 			Entry("Version", k8s.AnnotationVersion, "version_1234"),
-			Entry("OriginalRequest", k8s.AnnotationOriginalRequest, "original request"),
-			Entry("RegisteredRoutes", k8s.AnnotationRegisteredRoutes, `[{"hostname":"my.example.route","port":1000}]`),
 			Entry("SpaceName", k8s.AnnotationSpaceName, "space-foo"),
 			Entry("SpaceGUID", k8s.AnnotationSpaceGUID, "space-guid"),
 			Entry("OrgName", k8s.AnnotationOrgName, "org-foo"),
 			Entry("OrgGUID", k8s.AnnotationOrgGUID, "org-guid"),
 		)
 
+		It("should not put the annotations that change without affecting the pods on the template", func() {
+			_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+			Expect(statefulSet.Spec.Template.Annotations).NotTo(HaveKey(k8s.AnnotationOriginalRequest))
+			Expect(statefulSet.Spec.Template.Annotations).NotTo(HaveKey(k8s.AnnotationRegisteredRoutes))
+			Expect(statefulSet.Spec.Template.Annotations).NotTo(HaveKey(k8s.AnnotationLastUpdated))
+		})
+
 		It("should provide last updated to the statefulset annotation", func() {
 			_, statefulSet := statefulSetClient.CreateArgsForCall(0)
 			Expect(statefulSet.Annotations).To(HaveKeyWithValue(k8s.AnnotationLastUpdated, lrp.LastUpdated))
@@ -347,6 +374,59 @@ var _ = Describe("Statefulset Desirer", func() {
 			))
 		})
 
+		Describe("VCAP_APPLICATION", func() {
+			BeforeEach(func() {
+				lrp.Env = map[string]string{
+					"VCAP_APPLICATION": `{"cf_api":"https://api.example.com","application_name":"old-name","application_uris":["my.example.route"],"instance_index":0,"limits":{"fds":16384,"mem":1}}`,
+				}
+			})
+
+			It("builds it from the LRP, keeping the other fields sent by Cloud Controller", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				env := statefulSet.Spec.Template.Spec.Containers[0].Env
+
+				vcapEnv := env[len(env)-1]
+				Expect(vcapEnv.Name).To(Equal("VCAP_APPLICATION"))
+				Expect(vcapEnv.Value).To(MatchJSON(`{
+					"cf_api": "https://api.example.com",
+					"application_id": "premium_app_guid_1234",
+					"application_name": "Baldur",
+					"name": "Baldur",
+					"application_uris": ["my.example.route"],
+					"organization_id": "org-guid",
+					"organization_name": "org-foo",
+					"space_id": "space-guid",
+					"space_name": "space-foo",
+					"process_id": "guid_1234",
+					"process_type": "worker",
+					"host": "0.0.0.0",
+					"port": 8888,
+					"instance_id": "$(CF_INSTANCE_GUID)",
+					"instance_index": 0,
+					"limits": {"fds": 16384, "mem": 1024, "disk": 2048}
+				}`))
+			})
+
+			It("defines it after the downward API env vars it references", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				env := statefulSet.Spec.Template.Spec.Containers[0].Env
+
+				names := []string{}
+				for _, e := range env {
+					names = append(names, e.Name)
+				}
+				Expect(names).To(HaveLen(5))
+				Expect(names[4]).To(Equal("VCAP_APPLICATION"))
+				Expect(names).To(ContainElement("CF_INSTANCE_GUID"))
+			})
+
+			It("keeps the one sent by Cloud Controller on the statefulset only", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Annotations).To(HaveKeyWithValue(k8s.AnnotationVCAPApplication, lrp.Env["VCAP_APPLICATION"]))
+				Expect(statefulSet.Spec.Template.Annotations).NotTo(HaveKey(k8s.AnnotationVCAPApplication))
+			})
+		})
+
 		When("the app has sidecars", func() {
 			BeforeEach(func() {
 				lrp.Sidecars = []opi.Sidecar{
@@ -376,12 +456,11 @@ var _ = Describe("Statefulset Desirer", func() {
 				Expect(containers).To(HaveLen(3))
 
 				Expect(containers).To(ContainElements(
-					corev1.Container{
-						Name:    "first-sidecar",
-						Image:   "busybox",
-						Command: []string{"echo", "the first sidecar"},
-						Env:     []corev1.EnvVar{{Name: "FOO", Value: "BAR"}},
-						Resources: corev1.ResourceRequirements{
+					MatchFields(IgnoreExtras, Fields{
+						"Name":    Equal("first-sidecar"),
+						"Image":   Equal("busybox"),
+						"Command": Equal([]string{"echo", "the first sidecar"}),
+						"Resources": Equal(corev1.ResourceRequirements{
 							Limits: corev1.ResourceList{
 								corev1.ResourceMemory:           *resource.NewScaledQuantity(101, resource.Mega),
 								corev1.ResourceEphemeralStorage: *resource.NewScaledQuantity(lrp.DiskMB, resource.Mega),
@@ -390,14 +469,13 @@ var _ = Describe("Statefulset Desirer", func() {
 								corev1.ResourceMemory: *resource.NewScaledQuantity(101, resource.Mega),
 								corev1.ResourceCPU:    *resource.NewScaledQuantity(int64(lrp.CPUWeight)*10, resource.Milli),
 							},
-						},
-					},
-					corev1.Container{
-						Name:    "second-sidecar",
-						Image:   "busybox",
-						Command: []string{"echo", "the second sidecar"},
-						Env:     []corev1.EnvVar{{Name: "FOO", Value: "BAZ"}},
-						Resources: corev1.ResourceRequirements{
+						}),
+					}),
+					MatchFields(IgnoreExtras, Fields{
+						"Name":    Equal("second-sidecar"),
+						"Image":   Equal("busybox"),
+						"Command": Equal([]string{"echo", "the second sidecar"}),
+						"Resources": Equal(corev1.ResourceRequirements{
 							Limits: corev1.ResourceList{
 								corev1.ResourceMemory:           *resource.NewScaledQuantity(102, resource.Mega),
 								corev1.ResourceEphemeralStorage: *resource.NewScaledQuantity(lrp.DiskMB, resource.Mega),
@@ -406,11 +484,101 @@ var _ = Describe("Statefulset Desirer", func() {
 								corev1.ResourceMemory: *resource.NewScaledQuantity(102, resource.Mega),
 								corev1.ResourceCPU:    *resource.NewScaledQuantity(int64(lrp.CPUWeight)*10, resource.Milli),
 							},
-						},
-					},
+						}),
+					}),
+				))
+			})
+
+			It("should give the sidecars their own env and the instance env", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+
+				sidecar := statefulSet.Spec.Template.Spec.Containers[1]
+				Expect(sidecar.Env).To(ConsistOf(
+					corev1.EnvVar{Name: "FOO", Value: "BAR"},
+					corev1.EnvVar{Name: eirini.EnvPodName, ValueFrom: expectedValFrom("metadata.name")},
+					corev1.EnvVar{Name: eirini.EnvCFInstanceGUID, ValueFrom: expectedValFrom("metadata.uid")},
+					corev1.EnvVar{Name: eirini.EnvCFInstanceInternalIP, ValueFrom: expectedValFrom("status.podIP")},
+					corev1.EnvVar{Name: eirini.EnvCFInstanceIP, ValueFrom: expectedValFrom("status.hostIP")},
+					statefulSet.Spec.Template.Spec.Containers[0].Env[4],
 				))
+				Expect(sidecar.Env[len(sidecar.Env)-1].Name).To(Equal("VCAP_APPLICATION"))
+			})
+
+			It("should not allow the sidecars to escalate privileges", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+
+				for _, sidecar := range statefulSet.Spec.Template.Spec.Containers[1:] {
+					Expect(sidecar.SecurityContext.AllowPrivilegeEscalation).To(PointTo(BeFalse()))
+				}
+			})
+
+			When("a sidecar exposes ports", func() {
+				BeforeEach(func() {
+					lrp.Sidecars[0].Ports = []int32{9000, 9001}
+				})
+
+				It("should expose the ports on the sidecar container", func() {
+					_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+
+					Expect(statefulSet.Spec.Template.Spec.Containers[1].Ports).To(ConsistOf(
+						corev1.ContainerPort{ContainerPort: 9000},
+						corev1.ContainerPort{ContainerPort: 9001},
+					))
+					Expect(statefulSet.Spec.Template.Spec.Containers[2].Ports).To(BeEmpty())
+				})
+			})
+
+			When("the app uses a buildpack droplet", func() {
+				BeforeEach(func() {
+					lrp.DropletDownload = &opi.DropletDownload{
+						URL:             "https://cc.example.com/droplet",
+						DownloaderImage: "eirini/downloader",
+					}
+				})
+
+				It("should mount the droplet in the sidecar containers", func() {
+					_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+
+					for _, sidecar := range statefulSet.Spec.Template.Spec.Containers[1:] {
+						Expect(sidecar.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: k8s.DropletVolumeName, MountPath: k8s.DropletMountPath}))
+					}
+				})
 			})
 		})
+
+		When("the app has placement tags", func() {
+			BeforeEach(func() {
+				statefulSetDesirer.NodePlacements = k8s.NodePlacements{
+					"isolated": {NodeSelector: map[string]string{"segment": "isolated"}},
+				}
+				lrp.PlacementTags = []string{"isolated"}
+			})
+
+			It("should schedule the pods on the nodes of the placement tags", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"segment": "isolated"}))
+				Expect(statefulSet.Annotations).To(HaveKeyWithValue(k8s.AnnotationPlacementTags, `["isolated"]`))
+			})
+
+			When("a placement tag has no configured placement", func() {
+				BeforeEach(func() {
+					lrp.PlacementTags = []string{"unknown"}
+				})
+
+				It("should create the statefulset without constraining its nodes", func() {
+					Expect(desireErr).NotTo(HaveOccurred())
+					Expect(statefulSetClient.CreateCallCount()).To(Equal(1))
+					_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+					Expect(statefulSet.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+					Expect(statefulSet.Spec.Template.Spec.Tolerations).To(BeEmpty())
+				})
+
+				It("should log the ignored placement tag", func() {
+					Expect(logger).To(gbytes.Say("ignoring-unknown-placement-tag"))
+				})
+			})
+		})
+
 		When("automounting service account token is allowed", func() {
 			BeforeEach(func() {
 				statefulSetDesirer.AllowAutomountServiceAccountToken = true
@@ -422,6 +590,66 @@ var _ = Describe("Statefulset Desirer", func() {
 			})
 		})
 
+		When("the app uses a buildpack droplet", func() {
+			BeforeEach(func() {
+				lrp.DropletDownload = &opi.DropletDownload{
+					URL:             "https://cc.example.com/droplet",
+					DownloaderImage: "eirini/downloader",
+				}
+			})
+
+			It("downloads the droplet in an init container", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				initContainers := statefulSet.Spec.Template.Spec.InitContainers
+				Expect(initContainers).To(HaveLen(1))
+				Expect(initContainers[0].Name).To(Equal(k8s.DropletDownloaderContainerName))
+				Expect(initContainers[0].Image).To(Equal("eirini/downloader"))
+				Expect(initContainers[0].Env).To(ContainElement(corev1.EnvVar{Name: eirini.EnvDownloadURL, Value: "https://cc.example.com/droplet"}))
+				Expect(initContainers[0].VolumeMounts).To(ConsistOf(
+					corev1.VolumeMount{Name: k8s.DropletVolumeName, MountPath: k8s.DropletMountPath},
+					corev1.VolumeMount{Name: "cc-uploader-certs", MountPath: "/etc/cf-api/certs", ReadOnly: true},
+				))
+			})
+
+			It("gives the init container the CC certs", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "cc-uploader-certs",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: eirini.CCUploaderSecretName},
+					},
+				}))
+			})
+
+			It("gives the init container the resources of the app container", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				spec := statefulSet.Spec.Template.Spec
+				Expect(spec.InitContainers[0].Resources).To(Equal(spec.Containers[0].Resources))
+				Expect(spec.InitContainers[0].Resources.Requests).NotTo(BeEmpty())
+			})
+
+			It("shares the droplet volume with the app container", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: k8s.DropletVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				}))
+
+				container := statefulSet.Spec.Template.Spec.Containers[0]
+				Expect(container.Name).To(Equal(k8s.OPIContainerName))
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: k8s.DropletVolumeName, MountPath: k8s.DropletMountPath}))
+			})
+		})
+
+		When("the app uses a docker image", func() {
+			It("does not add init containers", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Spec.InitContainers).To(BeEmpty())
+			})
+		})
+
 		When("application should run as root", func() {
 			BeforeEach(func() {
 				lrp.RunsAsRoot = true
@@ -493,6 +721,173 @@ var _ = Describe("Statefulset Desirer", func() {
 			})
 		})
 
+		It("should not create a network policy", func() {
+			Expect(networkPolicyClient.CreateCallCount()).To(BeZero())
+		})
+
+		When("the app has egress rules", func() {
+			BeforeEach(func() {
+				lrp.EgressRules = []opi.EgressRule{
+					{Protocol: "tcp", Destinations: []string{"10.0.0.1-10.0.0.3"}, Ports: []int32{80, 443}},
+					{Protocol: "all", Destinations: []string{"192.168.0.0/16"}},
+				}
+			})
+
+			It("should create a network policy restricting the egress of the app", func() {
+				Expect(networkPolicyClient.CreateCallCount()).To(Equal(1))
+
+				policyNamespace, policy := networkPolicyClient.CreateArgsForCall(0)
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(policyNamespace).To(Equal("the-namespace"))
+				Expect(policy.Name).To(Equal(statefulSet.Name))
+				Expect(policy.Namespace).To(Equal("the-namespace"))
+				Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+					k8s.LabelGUID:       lrp.GUID,
+					k8s.LabelVersion:    lrp.Version,
+					k8s.LabelSourceType: "APP",
+				}))
+				Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeEgress))
+				Expect(policy.Spec.Egress).To(HaveLen(2))
+			})
+
+			It("should record the egress rules on the statefulset", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Annotations).To(HaveKeyWithValue(k8s.AnnotationEgressRules,
+					`[{"protocol":"tcp","destinations":["10.0.0.1-10.0.0.3"],"ports":[80,443]},{"protocol":"all","destinations":["192.168.0.0/16"]}]`))
+			})
+
+			It("should apply the desire options to the network policy", func() {
+				Expect(desireOptOne.CallCount()).To(Equal(2))
+				Expect(desireOptOne.ArgsForCall(1)).To(BeAssignableToTypeOf(&networkingv1.NetworkPolicy{}))
+				Expect(desireOptTwo.CallCount()).To(Equal(2))
+			})
+
+			When("an egress rule is invalid", func() {
+				BeforeEach(func() {
+					lrp.EgressRules = []opi.EgressRule{{Protocol: "all", Destinations: []string{"not-an-ip"}}}
+				})
+
+				It("should fail", func() {
+					Expect(desireErr).To(MatchError(ContainSubstring(`invalid egress destination "not-an-ip"`)))
+				})
+			})
+
+			When("creating the network policy fails", func() {
+				BeforeEach(func() {
+					networkPolicyClient.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("should propagate the error", func() {
+					Expect(desireErr).To(MatchError(ContainSubstring("failed to create network policy")))
+				})
+			})
+
+			When("all egress rules are ICMP rules", func() {
+				BeforeEach(func() {
+					lrp.EgressRules = []opi.EgressRule{
+						{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &opi.ICMPInfo{Type: 8, Code: 0}},
+					}
+				})
+
+				It("should not create a network policy that denies all egress", func() {
+					Expect(desireErr).NotTo(HaveOccurred())
+					Expect(networkPolicyClient.CreateCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		It("should not create an internal service", func() {
+			Expect(serviceClient.GetCallCount()).To(BeZero())
+			Expect(serviceClient.CreateCallCount()).To(BeZero())
+		})
+
+		When("the app has internal routes", func() {
+			BeforeEach(func() {
+				lrp.InternalRoutes = []string{"baldur.apps.internal"}
+			})
+
+			It("should create a headless service selecting the pods of the lrp version", func() {
+				Expect(serviceClient.CreateCallCount()).To(Equal(1))
+
+				serviceNamespace, service := serviceClient.CreateArgsForCall(0)
+				Expect(serviceNamespace).To(Equal("the-namespace"))
+				Expect(service.Name).To(HavePrefix("internal-baldur-space-foo-"))
+				Expect(service.Namespace).To(Equal("the-namespace"))
+				Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+				Expect(service.Spec.Selector).To(Equal(map[string]string{
+					k8s.LabelGUID:       "guid_1234",
+					k8s.LabelVersion:    "version_1234",
+					k8s.LabelSourceType: "APP",
+				}))
+				Expect(service.Spec.Ports).To(HaveLen(2))
+				Expect(service.Spec.Ports[0].Port).To(Equal(int32(8888)))
+				Expect(service.Annotations).To(HaveKeyWithValue(k8s.AnnotationInternalRoutes, `["baldur.apps.internal"]`))
+			})
+
+			It("should record the internal routes on the statefulset", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Annotations).To(HaveKeyWithValue(k8s.AnnotationInternalRoutes, `["baldur.apps.internal"]`))
+			})
+
+			It("should apply the desire options to the service", func() {
+				Expect(desireOptOne.CallCount()).To(Equal(2))
+				Expect(desireOptOne.ArgsForCall(1)).To(BeAssignableToTypeOf(&corev1.Service{}))
+			})
+
+			When("cluster IP services are configured", func() {
+				BeforeEach(func() {
+					statefulSetDesirer.InternalServiceType = k8s.InternalServiceTypeClusterIP
+				})
+
+				It("should create a service with a cluster IP", func() {
+					_, service := serviceClient.CreateArgsForCall(0)
+					Expect(service.Spec.ClusterIP).To(BeEmpty())
+					Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+				})
+			})
+
+			When("an unknown service type is configured", func() {
+				BeforeEach(func() {
+					statefulSetDesirer.InternalServiceType = "LoadBalancer"
+				})
+
+				It("should fail", func() {
+					Expect(desireErr).To(MatchError(ContainSubstring(`unsupported internal service type "LoadBalancer"`)))
+				})
+			})
+
+			When("the service already exists for a previous version", func() {
+				BeforeEach(func() {
+					serviceClient.GetReturns(&corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{Name: "internal-baldur", Namespace: "the-namespace"},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: "10.0.0.10",
+							Selector:  map[string]string{k8s.LabelGUID: "guid_1234", k8s.LabelVersion: "version_0"},
+						},
+					}, nil)
+				})
+
+				It("should point the service to the new version", func() {
+					Expect(serviceClient.CreateCallCount()).To(BeZero())
+					Expect(serviceClient.UpdateCallCount()).To(Equal(1))
+
+					_, service := serviceClient.UpdateArgsForCall(0)
+					Expect(service.Spec.Selector).To(HaveKeyWithValue(k8s.LabelVersion, "version_1234"))
+					Expect(service.Spec.ClusterIP).To(Equal("10.0.0.10"))
+				})
+			})
+
+			When("creating the service fails", func() {
+				BeforeEach(func() {
+					serviceClient.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("should propagate the error", func() {
+					Expect(desireErr).To(MatchError(ContainSubstring("failed to create internal service")))
+				})
+			})
+		})
+
 		When("the app references a private docker image", func() {
 			BeforeEach(func() {
 				lrp.PrivateRegistry = &opi.PrivateRegistry{
@@ -527,6 +922,115 @@ var _ = Describe("Statefulset Desirer", func() {
 				Expect(secret.Name).To(Equal("baldur-space-foo-34f869d015-registry-credentials"))
 			})
 		})
+
+		When("the app references an existing registry secret", func() {
+			BeforeEach(func() {
+				lrp.PrivateRegistry = &opi.PrivateRegistry{
+					SecretName: "my-registry-secret",
+				}
+			})
+
+			It("does not create a registry credentials secret", func() {
+				Expect(secretsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("uses the referenced secret as image pull secret", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
+					corev1.LocalObjectReference{Name: registrySecretName},
+					corev1.LocalObjectReference{Name: "my-registry-secret"},
+				))
+			})
+		})
+
+		It("does not create an env secret when the env is not sensitive", func() {
+			Expect(secretsClient.CreateCallCount()).To(BeZero())
+			_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+			Expect(statefulSet.Spec.Template.Annotations).NotTo(HaveKey(k8s.AnnotationEnvSecretChecksum))
+		})
+
+		When("the app has sensitive env", func() {
+			BeforeEach(func() {
+				statefulSetDesirer.SensitiveEnv = k8s.SensitiveEnv{"*_PASSWORD"}
+				lrp.Env = map[string]string{
+					"VCAP_SERVICES": `{"db":[{"credentials":{"password":"s3cr3t"}}]}`,
+					"DB_PASSWORD":   "s3cr3t",
+					"FOO":           "foo",
+				}
+				lrp.Sidecars = []opi.Sidecar{
+					{Name: "sidecar", Env: map[string]string{"SIDECAR_PASSWORD": "hush"}},
+				}
+			})
+
+			It("stores it in a secret", func() {
+				Expect(secretsClient.CreateCallCount()).To(Equal(1))
+				secretNamespace, secret := secretsClient.CreateArgsForCall(0)
+				Expect(secretNamespace).To(Equal("the-namespace"))
+				Expect(secret.Name).To(Equal("baldur-space-foo-34f869d015-env"))
+				Expect(secret.Data).To(Equal(map[string][]byte{
+					"VCAP_SERVICES":            []byte(`{"db":[{"credentials":{"password":"s3cr3t"}}]}`),
+					"DB_PASSWORD":              []byte("s3cr3t"),
+					"sidecar.SIDECAR_PASSWORD": []byte("hush"),
+				}))
+			})
+
+			It("applies the desire options to the secret", func() {
+				Expect(desireOptOne.CallCount()).To(BeNumerically(">=", 1))
+				_, secret := secretsClient.CreateArgsForCall(0)
+				Expect(desireOptOne.ArgsForCall(0)).To(Equal(secret))
+			})
+
+			It("references the sensitive env from the secret", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				containers := statefulSet.Spec.Template.Spec.Containers
+
+				Expect(containers[0].Env).To(ContainElements(
+					corev1.EnvVar{Name: "FOO", Value: "foo"},
+					corev1.EnvVar{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef("baldur-space-foo-34f869d015-env", "VCAP_SERVICES")},
+					corev1.EnvVar{Name: "DB_PASSWORD", ValueFrom: expectedSecretKeyRef("baldur-space-foo-34f869d015-env", "DB_PASSWORD")},
+				))
+				Expect(containers[1].Env).To(ContainElement(
+					corev1.EnvVar{Name: "SIDECAR_PASSWORD", ValueFrom: expectedSecretKeyRef("baldur-space-foo-34f869d015-env", "sidecar.SIDECAR_PASSWORD")},
+				))
+			})
+
+			It("does not put sensitive values in the statefulset", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				statefulSetJSON, err := json.Marshal(statefulSet)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(statefulSetJSON)).NotTo(ContainSubstring("s3cr3t"))
+				Expect(string(statefulSetJSON)).NotTo(ContainSubstring("hush"))
+			})
+
+			It("records the checksum of the secret on the pod template", func() {
+				_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+				Expect(statefulSet.Spec.Template.Annotations).To(HaveKeyWithValue(k8s.AnnotationEnvSecretChecksum, Not(BeEmpty())))
+			})
+
+			When("the secret already exists", func() {
+				BeforeEach(func() {
+					secretsClient.CreateReturns(nil, k8serrors.NewAlreadyExists(schema.GroupResource{}, "baldur-space-foo-34f869d015-env"))
+				})
+
+				It("updates it", func() {
+					Expect(desireErr).NotTo(HaveOccurred())
+					Expect(secretsClient.UpdateCallCount()).To(Equal(1))
+					_, secret := secretsClient.UpdateArgsForCall(0)
+					Expect(secret.Name).To(Equal("baldur-space-foo-34f869d015-env"))
+				})
+			})
+
+			When("creating the secret fails", func() {
+				BeforeEach(func() {
+					secretsClient.CreateReturns(nil, errors.New("boom"))
+				})
+
+				It("does not create the statefulset", func() {
+					Expect(desireErr).To(MatchError(ContainSubstring("failed to create env secret")))
+					Expect(statefulSetClient.CreateCallCount()).To(BeZero())
+				})
+			})
+		})
 	})
 
 	Describe("Get", func() {
@@ -547,6 +1051,81 @@ var _ = Describe("Statefulset Desirer", func() {
 			Expect(lrp.AppName).To(Equal("baldur-app"))
 		})
 
+		When("the app has sensitive env", func() {
+			BeforeEach(func() {
+				mapper.Returns(&opi.LRP{
+					Env:      map[string]string{"FOO": "bar"},
+					Sidecars: []opi.Sidecar{{Name: "the-sidecar"}},
+				}, nil)
+
+				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{{
+					ObjectMeta: metav1.ObjectMeta{Name: "baldur", Namespace: "the-namespace"},
+					Spec: appsv1.StatefulSetSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name: k8s.OPIContainerName,
+										Env: []corev1.EnvVar{
+											{Name: "FOO", Value: "bar"},
+											{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef("baldur-env", "VCAP_SERVICES")},
+										},
+									},
+									{
+										Name: "the-sidecar",
+										Env: []corev1.EnvVar{
+											{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef("baldur-env", "the-sidecar.VCAP_SERVICES")},
+										},
+									},
+								},
+							},
+						},
+					},
+				}}, nil)
+
+				secretsClient.GetReturns(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "baldur-env"},
+					Data: map[string][]byte{
+						"VCAP_SERVICES":             []byte("app-credentials"),
+						"the-sidecar.VCAP_SERVICES": []byte("sidecar-credentials"),
+					},
+				}, nil)
+			})
+
+			It("reads it from the env secret", func() {
+				lrp, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(secretsClient.GetCallCount()).To(Equal(1))
+				secretNamespace, secretName := secretsClient.GetArgsForCall(0)
+				Expect(secretNamespace).To(Equal("the-namespace"))
+				Expect(secretName).To(Equal("baldur-env"))
+
+				Expect(lrp.Env).To(Equal(map[string]string{"FOO": "bar", "VCAP_SERVICES": "app-credentials"}))
+				Expect(lrp.Sidecars[0].Env).To(Equal(map[string]string{"VCAP_SERVICES": "sidecar-credentials"}))
+			})
+
+			When("getting the env secret fails", func() {
+				BeforeEach(func() {
+					secretsClient.GetReturns(nil, errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})
+					Expect(err).To(MatchError(ContainSubstring("failed to get env secret")))
+				})
+			})
+		})
+
+		When("the app has no sensitive env", func() {
+			It("does not get the env secret", func() {
+				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{{}}, nil)
+				_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secretsClient.GetCallCount()).To(BeZero())
+			})
+		})
+
 		When("the app does not exist", func() {
 			BeforeEach(func() {
 				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{}, nil)
@@ -563,93 +1142,382 @@ var _ = Describe("Statefulset Desirer", func() {
 				statefulSetClient.GetByLRPIdentifierReturns(nil, errors.New("who is this?"))
 			})
 
-			It("should return an error", func() {
-				_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "idontknow", Version: "42"})
-				Expect(err).To(MatchError(ContainSubstring("failed to list statefulsets")))
+			It("should return an error", func() {
+				_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "idontknow", Version: "42"})
+				Expect(err).To(MatchError(ContainSubstring("failed to list statefulsets")))
+			})
+		})
+
+		When("there are 2 lrps with the same identifier", func() {
+			BeforeEach(func() {
+				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{{}, {}}, nil)
+			})
+
+			It("should return an error", func() {
+				_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "idontknow", Version: "42"})
+				Expect(err).To(MatchError(ContainSubstring("multiple statefulsets found for LRP identifier")))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		var (
+			updatedLRP   *opi.LRP
+			statefulSets []appsv1.StatefulSet
+			err          error
+		)
+
+		BeforeEach(func() {
+			updatedLRP = &opi.LRP{
+				LRPIdentifier: opi.LRPIdentifier{
+					GUID:    "guid_1234",
+					Version: "version_1234",
+				},
+				AppName:         "baldur",
+				SpaceName:       "space-foo",
+				TargetInstances: 5,
+				LastUpdated:     "now",
+				AppURIs:         []opi.Route{{Hostname: "new-route.io", Port: 6666}},
+				Image:           "new/image",
+				Sidecars: []opi.Sidecar{
+					{Name: "another-container", Command: []string{"sidecar"}, MemoryMB: 10},
+				},
+			}
+
+			replicas := int32(3)
+
+			statefulSets = []appsv1.StatefulSet{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baldur",
+						Namespace: "the-namespace",
+						Annotations: map[string]string{
+							k8s.AnnotationProcessGUID:          "Baldur-guid",
+							k8s.AnnotationLastUpdated:          "never",
+							k8s.AnnotationRegisteredRoutes:     `[{"hostname":"myroute.io","port":1000}]`,
+							k8s.AnnotationLastReportedLRPCrash: "123",
+							"prometheus.io/scrape":             "true",
+						},
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Replicas: &replicas,
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Annotations: map[string]string{
+									"prometheus.io/scrape": "true",
+								},
+							},
+							Spec: corev1.PodSpec{
+								ImagePullSecrets: []corev1.LocalObjectReference{
+									{Name: "baldur-registry-credentials"},
+								},
+								Containers: []corev1.Container{
+									{Name: "another-container", Image: "another/image"},
+									{Name: k8s.OPIContainerName, Image: "old/image"},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			statefulSetClient.GetByLRPIdentifierReturns(statefulSets, nil)
+		})
+
+		JustBeforeEach(func() {
+			err = statefulSetDesirer.Update(updatedLRP)
+		})
+
+		It("succeeds", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("updates the statefulset", func() {
+			Expect(statefulSetClient.UpdateCallCount()).To(Equal(1))
+
+			namespace, st := statefulSetClient.UpdateArgsForCall(0)
+			Expect(namespace).To(Equal("the-namespace"))
+			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationLastUpdated, "now"))
+			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationRegisteredRoutes, `[{"hostname":"new-route.io","port":6666}]`))
+			Expect(st.GetAnnotations()).NotTo(HaveKey("another"))
+			Expect(*st.Spec.Replicas).To(Equal(int32(5)))
+			Expect(st.Spec.Template.Spec.Containers[0].Name).To(Equal(k8s.OPIContainerName))
+			Expect(st.Spec.Template.Spec.Containers[0].Image).To(Equal("new/image"))
+			Expect(st.Spec.Template.Spec.Containers[1].Name).To(Equal("another-container"))
+			Expect(st.Spec.Template.Spec.Containers[1].Image).To(Equal("new/image"))
+		})
+
+		It("keeps the annotations owned by eirini", func() {
+			_, st := statefulSetClient.UpdateArgsForCall(0)
+			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationProcessGUID, "guid_1234-version_1234"))
+			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationLastReportedLRPCrash, "123"))
+		})
+
+		It("does not change the image pull secrets", func() {
+			_, st := statefulSetClient.UpdateArgsForCall(0)
+			Expect(st.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
+				corev1.LocalObjectReference{Name: "baldur-registry-credentials"},
+			))
+			Expect(secretsClient.UpdateCallCount()).To(BeZero())
+			Expect(secretsClient.CreateCallCount()).To(BeZero())
+		})
+
+		When("the env, resources and command change", func() {
+			BeforeEach(func() {
+				updatedLRP.Env = map[string]string{"FOO": "bar"}
+				updatedLRP.MemoryMB = 512
+				updatedLRP.DiskMB = 1024
+				updatedLRP.CPUWeight = 50
+				updatedLRP.Command = []string{"/bin/sh", "-c", "run"}
+			})
+
+			It("updates the app container in place", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				container := st.Spec.Template.Spec.Containers[0]
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "FOO", Value: "bar"}))
+				Expect(container.Command).To(Equal([]string{"/bin/sh", "-c", "run"}))
+				Expect(container.Resources.Limits.Memory().ScaledValue(resource.Mega)).To(Equal(int64(512)))
+				Expect(container.Resources.Limits.StorageEphemeral().ScaledValue(resource.Mega)).To(Equal(int64(1024)))
+				Expect(container.Resources.Requests.Cpu().MilliValue()).To(Equal(int64(500)))
+			})
+		})
+
+		When("the health check changes", func() {
+			BeforeEach(func() {
+				updatedLRP.Health = opi.Healtcheck{Type: "http", Port: 8080, Endpoint: "/healthz"}
+				livenessProbeCreator.Returns(&corev1.Probe{InitialDelaySeconds: 42})
+				readinessProbeCreator.Returns(&corev1.Probe{InitialDelaySeconds: 24})
+			})
+
+			It("recreates the probes from the updated LRP", func() {
+				Expect(livenessProbeCreator.CallCount()).To(Equal(1))
+				Expect(livenessProbeCreator.ArgsForCall(0).Health).To(Equal(updatedLRP.Health))
+
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				container := st.Spec.Template.Spec.Containers[0]
+				Expect(container.LivenessProbe).To(Equal(&corev1.Probe{InitialDelaySeconds: 42}))
+				Expect(container.ReadinessProbe).To(Equal(&corev1.Probe{InitialDelaySeconds: 24}))
+				Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationHealthCheck,
+					`{"type":"http","port":8080,"endpoint":"/healthz","timeout_ms":0}`))
+			})
+		})
+
+		When("the sidecars are removed", func() {
+			BeforeEach(func() {
+				updatedLRP.Sidecars = nil
+			})
+
+			It("removes the sidecar containers", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.Spec.Template.Spec.Containers).To(HaveLen(1))
+				Expect(st.Spec.Template.Spec.Containers[0].Name).To(Equal(k8s.OPIContainerName))
+			})
+		})
+
+		When("the user defined annotations change", func() {
+			BeforeEach(func() {
+				updatedLRP.UserDefinedAnnotations = map[string]string{"prometheus.io/port": "9090"}
+			})
+
+			It("replaces the user defined annotations", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.GetAnnotations()).To(HaveKeyWithValue("prometheus.io/port", "9090"))
+				Expect(st.GetAnnotations()).NotTo(HaveKey("prometheus.io/scrape"))
+				Expect(st.Spec.Template.Annotations).To(HaveKeyWithValue("prometheus.io/port", "9090"))
+				Expect(st.Spec.Template.Annotations).NotTo(HaveKey("prometheus.io/scrape"))
+			})
+		})
+
+		When("the lrp has private registry credentials", func() {
+			BeforeEach(func() {
+				updatedLRP.PrivateRegistry = &opi.PrivateRegistry{
+					Server:   "registry.io",
+					Username: "user",
+					Password: "new-password",
+				}
+			})
+
+			It("updates the registry credentials secret", func() {
+				Expect(secretsClient.UpdateCallCount()).To(Equal(1))
+				secretNamespace, secret := secretsClient.UpdateArgsForCall(0)
+				Expect(secretNamespace).To(Equal("the-namespace"))
+				Expect(secret.Name).To(Equal("baldur-registry-credentials"))
+				Expect(secret.StringData).To(HaveKeyWithValue(".dockerconfigjson", ContainSubstring(
+					base64.StdEncoding.EncodeToString([]byte("user:new-password")),
+				)))
+				Expect(secretsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("rotates the credentials in place without restarting running instances", func() {
+				Expect(secretsClient.DeleteCallCount()).To(BeZero())
+				Expect(podsClient.DeleteCallCount()).To(BeZero())
+
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.Spec.Template.Spec.ImagePullSecrets).To(ContainElement(
+					statefulSets[0].Spec.Template.Spec.ImagePullSecrets[0],
+				))
+			})
+
+			It("uses the registry credentials secret", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
+					corev1.LocalObjectReference{Name: registrySecretName},
+					corev1.LocalObjectReference{Name: "baldur-registry-credentials"},
+				))
+			})
+
+			When("the registry credentials secret does not exist", func() {
+				BeforeEach(func() {
+					statefulSets[0].OwnerReferences = []metav1.OwnerReference{{Kind: "LRP", Name: "the-lrp"}}
+					statefulSetClient.GetByLRPIdentifierReturns(statefulSets, nil)
+					secretsClient.UpdateReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "baldur-registry-credentials"))
+				})
+
+				It("creates it", func() {
+					Expect(secretsClient.CreateCallCount()).To(Equal(1))
+					_, secret := secretsClient.CreateArgsForCall(0)
+					Expect(secret.Name).To(Equal("baldur-registry-credentials"))
+				})
+
+				It("makes the statefulset owners own it", func() {
+					_, secret := secretsClient.CreateArgsForCall(0)
+					Expect(secret.OwnerReferences).To(ConsistOf(metav1.OwnerReference{Kind: "LRP", Name: "the-lrp"}))
+				})
+			})
+
+			When("updating the registry credentials secret fails", func() {
+				BeforeEach(func() {
+					secretsClient.UpdateReturns(nil, errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to update private registry secret")))
+				})
+
+				It("does not update the statefulset", func() {
+					Expect(statefulSetClient.UpdateCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		When("the lrp references an existing registry secret", func() {
+			BeforeEach(func() {
+				updatedLRP.PrivateRegistry = &opi.PrivateRegistry{
+					SecretName: "my-registry-secret",
+				}
+			})
+
+			It("does not touch any registry secret", func() {
+				Expect(secretsClient.UpdateCallCount()).To(BeZero())
+				Expect(secretsClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("uses the referenced secret as image pull secret", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(
+					corev1.LocalObjectReference{Name: registrySecretName},
+					corev1.LocalObjectReference{Name: "my-registry-secret"},
+				))
+			})
+		})
+
+		When("the lrp has sensitive env", func() {
+			BeforeEach(func() {
+				updatedLRP.Env = map[string]string{"VCAP_SERVICES": "new-credentials"}
+				statefulSets[0].OwnerReferences = []metav1.OwnerReference{{Kind: "LRP", Name: "baldur"}}
+				statefulSetClient.GetByLRPIdentifierReturns(statefulSets, nil)
+			})
+
+			It("rotates the env secret", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secretsClient.UpdateCallCount()).To(Equal(1))
+				secretNamespace, secret := secretsClient.UpdateArgsForCall(0)
+				Expect(secretNamespace).To(Equal("the-namespace"))
+				Expect(secret.Name).To(Equal("baldur-env"))
+				Expect(secret.OwnerReferences).To(Equal(statefulSets[0].OwnerReferences))
+				Expect(secret.Data).To(Equal(map[string][]byte{"VCAP_SERVICES": []byte("new-credentials")}))
+			})
+
+			It("rolls out pods referencing the new secret data", func() {
+				_, st := statefulSetClient.UpdateArgsForCall(0)
+				Expect(st.Spec.Template.Annotations).To(HaveKey(k8s.AnnotationEnvSecretChecksum))
+				Expect(st.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+					corev1.EnvVar{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef("baldur-env", "VCAP_SERVICES")},
+				))
 			})
-		})
 
-		When("there are 2 lrps with the same identifier", func() {
-			BeforeEach(func() {
-				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{{}, {}}, nil)
+			When("the env secret does not exist", func() {
+				BeforeEach(func() {
+					secretsClient.UpdateReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "baldur-env"))
+				})
+
+				It("creates it", func() {
+					Expect(secretsClient.CreateCallCount()).To(Equal(1))
+					_, secret := secretsClient.CreateArgsForCall(0)
+					Expect(secret.Name).To(Equal("baldur-env"))
+				})
 			})
 
-			It("should return an error", func() {
-				_, err := statefulSetDesirer.Get(opi.LRPIdentifier{GUID: "idontknow", Version: "42"})
-				Expect(err).To(MatchError(ContainSubstring("multiple statefulsets found for LRP identifier")))
+			When("updating the env secret fails", func() {
+				BeforeEach(func() {
+					secretsClient.UpdateReturns(nil, errors.New("boom"))
+				})
+
+				It("does not update the statefulset", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to update env secret")))
+					Expect(statefulSetClient.UpdateCallCount()).To(BeZero())
+				})
 			})
 		})
-	})
 
-	Describe("Update", func() {
-		var (
-			updatedLRP *opi.LRP
-			err        error
-		)
+		Describe("updates that do not change the pods", func() {
+			var currentStatefulSet *appsv1.StatefulSet
 
-		BeforeEach(func() {
-			updatedLRP = &opi.LRP{
-				LRPIdentifier: opi.LRPIdentifier{
-					GUID:    "guid_1234",
-					Version: "version_1234",
-				},
-				AppName:         "baldur",
-				SpaceName:       "space-foo",
-				TargetInstances: 5,
-				LastUpdated:     "now",
-				AppURIs:         []opi.Route{{Hostname: "new-route.io", Port: 6666}},
-				Image:           "new/image",
-			}
+			JustBeforeEach(func() {
+				_, currentStatefulSet = statefulSetClient.UpdateArgsForCall(0)
+				statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{*currentStatefulSet}, nil)
+			})
 
-			replicas := int32(3)
+			It("keeps the pod template when only the instances change", func() {
+				updatedLRP.TargetInstances = 10
+				Expect(statefulSetDesirer.Update(updatedLRP)).To(Succeed())
 
-			st := []appsv1.StatefulSet{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "baldur",
-						Namespace: "the-namespace",
-						Annotations: map[string]string{
-							k8s.AnnotationProcessGUID:      "Baldur-guid",
-							k8s.AnnotationLastUpdated:      "never",
-							k8s.AnnotationRegisteredRoutes: `[{"hostname":"myroute.io","port":1000}]`,
-						},
-					},
-					Spec: appsv1.StatefulSetSpec{
-						Replicas: &replicas,
-						Template: corev1.PodTemplateSpec{
-							Spec: corev1.PodSpec{
-								Containers: []corev1.Container{
-									{Name: "another-container", Image: "another/image"},
-									{Name: k8s.OPIContainerName, Image: "old/image"},
-								},
-							},
-						},
-					},
-				},
-			}
+				_, st := statefulSetClient.UpdateArgsForCall(1)
+				Expect(*st.Spec.Replicas).To(Equal(int32(10)))
+				Expect(st.Spec.Template).To(Equal(currentStatefulSet.Spec.Template))
+			})
 
-			statefulSetClient.GetByLRPIdentifierReturns(st, nil)
-		})
+			It("keeps the pod template when only the routes change", func() {
+				updatedLRP.AppURIs = []opi.Route{{Hostname: "newer-route.io", Port: 7777}}
+				updatedLRP.LastUpdated = "later"
+				updatedLRP.LRP = "newer request"
+				Expect(statefulSetDesirer.Update(updatedLRP)).To(Succeed())
 
-		JustBeforeEach(func() {
-			err = statefulSetDesirer.Update(updatedLRP)
-		})
+				_, st := statefulSetClient.UpdateArgsForCall(1)
+				Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationRegisteredRoutes, `[{"hostname":"newer-route.io","port":7777}]`))
+				Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationLastUpdated, "later"))
+				Expect(st.Spec.Template).To(Equal(currentStatefulSet.Spec.Template))
+			})
 
-		It("succeeds", func() {
-			Expect(err).NotTo(HaveOccurred())
+			It("replaces the pod template when the pods change", func() {
+				updatedLRP.Command = []string{"/bin/sh", "-c", "run"}
+				Expect(statefulSetDesirer.Update(updatedLRP)).To(Succeed())
+
+				_, st := statefulSetClient.UpdateArgsForCall(1)
+				Expect(st.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"/bin/sh", "-c", "run"}))
+				Expect(st.Spec.Template.Annotations[k8s.AnnotationPodTemplateChecksum]).NotTo(Equal(
+					currentStatefulSet.Spec.Template.Annotations[k8s.AnnotationPodTemplateChecksum],
+				))
+			})
 		})
 
-		It("updates the statefulset", func() {
-			Expect(statefulSetClient.UpdateCallCount()).To(Equal(1))
+		It("produces the same statefulset when applied twice", func() {
+			_, st := statefulSetClient.UpdateArgsForCall(0)
+			statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{*st}, nil)
 
-			namespace, st := statefulSetClient.UpdateArgsForCall(0)
-			Expect(namespace).To(Equal("the-namespace"))
-			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationLastUpdated, "now"))
-			Expect(st.GetAnnotations()).To(HaveKeyWithValue(k8s.AnnotationRegisteredRoutes, `[{"hostname":"new-route.io","port":6666}]`))
-			Expect(st.GetAnnotations()).NotTo(HaveKey("another"))
-			Expect(*st.Spec.Replicas).To(Equal(int32(5)))
-			Expect(st.Spec.Template.Spec.Containers[0].Image).To(Equal("another/image"))
-			Expect(st.Spec.Template.Spec.Containers[1].Image).To(Equal("new/image"))
+			Expect(statefulSetDesirer.Update(updatedLRP)).To(Succeed())
+			_, secondSt := statefulSetClient.UpdateArgsForCall(1)
+			Expect(secondSt).To(Equal(st))
 		})
 
 		When("the image is missing", func() {
@@ -742,6 +1610,100 @@ var _ = Describe("Statefulset Desirer", func() {
 			})
 		})
 
+		It("removes the network policy when there are no egress rules", func() {
+			Expect(networkPolicyClient.DeleteCallCount()).To(Equal(1))
+			namespace, name := networkPolicyClient.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("the-namespace"))
+			Expect(name).To(Equal("baldur"))
+		})
+
+		When("the lrp has egress rules", func() {
+			BeforeEach(func() {
+				updatedLRP.EgressRules = []opi.EgressRule{
+					{Protocol: "udp", Destinations: []string{"10.0.0.53"}, Ports: []int32{53}},
+				}
+				statefulSets[0].OwnerReferences = []metav1.OwnerReference{{Kind: "LRP", Name: "baldur-lrp"}}
+			})
+
+			It("updates the network policy", func() {
+				Expect(networkPolicyClient.UpdateCallCount()).To(Equal(1))
+				namespace, policy := networkPolicyClient.UpdateArgsForCall(0)
+				Expect(namespace).To(Equal("the-namespace"))
+				Expect(policy.Name).To(Equal("baldur"))
+				Expect(policy.Spec.Egress).To(HaveLen(1))
+				Expect(networkPolicyClient.CreateCallCount()).To(BeZero())
+			})
+
+			It("gives the network policy the owners of the statefulset", func() {
+				_, policy := networkPolicyClient.UpdateArgsForCall(0)
+				Expect(policy.OwnerReferences).To(ConsistOf(metav1.OwnerReference{Kind: "LRP", Name: "baldur-lrp"}))
+			})
+
+			When("the network policy does not exist yet", func() {
+				BeforeEach(func() {
+					networkPolicyClient.UpdateReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "baldur"))
+				})
+
+				It("creates it", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(networkPolicyClient.CreateCallCount()).To(Equal(1))
+					_, policy := networkPolicyClient.CreateArgsForCall(0)
+					Expect(policy.Name).To(Equal("baldur"))
+				})
+			})
+
+			When("updating the network policy fails", func() {
+				BeforeEach(func() {
+					networkPolicyClient.UpdateReturns(nil, errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to update network policy")))
+				})
+			})
+
+			When("all egress rules are ICMP rules", func() {
+				BeforeEach(func() {
+					updatedLRP.EgressRules = []opi.EgressRule{
+						{Protocol: "icmp", Destinations: []string{"10.0.0.1"}, IcmpInfo: &opi.ICMPInfo{Type: 8, Code: 0}},
+					}
+				})
+
+				It("removes the network policy", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(networkPolicyClient.UpdateCallCount()).To(BeZero())
+					Expect(networkPolicyClient.DeleteCallCount()).To(Equal(1))
+					_, name := networkPolicyClient.DeleteArgsForCall(0)
+					Expect(name).To(Equal("baldur"))
+				})
+			})
+		})
+
+		When("the lrp has internal routes", func() {
+			BeforeEach(func() {
+				updatedLRP.InternalRoutes = []string{"baldur.apps.internal"}
+				statefulSets[0].OwnerReferences = []metav1.OwnerReference{{Kind: "LRP", Name: "baldur-lrp"}}
+			})
+
+			It("makes sure the internal service exists", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(serviceClient.CreateCallCount()).To(Equal(1))
+				namespace, service := serviceClient.CreateArgsForCall(0)
+				Expect(namespace).To(Equal("the-namespace"))
+				Expect(service.OwnerReferences).To(ConsistOf(metav1.OwnerReference{Kind: "LRP", Name: "baldur-lrp"}))
+			})
+
+			When("getting the service fails", func() {
+				BeforeEach(func() {
+					serviceClient.GetReturns(nil, errors.New("boom"))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError(ContainSubstring("failed to get internal service")))
+				})
+			})
+		})
+
 		When("update fails", func() {
 			BeforeEach(func() {
 				statefulSetClient.UpdateReturns(nil, errors.New("boom"))
@@ -778,6 +1740,109 @@ var _ = Describe("Statefulset Desirer", func() {
 		})
 	})
 
+	Describe("Scaling an app with sensitive env", func() {
+		var (
+			lrp         *opi.LRP
+			statefulSet *appsv1.StatefulSet
+		)
+
+		BeforeEach(func() {
+			statefulSetDesirer.StatefulSetToLRPMapper = k8s.StatefulSetToLRP
+
+			lrp = createLRP("Baldur", []opi.Route{{Hostname: "my.example.route", Port: 1000}})
+			lrp.Env = map[string]string{"FOO": "bar", "VCAP_SERVICES": `{"user-provided":[]}`}
+			Expect(statefulSetDesirer.Desire("the-namespace", lrp)).To(Succeed())
+
+			_, statefulSet = statefulSetClient.CreateArgsForCall(0)
+			statefulSet.Namespace = "the-namespace"
+			statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{*statefulSet}, nil)
+
+			_, envSecret := secretsClient.CreateArgsForCall(0)
+			Expect(envSecret.Name).To(Equal(statefulSet.Name + "-env"))
+			secretsClient.GetReturns(envSecret, nil)
+		})
+
+		It("keeps the sensitive env", func() {
+			currentLRP, err := statefulSetDesirer.Get(lrp.LRPIdentifier)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(currentLRP.Env).To(HaveKeyWithValue("FOO", "bar"))
+			Expect(currentLRP.Env).To(HaveKeyWithValue("VCAP_SERVICES", `{"user-provided":[]}`))
+
+			currentLRP.TargetInstances = 3
+			Expect(statefulSetDesirer.Update(currentLRP)).To(Succeed())
+
+			_, updatedStatefulSet := statefulSetClient.UpdateArgsForCall(0)
+			Expect(*updatedStatefulSet.Spec.Replicas).To(BeNumerically("==", 3))
+			Expect(updatedStatefulSet.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "FOO", Value: "bar"},
+				corev1.EnvVar{Name: "VCAP_SERVICES", ValueFrom: expectedSecretKeyRef(statefulSet.Name+"-env", "VCAP_SERVICES")},
+			))
+			Expect(updatedStatefulSet.Spec.Template.Annotations).To(Equal(statefulSet.Spec.Template.Annotations))
+
+			_, updatedSecret := secretsClient.UpdateArgsForCall(0)
+			Expect(updatedSecret.Data).To(Equal(map[string][]byte{"VCAP_SERVICES": []byte(`{"user-provided":[]}`)}))
+		})
+	})
+
+	Describe("Scaling an app with a VCAP_APPLICATION sent by Cloud Controller", func() {
+		var (
+			desiredEnv             []corev1.EnvVar
+			updatedEnv             []corev1.EnvVar
+			updatedVCAPApplication string
+		)
+
+		BeforeEach(func() {
+			statefulSetDesirer.StatefulSetToLRPMapper = k8s.StatefulSetToLRP
+
+			lrp := createLRP("Baldur", []opi.Route{{Hostname: "my.example.route", Port: 1000}})
+			lrp.Env = map[string]string{
+				"VCAP_APPLICATION": `{"cf_api":"https://api.example.com","application_version":"v-1","version":"v-1","start":"2020-01-01","application_uris":["my.example.route"],"limits":{"fds":16384}}`,
+			}
+			Expect(statefulSetDesirer.Desire("the-namespace", lrp)).To(Succeed())
+
+			_, statefulSet := statefulSetClient.CreateArgsForCall(0)
+			statefulSetClient.GetByLRPIdentifierReturns([]appsv1.StatefulSet{*statefulSet}, nil)
+			desiredEnv = statefulSet.Spec.Template.Spec.Containers[0].Env
+
+			currentLRP, err := statefulSetDesirer.Get(lrp.LRPIdentifier)
+			Expect(err).NotTo(HaveOccurred())
+
+			currentLRP.TargetInstances = 3
+			currentLRP.AppURIs = []opi.Route{{Hostname: "new.example.route", Port: 1000}}
+			Expect(statefulSetDesirer.Update(currentLRP)).To(Succeed())
+
+			_, updatedStatefulSet := statefulSetClient.UpdateArgsForCall(0)
+			updatedEnv = updatedStatefulSet.Spec.Template.Spec.Containers[0].Env
+			for _, env := range updatedEnv {
+				if env.Name == "VCAP_APPLICATION" {
+					updatedVCAPApplication = env.Value
+				}
+			}
+		})
+
+		It("keeps the fields only Cloud Controller knows about", func() {
+			vcap := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(updatedVCAPApplication), &vcap)).To(Succeed())
+			Expect(vcap).To(HaveKeyWithValue("cf_api", "https://api.example.com"))
+			Expect(vcap).To(HaveKeyWithValue("application_version", "v-1"))
+			Expect(vcap).To(HaveKeyWithValue("version", "v-1"))
+			Expect(vcap).To(HaveKeyWithValue("start", "2020-01-01"))
+			Expect(vcap).To(HaveKeyWithValue("application_uris", []interface{}{"my.example.route"}))
+			Expect(vcap).To(HaveKeyWithValue("limits", map[string]interface{}{"fds": 16384.0, "mem": 1024.0, "disk": 2048.0}))
+		})
+
+		It("overrides the fields eirini sets", func() {
+			vcap := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(updatedVCAPApplication), &vcap)).To(Succeed())
+			Expect(vcap).To(HaveKeyWithValue("instance_id", "$(CF_INSTANCE_GUID)"))
+			Expect(vcap).NotTo(HaveKey("instance_index"))
+		})
+
+		It("does not change the container env when the routes change", func() {
+			Expect(updatedEnv).To(Equal(desiredEnv))
+		})
+	})
+
 	Context("When listing apps", func() {
 		It("translates all existing statefulSets to opi.LRPs", func() {
 			st := []appsv1.StatefulSet{
@@ -868,6 +1933,30 @@ var _ = Describe("Statefulset Desirer", func() {
 			Expect(pdbName).To(Equal("baldur"))
 		})
 
+		When("the stateful set has an env secret", func() {
+			BeforeEach(func() {
+				statefulSets[0].Spec.Template.Annotations = map[string]string{k8s.AnnotationEnvSecretChecksum: "checksum"}
+			})
+
+			It("deletes the env secret", func() {
+				Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+				Expect(secretsClient.DeleteCallCount()).To(Equal(1))
+				secretNs, secretName := secretsClient.DeleteArgsForCall(0)
+				Expect(secretName).To(Equal("baldur-env"))
+				Expect(secretNs).To(Equal("the-namespace"))
+			})
+
+			When("the env secret does not exist", func() {
+				BeforeEach(func() {
+					secretsClient.DeleteReturns(k8serrors.NewNotFound(schema.GroupResource{}, "baldur-env"))
+				})
+
+				It("succeeds", func() {
+					Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+				})
+			})
+		})
+
 		When("the stateful set runs an image from a private registry", func() {
 			BeforeEach(func() {
 				statefulSets[0].Spec = appsv1.StatefulSetSpec{
@@ -934,6 +2023,69 @@ var _ = Describe("Statefulset Desirer", func() {
 			})
 		})
 
+		It("should delete the network policy", func() {
+			Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+			Expect(networkPolicyClient.DeleteCallCount()).To(Equal(1))
+			namespace, name := networkPolicyClient.DeleteArgsForCall(0)
+			Expect(namespace).To(Equal("the-namespace"))
+			Expect(name).To(Equal("baldur"))
+		})
+
+		It("looks up the internal service of the app", func() {
+			Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+			Expect(serviceClient.GetCallCount()).To(Equal(1))
+			Expect(serviceClient.DeleteCallCount()).To(BeZero())
+		})
+
+		When("the internal service selects the stopped version", func() {
+			BeforeEach(func() {
+				serviceClient.GetReturns(&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "internal-baldur"},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{k8s.LabelVersion: "version_1234"},
+					},
+				}, nil)
+			})
+
+			It("deletes it", func() {
+				Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+				Expect(serviceClient.DeleteCallCount()).To(Equal(1))
+				namespace, _ := serviceClient.DeleteArgsForCall(0)
+				Expect(namespace).To(Equal("the-namespace"))
+			})
+
+			When("deleting it fails", func() {
+				It("returns an error", func() {
+					serviceClient.DeleteReturns(errors.New("boom"))
+
+					Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(MatchError(ContainSubstring("failed to delete internal service")))
+				})
+			})
+		})
+
+		When("the internal service selects a newer version", func() {
+			BeforeEach(func() {
+				serviceClient.GetReturns(&corev1.Service{
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{k8s.LabelVersion: "version_5678"},
+					},
+				}, nil)
+			})
+
+			It("keeps it", func() {
+				Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(Succeed())
+				Expect(serviceClient.DeleteCallCount()).To(BeZero())
+			})
+		})
+
+		When("network policy deletion fails", func() {
+			It("returns an error", func() {
+				networkPolicyClient.DeleteReturns(errors.New("boom"))
+
+				Expect(statefulSetDesirer.Stop(opi.LRPIdentifier{GUID: "guid_1234", Version: "version_1234"})).To(MatchError(ContainSubstring("failed to delete network policy")))
+			})
+		})
+
 		When("pdb deletion fails", func() {
 			It("returns an error", func() {
 				pdbClient.DeleteReturns(errors.New("boom"))
@@ -1098,6 +2250,64 @@ var _ = Describe("Statefulset Desirer", func() {
 			Expect(instances[0].PlacementError).To(BeEmpty())
 		})
 
+		When("the instance container has crashed before", func() {
+			var finishedAt metav1.Time
+
+			BeforeEach(func() {
+				finishedAt = metav1.Unix(456, 0)
+				pods := []corev1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "odin-2"},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							ContainerStatuses: []corev1.ContainerStatus{
+								{
+									Name:         k8s.OPIContainerName,
+									State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+									RestartCount: 2,
+									LastTerminationState: corev1.ContainerState{
+										Terminated: &corev1.ContainerStateTerminated{
+											Reason:     "Error",
+											ExitCode:   3,
+											FinishedAt: finishedAt,
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+				podsClient.GetByLRPIdentifierReturns(pods, nil)
+				eventsClient.GetByPodReturns([]corev1.Event{}, nil)
+			})
+
+			It("returns the last crash of the instance", func() {
+				instances, err := statefulSetDesirer.GetInstances(opi.LRPIdentifier{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instances).To(HaveLen(1))
+				Expect(instances[0].LastCrash).To(Equal(&opi.InstanceCrash{
+					Reason:     "Error",
+					ExitStatus: 3,
+					CrashCount: 2,
+					Timestamp:  finishedAt.UnixNano(),
+				}))
+			})
+		})
+
+		When("the instance container has never crashed", func() {
+			It("does not return a last crash", func() {
+				pods := []corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "odin-0"}},
+				}
+				podsClient.GetByLRPIdentifierReturns(pods, nil)
+				eventsClient.GetByPodReturns([]corev1.Event{}, nil)
+
+				instances, err := statefulSetDesirer.GetInstances(opi.LRPIdentifier{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instances[0].LastCrash).To(BeNil())
+			})
+		})
+
 		When("pod list fails", func() {
 			It("should return a meaningful error", func() {
 				podsClient.GetByLRPIdentifierReturns(nil, errors.New("boom"))
@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TaskStartGracePeriod is how long a task pod may stay unschedulable or keep
+// failing to pull an image before its task fails. Both often recover on their
+// own, e.g. once the cluster has scaled up or the registry is reachable again,
+// and the kubelet keeps retrying pulls with an increasing back-off.
+const TaskStartGracePeriod = 2 * time.Minute
+
+// imagePullFailureReasons are the reasons the kubelet gives for containers
+// waiting on an image it cannot pull, and whether pulling the image is worth
+// retrying.
+var imagePullFailureReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  false,
+	"ErrImageNeverPull": false,
+}
+
+// TaskFailureReason describes why a task attempt failed, in the form
+// "<reason> (exit code <code>): <message>". The reason is DeadlineExceeded for
+// timed out tasks and the reason of the terminated task container otherwise,
+// e.g. Error or OOMKilled. The message is the termination message of the task
+// container.
+func TaskFailureReason(pod *corev1.Pod, terminated *corev1.ContainerStateTerminated) string {
+	var (
+		reason   string
+		exitCode *int32
+		message  string
+	)
+
+	if terminated != nil {
+		reason = terminated.Reason
+		exitCode = &terminated.ExitCode
+		message = terminated.Message
+	}
+
+	if pod.Status.Reason == PodDeadlineExceededReason {
+		reason = PodDeadlineExceededReason
+		message = TaskTimeoutFailureReason
+	}
+
+	return describeTaskFailure(reason, exitCode, message)
+}
+
+// TaskStartFailureReason tells whether the task pod will never start, either
+// because it cannot be scheduled or because one of its images cannot be
+// pulled, and describes why. Pods get TaskStartGracePeriod to recover from
+// failures that may be transient.
+func TaskStartFailureReason(pod *corev1.Pod) (string, bool) {
+	reason, remaining, failing := taskStartFailure(pod)
+	if !failing || remaining > 0 {
+		return "", false
+	}
+
+	return reason, true
+}
+
+// TaskStartGraceRemaining tells whether the task pod is failing to start
+// without having failed yet, and how long it has left to recover.
+func TaskStartGraceRemaining(pod *corev1.Pod) (time.Duration, bool) {
+	_, remaining, failing := taskStartFailure(pod)
+	if !failing || remaining <= 0 {
+		return 0, false
+	}
+
+	return remaining, true
+}
+
+func taskStartFailure(pod *corev1.Pod) (string, time.Duration, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			since := condition.LastTransitionTime.Time
+			if since.IsZero() {
+				since = pod.CreationTimestamp.Time
+			}
+
+			return describeTaskFailure(condition.Reason, nil, condition.Message), gracePeriodLeft(since), true
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting == nil {
+			continue
+		}
+
+		retryable, ok := imagePullFailureReasons[waiting.Reason]
+		if !ok {
+			continue
+		}
+
+		reason := describeTaskFailure(waiting.Reason, nil, waiting.Message)
+		if !retryable {
+			return reason, 0, true
+		}
+
+		since := pod.CreationTimestamp.Time
+		if pod.Status.StartTime != nil {
+			since = pod.Status.StartTime.Time
+		}
+
+		return reason, gracePeriodLeft(since), true
+	}
+
+	return "", 0, false
+}
+
+func gracePeriodLeft(since time.Time) time.Duration {
+	return time.Until(since.Add(TaskStartGracePeriod))
+}
+
+func describeTaskFailure(reason string, exitCode *int32, message string) string {
+	description := reason
+
+	if exitCode != nil {
+		description = fmt.Sprintf("exit code %d", *exitCode)
+
+		if reason != "" {
+			description = fmt.Sprintf("%s (%s)", reason, description)
+		}
+	}
+
+	if message = strings.TrimSpace(message); message != "" {
+		if description == "" {
+			return message
+		}
+
+		description = fmt.Sprintf("%s: %s", description, message)
+	}
+
+	return description
+}
@@ -0,0 +1,259 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	AnnotationTaskAttempt             = "cloudfoundry.org/task_attempt"
+	AnnotationTaskMaxAttempts         = "cloudfoundry.org/task_max_attempts"
+	AnnotationTaskRun                 = "cloudfoundry.org/task_run"
+	AnnotationTaskRetryBackoffSeconds = "cloudfoundry.org/task_retry_backoff_seconds"
+	AnnotationTaskRetryExitCodes      = "cloudfoundry.org/task_retry_exit_codes"
+
+	maxJobNameLength = 63
+)
+
+// applyRetryPolicy keeps the retry policy of the task in annotations. Every
+// attempt runs in a job of its own that is never retried by Kubernetes: the
+// task reporter creates the job of the next attempt once the backoff has
+// passed, and only for the exit codes worth retrying.
+func applyRetryPolicy(job *batch.Job, policy *opi.TaskRetryPolicy) error {
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return nil
+	}
+
+	job.Annotations[AnnotationTaskAttempt] = "1"
+	job.Annotations[AnnotationTaskMaxAttempts] = strconv.Itoa(int(policy.MaxAttempts))
+
+	if policy.BackoffSeconds > 0 {
+		job.Annotations[AnnotationTaskRetryBackoffSeconds] = strconv.FormatInt(policy.BackoffSeconds, 10)
+	}
+
+	if len(policy.RetryOnExitCodes) > 0 {
+		exitCodes, err := json.Marshal(policy.RetryOnExitCodes)
+		if err != nil {
+			return err
+		}
+
+		job.Annotations[AnnotationTaskRetryExitCodes] = string(exitCodes)
+	}
+
+	return nil
+}
+
+// TaskAttempt returns which attempt of its task the job or pod runs, starting
+// from 1.
+func TaskAttempt(object metav1.Object) int {
+	attempt, err := strconv.Atoi(object.GetAnnotations()[AnnotationTaskAttempt])
+	if err != nil || attempt < 1 {
+		return 1
+	}
+
+	return attempt
+}
+
+// TaskRun identifies the run of a task the job is an attempt of. This is the
+// name of the job of the first attempt, as the runs of a scheduled task share
+// its GUID.
+func TaskRun(job batch.Job) string {
+	if run, ok := job.Annotations[AnnotationTaskRun]; ok {
+		return run
+	}
+
+	return job.Name
+}
+
+// TaskAttemptWillBeRetried tells whether another attempt of the task follows
+// the attempt run by the job in the given pod. Attempts that ran out of time
+// are not retried, as the timeout applies to the task.
+func TaskAttemptWillBeRetried(job batch.Job, pod *corev1.Pod) bool {
+	status, ok := getTaskContainerStatus(pod)
+	if !ok || status.State.Terminated == nil || taskAttemptTimedOut(job, pod) {
+		return false
+	}
+
+	exitCode := status.State.Terminated.ExitCode
+
+	maxAttempts, err := strconv.Atoi(job.Annotations[AnnotationTaskMaxAttempts])
+	if err != nil || exitCode == 0 || TaskAttempt(&job) >= maxAttempts {
+		return false
+	}
+
+	retryExitCodes := []int32{}
+	if annotation, ok := job.Annotations[AnnotationTaskRetryExitCodes]; ok {
+		if err := json.Unmarshal([]byte(annotation), &retryExitCodes); err != nil {
+			return false
+		}
+	}
+
+	if len(retryExitCodes) == 0 {
+		return true
+	}
+
+	for _, retryExitCode := range retryExitCodes {
+		if retryExitCode == exitCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func taskAttemptTimedOut(job batch.Job, pod *corev1.Pod) bool {
+	if condition, ok := getJobCondition(job, batch.JobFailed); ok && condition.Reason == JobDeadlineExceededReason {
+		return true
+	}
+
+	return pod.Status.Reason == PodDeadlineExceededReason
+}
+
+// TaskRetryBackoff returns how long to wait before the next attempt of the
+// task run by the job.
+func TaskRetryBackoff(job batch.Job) time.Duration {
+	seconds, err := strconv.ParseInt(job.Annotations[AnnotationTaskRetryBackoffSeconds], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// NextTaskAttempt returns the job running the attempt following the one run
+// by the given job. It is named after the run, so that each attempt is
+// created once only.
+func NextTaskAttempt(job batch.Job) *batch.Job {
+	attempt := strconv.Itoa(TaskAttempt(&job) + 1)
+	run := TaskRun(job)
+
+	suffix := fmt.Sprintf("-%s", attempt)
+	name := run
+
+	if len(name)+len(suffix) > maxJobNameLength {
+		name = name[:maxJobNameLength-len(suffix)]
+	}
+
+	next := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + suffix,
+			Namespace:       job.Namespace,
+			Labels:          withoutJobControllerLabels(job.Labels),
+			Annotations:     map[string]string{},
+			OwnerReferences: job.OwnerReferences,
+		},
+		Spec: *job.Spec.DeepCopy(),
+	}
+
+	for key, value := range job.Annotations {
+		next.Annotations[key] = value
+	}
+
+	next.Annotations[AnnotationTaskAttempt] = attempt
+	next.Annotations[AnnotationTaskRun] = run
+
+	// The job controller selects the pods of a job by its UID
+	next.Spec.Selector = nil
+	next.Spec.ManualSelector = nil
+	next.Spec.Template.Labels = withoutJobControllerLabels(next.Spec.Template.Labels)
+
+	if next.Spec.Template.Annotations == nil {
+		next.Spec.Template.Annotations = map[string]string{}
+	}
+
+	next.Spec.Template.Annotations[AnnotationTaskAttempt] = attempt
+
+	return next
+}
+
+// TaskAttemptResources returns the secrets and the network policy shared by
+// the attempts of the task run by the job, with their name and namespace only.
+// They are created with the job of the first attempt, and each attempt owns
+// them too, so that they outlive the jobs of the previous attempts. The
+// resources of a scheduled run belong to its scheduled task instead.
+func TaskAttemptResources(job batch.Job) []runtime.Object {
+	if job.Labels[LabelScheduledTask] == ScheduledTaskTrue {
+		return nil
+	}
+
+	run := TaskRun(job)
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: job.Namespace}
+	}
+
+	resources := []runtime.Object{
+		&networkingv1.NetworkPolicy{ObjectMeta: objectMeta(run)},
+	}
+
+	if name := envSecretName(run); referencesSecret(job.Spec.Template.Spec.Containers, name) {
+		resources = append(resources, &corev1.Secret{ObjectMeta: objectMeta(name)})
+	}
+
+	dockerSecretNamePrefix := dockerImagePullSecretNamePrefix(
+		job.Annotations[AnnotationAppName],
+		job.Annotations[AnnotationSpaceName],
+		job.Labels[LabelGUID],
+	)
+
+	for _, secret := range job.Spec.Template.Spec.ImagePullSecrets {
+		if strings.HasPrefix(secret.Name, dockerSecretNamePrefix) {
+			resources = append(resources, &corev1.Secret{ObjectMeta: objectMeta(secret.Name)})
+		}
+	}
+
+	return resources
+}
+
+// LatestTaskAttempt returns the job running the latest attempt of a task,
+// given the jobs of all its attempts. It fails if the jobs are not the
+// attempts of a single run.
+func LatestTaskAttempt(jobs []batch.Job) (batch.Job, error) {
+	if len(jobs) == 0 {
+		return batch.Job{}, errors.New("no jobs found")
+	}
+
+	latest := jobs[0]
+	attempts := map[int]bool{}
+
+	for i := range jobs {
+		job := jobs[i]
+		attempt := TaskAttempt(&job)
+
+		if attempts[attempt] || TaskRun(job) != TaskRun(latest) {
+			return batch.Job{}, fmt.Errorf("multiple jobs found for task GUID %q", job.Labels[LabelGUID])
+		}
+
+		attempts[attempt] = true
+
+		if attempt > TaskAttempt(&latest) {
+			latest = job
+		}
+	}
+
+	return latest, nil
+}
+
+func withoutJobControllerLabels(labels map[string]string) map[string]string {
+	result := map[string]string{}
+
+	for key, value := range labels {
+		if key == "controller-uid" || key == "job-name" || key == LabelTaskCompleted {
+			continue
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
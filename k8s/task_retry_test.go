@@ -0,0 +1,230 @@
+package k8s_test
+
+import (
+	. "code.cloudfoundry.org/eirini/k8s"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Task retries", func() {
+	var job batch.Job
+
+	BeforeEach(func() {
+		manualSelector := true
+		job = batch.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-job",
+				Namespace: "my-namespace",
+				Labels: map[string]string{
+					LabelGUID:          "task-guid",
+					LabelTaskCompleted: TaskCompletedTrue,
+				},
+				Annotations: map[string]string{
+					AnnotationTaskAttempt:     "1",
+					AnnotationTaskMaxAttempts: "3",
+				},
+			},
+			Spec: batch.JobSpec{
+				BackoffLimit:   int32ptr(0),
+				ManualSelector: &manualSelector,
+				Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "the-uid"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							LabelGUID:        "task-guid",
+							"controller-uid": "the-uid",
+							"job-name":       "my-job",
+						},
+						Annotations: map[string]string{
+							AnnotationTaskAttempt: "1",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	Describe("TaskAttemptWillBeRetried", func() {
+		exitedPod := func(exitCode int32) *corev1.Pod {
+			return &corev1.Pod{
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "opi-task",
+							State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+						},
+					},
+				},
+			}
+		}
+
+		It("retries failed attempts until the maximum attempts", func() {
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(1))).To(BeTrue())
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(0))).To(BeFalse())
+
+			job.Annotations[AnnotationTaskAttempt] = "3"
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(1))).To(BeFalse())
+		})
+
+		It("retries only the exit codes to retry on", func() {
+			job.Annotations[AnnotationTaskRetryExitCodes] = "[75]"
+
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(75))).To(BeTrue())
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(1))).To(BeFalse())
+		})
+
+		It("does not retry tasks without a retry policy", func() {
+			delete(job.Annotations, AnnotationTaskMaxAttempts)
+
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(1))).To(BeFalse())
+		})
+
+		It("does not retry attempts that have not exited", func() {
+			Expect(TaskAttemptWillBeRetried(job, &corev1.Pod{})).To(BeFalse())
+			Expect(TaskAttemptWillBeRetried(job, nil)).To(BeFalse())
+		})
+
+		It("does not retry attempts killed for exceeding the pod deadline", func() {
+			pod := exitedPod(137)
+			pod.Status.Reason = PodDeadlineExceededReason
+
+			Expect(TaskAttemptWillBeRetried(job, pod)).To(BeFalse())
+		})
+
+		It("does not retry attempts whose job has exceeded its deadline", func() {
+			job.Status.Conditions = []batch.JobCondition{
+				{Type: batch.JobFailed, Status: corev1.ConditionTrue, Reason: JobDeadlineExceededReason},
+			}
+
+			Expect(TaskAttemptWillBeRetried(job, exitedPod(137))).To(BeFalse())
+		})
+	})
+
+	Describe("TaskAttemptResources", func() {
+		var resources []runtime.Object
+
+		BeforeEach(func() {
+			job.Annotations[AnnotationAppName] = "app"
+			job.Annotations[AnnotationSpaceName] = "space"
+			job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
+				{Name: "app-space-registry-secret-task-guid"},
+				{Name: "registry-secret"},
+			}
+			job.Spec.Template.Spec.Containers = []corev1.Container{
+				{
+					Name: "opi-task",
+					Env: []corev1.EnvVar{
+						{
+							Name: "VCAP_SERVICES",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "my-job-env"},
+									Key:                  "VCAP_SERVICES",
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			resources = TaskAttemptResources(job)
+		})
+
+		It("returns the network policy and the secrets created with the first attempt", func() {
+			Expect(resources).To(ConsistOf(
+				&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "my-namespace"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-job-env", Namespace: "my-namespace"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-space-registry-secret-task-guid", Namespace: "my-namespace"}},
+			))
+		})
+
+		When("the job is itself a retry", func() {
+			BeforeEach(func() {
+				job = *NextTaskAttempt(job)
+			})
+
+			It("returns the resources of the first attempt", func() {
+				Expect(resources).To(ContainElement(
+					&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "my-namespace"}},
+				))
+			})
+		})
+
+		When("the job is a scheduled run", func() {
+			BeforeEach(func() {
+				job.Labels[LabelScheduledTask] = ScheduledTaskTrue
+			})
+
+			It("returns nothing, as the resources belong to the scheduled task", func() {
+				Expect(resources).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("NextTaskAttempt", func() {
+		var next *batch.Job
+
+		JustBeforeEach(func() {
+			next = NextTaskAttempt(job)
+		})
+
+		It("names the job after the run and the attempt", func() {
+			Expect(next.Name).To(Equal("my-job-2"))
+			Expect(next.Namespace).To(Equal("my-namespace"))
+			Expect(next.Annotations).To(HaveKeyWithValue(AnnotationTaskRun, "my-job"))
+			Expect(TaskRun(*next)).To(Equal("my-job"))
+		})
+
+		It("runs the next attempt", func() {
+			Expect(TaskAttempt(next)).To(Equal(2))
+			Expect(next.Spec.Template.Annotations).To(HaveKeyWithValue(AnnotationTaskAttempt, "2"))
+			Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(AnnotationTaskAttempt, "1"))
+		})
+
+		It("lets the job controller select the pods of the new job", func() {
+			Expect(next.Spec.Selector).To(BeNil())
+			Expect(next.Spec.ManualSelector).To(BeNil())
+			Expect(next.Spec.Template.Labels).To(Equal(map[string]string{LabelGUID: "task-guid"}))
+		})
+
+		It("does not mark the new job as completed", func() {
+			Expect(next.Labels).To(Equal(map[string]string{LabelGUID: "task-guid"}))
+		})
+
+		When("the job is itself a retry", func() {
+			BeforeEach(func() {
+				job = *NextTaskAttempt(job)
+			})
+
+			It("keeps the run", func() {
+				Expect(next.Name).To(Equal("my-job-3"))
+				Expect(TaskRun(*next)).To(Equal("my-job"))
+			})
+		})
+	})
+
+	Describe("LatestTaskAttempt", func() {
+		It("returns the job of the latest attempt", func() {
+			next := NextTaskAttempt(job)
+
+			latest, err := LatestTaskAttempt([]batch.Job{*next, job})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latest.Name).To(Equal("my-job-2"))
+		})
+
+		It("fails for jobs of different runs", func() {
+			other := job
+			other.Name = "other-job"
+
+			_, err := LatestTaskAttempt([]batch.Job{job, other})
+			Expect(err).To(MatchError(ContainSubstring("multiple jobs found")))
+		})
+	})
+})
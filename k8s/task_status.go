@@ -0,0 +1,202 @@
+package k8s
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/eirini/opi"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PodDeadlineExceededReason is set by the kubelet on pods it killed for
+	// running longer than their active deadline.
+	PodDeadlineExceededReason = "DeadlineExceeded"
+	// JobDeadlineExceededReason is the reason of the failed condition of jobs
+	// that ran longer than their active deadline.
+	JobDeadlineExceededReason = "DeadlineExceeded"
+	TaskTimeoutFailureReason  = "task exceeded its timeout"
+)
+
+func getTaskStatus(job batch.Job, pods []corev1.Pod) opi.TaskStatus {
+	status := opi.TaskStatus{
+		State:      opi.TaskPendingState,
+		StartedAt:  unixNanoOrZero(job.Status.StartTime),
+		FinishedAt: unixNanoOrZero(job.Status.CompletionTime),
+		Attempts:   getTaskAttempts(pods),
+	}
+
+	pod := getLatestPod(getPodsOfAttempt(TaskAttempt(&job), pods))
+	applyPodState(&status, pod)
+
+	// The job of a failed attempt fails as well, even if the task is retried
+	willBeRetried := status.State == opi.TaskFailedState && TaskAttemptWillBeRetried(job, pod)
+	if willBeRetried {
+		status.State = opi.TaskPendingState
+		status.FinishedAt = 0
+		status.ExitCode = nil
+		status.FailureReason = ""
+	}
+
+	if condition, ok := getJobCondition(job, batch.JobFailed); ok && !willBeRetried {
+		status.State = opi.TaskFailedState
+
+		if status.FailureReason == "" {
+			status.FailureReason = condition.Reason
+		}
+
+		if status.FinishedAt == 0 {
+			status.FinishedAt = condition.LastTransitionTime.UnixNano()
+		}
+	}
+
+	if _, ok := getJobCondition(job, batch.JobComplete); ok {
+		status.State = opi.TaskSucceededState
+	}
+
+	if job.DeletionTimestamp != nil && !isTerminalTaskState(status.State) {
+		status.State = opi.TaskCancelledState
+		status.FailureReason = "task was cancelled"
+	}
+
+	return status
+}
+
+func applyPodState(status *opi.TaskStatus, pod *corev1.Pod) {
+	if pod == nil {
+		return
+	}
+
+	containerStatus, _ := getTaskContainerStatus(pod)
+	applyContainerState(status, containerStatus.State)
+
+	if reason, ok := TaskStartFailureReason(pod); ok {
+		status.State = opi.TaskFailedState
+		status.FailureReason = reason
+
+		return
+	}
+
+	if status.State == opi.TaskFailedState || pod.Status.Reason == PodDeadlineExceededReason {
+		status.State = opi.TaskFailedState
+		status.FailureReason = TaskFailureReason(pod, containerStatus.State.Terminated)
+	}
+}
+
+// getTaskAttempts returns the history of a task, oldest attempt first. Every
+// attempt runs in a pod of its own.
+func getTaskAttempts(pods []corev1.Pod) []opi.TaskAttempt {
+	sortedPods := append([]corev1.Pod{}, pods...)
+	sort.SliceStable(sortedPods, func(i, j int) bool {
+		if attemptI, attemptJ := TaskAttempt(&sortedPods[i]), TaskAttempt(&sortedPods[j]); attemptI != attemptJ {
+			return attemptI < attemptJ
+		}
+
+		return sortedPods[i].CreationTimestamp.Before(&sortedPods[j].CreationTimestamp)
+	})
+
+	attempts := []opi.TaskAttempt{}
+
+	for i := range sortedPods {
+		status := opi.TaskStatus{}
+		applyPodState(&status, &sortedPods[i])
+
+		if status.State == "" {
+			continue
+		}
+
+		attempts = append(attempts, opi.TaskAttempt{
+			StartedAt:     status.StartedAt,
+			FinishedAt:    status.FinishedAt,
+			ExitCode:      status.ExitCode,
+			FailureReason: status.FailureReason,
+		})
+	}
+
+	return attempts
+}
+
+func applyContainerState(status *opi.TaskStatus, state corev1.ContainerState) {
+	if state.Running != nil {
+		status.State = opi.TaskRunningState
+		status.StartedAt = state.Running.StartedAt.UnixNano()
+
+		return
+	}
+
+	terminated := state.Terminated
+	if terminated == nil {
+		return
+	}
+
+	exitCode := terminated.ExitCode
+	status.ExitCode = &exitCode
+	status.StartedAt = terminated.StartedAt.UnixNano()
+	status.FinishedAt = terminated.FinishedAt.UnixNano()
+	status.State = opi.TaskSucceededState
+
+	if exitCode != 0 {
+		status.State = opi.TaskFailedState
+	}
+}
+
+func getTaskContainerStatus(pod *corev1.Pod) (corev1.ContainerStatus, bool) {
+	if pod == nil {
+		return corev1.ContainerStatus{}, false
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == opiTaskContainerName {
+			return status, true
+		}
+	}
+
+	return corev1.ContainerStatus{}, false
+}
+
+func getJobCondition(job batch.Job, conditionType batch.JobConditionType) (batch.JobCondition, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return condition, true
+		}
+	}
+
+	return batch.JobCondition{}, false
+}
+
+func getPodsOfAttempt(attempt int, pods []corev1.Pod) []corev1.Pod {
+	attemptPods := []corev1.Pod{}
+
+	for i := range pods {
+		if TaskAttempt(&pods[i]) == attempt {
+			attemptPods = append(attemptPods, pods[i])
+		}
+	}
+
+	return attemptPods
+}
+
+func getLatestPod(pods []corev1.Pod) *corev1.Pod {
+	var latest *corev1.Pod
+
+	for i := range pods {
+		if latest == nil || latest.CreationTimestamp.Before(&pods[i].CreationTimestamp) {
+			latest = &pods[i]
+		}
+	}
+
+	return latest
+}
+
+func isTerminalTaskState(state string) bool {
+	return state == opi.TaskSucceededState || state == opi.TaskFailedState
+}
+
+func unixNanoOrZero(t *metav1.Time) int64 {
+	if t == nil {
+		return 0
+	}
+
+	return t.UnixNano()
+}
@@ -44,3 +44,17 @@ func GetStatefulsetName(lrp *opi.LRP) (string, error) {
 
 	return fmt.Sprintf("%s-%s", namePrefix, nameSuffix), nil
 }
+
+// GetInternalServiceName does not depend on the LRP version, so that the
+// Service keeps its DNS name when the LRP is replaced by a new version.
+func GetInternalServiceName(appName, spaceName, guid string) (string, error) {
+	nameSuffix, err := util.Hash(guid)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate hash")
+	}
+
+	namePrefix := SanitizeName(fmt.Sprintf("%s-%s", appName, spaceName), guid)
+	namePrefix = strings.Trim(strings.ReplaceAll(namePrefix, ".", "-"), "-")
+
+	return fmt.Sprintf("internal-%s-%s", namePrefix, nameSuffix), nil
+}
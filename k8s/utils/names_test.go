@@ -55,4 +55,19 @@ var _ = Describe("Names", func() {
 			})
 		})
 	})
+
+	Describe("GetInternalServiceName", func() {
+		It("calculates the name from the app, the space and the guid only", func() {
+			name, err := GetInternalServiceName("app", "space", "guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(MatchRegexp(`^internal-app-space-[0-9a-f]{10}$`))
+		})
+
+		It("produces a valid DNS label", func() {
+			name, err := GetInternalServiceName("my.app", "space-with-very-very-very-very-very-very-long-name", "guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(MatchRegexp(`^[a-z]([-a-z0-9]*[a-z0-9])?$`))
+			Expect(len(name)).To(BeNumerically("<=", 63))
+		})
+	})
 })
@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/opi"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	vcapInstanceIndex = "instance_index"
+	vcapInstanceID    = "instance_id"
+	vcapLimits        = "limits"
+)
+
+// The VCAP_APPLICATION sent by Cloud Controller describes the app, not one
+// of its instances. Eirini overrides the fields it knows better and sets
+// instance_id to the pod UID. The application URIs are left as sent by Cloud
+// Controller, as routes change without the app instances being restarted.
+// Kubernetes only expands $(VAR) references to variables defined earlier, so
+// the built variable has to be placed after the downward API ones.
+var vcapInstanceIDRef = fmt.Sprintf("$(%s)", eirini.EnvCFInstanceGUID)
+
+func lrpVCAPApplication(lrp *opi.LRP) (corev1.EnvVar, error) {
+	vcap := parseVCAPApplication(lrp.Env)
+
+	vcap["application_id"] = lrp.AppGUID
+	vcap["application_name"] = lrp.AppName
+	vcap["name"] = lrp.AppName
+	vcap["organization_id"] = lrp.OrgGUID
+	vcap["organization_name"] = lrp.OrgName
+	vcap["space_id"] = lrp.SpaceGUID
+	vcap["space_name"] = lrp.SpaceName
+	vcap["process_id"] = lrp.GUID
+	vcap["process_type"] = lrp.ProcessType
+	vcap["host"] = "0.0.0.0"
+	vcap[vcapInstanceID] = vcapInstanceIDRef
+	setVCAPLimits(vcap, lrp.MemoryMB, lrp.DiskMB)
+
+	if len(lrp.Ports) > 0 {
+		vcap["port"] = lrp.Ports[0]
+	}
+
+	return toVCAPApplicationEnvVar(vcap)
+}
+
+func taskVCAPApplication(task *opi.Task) (corev1.EnvVar, error) {
+	vcap := parseVCAPApplication(task.Env)
+
+	vcap["application_id"] = task.AppGUID
+	vcap["application_name"] = task.AppName
+	vcap["name"] = task.AppName
+	vcap["organization_id"] = task.OrgGUID
+	vcap["organization_name"] = task.OrgName
+	vcap["space_id"] = task.SpaceGUID
+	vcap["space_name"] = task.SpaceName
+	vcap[vcapInstanceID] = vcapInstanceIDRef
+	setVCAPLimits(vcap, task.MemoryMB, task.DiskMB)
+
+	return toVCAPApplicationEnvVar(vcap)
+}
+
+// SetVCAPApplicationInstanceIndex sets the instance index in the
+// VCAP_APPLICATION of the given env, if there is one. The index of a
+// statefulset pod is only known once the pod is created.
+func SetVCAPApplicationInstanceIndex(env []corev1.EnvVar, index int) error {
+	for i := range env {
+		if env[i].Name != eirini.EnvVCAPApplication {
+			continue
+		}
+
+		vcap := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(env[i].Value), &vcap); err != nil {
+			return errors.Wrap(err, "failed to unmarshal VCAP_APPLICATION")
+		}
+
+		vcap[vcapInstanceIndex] = index
+
+		vcapEnv, err := toVCAPApplicationEnvVar(vcap)
+		if err != nil {
+			return err
+		}
+
+		env[i] = vcapEnv
+	}
+
+	return nil
+}
+
+// withoutVCAPApplication drops the VCAP_APPLICATION sent by Cloud Controller,
+// as it is replaced by the one eirini builds.
+func withoutVCAPApplication(env map[string]string) map[string]string {
+	if _, ok := env[eirini.EnvVCAPApplication]; !ok {
+		return env
+	}
+
+	filtered := make(map[string]string, len(env))
+
+	for k, v := range env {
+		if k != eirini.EnvVCAPApplication {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
+func parseVCAPApplication(env map[string]string) map[string]interface{} {
+	vcap := map[string]interface{}{}
+
+	if err := json.Unmarshal([]byte(env[eirini.EnvVCAPApplication]), &vcap); err != nil || vcap == nil {
+		return map[string]interface{}{}
+	}
+
+	return vcap
+}
+
+func setVCAPLimits(vcap map[string]interface{}, memoryMB, diskMB int64) {
+	limits, ok := vcap[vcapLimits].(map[string]interface{})
+	if !ok {
+		limits = map[string]interface{}{}
+	}
+
+	limits["mem"] = memoryMB
+	limits["disk"] = diskMB
+	vcap[vcapLimits] = limits
+}
+
+func toVCAPApplicationEnvVar(vcap map[string]interface{}) (corev1.EnvVar, error) {
+	vcapJSON, err := json.Marshal(vcap)
+	if err != nil {
+		return corev1.EnvVar{}, errors.Wrap(err, "failed to marshal VCAP_APPLICATION")
+	}
+
+	return corev1.EnvVar{Name: eirini.EnvVCAPApplication, Value: string(vcapJSON)}, nil
+}
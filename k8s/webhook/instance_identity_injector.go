@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/eirini"
+	"code.cloudfoundry.org/eirini/k8s"
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
+	eirinix "code.cloudfoundry.org/eirinix"
+	"code.cloudfoundry.org/lager"
+	exterrors "github.com/pkg/errors"
+	"k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//counterfeiter:generate . InstanceIdentityIssuer
+//counterfeiter:generate . InstanceIdentitySecretsClient
+
+type InstanceIdentityIssuer interface {
+	Issue(identity instanceid.Identity, now time.Time, validity time.Duration) (instanceid.Credentials, error)
+}
+
+type InstanceIdentitySecretsClient interface {
+	Create(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+	Update(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+}
+
+// InstanceIdentityInjector issues a certificate identifying the app instance
+// to every new app pod. The certificate is stored in a secret owned by the
+// same statefulset as the pod and mounted into the opi container. The pod
+// cannot own the secret, as it has no UID before it is created; secrets whose
+// pod is gone are deleted by the instanceid.Rotator.
+type InstanceIdentityInjector struct {
+	logger   lager.Logger
+	issuer   InstanceIdentityIssuer
+	secrets  InstanceIdentitySecretsClient
+	validity time.Duration
+}
+
+func NewInstanceIdentityInjector(
+	logger lager.Logger,
+	issuer InstanceIdentityIssuer,
+	secrets InstanceIdentitySecretsClient,
+	validity time.Duration,
+) InstanceIdentityInjector {
+	return InstanceIdentityInjector{
+		logger:   logger,
+		issuer:   issuer,
+		secrets:  secrets,
+		validity: validity,
+	}
+}
+
+func (i InstanceIdentityInjector) Handle(ctx context.Context, eiriniManager eirinix.Manager, pod *corev1.Pod, req admission.Request) admission.Response {
+	logger := i.logger.Session("handle-webhook-request")
+
+	if req.Operation != v1beta1.Create {
+		return admission.Allowed("pod was already created")
+	}
+
+	if pod == nil {
+		err := errors.New("no pod could be decoded from the request")
+		logger.Error("no-pod-in-request", err)
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	namespace := pod.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+
+	logger = logger.WithData(lager.Data{"pod-name": pod.Name, "pod-namespace": namespace})
+
+	podCopy := pod.DeepCopy()
+
+	if err := i.injectInstanceIdentity(logger, namespace, podCopy); err != nil {
+		logger.Error("failed-to-inject-instance-identity", err)
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return eiriniManager.PatchFromPod(req, podCopy)
+}
+
+func (i InstanceIdentityInjector) injectInstanceIdentity(logger lager.Logger, namespace string, pod *corev1.Pod) error {
+	container := findOPIContainer(pod)
+	if container == nil {
+		logger.Info("no-opi-container-found")
+
+		return errors.New("no opi container found in pod")
+	}
+
+	identity := instanceid.Identity{
+		InstanceName: pod.Name,
+		OrgGUID:      pod.Labels[k8s.LabelOrgGUID],
+		SpaceGUID:    pod.Labels[k8s.LabelSpaceGUID],
+		AppGUID:      pod.Labels[k8s.LabelAppGUID],
+	}
+
+	credentials, err := i.issuer.Issue(identity, time.Now(), i.validity)
+	if err != nil {
+		return exterrors.Wrap(err, "failed to issue instance identity")
+	}
+
+	secret := instanceid.ToSecret(identity, credentials)
+	secret.Namespace = namespace
+	secret.OwnerReferences = pod.OwnerReferences
+
+	if err := i.storeSecret(namespace, secret); err != nil {
+		return err
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: instanceid.VolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secret.Name},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      instanceid.VolumeName,
+		MountPath: instanceid.MountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: eirini.EnvCFInstanceCert, Value: filepath.Join(instanceid.MountPath, instanceid.CertFileName)},
+		corev1.EnvVar{Name: eirini.EnvCFInstanceKey, Value: filepath.Join(instanceid.MountPath, instanceid.KeyFileName)},
+	)
+
+	logger.Debug("patching-instance-identity", lager.Data{"secret-name": secret.Name, "not-after": credentials.NotAfter})
+
+	return nil
+}
+
+// storeSecret overwrites the secret left behind by a previous pod with the
+// same name, e.g. when a statefulset pod is recreated.
+func (i InstanceIdentityInjector) storeSecret(namespace string, secret *corev1.Secret) error {
+	_, err := i.secrets.Create(namespace, secret)
+	if k8serrors.IsAlreadyExists(err) {
+		_, err = i.secrets.Update(namespace, secret)
+	}
+
+	return exterrors.Wrap(err, "failed to store instance identity secret")
+}
+
+func findOPIContainer(pod *corev1.Pod) *corev1.Container {
+	for c := range pod.Spec.Containers {
+		if pod.Spec.Containers[c].Name == k8s.OPIContainerName {
+			return &pod.Spec.Containers[c]
+		}
+	}
+
+	return nil
+}
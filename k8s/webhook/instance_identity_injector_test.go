@@ -0,0 +1,205 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
+	"code.cloudfoundry.org/eirini/k8s/webhook"
+	"code.cloudfoundry.org/eirini/k8s/webhook/webhookfakes"
+	eirinix "code.cloudfoundry.org/eirinix"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gomodules.xyz/jsonpatch/v2"
+	"k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("InstanceIdentityInjector", func() {
+	var (
+		injector                 eirinix.Extension
+		manager                  *webhookfakes.FakeManager
+		issuer                   *webhookfakes.FakeInstanceIdentityIssuer
+		secrets                  *webhookfakes.FakeInstanceIdentitySecretsClient
+		pod                      *corev1.Pod
+		ownerReferences          []metav1.OwnerReference
+		req                      admission.Request
+		actualResp, expectedResp admission.Response
+	)
+
+	BeforeEach(func() {
+		manager = new(webhookfakes.FakeManager)
+		issuer = new(webhookfakes.FakeInstanceIdentityIssuer)
+		issuer.IssueReturns(instanceid.Credentials{CertPEM: []byte("cert"), KeyPEM: []byte("key")}, nil)
+		secrets = new(webhookfakes.FakeInstanceIdentitySecretsClient)
+		injector = webhook.NewInstanceIdentityInjector(lagertest.NewTestLogger("instance-identity-injector"), issuer, secrets, time.Hour)
+
+		ownerReferences = []metav1.OwnerReference{{Kind: "StatefulSet", Name: "some-app"}}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "some-app-3",
+				Labels: map[string]string{
+					"cloudfoundry.org/org_guid":   "org-guid",
+					"cloudfoundry.org/space_guid": "space-guid",
+					"cloudfoundry.org/app_guid":   "app-guid",
+				},
+				OwnerReferences: ownerReferences,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "opi"},
+					{Name: "sidecar"},
+				},
+			},
+		}
+
+		req = admission.Request{
+			AdmissionRequest: v1beta1.AdmissionRequest{
+				Operation: v1beta1.Create,
+				Namespace: "workloads",
+			},
+		}
+
+		expectedResp = admission.Response{
+			Patches: []jsonpatch.JsonPatchOperation{
+				{Operation: "add", Path: "somewhere", Value: "something"},
+			},
+		}
+		manager.PatchFromPodReturns(expectedResp)
+	})
+
+	JustBeforeEach(func() {
+		actualResp = injector.Handle(context.Background(), manager, pod, req)
+	})
+
+	It("issues a certificate for the instance", func() {
+		Expect(issuer.IssueCallCount()).To(Equal(1))
+		identity, _, validity := issuer.IssueArgsForCall(0)
+		Expect(identity).To(Equal(instanceid.Identity{
+			InstanceName: "some-app-3",
+			OrgGUID:      "org-guid",
+			SpaceGUID:    "space-guid",
+			AppGUID:      "app-guid",
+		}))
+		Expect(validity).To(Equal(time.Hour))
+	})
+
+	It("stores the credentials in a secret owned by the pod owner", func() {
+		Expect(secrets.CreateCallCount()).To(Equal(1))
+		namespace, secret := secrets.CreateArgsForCall(0)
+		Expect(namespace).To(Equal("workloads"))
+		Expect(secret.Name).To(Equal("some-app-3-instance-identity"))
+		Expect(secret.OwnerReferences).To(Equal(ownerReferences))
+		Expect(secret.Labels).To(HaveKeyWithValue("cloudfoundry.org/instance_identity", "true"))
+		Expect(secret.Data).To(Equal(map[string][]byte{
+			"instance.crt": []byte("cert"),
+			"instance.key": []byte("key"),
+		}))
+	})
+
+	It("mounts the credentials into the opi container", func() {
+		Expect(actualResp).To(Equal(expectedResp))
+		Expect(manager.PatchFromPodCallCount()).To(Equal(1))
+
+		actualReq, actualPod := manager.PatchFromPodArgsForCall(0)
+		Expect(actualReq).To(Equal(req))
+		Expect(actualPod.Spec.Volumes).To(ConsistOf(corev1.Volume{
+			Name: "instance-identity",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "some-app-3-instance-identity"},
+			},
+		}))
+		Expect(actualPod.Spec.Containers).To(ConsistOf(
+			corev1.Container{
+				Name: "opi",
+				Env: []corev1.EnvVar{
+					{Name: "CF_INSTANCE_CERT", Value: "/etc/cf-instance-credentials/instance.crt"},
+					{Name: "CF_INSTANCE_KEY", Value: "/etc/cf-instance-credentials/instance.key"},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "instance-identity", MountPath: "/etc/cf-instance-credentials", ReadOnly: true},
+				},
+			},
+			corev1.Container{Name: "sidecar"},
+		))
+	})
+
+	It("does not mutate the passed pod", func() {
+		Expect(pod.Spec.Volumes).To(BeEmpty())
+		Expect(pod.Spec.Containers[0].Env).To(BeEmpty())
+	})
+
+	When("the secret already exists", func() {
+		BeforeEach(func() {
+			secrets.CreateReturns(nil, k8serrors.NewAlreadyExists(schema.GroupResource{}, "some-app-3-instance-identity"))
+		})
+
+		It("replaces it", func() {
+			Expect(secrets.UpdateCallCount()).To(Equal(1))
+			namespace, secret := secrets.UpdateArgsForCall(0)
+			Expect(namespace).To(Equal("workloads"))
+			Expect(secret.Name).To(Equal("some-app-3-instance-identity"))
+			Expect(actualResp).To(Equal(expectedResp))
+		})
+	})
+
+	When("creating the secret fails", func() {
+		BeforeEach(func() {
+			secrets.CreateReturns(nil, errors.New("boom"))
+		})
+
+		It("returns an error response", func() {
+			ExpectBadRequestErrorResponse(actualResp, "failed to store instance identity secret: boom")
+		})
+	})
+
+	When("issuing the certificate fails", func() {
+		BeforeEach(func() {
+			issuer.IssueReturns(instanceid.Credentials{}, errors.New("boom"))
+		})
+
+		It("returns an error response", func() {
+			ExpectBadRequestErrorResponse(actualResp, "failed to issue instance identity: boom")
+			Expect(secrets.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the pod has no OPI container", func() {
+		BeforeEach(func() {
+			pod.Spec.Containers[0].Name = "ipo"
+		})
+
+		It("returns an error response", func() {
+			ExpectBadRequestErrorResponse(actualResp, "no opi container found in pod")
+			Expect(issuer.IssueCallCount()).To(Equal(0))
+		})
+	})
+
+	When("no pod is passed to handle", func() {
+		BeforeEach(func() {
+			pod = nil
+		})
+
+		It("returns an error response", func() {
+			ExpectBadRequestErrorResponse(actualResp, "no pod could be decoded from the request")
+		})
+	})
+
+	When("the operation is not Create", func() {
+		BeforeEach(func() {
+			req.AdmissionRequest.Operation = v1beta1.Update
+		})
+
+		It("allows the operation without issuing a certificate", func() {
+			Expect(issuer.IssueCallCount()).To(Equal(0))
+			Expect(manager.PatchFromPodCallCount()).To(Equal(0))
+			ExpectAllowResponse(actualResp)
+		})
+	})
+})
@@ -63,6 +63,13 @@ func injectInstanceIndex(logger lager.Logger, pod *corev1.Pod) error {
 		return exterrors.Wrap(err, "failed to parse app index")
 	}
 
+	for c := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[c]
+		if err := k8s.SetVCAPApplicationInstanceIndex(container.Env, index); err != nil {
+			return exterrors.Wrapf(err, "failed to patch VCAP_APPLICATION of container %s", container.Name)
+		}
+	}
+
 	for c := range pod.Spec.Containers {
 		container := &pod.Spec.Containers[c]
 		if container.Name == k8s.OPIContainerName {
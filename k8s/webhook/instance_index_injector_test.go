@@ -86,6 +86,41 @@ var _ = Describe("InstanceIndexInjector", func() {
 		}))
 	})
 
+	When("the containers have a VCAP_APPLICATION", func() {
+		BeforeEach(func() {
+			vcapApplication := corev1.EnvVar{Name: "VCAP_APPLICATION", Value: `{"application_id":"app-guid","instance_id":"$(CF_INSTANCE_GUID)"}`}
+			pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, vcapApplication)
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+				Name: "sidecar",
+				Env:  []corev1.EnvVar{vcapApplication},
+			})
+		})
+
+		It("sets the instance index in all of them", func() {
+			_, actualPod := manager.PatchFromPodArgsForCall(0)
+			expectedVCAPApplication := `{"application_id":"app-guid","instance_id":"$(CF_INSTANCE_GUID)","instance_index":3}`
+
+			Expect(actualPod.Spec.Containers[0].Env[2].Name).To(Equal("VCAP_APPLICATION"))
+			Expect(actualPod.Spec.Containers[0].Env[2].Value).To(MatchJSON(expectedVCAPApplication))
+			Expect(actualPod.Spec.Containers[1].Env).To(HaveLen(1))
+			Expect(actualPod.Spec.Containers[1].Env[0].Value).To(MatchJSON(expectedVCAPApplication))
+		})
+
+		It("does not mutate the passed pod", func() {
+			Expect(pod.Spec.Containers[1].Env[0].Value).NotTo(ContainSubstring("instance_index"))
+		})
+
+		When("VCAP_APPLICATION is not valid JSON", func() {
+			BeforeEach(func() {
+				pod.Spec.Containers[1].Env[0].Value = "{"
+			})
+
+			It("returns an error response", func() {
+				ExpectBadRequestErrorResponse(actualResp, "failed to patch VCAP_APPLICATION of container sidecar")
+			})
+		})
+	})
+
 	Context("the passed pod has already been created", func() {
 		When("operation is Update", func() {
 			BeforeEach(func() {
@@ -0,0 +1,122 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package webhookfakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/eirini/k8s/instanceid"
+	"code.cloudfoundry.org/eirini/k8s/webhook"
+)
+
+type FakeInstanceIdentityIssuer struct {
+	IssueStub        func(instanceid.Identity, time.Time, time.Duration) (instanceid.Credentials, error)
+	issueMutex       sync.RWMutex
+	issueArgsForCall []struct {
+		arg1 instanceid.Identity
+		arg2 time.Time
+		arg3 time.Duration
+	}
+	issueReturns struct {
+		result1 instanceid.Credentials
+		result2 error
+	}
+	issueReturnsOnCall map[int]struct {
+		result1 instanceid.Credentials
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInstanceIdentityIssuer) Issue(arg1 instanceid.Identity, arg2 time.Time, arg3 time.Duration) (instanceid.Credentials, error) {
+	fake.issueMutex.Lock()
+	ret, specificReturn := fake.issueReturnsOnCall[len(fake.issueArgsForCall)]
+	fake.issueArgsForCall = append(fake.issueArgsForCall, struct {
+		arg1 instanceid.Identity
+		arg2 time.Time
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.IssueStub
+	fakeReturns := fake.issueReturns
+	fake.recordInvocation("Issue", []interface{}{arg1, arg2, arg3})
+	fake.issueMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInstanceIdentityIssuer) IssueCallCount() int {
+	fake.issueMutex.RLock()
+	defer fake.issueMutex.RUnlock()
+	return len(fake.issueArgsForCall)
+}
+
+func (fake *FakeInstanceIdentityIssuer) IssueCalls(stub func(instanceid.Identity, time.Time, time.Duration) (instanceid.Credentials, error)) {
+	fake.issueMutex.Lock()
+	defer fake.issueMutex.Unlock()
+	fake.IssueStub = stub
+}
+
+func (fake *FakeInstanceIdentityIssuer) IssueArgsForCall(i int) (instanceid.Identity, time.Time, time.Duration) {
+	fake.issueMutex.RLock()
+	defer fake.issueMutex.RUnlock()
+	argsForCall := fake.issueArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInstanceIdentityIssuer) IssueReturns(result1 instanceid.Credentials, result2 error) {
+	fake.issueMutex.Lock()
+	defer fake.issueMutex.Unlock()
+	fake.IssueStub = nil
+	fake.issueReturns = struct {
+		result1 instanceid.Credentials
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceIdentityIssuer) IssueReturnsOnCall(i int, result1 instanceid.Credentials, result2 error) {
+	fake.issueMutex.Lock()
+	defer fake.issueMutex.Unlock()
+	fake.IssueStub = nil
+	if fake.issueReturnsOnCall == nil {
+		fake.issueReturnsOnCall = make(map[int]struct {
+			result1 instanceid.Credentials
+			result2 error
+		})
+	}
+	fake.issueReturnsOnCall[i] = struct {
+		result1 instanceid.Credentials
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceIdentityIssuer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.issueMutex.RLock()
+	defer fake.issueMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInstanceIdentityIssuer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ webhook.InstanceIdentityIssuer = new(FakeInstanceIdentityIssuer)
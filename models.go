@@ -10,6 +10,9 @@ const (
 	EnvAppID              = "APP_ID"
 	EnvCompletionCallback = "COMPLETION_CALLBACK"
 	EnvEiriniAddress      = "EIRINI_ADDRESS"
+	EnvStartCommand       = "START_COMMAND"
+	EnvStagingGUID        = "STAGING_GUID"
+	EnvBuildpacks         = "BUILDPACKS"
 
 	EnvPodName              = "POD_NAME"
 	EnvCFInstanceIP         = "CF_INSTANCE_IP"
@@ -19,9 +22,15 @@ const (
 	EnvCFInstanceAddr       = "CF_INSTANCE_ADDR"
 	EnvCFInstancePort       = "CF_INSTANCE_PORT"
 	EnvCFInstancePorts      = "CF_INSTANCE_PORTS"
+	EnvCFInstanceCert       = "CF_INSTANCE_CERT"
+	EnvCFInstanceKey        = "CF_INSTANCE_KEY"
+	EnvVCAPApplication      = "VCAP_APPLICATION"
 
 	AppMetricsEmissionIntervalInSecs = 15
 
+	InstanceIdentityCertValidityInSecs     = 3600
+	InstanceIdentityRotationIntervalInSecs = 60
+
 	RegistrySecretName = "default-image-pull-secret"
 
 	// Certs
@@ -47,6 +56,10 @@ var ErrNotFound = errors.New("not found")
 
 var ErrInvalidInstanceIndex = errors.New("invalid instance index")
 
+var ErrInvalidNetworkPolicy = errors.New("invalid network policy")
+
+var ErrInvalidLRPUpdate = errors.New("invalid lrp update")
+
 type Config struct {
 	Properties              Properties `yaml:"opi"`
 	WorkloadsNamespace      string
@@ -79,10 +92,59 @@ type Properties struct { //nolint:maligned
 
 	ApplicationServiceAccount string `yaml:"application_service_account"`
 
+	DownloaderImage string            `yaml:"downloader_image"`
+	ExecutorImage   string            `yaml:"executor_image"`
+	UploaderImage   string            `yaml:"uploader_image"`
+	StackImages     map[string]string `yaml:"stack_images"`
+	EiriniAddress   string            `yaml:"eirini_address"`
+
 	AllowRunImageAsRoot                     bool `yaml:"allow_run_image_as_root"`
 	UnsafeAllowAutomountServiceAccountToken bool `yaml:"unsafe_allow_automount_service_account_token"`
 
 	ServePlaintext bool `yaml:"serve_plaintext"`
+
+	ProbeTimeoutSeconds      int32 `yaml:"probe_timeout_seconds"`
+	ProbePeriodSeconds       int32 `yaml:"probe_period_seconds"`
+	ProbeFailureThreshold    int32 `yaml:"probe_failure_threshold"`
+	ProbeStartTimeoutSeconds int32 `yaml:"probe_start_timeout_seconds"`
+
+	NodePlacements map[string]NodePlacement `yaml:"node_placements"`
+
+	// InternalServiceType is the type of the Service created for LRPs with
+	// internal routes: "headless" (the default) or "ClusterIP". Resolving
+	// the internal route hostnames to the Service is left to the cluster DNS.
+	InternalServiceType string `yaml:"internal_service_type"`
+
+	// SensitiveEnvPatterns match the names of env vars that are stored in
+	// secrets instead of pod specs, e.g. "*_PASSWORD". VCAP_SERVICES is always
+	// stored in a secret.
+	SensitiveEnvPatterns []string `yaml:"sensitive_env_patterns"`
+
+	// Tasks without a timeout run for at most TaskTimeoutSeconds, and no task
+	// runs for longer than MaxTaskTimeoutSeconds. Zero means no limit.
+	TaskTimeoutSeconds    int64 `yaml:"task_timeout_seconds"`
+	MaxTaskTimeoutSeconds int64 `yaml:"max_task_timeout_seconds"`
+}
+
+// A NodePlacement describes the nodes that workloads with a given placement
+// tag or isolation segment are scheduled on.
+type NodePlacement struct {
+	NodeSelector map[string]string         `yaml:"node_selector"`
+	NodeAffinity []NodeSelectorRequirement `yaml:"node_affinity"`
+	Tolerations  []Toleration              `yaml:"tolerations"`
+}
+
+type NodeSelectorRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values"`
+}
+
+type Toleration struct {
+	Key      string `yaml:"key"`
+	Operator string `yaml:"operator"`
+	Value    string `yaml:"value"`
+	Effect   string `yaml:"effect"`
 }
 
 type EventReporterConfig struct {
@@ -146,5 +208,13 @@ type InstanceIndexEnvInjectorConfig struct {
 
 	WorkloadsNamespace string
 
+	// Instance identity certificates are only issued when a CA secret is
+	// configured. Certificates are renewed once half of their validity has
+	// passed.
+	InstanceIdentityCASecretName           string `yaml:"instance_identity_ca_secret_name"`
+	InstanceIdentityCASecretNamespace      string `yaml:"instance_identity_ca_secret_namespace"`
+	InstanceIdentityCertValidityInSecs     int    `yaml:"instance_identity_cert_validity_in_secs"`
+	InstanceIdentityRotationIntervalInSecs int    `yaml:"instance_identity_rotation_interval_in_secs"`
+
 	KubeConfig `yaml:",inline"`
 }
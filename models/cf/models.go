@@ -36,6 +36,7 @@ type DesireLRPRequest struct {
 	PlacementTags           []string                   `json:"placement_tags"`
 	Ports                   []int32                    `json:"ports"`
 	Routes                  map[string]json.RawMessage `json:"routes"`
+	InternalRoutes          []InternalRoute            `json:"internal_routes"`
 	Environment             map[string]string          `json:"environment"`
 	EgressRules             []json.RawMessage          `json:"egress_rules"`
 	NumInstances            int                        `json:"instances"`
@@ -43,16 +44,24 @@ type DesireLRPRequest struct {
 	HealthCheckType         string                     `json:"health_check_type"`
 	HealthCheckHTTPEndpoint string                     `json:"health_check_http_endpoint"`
 	HealthCheckTimeoutMs    uint                       `json:"health_check_timeout_ms"`
+	HealthCheckPort         int32                      `json:"health_check_port"`
+	HealthCheckIntervalMs   uint                       `json:"health_check_interval_ms"`
+	HealthCheckThreshold    int32                      `json:"health_check_failure_threshold"`
 	StartTimeoutMs          uint                       `json:"start_timeout_ms"`
 	MemoryMB                int64                      `json:"memory_mb"`
 	DiskMB                  int64                      `json:"disk_mb"`
 	CPUWeight               uint8                      `json:"cpu_weight"`
 	VolumeMounts            []VolumeMount              `json:"volume_mounts"`
 	Lifecycle               Lifecycle                  `json:"lifecycle"`
+	Sidecars                []Sidecar                  `json:"sidecars"`
 	UserDefinedAnnotations  map[string]string          `json:"user_defined_annotations"`
 	LRP                     string
 }
 
+type InternalRoute struct {
+	Hostname string `json:"hostname"`
+}
+
 type DesiredLRPSchedulingInfo struct {
 	DesiredLRPKey `json:"desired_lrp_key"`
 	GUID          string `json:"guid"`
@@ -73,7 +82,8 @@ type DesiredLRPLifecycleResponse struct {
 }
 
 type Lifecycle struct {
-	DockerLifecycle *DockerLifecycle `json:"docker_lifecycle"`
+	DockerLifecycle    *DockerLifecycle    `json:"docker_lifecycle"`
+	BuildpackLifecycle *BuildpackLifecycle `json:"buildpack_lifecycle"`
 }
 
 type DockerLifecycle struct {
@@ -83,6 +93,12 @@ type DockerLifecycle struct {
 	RegistryPassword string   `json:"registry_password"`
 }
 
+type BuildpackLifecycle struct {
+	DropletURL   string `json:"droplet_url"`
+	StartCommand string `json:"start_command"`
+	Stack        string `json:"stack"`
+}
+
 type TaskRequest struct {
 	GUID               string                `json:"guid"`
 	Name               string                `json:"name"`
@@ -96,18 +112,89 @@ type TaskRequest struct {
 	CompletionCallback string                `json:"completion_callback"`
 	Environment        []EnvironmentVariable `json:"environment"`
 	Lifecycle          Lifecycle             `json:"lifecycle"`
+	MemoryMB           int64                 `json:"memory_mb"`
+	DiskMB             int64                 `json:"disk_mb"`
+	CPUWeight          uint8                 `json:"cpu_weight"`
+	IsolationSegment   string                `json:"isolation_segment"`
+	EgressRules        []json.RawMessage     `json:"egress_rules"`
+	TimeoutSeconds     int64                 `json:"timeout_seconds,omitempty"`
+	RetryPolicy        *TaskRetryPolicy      `json:"retry_policy,omitempty"`
+	ResultFile         string                `json:"result_file,omitempty"`
+}
+
+// TaskRetryPolicy lets idempotent tasks be run again when they fail. Only
+// failures with one of the given exit codes are retried, or any failure when
+// no exit codes are given.
+type TaskRetryPolicy struct {
+	MaxAttempts      int32   `json:"max_attempts"`
+	BackoffSeconds   int64   `json:"backoff_seconds,omitempty"`
+	RetryOnExitCodes []int32 `json:"retry_on_exit_codes,omitempty"`
 }
 
 type TaskResponse struct {
-	GUID string `json:"guid"`
+	GUID          string   `json:"guid"`
+	Name          string   `json:"name,omitempty"`
+	AppGUID       string   `json:"app_guid,omitempty"`
+	AppName       string   `json:"app_name,omitempty"`
+	OrgGUID       string   `json:"org_guid,omitempty"`
+	OrgName       string   `json:"org_name,omitempty"`
+	SpaceGUID     string   `json:"space_guid,omitempty"`
+	SpaceName     string   `json:"space_name,omitempty"`
+	Command       []string `json:"command,omitempty"`
+	State         string   `json:"state,omitempty"`
+	StartedAt     int64    `json:"started_at,omitempty"`
+	FinishedAt    int64    `json:"finished_at,omitempty"`
+	ExitCode      *int32   `json:"exit_code,omitempty"`
+	FailureReason string   `json:"failure_reason,omitempty"`
 }
 
 type TasksResponse []TaskResponse
 
+// ScheduledTaskRequest desires a task that is run on a cron schedule. The
+// schedule, concurrency policy and history limits follow the Kubernetes
+// CronJob semantics.
+type ScheduledTaskRequest struct {
+	TaskRequest
+	Schedule                   string `json:"schedule"`
+	ConcurrencyPolicy          string `json:"concurrency_policy,omitempty"`
+	Suspend                    bool   `json:"suspend,omitempty"`
+	SuccessfulRunsHistoryLimit *int32 `json:"successful_runs_history_limit,omitempty"`
+	FailedRunsHistoryLimit     *int32 `json:"failed_runs_history_limit,omitempty"`
+}
+
+type ScheduledTaskUpdateRequest struct {
+	Schedule                   string `json:"schedule"`
+	ConcurrencyPolicy          string `json:"concurrency_policy,omitempty"`
+	Suspend                    bool   `json:"suspend,omitempty"`
+	SuccessfulRunsHistoryLimit *int32 `json:"successful_runs_history_limit,omitempty"`
+	FailedRunsHistoryLimit     *int32 `json:"failed_runs_history_limit,omitempty"`
+}
+
+type ScheduledTaskResponse struct {
+	GUID              string   `json:"guid"`
+	Name              string   `json:"name,omitempty"`
+	AppGUID           string   `json:"app_guid,omitempty"`
+	AppName           string   `json:"app_name,omitempty"`
+	OrgGUID           string   `json:"org_guid,omitempty"`
+	OrgName           string   `json:"org_name,omitempty"`
+	SpaceGUID         string   `json:"space_guid,omitempty"`
+	SpaceName         string   `json:"space_name,omitempty"`
+	Command           []string `json:"command,omitempty"`
+	Schedule          string   `json:"schedule"`
+	ConcurrencyPolicy string   `json:"concurrency_policy,omitempty"`
+	Suspend           bool     `json:"suspend"`
+	LastScheduledAt   int64    `json:"last_scheduled_at,omitempty"`
+	ActiveRuns        int      `json:"active_runs"`
+}
+
+type ScheduledTasksResponse []ScheduledTaskResponse
+
 type TaskCompletedRequest struct {
 	TaskGUID      string `json:"task_guid"`
 	Failed        bool   `json:"failed"`
 	FailureReason string `json:"failure_reason"`
+	Attempts      int    `json:"attempts,omitempty"`
+	Result        string `json:"result,omitempty"`
 }
 
 type StagingRequest struct {
@@ -123,6 +210,7 @@ type StagingRequest struct {
 	MemoryMB           int64                 `json:"memory_mb"`
 	DiskMB             int64                 `json:"disk_mb"`
 	CPUWeight          uint8                 `json:"cpu_weight"`
+	IsolationSegment   string                `json:"isolation_segment"`
 }
 
 type StagingCompletedRequest struct {
@@ -134,7 +222,8 @@ type StagingCompletedRequest struct {
 }
 
 type StagingLifecycle struct {
-	DockerLifecycle *StagingDockerLifecycle `json:"docker_lifecycle"`
+	DockerLifecycle    *StagingDockerLifecycle    `json:"docker_lifecycle"`
+	BuildpackLifecycle *StagingBuildpackLifecycle `json:"buildpack_lifecycle"`
 }
 
 type StagingDockerLifecycle struct {
@@ -143,6 +232,20 @@ type StagingDockerLifecycle struct {
 	RegistryPassword string `json:"registry_password"`
 }
 
+type StagingBuildpackLifecycle struct {
+	AppBitsDownloadURI string      `json:"app_bits_download_uri"`
+	DropletUploadURI   string      `json:"droplet_upload_uri"`
+	Buildpacks         []Buildpack `json:"buildpacks"`
+	Stack              string      `json:"stack"`
+}
+
+type Buildpack struct {
+	Name       string `json:"name"`
+	Key        string `json:"key"`
+	URL        string `json:"url"`
+	SkipDetect bool   `json:"skip_detect"`
+}
+
 type EnvironmentVariable struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -154,11 +257,87 @@ type UpdateDesiredLRPRequest struct {
 	Update  DesiredLRPUpdate `json:"update,omitempty"`
 }
 
+// DesiredLRPUpdate holds the changes to apply to a running LRP. Optional
+// fields that are not set leave the current value untouched.
 type DesiredLRPUpdate struct {
-	Instances  int                        `json:"instances"`
-	Routes     map[string]json.RawMessage `json:"routes"`
-	Annotation string                     `json:"annotation"`
-	Image      string                     `json:"image"`
+	Instances               int                        `json:"instances"`
+	Routes                  map[string]json.RawMessage `json:"routes"`
+	Annotation              string                     `json:"annotation"`
+	Image                   string                     `json:"image"`
+	Environment             map[string]string          `json:"environment,omitempty"`
+	Command                 []string                   `json:"command,omitempty"`
+	StartCommand            *string                    `json:"start_command,omitempty"`
+	MemoryMB                *int64                     `json:"memory_mb,omitempty"`
+	DiskMB                  *int64                     `json:"disk_mb,omitempty"`
+	CPUWeight               *uint8                     `json:"cpu_weight,omitempty"`
+	HealthCheckType         *string                    `json:"health_check_type,omitempty"`
+	HealthCheckHTTPEndpoint *string                    `json:"health_check_http_endpoint,omitempty"`
+	HealthCheckTimeoutMs    *uint                      `json:"health_check_timeout_ms,omitempty"`
+	HealthCheckPort         *int32                     `json:"health_check_port,omitempty"`
+	HealthCheckIntervalMs   *uint                      `json:"health_check_interval_ms,omitempty"`
+	HealthCheckThreshold    *int32                     `json:"health_check_failure_threshold,omitempty"`
+	StartTimeoutMs          *uint                      `json:"start_timeout_ms,omitempty"`
+	Sidecars                []Sidecar                  `json:"sidecars,omitempty"`
+	UserDefinedAnnotations  map[string]string          `json:"user_defined_annotations,omitempty"`
+	RegistryUsername        *string                    `json:"registry_username,omitempty"`
+	RegistryPassword        *string                    `json:"registry_password,omitempty"`
+}
+
+type Sidecar struct {
+	Name         string            `json:"name"`
+	Command      string            `json:"command"`
+	MemoryMB     int64             `json:"memory_mb"`
+	Environment  map[string]string `json:"environment"`
+	ProcessTypes []string          `json:"process_types"`
+	Ports        []int32           `json:"ports"`
+}
+
+// An EgressRule is an application security group rule as sent by the cloud
+// controller. Older cloud controllers send a single destination instead of
+// a list.
+type EgressRule struct {
+	Protocol     string     `json:"protocol"`
+	Destination  string     `json:"destination,omitempty"`
+	Destinations []string   `json:"destinations,omitempty"`
+	Ports        []int32    `json:"ports,omitempty"`
+	PortRange    *PortRange `json:"port_range,omitempty"`
+	IcmpInfo     *ICMPInfo  `json:"icmp_info,omitempty"`
+	Log          bool       `json:"log"`
+}
+
+type PortRange struct {
+	Start int32 `json:"start"`
+	End   int32 `json:"end"`
+}
+
+type ICMPInfo struct {
+	Type int32 `json:"type"`
+	Code int32 `json:"code"`
+}
+
+// A NetworkPolicy allows the instances of the source app to reach the
+// instances of the destination app on a range of ports.
+type NetworkPolicy struct {
+	Source      NetworkPolicySource      `json:"source"`
+	Destination NetworkPolicyDestination `json:"destination"`
+}
+
+type NetworkPolicySource struct {
+	ID string `json:"id"`
+}
+
+type NetworkPolicyDestination struct {
+	ID       string    `json:"id"`
+	Protocol string    `json:"protocol"`
+	Ports    PortRange `json:"ports"`
+}
+
+type NetworkPoliciesRequest struct {
+	Policies []NetworkPolicy `json:"policies"`
+}
+
+type NetworkPoliciesResponse struct {
+	Policies []NetworkPolicy `json:"policies"`
 }
 
 type GetInstancesResponse struct {
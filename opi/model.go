@@ -11,6 +11,16 @@ const (
 	CrashedState            = "CRASHED"
 	UnknownState            = "UNKNOWN"
 	InsufficientMemoryError = "Insufficient resources: memory"
+
+	TaskPendingState   = "PENDING"
+	TaskRunningState   = "RUNNING"
+	TaskSucceededState = "SUCCEEDED"
+	TaskFailedState    = "FAILED"
+	TaskCancelledState = "CANCELLED"
+
+	AllowConcurrentRuns   = "Allow"
+	ForbidConcurrentRuns  = "Forbid"
+	ReplaceConcurrentRuns = "Replace"
 )
 
 type LRPIdentifier struct {
@@ -37,6 +47,7 @@ type LRP struct {
 	Command                []string
 	Sidecars               []Sidecar
 	PrivateRegistry        *PrivateRegistry
+	DropletDownload        *DropletDownload
 	Env                    map[string]string
 	Health                 Healtcheck
 	Ports                  []int32
@@ -47,8 +58,11 @@ type LRP struct {
 	RunsAsRoot             bool
 	CPUWeight              uint8
 	VolumeMounts           []VolumeMount
+	PlacementTags          []string
+	EgressRules            []EgressRule
 	LRP                    string
 	AppURIs                []Route
+	InternalRoutes         []string
 	LastUpdated            string
 	UserDefinedAnnotations map[string]string
 }
@@ -58,6 +72,7 @@ type Sidecar struct {
 	Command  []string
 	MemoryMB int64
 	Env      map[string]string
+	Ports    []int32
 }
 
 type Route struct {
@@ -65,10 +80,50 @@ type Route struct {
 	Port     int32  `json:"port"`
 }
 
+// A PrivateRegistry either holds the registry credentials or names an
+// existing image pull secret, in which case no secret is generated for them.
 type PrivateRegistry struct {
-	Server   string
-	Username string
-	Password string
+	Server     string
+	Username   string
+	Password   string
+	SecretName string
+}
+
+// A DropletDownload describes a buildpack droplet that has to be downloaded
+// into the app container before the app process starts.
+type DropletDownload struct {
+	URL             string
+	DownloaderImage string
+}
+
+// An EgressRule allows outbound traffic from the app to the given
+// destinations. Destinations are CIDRs, single IPs or IP ranges such as
+// "10.0.0.1-10.0.0.10". An empty protocol or "all" allows every protocol.
+type EgressRule struct {
+	Protocol     string     `json:"protocol"`
+	Destinations []string   `json:"destinations"`
+	Ports        []int32    `json:"ports,omitempty"`
+	PortRange    *PortRange `json:"port_range,omitempty"`
+	IcmpInfo     *ICMPInfo  `json:"icmp_info,omitempty"`
+}
+
+type PortRange struct {
+	Start int32 `json:"start"`
+	End   int32 `json:"end"`
+}
+
+type ICMPInfo struct {
+	Type int32 `json:"type"`
+	Code int32 `json:"code"`
+}
+
+// An AppNetworkPolicy allows the instances of the source app to reach the
+// instances of the destination app on the given ports.
+type AppNetworkPolicy struct {
+	SourceAppGUID      string    `json:"source_app_guid"`
+	DestinationAppGUID string    `json:"destination_app_guid"`
+	Protocol           string    `json:"protocol"`
+	Ports              PortRange `json:"ports"`
 }
 
 type VolumeMount struct {
@@ -81,13 +136,29 @@ type Instance struct {
 	Since          int64
 	State          string
 	PlacementError string
+	LastCrash      *InstanceCrash
 }
 
+// An InstanceCrash describes the most recent termination of an instance
+// container.
+type InstanceCrash struct {
+	Reason     string
+	ExitStatus int
+	CrashCount int
+	Timestamp  int64
+}
+
+// A Healtcheck describes how the app is probed. TimeoutMs is the timeout of
+// a single check and StartTimeoutMs is how long the app may take to pass its
+// first check.
 type Healtcheck struct {
-	Type      string
-	Port      int32
-	Endpoint  string
-	TimeoutMs uint
+	Type             string `json:"type"`
+	Port             int32  `json:"port"`
+	Endpoint         string `json:"endpoint"`
+	TimeoutMs        uint   `json:"timeout_ms"`
+	StartTimeoutMs   uint   `json:"start_timeout_ms,omitempty"`
+	IntervalMs       uint   `json:"interval_ms,omitempty"`
+	FailureThreshold int32  `json:"failure_threshold,omitempty"`
 }
 
 // A Task is a one-off process that is run exactly once and returns a
@@ -98,6 +169,7 @@ type Task struct {
 	Image              string
 	CompletionCallback string
 	PrivateRegistry    *PrivateRegistry
+	DropletDownload    *DropletDownload
 	Env                map[string]string
 	Command            []string
 	AppName            string
@@ -109,4 +181,59 @@ type Task struct {
 	MemoryMB           int64
 	DiskMB             int64
 	CPUWeight          uint8
+	PlacementTags      []string
+	EgressRules        []EgressRule
+	TimeoutSeconds     int64
+	RetryPolicy        *TaskRetryPolicy
+	ResultFile         string
+	Status             TaskStatus
+}
+
+// A TaskRetryPolicy runs a failed task again, up to MaxAttempts times in
+// total, waiting at least BackoffSeconds between attempts. When
+// RetryOnExitCodes is empty any failure is retried.
+type TaskRetryPolicy struct {
+	MaxAttempts      int32
+	BackoffSeconds   int64
+	RetryOnExitCodes []int32
+}
+
+// A StagingTask is a Task that builds a droplet out of the app bits. It
+// downloads the bits, runs the buildpacks on them and uploads the result.
+type StagingTask struct {
+	*Task
+	DownloaderImage string
+	ExecutorImage   string
+	UploaderImage   string
+}
+
+// A ScheduledTask is a Task that is run repeatedly on a cron schedule. Every
+// run reports its completion to the task's completion callback.
+type ScheduledTask struct {
+	*Task
+	Schedule                   string
+	ConcurrencyPolicy          string
+	Suspend                    bool
+	SuccessfulRunsHistoryLimit *int32
+	FailedRunsHistoryLimit     *int32
+	LastScheduledAt            int64
+	ActiveRuns                 int
+}
+
+type TaskStatus struct {
+	State         string
+	StartedAt     int64
+	FinishedAt    int64
+	ExitCode      *int32
+	FailureReason string
+	Attempts      []TaskAttempt
+}
+
+// A TaskAttempt is a single run of a task. Tasks with a retry policy can have
+// several of them.
+type TaskAttempt struct {
+	StartedAt     int64
+	FinishedAt    int64
+	ExitCode      *int32
+	FailureReason string
 }
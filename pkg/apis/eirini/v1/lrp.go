@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -40,13 +42,42 @@ type LRPSpec struct {
 	RunsAsRoot             bool              `json:"runsAsRoot"`
 	CPUWeight              uint8             `json:"cpuWeight"`
 	VolumeMounts           []VolumeMount     `json:"volumeMounts,omitempty"`
+	PlacementTags          []string          `json:"placementTags,omitempty"`
+	EgressRules            []EgressRule      `json:"egressRules,omitempty"`
 	LastUpdated            string            `json:"lastUpdated"`
 	UserDefinedAnnotations map[string]string `json:"userDefinedAnnotations,omitempty"`
 	AppRoutes              []Route           `json:"appRoutes"`
+	InternalRoutes         []string          `json:"internalRoutes,omitempty"`
 }
 
+const (
+	LRPReadyConditionType       = "Ready"
+	LRPProgressingConditionType = "Progressing"
+	LRPDegradedConditionType    = "Degraded"
+)
+
 type LRPStatus struct {
-	Replicas int32 `json:"replicas"`
+	ObservedGeneration int64               `json:"observedGeneration,omitempty"`
+	Replicas           int32               `json:"replicas"`
+	DesiredInstances   int32               `json:"desiredInstances"`
+	ReadyInstances     int32               `json:"readyInstances"`
+	Instances          []InstanceStatus    `json:"instances,omitempty"`
+	Conditions         []meta_v1.Condition `json:"conditions,omitempty"`
+}
+
+type InstanceStatus struct {
+	Index          int            `json:"index"`
+	State          string         `json:"state"`
+	Since          *meta_v1.Time  `json:"since,omitempty"`
+	PlacementError string         `json:"placementError,omitempty"`
+	LastCrash      *InstanceCrash `json:"lastCrash,omitempty"`
+}
+
+type InstanceCrash struct {
+	Reason     string       `json:"reason"`
+	ExitStatus int          `json:"exitStatus"`
+	CrashCount int          `json:"crashCount"`
+	Timestamp  meta_v1.Time `json:"timestamp"`
 }
 
 type Route struct {
@@ -59,12 +90,57 @@ type Sidecar struct {
 	Command  []string          `json:"command"`
 	MemoryMB int64             `json:"memoryMB"`
 	Env      map[string]string `json:"env,omitempty"`
+	Ports    []int32           `json:"ports,omitempty"`
+}
+
+type EgressRule struct {
+	Protocol     string     `json:"protocol"`
+	Destinations []string   `json:"destinations"`
+	Ports        []int32    `json:"ports,omitempty"`
+	PortRange    *PortRange `json:"portRange,omitempty"`
+	IcmpInfo     *ICMPInfo  `json:"icmpInfo,omitempty"`
+}
+
+type PortRange struct {
+	Start int32 `json:"start"`
+	End   int32 `json:"end"`
+}
+
+type ICMPInfo struct {
+	Type int32 `json:"type"`
+	Code int32 `json:"code"`
 }
 
+// PrivateRegistry either holds the credentials for the registry the image is
+// pulled from, or references an existing dockerconfigjson Secret in the
+// namespace of the workload.
 type PrivateRegistry struct {
-	Server   string `json:"server"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Server    string           `json:"server,omitempty"`
+	Username  string           `json:"username,omitempty"`
+	Password  string           `json:"password,omitempty"`
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+type SecretReference struct {
+	Name string `json:"name"`
+}
+
+// Validate rejects registries that set both a secret reference and plain
+// credentials, as it would be unclear which of them is used.
+func (r *PrivateRegistry) Validate() error {
+	if r == nil || r.SecretRef == nil {
+		return nil
+	}
+
+	if r.SecretRef.Name == "" {
+		return errors.New("privateRegistry.secretRef.name must not be empty")
+	}
+
+	if r.Server != "" || r.Username != "" || r.Password != "" {
+		return errors.New("privateRegistry.secretRef cannot be combined with server, username and password")
+	}
+
+	return nil
 }
 
 type VolumeMount struct {
@@ -73,10 +149,13 @@ type VolumeMount struct {
 }
 
 type Healtcheck struct {
-	Type      string `json:"type"`
-	Port      int32  `json:"port"`
-	Endpoint  string `json:"endpoint"`
-	TimeoutMs uint   `json:"timeoutMs"`
+	Type             string `json:"type"`
+	Port             int32  `json:"port"`
+	Endpoint         string `json:"endpoint"`
+	TimeoutMs        uint   `json:"timeoutMs"`
+	StartTimeoutMs   uint   `json:"startTimeoutMs,omitempty"`
+	IntervalMs       uint   `json:"intervalMs,omitempty"`
+	FailureThreshold int32  `json:"failureThreshold,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
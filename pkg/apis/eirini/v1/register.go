@@ -32,6 +32,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&LRPList{},
 		&Task{},
 		&TaskList{},
+		&ScheduledTask{},
+		&ScheduledTaskList{},
 	)
 
 	meta_v1.AddToGroupVersion(scheme, SchemeGroupVersion)
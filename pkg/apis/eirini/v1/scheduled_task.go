@@ -0,0 +1,51 @@
+package v1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+
+// ScheduledTask describes a task that is run repeatedly on a cron schedule
+type ScheduledTask struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledTaskSpec   `json:"spec"`
+	Status ScheduledTaskStatus `json:"status,omitempty"`
+}
+
+type ScheduledTaskSpec struct {
+	Schedule                   string            `json:"schedule"`
+	ConcurrencyPolicy          ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	Suspend                    bool              `json:"suspend,omitempty"`
+	SuccessfulRunsHistoryLimit *int32            `json:"successfulRunsHistoryLimit,omitempty"`
+	FailedRunsHistoryLimit     *int32            `json:"failedRunsHistoryLimit,omitempty"`
+	Task                       TaskSpec          `json:"task"`
+}
+
+// ConcurrencyPolicy tells what to do when a run is due while the previous one
+// is still running
+type ConcurrencyPolicy string
+
+const (
+	AllowConcurrent   ConcurrencyPolicy = "Allow"
+	ForbidConcurrent  ConcurrencyPolicy = "Forbid"
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+type ScheduledTaskStatus struct {
+	LastScheduleTime *meta_v1.Time `json:"lastScheduleTime,omitempty"`
+	ActiveRuns       int32         `json:"activeRuns,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ScheduledTaskList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+
+	Items []ScheduledTask `json:"items"`
+}
@@ -5,15 +5,16 @@ import (
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
 
 // Task describes a short-lived job running alongside an LRP
 type Task struct {
 	meta_v1.TypeMeta   `json:",inline"`
 	meta_v1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec TaskSpec `json:"spec"`
+	Spec   TaskSpec   `json:"spec"`
+	Status TaskStatus `json:"status,omitempty"`
 }
 
 type TaskSpec struct {
@@ -33,6 +34,48 @@ type TaskSpec struct {
 	MemoryMB           int64             `json:"memoryMB"`
 	DiskMB             int64             `json:"diskMB"`
 	CPUWeight          uint8             `json:"cpuWeight"`
+	IsolationSegment   string            `json:"isolationSegment,omitempty"`
+	EgressRules        []EgressRule      `json:"egressRules,omitempty"`
+	TimeoutSeconds     int64             `json:"timeoutSeconds,omitempty"`
+	RetryPolicy        *TaskRetryPolicy  `json:"retryPolicy,omitempty"`
+	ResultFile         string            `json:"resultFile,omitempty"`
+}
+
+type TaskRetryPolicy struct {
+	MaxAttempts      int32   `json:"maxAttempts"`
+	BackoffSeconds   int64   `json:"backoffSeconds,omitempty"`
+	RetryOnExitCodes []int32 `json:"retryOnExitCodes,omitempty"`
+}
+
+type TaskPhase string
+
+const (
+	TaskPending   TaskPhase = "Pending"
+	TaskRunning   TaskPhase = "Running"
+	TaskSucceeded TaskPhase = "Succeeded"
+	TaskFailed    TaskPhase = "Failed"
+	TaskCancelled TaskPhase = "Cancelled"
+
+	TaskStartedConditionType   = "Started"
+	TaskSucceededConditionType = "Succeeded"
+	TaskFailedConditionType    = "Failed"
+)
+
+type TaskStatus struct {
+	Phase         TaskPhase           `json:"phase,omitempty"`
+	StartTime     *meta_v1.Time       `json:"startTime,omitempty"`
+	EndTime       *meta_v1.Time       `json:"endTime,omitempty"`
+	ExitCode      *int32              `json:"exitCode,omitempty"`
+	FailureReason string              `json:"failureReason,omitempty"`
+	Attempts      []TaskAttempt       `json:"attempts,omitempty"`
+	Conditions    []meta_v1.Condition `json:"conditions,omitempty"`
+}
+
+type TaskAttempt struct {
+	StartTime     *meta_v1.Time `json:"startTime,omitempty"`
+	EndTime       *meta_v1.Time `json:"endTime,omitempty"`
+	ExitCode      *int32        `json:"exitCode,omitempty"`
+	FailureReason string        `json:"failureReason,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -27,6 +27,7 @@ import (
 type EiriniV1Interface interface {
 	RESTClient() rest.Interface
 	LRPsGetter
+	ScheduledTasksGetter
 	TasksGetter
 }
 
@@ -39,6 +40,10 @@ func (c *EiriniV1Client) LRPs(namespace string) LRPInterface {
 	return newLRPs(c, namespace)
 }
 
+func (c *EiriniV1Client) ScheduledTasks(namespace string) ScheduledTaskInterface {
+	return newScheduledTasks(c, namespace)
+}
+
 func (c *EiriniV1Client) Tasks(namespace string) TaskInterface {
 	return newTasks(c, namespace)
 }
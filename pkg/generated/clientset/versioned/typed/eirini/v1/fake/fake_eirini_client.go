@@ -32,6 +32,10 @@ func (c *FakeEiriniV1) LRPs(namespace string) v1.LRPInterface {
 	return &FakeLRPs{c, namespace}
 }
 
+func (c *FakeEiriniV1) ScheduledTasks(namespace string) v1.ScheduledTaskInterface {
+	return &FakeScheduledTasks{c, namespace}
+}
+
 func (c *FakeEiriniV1) Tasks(namespace string) v1.TaskInterface {
 	return &FakeTasks{c, namespace}
 }
@@ -0,0 +1,142 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	eiriniv1 "code.cloudfoundry.org/eirini/pkg/apis/eirini/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeScheduledTasks implements ScheduledTaskInterface
+type FakeScheduledTasks struct {
+	Fake *FakeEiriniV1
+	ns   string
+}
+
+var scheduledtasksResource = schema.GroupVersionResource{Group: "eirini.cloudfoundry.org", Version: "v1", Resource: "scheduledtasks"}
+
+var scheduledtasksKind = schema.GroupVersionKind{Group: "eirini.cloudfoundry.org", Version: "v1", Kind: "ScheduledTask"}
+
+// Get takes name of the scheduledTask, and returns the corresponding scheduledTask object, and an error if there is any.
+func (c *FakeScheduledTasks) Get(ctx context.Context, name string, options v1.GetOptions) (result *eiriniv1.ScheduledTask, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(scheduledtasksResource, c.ns, name), &eiriniv1.ScheduledTask{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*eiriniv1.ScheduledTask), err
+}
+
+// List takes label and field selectors, and returns the list of ScheduledTasks that match those selectors.
+func (c *FakeScheduledTasks) List(ctx context.Context, opts v1.ListOptions) (result *eiriniv1.ScheduledTaskList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(scheduledtasksResource, scheduledtasksKind, c.ns, opts), &eiriniv1.ScheduledTaskList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &eiriniv1.ScheduledTaskList{ListMeta: obj.(*eiriniv1.ScheduledTaskList).ListMeta}
+	for _, item := range obj.(*eiriniv1.ScheduledTaskList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested scheduledTasks.
+func (c *FakeScheduledTasks) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(scheduledtasksResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a scheduledTask and creates it.  Returns the server's representation of the scheduledTask, and an error, if there is any.
+func (c *FakeScheduledTasks) Create(ctx context.Context, scheduledTask *eiriniv1.ScheduledTask, opts v1.CreateOptions) (result *eiriniv1.ScheduledTask, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(scheduledtasksResource, c.ns, scheduledTask), &eiriniv1.ScheduledTask{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*eiriniv1.ScheduledTask), err
+}
+
+// Update takes the representation of a scheduledTask and updates it. Returns the server's representation of the scheduledTask, and an error, if there is any.
+func (c *FakeScheduledTasks) Update(ctx context.Context, scheduledTask *eiriniv1.ScheduledTask, opts v1.UpdateOptions) (result *eiriniv1.ScheduledTask, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(scheduledtasksResource, c.ns, scheduledTask), &eiriniv1.ScheduledTask{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*eiriniv1.ScheduledTask), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeScheduledTasks) UpdateStatus(ctx context.Context, scheduledTask *eiriniv1.ScheduledTask, opts v1.UpdateOptions) (*eiriniv1.ScheduledTask, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(scheduledtasksResource, "status", c.ns, scheduledTask), &eiriniv1.ScheduledTask{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*eiriniv1.ScheduledTask), err
+}
+
+// Delete takes name of the scheduledTask and deletes it. Returns an error if one occurs.
+func (c *FakeScheduledTasks) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(scheduledtasksResource, c.ns, name), &eiriniv1.ScheduledTask{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeScheduledTasks) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(scheduledtasksResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &eiriniv1.ScheduledTaskList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched scheduledTask.
+func (c *FakeScheduledTasks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *eiriniv1.ScheduledTask, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(scheduledtasksResource, c.ns, name, pt, data, subresources...), &eiriniv1.ScheduledTask{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*eiriniv1.ScheduledTask), err
+}
@@ -20,4 +20,6 @@ package v1
 
 type LRPExpansion interface{}
 
+type ScheduledTaskExpansion interface{}
+
 type TaskExpansion interface{}
@@ -0,0 +1,195 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "code.cloudfoundry.org/eirini/pkg/apis/eirini/v1"
+	scheme "code.cloudfoundry.org/eirini/pkg/generated/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ScheduledTasksGetter has a method to return a ScheduledTaskInterface.
+// A group's client should implement this interface.
+type ScheduledTasksGetter interface {
+	ScheduledTasks(namespace string) ScheduledTaskInterface
+}
+
+// ScheduledTaskInterface has methods to work with ScheduledTask resources.
+type ScheduledTaskInterface interface {
+	Create(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.CreateOptions) (*v1.ScheduledTask, error)
+	Update(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.UpdateOptions) (*v1.ScheduledTask, error)
+	UpdateStatus(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.UpdateOptions) (*v1.ScheduledTask, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ScheduledTask, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.ScheduledTaskList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.ScheduledTask, err error)
+	ScheduledTaskExpansion
+}
+
+// scheduledTasks implements ScheduledTaskInterface
+type scheduledTasks struct {
+	client rest.Interface
+	ns     string
+}
+
+// newScheduledTasks returns a ScheduledTasks
+func newScheduledTasks(c *EiriniV1Client, namespace string) *scheduledTasks {
+	return &scheduledTasks{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the scheduledTask, and returns the corresponding scheduledTask object, and an error if there is any.
+func (c *scheduledTasks) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.ScheduledTask, err error) {
+	result = &v1.ScheduledTask{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ScheduledTasks that match those selectors.
+func (c *scheduledTasks) List(ctx context.Context, opts metav1.ListOptions) (result *v1.ScheduledTaskList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.ScheduledTaskList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested scheduledTasks.
+func (c *scheduledTasks) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a scheduledTask and creates it.  Returns the server's representation of the scheduledTask, and an error, if there is any.
+func (c *scheduledTasks) Create(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.CreateOptions) (result *v1.ScheduledTask, err error) {
+	result = &v1.ScheduledTask{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(scheduledTask).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a scheduledTask and updates it. Returns the server's representation of the scheduledTask, and an error, if there is any.
+func (c *scheduledTasks) Update(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.UpdateOptions) (result *v1.ScheduledTask, err error) {
+	result = &v1.ScheduledTask{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		Name(scheduledTask.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(scheduledTask).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *scheduledTasks) UpdateStatus(ctx context.Context, scheduledTask *v1.ScheduledTask, opts metav1.UpdateOptions) (result *v1.ScheduledTask, err error) {
+	result = &v1.ScheduledTask{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		Name(scheduledTask.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(scheduledTask).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the scheduledTask and deletes it. Returns an error if one occurs.
+func (c *scheduledTasks) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *scheduledTasks) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched scheduledTask.
+func (c *scheduledTasks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.ScheduledTask, err error) {
+	result = &v1.ScheduledTask{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("scheduledtasks").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
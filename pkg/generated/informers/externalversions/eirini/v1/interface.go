@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// LRPs returns a LRPInformer.
 	LRPs() LRPInformer
+	// ScheduledTasks returns a ScheduledTaskInformer.
+	ScheduledTasks() ScheduledTaskInformer
 	// Tasks returns a TaskInformer.
 	Tasks() TaskInformer
 }
@@ -46,6 +48,11 @@ func (v *version) LRPs() LRPInformer {
 	return &lRPInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ScheduledTasks returns a ScheduledTaskInformer.
+func (v *version) ScheduledTasks() ScheduledTaskInformer {
+	return &scheduledTaskInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Tasks returns a TaskInformer.
 func (v *version) Tasks() TaskInformer {
 	return &taskInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
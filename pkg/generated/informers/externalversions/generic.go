@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=eirini.cloudfoundry.org, Version=v1
 	case v1.SchemeGroupVersion.WithResource("lrps"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Eirini().V1().LRPs().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("scheduledtasks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Eirini().V1().ScheduledTasks().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("tasks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Eirini().V1().Tasks().Informer()}, nil
 
@@ -26,6 +26,14 @@ type LRPListerExpansion interface{}
 // LRPNamespaceLister.
 type LRPNamespaceListerExpansion interface{}
 
+// ScheduledTaskListerExpansion allows custom methods to be added to
+// ScheduledTaskLister.
+type ScheduledTaskListerExpansion interface{}
+
+// ScheduledTaskNamespaceListerExpansion allows custom methods to be added to
+// ScheduledTaskNamespaceLister.
+type ScheduledTaskNamespaceListerExpansion interface{}
+
 // TaskListerExpansion allows custom methods to be added to
 // TaskLister.
 type TaskListerExpansion interface{}
@@ -102,6 +102,34 @@ var _ = Describe("Pod", func() {
 		})
 	})
 
+	Describe("GetByTaskGUID", func() {
+		var guid string
+
+		BeforeEach(func() {
+			createTaskPods(fixture.Namespace, "one", "two")
+
+			guid = tests.GenerateGUID()
+
+			createPod(fixture.Namespace, "three", map[string]string{
+				k8s.LabelGUID:       guid,
+				k8s.LabelSourceType: "TASK",
+			})
+			createPod(fixture.Namespace, "four", map[string]string{
+				k8s.LabelGUID:       guid,
+				k8s.LabelSourceType: "APP",
+			})
+		})
+
+		It("lists the task pods with the specified guid", func() {
+			Eventually(func() []string {
+				pods, err := podClient.GetByTaskGUID(guid)
+				Expect(err).NotTo(HaveOccurred())
+
+				return podNames(pods)
+			}).Should(ConsistOf("three"))
+		})
+	})
+
 	Describe("Delete", func() {
 		BeforeEach(func() {
 			createLrpPods(fixture.Namespace, "foo")
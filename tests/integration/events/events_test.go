@@ -89,11 +89,14 @@ var _ = Describe("Events", func() {
 				Secrets:                   client.NewSecret(fixture.Clientset),
 				StatefulSets:              client.NewStatefulSet(fixture.Clientset, fixture.Namespace),
 				PodDisruptionBudgets:      client.NewPodDisruptionBudget(fixture.Clientset),
+				NetworkPolicies:           client.NewNetworkPolicy(fixture.Clientset, fixture.Namespace),
+				Services:                  client.NewService(fixture.Clientset),
 				EventsClient:              client.NewEvent(fixture.Clientset),
 				StatefulSetToLRPMapper:    k8s.StatefulSetToLRP,
 				RegistrySecretName:        "registry-secret",
 				LivenessProbeCreator:      k8s.CreateLivenessProbe,
 				ReadinessProbeCreator:     k8s.CreateReadinessProbe,
+				StartupProbeCreator:       k8s.CreateStartupProbe,
 				Logger:                    logger,
 				ApplicationServiceAccount: tests.GetApplicationServiceAccount(),
 			}
@@ -232,10 +235,15 @@ var _ = Describe("Events", func() {
 			taskDesirer = k8s.NewTaskDesirer(
 				logger,
 				client.NewJob(fixture.Clientset, fixture.Namespace),
+				client.NewPod(fixture.Clientset, fixture.Namespace),
 				nil,
+				client.NewNetworkPolicy(fixture.Clientset, fixture.Namespace),
 				tests.GetApplicationServiceAccount(),
 				"",
 				false,
+				nil,
+				nil,
+				k8s.TaskTimeouts{},
 			)
 		})
 
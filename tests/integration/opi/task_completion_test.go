@@ -11,10 +11,12 @@ import (
 	"code.cloudfoundry.org/eirini"
 	"code.cloudfoundry.org/eirini/k8s"
 	"code.cloudfoundry.org/eirini/models/cf"
+	"code.cloudfoundry.org/eirini/opi"
 	"code.cloudfoundry.org/eirini/tests"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/gstruct"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -68,18 +70,31 @@ var _ = Describe("Tasks completion", func() {
 		Eventually(taskReporterSession.Terminate()).Should(gexec.Exit())
 	})
 
-	It("does not list completed tasks that have not reached their ttl", func() {
-		Eventually(listTasks).Should(BeEmpty())
-		Expect(getJob(taskGUID)).NotTo(BeNil())
+	JustBeforeEach(func() {
+		Eventually(func() map[string]string {
+			job := getJob(taskGUID)
+			if job == nil {
+				return nil
+			}
+
+			return job.Labels
+		}).Should(HaveKeyWithValue(k8s.LabelTaskCompleted, k8s.TaskCompletedTrue))
 	})
 
-	It("does not get a completed tasks that has not reached its ttl", func() {
-		Eventually(func() error {
-			_, err := getTask(taskGUID)
+	It("lists completed tasks with their status until they reach their ttl", func() {
+		tasks, err := listTasks()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+			"GUID":  Equal(taskGUID),
+			"State": Equal(opi.TaskSucceededState),
+		})))
+	})
 
-			return err
-		}).Should(MatchError(ContainSubstring("404 Not Found")))
-		Expect(getJob(taskGUID)).NotTo(BeNil())
+	It("gets a completed task with its status until it reaches its ttl", func() {
+		task, err := getTask(taskGUID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.GUID).To(Equal(taskGUID))
+		Expect(task.State).To(Equal(opi.TaskSucceededState))
 	})
 })
 
@@ -426,7 +426,8 @@ var _ = Describe("Tasks", func() {
 			err = json.NewDecoder(resp.Body).Decode(&tasks)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(tasks).To(ConsistOf(cf.TaskResponse{GUID: request.GUID}))
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].GUID).To(Equal(request.GUID))
 		})
 
 		When("the task is marked as completed", func() {
@@ -447,7 +448,7 @@ var _ = Describe("Tasks", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 
-			It("does not return it", func() {
+			It("still returns it", func() {
 				httpRequest, err := http.NewRequest("GET", fmt.Sprintf("%s/tasks", url), nil)
 				Expect(err).NotTo(HaveOccurred())
 				resp, err := httpClient.Do(httpRequest)
@@ -459,7 +460,8 @@ var _ = Describe("Tasks", func() {
 				err = json.NewDecoder(resp.Body).Decode(&tasks)
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(tasks).To(BeEmpty())
+				Expect(tasks).To(HaveLen(1))
+				Expect(tasks[0].GUID).To(Equal(request.GUID))
 			})
 		})
 	})
@@ -44,11 +44,14 @@ var _ = Describe("StatefulSetDesirer", func() {
 			Secrets:                   client.NewSecret(fixture.Clientset),
 			StatefulSets:              client.NewStatefulSet(fixture.Clientset, fixture.Namespace),
 			PodDisruptionBudgets:      client.NewPodDisruptionBudget(fixture.Clientset),
+			NetworkPolicies:           client.NewNetworkPolicy(fixture.Clientset, fixture.Namespace),
+			Services:                  client.NewService(fixture.Clientset),
 			EventsClient:              client.NewEvent(fixture.Clientset),
 			StatefulSetToLRPMapper:    k8s.StatefulSetToLRP,
 			RegistrySecretName:        "registry-secret",
 			LivenessProbeCreator:      k8s.CreateLivenessProbe,
 			ReadinessProbeCreator:     k8s.CreateReadinessProbe,
+			StartupProbeCreator:       k8s.CreateStartupProbe,
 			Logger:                    logger,
 			ApplicationServiceAccount: tests.GetApplicationServiceAccount(),
 		}
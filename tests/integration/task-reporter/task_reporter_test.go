@@ -2,6 +2,7 @@ package task_reporter_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -21,6 +22,7 @@ import (
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
+	"github.com/onsi/gomega/types"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -66,10 +68,15 @@ var _ = Describe("TaskReporter", func() {
 		taskDesirer = k8s.NewTaskDesirer(
 			lagertest.NewTestLogger("test-task-desirer"),
 			client.NewJob(fixture.Clientset, fixture.Namespace),
+			client.NewPod(fixture.Clientset, fixture.Namespace),
 			client.NewSecret(fixture.Clientset),
+			client.NewNetworkPolicy(fixture.Clientset, fixture.Namespace),
 			"",
 			"",
 			false,
+			nil,
+			nil,
+			k8s.TaskTimeouts{},
 		)
 
 		taskGUID := tests.GenerateGUID()
@@ -92,7 +99,7 @@ var _ = Describe("TaskReporter", func() {
 		handlers = []http.HandlerFunc{
 			ghttp.CombineHandlers(
 				ghttp.VerifyRequest("POST", "/the-callback"),
-				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{TaskGUID: taskGUID}),
+				ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{TaskGUID: taskGUID, Attempts: 1}),
 			),
 		}
 	})
@@ -166,8 +173,9 @@ var _ = Describe("TaskReporter", func() {
 					ghttp.VerifyRequest("POST", "/the-callback"),
 					ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
 						TaskGUID:      task.GUID,
+						Attempts:      1,
 						Failed:        true,
-						FailureReason: "Error",
+						FailureReason: "Error (exit code 1)",
 					}),
 				),
 			}
@@ -183,6 +191,94 @@ var _ = Describe("TaskReporter", func() {
 		})
 	})
 
+	When("a failing task can be retried", func() {
+		BeforeEach(func() {
+			task.Command = []string{"false"}
+			task.RetryPolicy = &opi.TaskRetryPolicy{
+				MaxAttempts:    2,
+				BackoffSeconds: 1,
+			}
+
+			handlers = []http.HandlerFunc{
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/the-callback"),
+					ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+						TaskGUID:      task.GUID,
+						Attempts:      2,
+						Failed:        true,
+						FailureReason: "Error (exit code 1)",
+					}),
+				),
+			}
+		})
+
+		It("notifies the cloud controller once the last attempt has failed", func() {
+			Eventually(cloudControllerServer.ReceivedRequests, "2m").Should(HaveLen(1))
+			Consistently(cloudControllerServer.ReceivedRequests, "10s").Should(HaveLen(1))
+		})
+	})
+
+	When("a task writes a result file", func() {
+		BeforeEach(func() {
+			task.Command = []string{"sh", "-c", "echo -n 42 > /tmp/result"}
+			task.ResultFile = "/tmp/result"
+
+			handlers = []http.HandlerFunc{
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/the-callback"),
+					ghttp.VerifyJSONRepresenting(cf.TaskCompletedRequest{
+						TaskGUID: task.GUID,
+						Attempts: 1,
+						Result:   "42",
+					}),
+				),
+			}
+		})
+
+		It("notifies the cloud controller of the result", func() {
+			Eventually(cloudControllerServer.ReceivedRequests).Should(HaveLen(1))
+		})
+	})
+
+	When("a task exceeds its timeout", func() {
+		BeforeEach(func() {
+			task.Command = []string{"sleep", "100"}
+			task.TimeoutSeconds = 5
+
+			handlers = []http.HandlerFunc{
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/the-callback"),
+					verifyTaskFailure(MatchRegexp(`^DeadlineExceeded \(exit code \d+\): task exceeded its timeout$`)),
+				),
+			}
+		})
+
+		It("notifies the cloud controller of the timeout", func() {
+			Eventually(cloudControllerServer.ReceivedRequests, "1m").Should(HaveLen(1))
+		})
+	})
+
+	When("the task image cannot be pulled", func() {
+		BeforeEach(func() {
+			task.Image = "eirini/does-not-exist-" + tests.GenerateGUID()
+
+			handlers = []http.HandlerFunc{
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/the-callback"),
+					verifyTaskFailure(MatchRegexp(`^(ErrImagePull|ImagePullBackOff): `)),
+				),
+			}
+		})
+
+		It("notifies the cloud controller of the failure", func() {
+			Eventually(cloudControllerServer.ReceivedRequests, "3m").Should(HaveLen(1))
+		})
+
+		It("deletes the job", func() {
+			Eventually(getTaskJobsFn(task.GUID), "3m").Should(BeEmpty())
+		})
+	})
+
 	When("the completion callback fails", func() {
 		BeforeEach(func() {
 			cloudControllerServer.SetAllowUnhandledRequests(true)
@@ -292,3 +388,12 @@ func getTasksWithSelector(selector string) ([]batchv1.Job, error) {
 
 	return jobs.Items, err
 }
+
+func verifyTaskFailure(failureReasonMatcher types.GomegaMatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var request cf.TaskCompletedRequest
+		Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+		Expect(request.Failed).To(BeTrue())
+		Expect(request.FailureReason).To(failureReasonMatcher)
+	}
+}